@@ -4,6 +4,9 @@ import (
 	"context"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
@@ -13,6 +16,11 @@ import (
 	"saas-api/internal/db"
 	httpserver "saas-api/internal/http"
 	"saas-api/internal/http/handlers"
+	"saas-api/internal/jwtkeys"
+	"saas-api/internal/lifecycle"
+	"saas-api/internal/mail"
+	"saas-api/internal/metrics"
+	"saas-api/internal/tracing"
 )
 
 func main() {
@@ -26,20 +34,77 @@ func main() {
 		log.Fatal().Err(err).Msg("config load failed")
 	}
 
+	// SIGTERM (deploy no Render/Railway) ou Ctrl+C inicia o encerramento
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	app := lifecycle.New(log.Logger, time.Duration(cfg.ShutdownTimeoutSeconds)*time.Second)
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		Exporter:    cfg.TracingExporter,
+		ServiceName: cfg.ServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("tracing setup failed")
+	}
+	app.OnStop("tracing", shutdownTracing)
+
 	database, err := db.NewMySQL(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPass, cfg.DBName)
 	if err != nil {
 		log.Fatal().Err(err).Msg("db connection failed")
 	}
-	defer database.Close()
+	app.OnStop("database", func(context.Context) error { return database.Close() })
+	if err := metrics.RegisterDB(database.DB, cfg.DBName); err != nil {
+		log.Fatal().Err(err).Msg("db metrics setup failed")
+	}
 
 	if cfg.RunMigrations {
 		log.Info().Msg("running migrations")
-		if err := db.Migrate(context.Background(), database); err != nil {
+		if err := db.Migrate(ctx, database); err != nil {
 			log.Fatal().Err(err).Msg("migrations failed")
 		}
 	}
 
-	router := httpserver.NewRouter(database, log.Logger, []byte(cfg.JWTSecret), cfg.JWTIssuer, cfg.JWTTTLMinutes)
+	mailer, err := mail.New(mail.Options{
+		Driver:   cfg.MailDriver,
+		From:     cfg.MailFrom,
+		SMTPHost: cfg.SMTPHost,
+		SMTPPort: cfg.SMTPPort,
+		SMTPUser: cfg.SMTPUser,
+		SMTPPass: cfg.SMTPPass,
+		FileDir:  cfg.MailFileDir,
+	}, log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("mailer setup failed")
+	}
+
+	keys, err := jwtkeys.Load(jwtkeys.Options{
+		Dir:           cfg.JWTKeysDir,
+		SigningKeyPEM: cfg.JWTSigningKey,
+		SigningKeyID:  cfg.JWTSigningKeyID,
+		HMACSecret:    cfg.JWTSecret,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("jwt keys setup failed")
+	}
+	if kid := keys.SigningKeyID(); kid != "" {
+		log.Info().Str("kid", kid).Strs("algs", keys.ValidMethods()).Msg("jwt signing key loaded")
+	} else {
+		log.Warn().Msg("jwt signing with legacy HS256 JWT_SECRET")
+	}
+
+	// heartbeat do scheduler para o readiness (nil com o sync desligado)
+	var schedulerBeat *handlers.Heartbeat
+	if cfg.ClockifyAutoSyncEnabled {
+		schedulerBeat = &handlers.Heartbeat{}
+	}
+	// tarefas das requisicoes que rodam depois da resposta (email de reset);
+	// registrado depois do banco para esvaziar a fila antes de fecha-lo
+	worker := lifecycle.NewWorker(256, 4)
+	worker.Start()
+	app.OnStop("background_worker", worker.Stop)
+
+	router := httpserver.NewRouter(database, log.Logger, cfg, mailer, keys, schedulerBeat, worker)
 
 	if cfg.ClockifyAutoSyncEnabled {
 		log.Info().
@@ -47,16 +112,22 @@ func main() {
 			Int("lookback_days", cfg.ClockifyAutoSyncLookbackDays).
 			Msg("clockify auto sync scheduler enabled")
 
-		go handlers.StartClockifyAutoSyncScheduler(
-			context.Background(),
-			&handlers.HRHandler{DB: database},
-			cfg.ClockifyAutoSyncHourUTC,
-			cfg.ClockifyAutoSyncLookbackDays,
-		)
+		hr := &handlers.HRHandler{DB: database}
+		app.Go("clockify_auto_sync", func(ctx context.Context) {
+			handlers.StartClockifyAutoSyncScheduler(ctx, hr, cfg.ClockifyAutoSyncHourUTC, cfg.ClockifyAutoSyncLookbackDays, schedulerBeat)
+		})
 	}
 
-	log.Info().Str("addr", cfg.HTTPAddr).Msg("api listening")
-	if err := http.ListenAndServe(cfg.HTTPAddr, router); err != nil {
-		log.Fatal().Err(err).Msg("server failed")
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Duration(cfg.HTTPReadTimeoutSeconds) * time.Second,
+		WriteTimeout:      time.Duration(cfg.HTTPWriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(cfg.HTTPIdleTimeoutSeconds) * time.Second,
+	}
+	if err := app.Run(ctx, srv); err != nil {
+		log.Error().Err(err).Msg("server failed")
+		os.Exit(1)
 	}
 }
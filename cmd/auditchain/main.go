@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"saas-api/internal/auditchain"
+	"saas-api/internal/config"
+	"saas-api/internal/db"
+)
+
+// Verifica a cadeia de hashes de audit_logs direto no banco (DB_* / MYSQL*).
+// Sai com codigo 2 quando alguma cadeia esta quebrada.
+func main() {
+	var (
+		tenantID uint64
+		all      bool
+		platform bool
+		asJSON   bool
+	)
+	flag.Uint64Var(&tenantID, "tenant", 0, "Tenant a verificar (0 = cadeia antiga da plataforma)")
+	flag.BoolVar(&all, "all", false, "Verifica todas as cadeias")
+	flag.BoolVar(&platform, "platform", false, "Verifica as cadeias sem tenant (login, reset de senha)")
+	flag.BoolVar(&asJSON, "json", false, "Saida em JSON (um relatorio por linha)")
+	flag.Parse()
+
+	_ = godotenv.Load()
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		fatalf("config: %v", err)
+	}
+	database, err := db.NewMySQL(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPass, cfg.DBName)
+	if err != nil {
+		fatalf("banco: %v", err)
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+	chains := []uint64{tenantID}
+	if all || platform {
+		var heads []uint64
+		if err := database.SelectContext(ctx, &heads, `SELECT chain_id FROM audit_chain_heads ORDER BY chain_id`); err != nil {
+			fatalf("erro ao listar cadeias: %v", err)
+		}
+		chains = nil
+		for _, id := range heads {
+			if all || auditchain.IsPlatformChain(id) {
+				chains = append(chains, id)
+			}
+		}
+	}
+
+	broken := false
+	for _, id := range chains {
+		rep, err := auditchain.Verify(ctx, database, id)
+		if err != nil {
+			fatalf("erro ao verificar cadeia %d: %v", id, err)
+		}
+		if !rep.Valid {
+			broken = true
+		}
+		if asJSON {
+			b, _ := json.Marshal(rep)
+			fmt.Println(string(b))
+			continue
+		}
+		printReport(rep)
+	}
+	if broken {
+		os.Exit(2)
+	}
+}
+
+func printReport(rep auditchain.Report) {
+	if rep.Valid {
+		fmt.Printf("cadeia %d OK: %d registros verificados, %d anteriores a cadeia, cabeca seq %d\n",
+			rep.ChainID, rep.Checked, rep.Unsealed, rep.HeadSeq)
+		return
+	}
+	fmt.Printf("cadeia %d QUEBRADA apos %d registros validos\n", rep.ChainID, rep.Checked)
+	if rep.Broken.ID != 0 {
+		fmt.Printf("  primeiro elo quebrado: audit_logs.id=%d seq=%d\n", rep.Broken.ID, rep.Broken.Seq)
+	} else {
+		fmt.Printf("  primeiro elo quebrado: seq=%d\n", rep.Broken.Seq)
+	}
+	fmt.Printf("  motivo: %s\n", rep.Broken.Reason)
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -54,6 +54,7 @@ func main() {
 		action   string
 		baseURL  string
 		token    string
+		apiKey   string
 		startRaw string
 		endRaw   string
 		lastDays int
@@ -62,20 +63,22 @@ func main() {
 	flag.StringVar(&action, "action", "sync", "Acao: sync ou status")
 	flag.StringVar(&baseURL, "base-url", os.Getenv("API_URL"), "Base URL da API (ex: https://api/v1)")
 	flag.StringVar(&token, "token", os.Getenv("API_TOKEN"), "Bearer token JWT")
+	flag.StringVar(&apiKey, "api-key", os.Getenv("API_KEY"), "API key do tenant (scope clockify:sync); alternativa ao -token")
 	flag.StringVar(&startRaw, "start-date", "", "Data inicial (YYYY-MM-DD)")
 	flag.StringVar(&endRaw, "end-date", "", "Data final (YYYY-MM-DD)")
 	flag.IntVar(&lastDays, "last-days", 30, "Janela em dias para sync quando start/end nao informados")
 	flag.Parse()
 
-	if baseURL == "" || token == "" {
-		fmt.Println("Uso: importer -action sync|status -base-url https://api/v1 -token <JWT>")
+	if baseURL == "" || (token == "" && apiKey == "") {
+		fmt.Println("Uso: importer -action sync|status -base-url https://api/v1 (-api-key <sk_...> | -token <JWT>)")
 		os.Exit(1)
 	}
+	creds := credentials{token: token, apiKey: apiKey}
 	baseURL = strings.TrimSuffix(baseURL, "/")
 
 	switch strings.ToLower(strings.TrimSpace(action)) {
 	case "status":
-		status, err := getStatus(baseURL, token)
+		status, err := getStatus(baseURL, creds)
 		if err != nil {
 			fatalf("erro ao consultar status: %v", err)
 		}
@@ -86,7 +89,7 @@ func main() {
 		if err != nil {
 			fatalf("datas invalidas: %v", err)
 		}
-		resp, err := runSync(baseURL, token, syncRequest{
+		resp, err := runSync(baseURL, creds, syncRequest{
 			StartDate: startDate,
 			EndDate:   endDate,
 		})
@@ -97,7 +100,7 @@ func main() {
 		fmt.Printf("Usuarios encontrados: %d | Mapeados: %d\n", resp.UsersFound, resp.EmployeesMapped)
 		fmt.Printf("Batidas processadas: %d | Gravadas: %d\n", resp.EntriesProcessed, resp.EntriesUpserted)
 
-		status, err := getStatus(baseURL, token)
+		status, err := getStatus(baseURL, creds)
 		if err != nil {
 			fatalf("sync ok, mas falhou ao consultar status: %v", err)
 		}
@@ -145,23 +148,38 @@ func parseDateArg(raw string) (time.Time, error) {
 	return t.UTC(), nil
 }
 
-func runSync(baseURL, token string, body syncRequest) (syncResponse, error) {
+func runSync(baseURL string, creds credentials, body syncRequest) (syncResponse, error) {
 	var out syncResponse
-	if err := doJSON(baseURL+"/integrations/clockify/sync", token, http.MethodPost, body, &out); err != nil {
+	if err := doJSON(baseURL+"/integrations/clockify/sync", creds, http.MethodPost, body, &out); err != nil {
 		return syncResponse{}, err
 	}
 	return out, nil
 }
 
-func getStatus(baseURL, token string) (statusResponse, error) {
+func getStatus(baseURL string, creds credentials) (statusResponse, error) {
 	var out statusResponse
-	if err := doJSON(baseURL+"/integrations/clockify/status", token, http.MethodGet, nil, &out); err != nil {
+	if err := doJSON(baseURL+"/integrations/clockify/status", creds, http.MethodGet, nil, &out); err != nil {
 		return statusResponse{}, err
 	}
 	return out, nil
 }
 
-func doJSON(url, token, method string, payload any, dst any) error {
+// credentials autentica as chamadas: a API key tem preferencia sobre o JWT,
+// que expira e exige login interativo.
+type credentials struct {
+	token  string
+	apiKey string
+}
+
+func (c credentials) apply(req *http.Request) {
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+}
+
+func doJSON(url string, creds credentials, method string, payload any, dst any) error {
 	var body io.Reader
 	if payload != nil {
 		b, err := json.Marshal(payload)
@@ -175,7 +193,7 @@ func doJSON(url, token, method string, payload any, dst any) error {
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
+	creds.apply(req)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"saas-api/internal/oidc/oidctest"
+)
+
+// Provedor OIDC falso para testar o SSO localmente. O login e automatico:
+// usa o email do login_hint (se cadastrado em -users) ou o primeiro usuario.
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer url (base url of this server)")
+	clientID := flag.String("client-id", "saas-api", "client id")
+	clientSecret := flag.String("client-secret", "dev-secret", "client secret")
+	users := flag.String("users", "dev@example.com", "comma separated emails")
+	flag.Parse()
+
+	idp, err := oidctest.New(*clientID, *clientSecret)
+	if err != nil {
+		log.Fatal(err)
+	}
+	idp.Issuer = strings.TrimSuffix(*issuer, "/")
+
+	for i, email := range strings.Split(*users, ",") {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" {
+			continue
+		}
+		u := oidctest.User{Subject: "mock|" + email, Email: email, EmailVerified: true, Name: email}
+		idp.Users[email] = u
+		if i == 0 {
+			idp.DefaultUser = u
+		}
+	}
+
+	log.Printf("mock oidc provider %s on %s (client_id=%s)", idp.Issuer, *addr, *clientID)
+	if err := http.ListenAndServe(*addr, idp); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,246 @@
+// Package auditchain grava audit_logs como uma cadeia de hashes por tenant:
+// cada linha guarda o hash da anterior (prev_hash) e o hash do proprio conteudo
+// canonico (row_hash). Editar, apagar ou inserir uma linha fora de Append quebra
+// a cadeia, e Verify aponta o primeiro elo quebrado.
+package auditchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const hashVersion = "v1"
+
+// Eventos sem tenant (falhas de login, pedidos de reset) sao espalhados em
+// PlatformChains cadeias a partir de PlatformChainBase, escolhidas pelo hash de
+// ChainKey, para que um ataque de credential stuffing nao fique enfileirado
+// numa unica cabeca. A cadeia 0 guarda so os eventos anteriores a essa divisao.
+const (
+	PlatformChainBase uint64 = 1 << 63
+	PlatformChains    uint64 = 64
+)
+
+// Entry e uma linha de audit_logs. TenantID nil vai para uma das cadeias da
+// plataforma, escolhida por ChainKey (o email, ou o IP quando vazio).
+type Entry struct {
+	TenantID        *uint64
+	UserID          *uint64
+	ActorUserID     *uint64
+	ImpersonationID *uint64
+	Action          string
+	Entity          string
+	EntityID        *string
+	BeforeJSON      []byte
+	AfterJSON       []byte
+	IP              *string
+	UserAgent       *string
+	CreatedAt       time.Time
+	ChainKey        string // nao e gravado; so escolhe a cadeia sem tenant
+}
+
+func (e Entry) ChainID() uint64 {
+	if e.TenantID != nil {
+		return *e.TenantID
+	}
+	key := e.ChainKey
+	if key == "" && e.IP != nil {
+		key = *e.IP
+	}
+	sum := sha256.Sum256([]byte(key))
+	return PlatformChainBase + binary.BigEndian.Uint64(sum[:8])%PlatformChains
+}
+
+// IsPlatformChain diz se a cadeia guarda eventos sem tenant.
+func IsPlatformChain(chainID uint64) bool {
+	return chainID == 0 || chainID >= PlatformChainBase
+}
+
+// Append grava a linha como proximo elo da cadeia do tenant. A cabeca da
+// cadeia (audit_chain_heads) fica travada ate o fim da transacao, o que
+// serializa as gravacoes do tenant. Com *sqlx.DB abre uma transacao propria.
+func Append(ctx context.Context, exec sqlx.ExtContext, e Entry) error {
+	if db, ok := exec.(*sqlx.DB); ok {
+		tx, err := db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if err := Append(ctx, tx, e); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	e.normalize()
+	chainID := e.ChainID()
+
+	if _, err := exec.ExecContext(ctx, `INSERT IGNORE INTO audit_chain_heads (chain_id, last_seq, last_hash) VALUES (?, 0, '')`, chainID); err != nil {
+		return err
+	}
+	var head struct {
+		LastSeq  uint64 `db:"last_seq"`
+		LastHash string `db:"last_hash"`
+	}
+	if err := sqlx.GetContext(ctx, exec, &head, `SELECT last_seq, last_hash FROM audit_chain_heads WHERE chain_id=? FOR UPDATE`, chainID); err != nil {
+		return err
+	}
+
+	seq := head.LastSeq + 1
+	hash, err := RowHash(chainID, seq, head.LastHash, e)
+	if err != nil {
+		return err
+	}
+
+	if _, err := exec.ExecContext(ctx, `
+		INSERT INTO audit_logs (tenant_id, user_id, actor_user_id, impersonation_id, action, entity, entity_id,
+			before_json, after_json, ip, user_agent, created_at, chain_id, chain_seq, prev_hash, row_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.TenantID, e.UserID, e.ActorUserID, e.ImpersonationID, e.Action, e.Entity, e.EntityID,
+		nullBytes(e.BeforeJSON), nullBytes(e.AfterJSON), e.IP, e.UserAgent, e.CreatedAt,
+		chainID, seq, head.LastHash, hash); err != nil {
+		return err
+	}
+
+	_, err = exec.ExecContext(ctx, `UPDATE audit_chain_heads SET last_seq=?, last_hash=? WHERE chain_id=?`, seq, hash, chainID)
+	return err
+}
+
+// normalize deixa a entrada igual ao que o banco devolve depois (segundos
+// inteiros em UTC, textos no tamanho das colunas).
+func (e *Entry) normalize() {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	e.CreatedAt = e.CreatedAt.UTC().Truncate(time.Second)
+	e.IP = truncPtr(e.IP, 64)
+	e.UserAgent = truncPtr(e.UserAgent, 255)
+	e.EntityID = truncPtr(e.EntityID, 64)
+}
+
+// RowHash e o sha256 do conteudo canonico da linha. JSON e recodificado com
+// chaves ordenadas, entao a normalizacao do tipo JSON do MySQL nao muda o hash.
+func RowHash(chainID, seq uint64, prevHash string, e Entry) (string, error) {
+	before, err := canonicalJSON(e.BeforeJSON)
+	if err != nil {
+		return "", fmt.Errorf("before_json: %w", err)
+	}
+	after, err := canonicalJSON(e.AfterJSON)
+	if err != nil {
+		return "", fmt.Errorf("after_json: %w", err)
+	}
+
+	payload, err := json.Marshal([]any{
+		hashVersion, chainID, seq, prevHash,
+		e.TenantID, e.UserID, e.ActorUserID, e.ImpersonationID,
+		e.Action, e.Entity, e.EntityID,
+		before, after,
+		e.IP, e.UserAgent,
+		e.CreatedAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func canonicalJSON(raw []byte) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeCanonical(&buf, v)
+	return buf.Bytes(), nil
+}
+
+// writeCanonical escreve objetos com chaves ordenadas e numeros em forma
+// unica (1.0 e 1 viram 1), como o MySQL devolve.
+func writeCanonical(buf *bytes.Buffer, v any) {
+	switch t := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			kb, _ := json.Marshal(k)
+			buf.Write(kb)
+			buf.WriteByte(':')
+			writeCanonical(buf, t[k])
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonical(buf, item)
+		}
+		buf.WriteByte(']')
+	case json.Number:
+		buf.WriteString(canonicalNumber(t))
+	default:
+		b, _ := json.Marshal(t)
+		buf.Write(b)
+	}
+}
+
+func canonicalNumber(n json.Number) string {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return strconv.FormatInt(i, 10)
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return strconv.FormatUint(u, 10)
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return string(n)
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func truncPtr(s *string, max int) *string {
+	if s == nil || len(*s) <= max {
+		return s
+	}
+	// sem cortar um caractere UTF-8 ao meio
+	for max > 0 && !utf8.RuneStart((*s)[max]) {
+		max--
+	}
+	v := (*s)[:max]
+	return &v
+}
+
+func nullBytes(b []byte) any {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+	return b
+}
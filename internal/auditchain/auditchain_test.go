@@ -0,0 +1,90 @@
+package auditchain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRowHashCanonicalJSON(t *testing.T) {
+	tenant := uint64(7)
+	base := Entry{
+		TenantID:  &tenant,
+		Action:    "update",
+		Entity:    "employees",
+		AfterJSON: []byte(`{"name":"Ana","salary_cents":150000,"tags":["a","b"]}`),
+		CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	want, err := RowHash(7, 1, "", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// o MySQL devolve o JSON reordenado e com espacos; 1.0 e 1 sao o mesmo numero
+	same := base
+	same.AfterJSON = []byte(`{"tags": ["a", "b"], "salary_cents": 150000.0, "name": "Ana"}`)
+	if got, _ := RowHash(7, 1, "", same); got != want {
+		t.Fatalf("equivalent JSON changed hash: %s != %s", got, want)
+	}
+
+	changed := []struct {
+		name string
+		mod  func(e *Entry) (uint64, string)
+	}{
+		{"content", func(e *Entry) (uint64, string) {
+			e.AfterJSON = []byte(`{"name":"Ana","salary_cents":150001,"tags":["a","b"]}`)
+			return 1, ""
+		}},
+		{"timestamp", func(e *Entry) (uint64, string) { e.CreatedAt = e.CreatedAt.Add(time.Second); return 1, "" }},
+		{"seq", func(e *Entry) (uint64, string) { return 2, "" }},
+		{"prev hash", func(e *Entry) (uint64, string) { return 1, strings.Repeat("0", 64) }},
+	}
+	for _, tc := range changed {
+		e := base
+		seq, prev := tc.mod(&e)
+		if got, _ := RowHash(7, seq, prev, e); got == want {
+			t.Fatalf("%s: hash did not change", tc.name)
+		}
+	}
+}
+
+func TestNormalizeTruncatesOnRuneBoundary(t *testing.T) {
+	ua := strings.Repeat("a", 254) + "é"
+	e := Entry{UserAgent: &ua, CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 900, time.FixedZone("BRT", -3*3600))}
+	e.normalize()
+
+	if got := *e.UserAgent; len(got) != 254 {
+		t.Fatalf("user agent length = %d", len(got))
+	}
+	if e.CreatedAt.Location() != time.UTC || e.CreatedAt.Nanosecond() != 0 {
+		t.Fatalf("created_at not normalized: %v", e.CreatedAt)
+	}
+}
+
+func TestChainIDSpreadsTenantlessEvents(t *testing.T) {
+	tenant := uint64(7)
+	if got := (Entry{TenantID: &tenant, ChainKey: "a@x.com"}).ChainID(); got != 7 {
+		t.Fatalf("tenant chain = %d", got)
+	}
+
+	seen := map[uint64]bool{}
+	for i := 0; i < 200; i++ {
+		id := Entry{ChainKey: "user" + strings.Repeat("x", i) + "@x.com"}.ChainID()
+		if !IsPlatformChain(id) || id >= PlatformChainBase+PlatformChains {
+			t.Fatalf("chain %d outside platform range", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) < 16 {
+		t.Fatalf("tenantless events in only %d chains", len(seen))
+	}
+
+	a := Entry{ChainKey: "ana@x.com"}
+	if a.ChainID() != a.ChainID() {
+		t.Fatal("same key must stay on the same chain")
+	}
+	ip := "10.0.0.1"
+	if (Entry{IP: &ip}).ChainID() != (Entry{ChainKey: ip}).ChainID() {
+		t.Fatal("empty key must fall back to the IP")
+	}
+}
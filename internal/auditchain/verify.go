@@ -0,0 +1,150 @@
+package auditchain
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const verifyBatch = 1000
+
+// Break descreve o primeiro elo quebrado encontrado.
+type Break struct {
+	ID     uint64 `json:"id,omitempty"` // linha de audit_logs (0 quando falta a linha)
+	Seq    uint64 `json:"seq"`
+	Reason string `json:"reason"`
+}
+
+// Report e o resultado de Verify. Unsealed conta linhas anteriores a cadeia
+// (gravadas antes da migracao), que nao tem hash.
+type Report struct {
+	ChainID  uint64    `json:"chain_id"`
+	Checked  uint64    `json:"checked"`
+	Unsealed uint64    `json:"unsealed"`
+	HeadSeq  uint64    `json:"head_seq"`
+	HeadHash string    `json:"head_hash,omitempty"`
+	Valid    bool      `json:"valid"`
+	Broken   *Break    `json:"broken,omitempty"`
+	Verified time.Time `json:"verified_at"`
+}
+
+type row struct {
+	ID              uint64         `db:"id"`
+	TenantID        *uint64        `db:"tenant_id"`
+	UserID          *uint64        `db:"user_id"`
+	ActorUserID     *uint64        `db:"actor_user_id"`
+	ImpersonationID *uint64        `db:"impersonation_id"`
+	Action          string         `db:"action"`
+	Entity          string         `db:"entity"`
+	EntityID        *string        `db:"entity_id"`
+	BeforeJSON      []byte         `db:"before_json"`
+	AfterJSON       []byte         `db:"after_json"`
+	IP              *string        `db:"ip"`
+	UserAgent       *string        `db:"user_agent"`
+	CreatedAt       time.Time      `db:"created_at"`
+	ChainSeq        sql.NullInt64  `db:"chain_seq"`
+	PrevHash        sql.NullString `db:"prev_hash"`
+	RowHash         sql.NullString `db:"row_hash"`
+}
+
+func (r row) entry() Entry {
+	return Entry{
+		TenantID:        r.TenantID,
+		UserID:          r.UserID,
+		ActorUserID:     r.ActorUserID,
+		ImpersonationID: r.ImpersonationID,
+		Action:          r.Action,
+		Entity:          r.Entity,
+		EntityID:        r.EntityID,
+		BeforeJSON:      r.BeforeJSON,
+		AfterJSON:       r.AfterJSON,
+		IP:              r.IP,
+		UserAgent:       r.UserAgent,
+		CreatedAt:       r.CreatedAt,
+	}
+}
+
+// Verify percorre a cadeia do tenant (ou da plataforma) em ordem e para no
+// primeiro problema: conteudo alterado, prev_hash que nao bate, sequencia com
+// buraco (linha apagada), linha sem hash depois do inicio da cadeia ou cabeca
+// a frente da ultima linha (final apagado).
+func Verify(ctx context.Context, q sqlx.QueryerContext, chainID uint64) (Report, error) {
+	rep := Report{ChainID: chainID, Verified: time.Now().UTC()}
+
+	var head struct {
+		LastSeq  uint64 `db:"last_seq"`
+		LastHash string `db:"last_hash"`
+	}
+	err := sqlx.GetContext(ctx, q, &head, `SELECT last_seq, last_hash FROM audit_chain_heads WHERE chain_id=?`, chainID)
+	if err != nil && err != sql.ErrNoRows {
+		return rep, err
+	}
+	rep.HeadSeq, rep.HeadHash = head.LastSeq, head.LastHash
+
+	var (
+		lastID   uint64
+		expected uint64 = 1
+		prev     string
+	)
+	fail := func(id, seq uint64, reason string) (Report, error) {
+		rep.Broken = &Break{ID: id, Seq: seq, Reason: reason}
+		return rep, nil
+	}
+
+	for {
+		batch := make([]row, 0, verifyBatch)
+		if err := sqlx.SelectContext(ctx, q, &batch, `
+			SELECT id, tenant_id, user_id, actor_user_id, impersonation_id, action, entity, entity_id,
+			       before_json, after_json, ip, user_agent, created_at, chain_seq, prev_hash, row_hash
+			FROM audit_logs
+			WHERE chain_id=? AND id > ?
+			ORDER BY id ASC
+			LIMIT ?`, chainID, lastID, verifyBatch); err != nil {
+			return rep, err
+		}
+
+		for _, r := range batch {
+			lastID = r.ID
+			if !r.ChainSeq.Valid {
+				if expected == 1 {
+					rep.Unsealed++
+					continue
+				}
+				return fail(r.ID, expected, "row without hash inserted after chain start")
+			}
+
+			seq := uint64(r.ChainSeq.Int64)
+			if seq != expected {
+				return fail(r.ID, expected, fmt.Sprintf("sequence gap: expected %d, found %d (rows deleted or reordered)", expected, seq))
+			}
+			if r.PrevHash.String != prev {
+				return fail(r.ID, seq, "prev_hash does not match previous row")
+			}
+			hash, err := RowHash(chainID, seq, prev, r.entry())
+			if err != nil {
+				return fail(r.ID, seq, "unreadable content: "+err.Error())
+			}
+			if hash != r.RowHash.String {
+				return fail(r.ID, seq, "row content was modified")
+			}
+
+			rep.Checked++
+			prev = hash
+			expected++
+		}
+
+		if len(batch) < verifyBatch {
+			break
+		}
+	}
+
+	if head.LastSeq != expected-1 || head.LastHash != prev {
+		return fail(0, expected, fmt.Sprintf("chain head at seq %d but last row is seq %d (rows deleted at the end)", head.LastSeq, expected-1))
+	}
+
+	rep.Valid = true
+	return rep, nil
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
+	"strings"
 
 	"github.com/caarlos0/env/v11"
 )
@@ -11,15 +13,83 @@ type Config struct {
 	AppEnv   string `env:"APP_ENV" envDefault:"dev"`
 	HTTPAddr string `env:"HTTP_ADDR" envDefault:":8080"`
 
+	// Limites do http.Server. A escrita cobre o handler inteiro (exportacoes
+	// de PDF/CSV e sync manual do Clockify incluidos).
+	HTTPReadTimeoutSeconds  int `env:"HTTP_READ_TIMEOUT_SECONDS" envDefault:"30"`
+	HTTPWriteTimeoutSeconds int `env:"HTTP_WRITE_TIMEOUT_SECONDS" envDefault:"300"`
+	HTTPIdleTimeoutSeconds  int `env:"HTTP_IDLE_TIMEOUT_SECONDS" envDefault:"120"`
+	// Tempo maximo para drenar requisicoes e parar os jobs no SIGTERM.
+	ShutdownTimeoutSeconds int `env:"SHUTDOWN_TIMEOUT_SECONDS" envDefault:"25"`
+
+	// Proxies (IPs ou CIDRs) cujo X-Forwarded-For/X-Real-IP e aceito para
+	// descobrir o IP do cliente. Vazio: usa sempre o IP da conexao.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:","`
+
 	DBHost string `env:"DB_HOST" envDefault:"127.0.0.1"`
 	DBPort string `env:"DB_PORT" envDefault:"3306"`
 	DBUser string `env:"DB_USER" envDefault:"root"`
 	DBPass string `env:"DB_PASS" envDefault:"luan"`
 	DBName string `env:"DB_NAME" envDefault:"saas"`
 
-	JWTSecret     string `env:"JWT_SECRET,required"`
-	JWTIssuer     string `env:"JWT_ISSUER" envDefault:"saas-api"`
-	JWTTTLMinutes int    `env:"JWT_TTL_MINUTES" envDefault:"60"`
+	// JWT_SECRET (HS256) e o modo legado; com chaves assimetricas ele so
+	// verifica tokens antigos ate expirarem.
+	JWTSecret       string `env:"JWT_SECRET"`
+	JWTKeysDir      string `env:"JWT_KEYS_DIR"`
+	JWTSigningKey   string `env:"JWT_SIGNING_KEY"` // PEM inline
+	JWTSigningKeyID string `env:"JWT_SIGNING_KEY_ID"`
+	JWTIssuer       string `env:"JWT_ISSUER" envDefault:"saas-api"`
+	JWTTTLMinutes   int    `env:"JWT_TTL_MINUTES" envDefault:"60"`
+
+	JWTRefreshTTLHours int `env:"JWT_REFRESH_TTL_HOURS" envDefault:"720"`
+
+	// Frontend usado nos links enviados por email (reset de senha etc).
+	AppBaseURL              string `env:"APP_BASE_URL" envDefault:"http://localhost:5173"`
+	PasswordResetTTLMinutes int    `env:"PASSWORD_RESET_TTL_MINUTES" envDefault:"60"`
+	InvitationTTLHours      int    `env:"INVITATION_TTL_HOURS" envDefault:"168"`
+
+	// URL publica da API; base da redirect_uri do SSO (cadastrada no provedor).
+	APIBaseURL string `env:"API_BASE_URL" envDefault:"http://localhost:8080"`
+	// Aceita issuer http em localhost/127.0.0.1 (provedor de teste local). So
+	// para dev: em producao o issuer precisa ser https.
+	SSOAllowInsecureIssuer bool `env:"SSO_ALLOW_INSECURE_ISSUER" envDefault:"false"`
+
+	MailDriver  string `env:"MAIL_DRIVER" envDefault:"log"` // log | file | smtp
+	MailFrom    string `env:"MAIL_FROM" envDefault:"no-reply@saas-api.local"`
+	MailFileDir string `env:"MAIL_FILE_DIR" envDefault:"tmp/mail"`
+	SMTPHost    string `env:"SMTP_HOST"`
+	SMTPPort    int    `env:"SMTP_PORT" envDefault:"587"`
+	SMTPUser    string `env:"SMTP_USER"`
+	SMTPPass    string `env:"SMTP_PASS"`
+
+	LoginMaxFailures    int `env:"LOGIN_MAX_FAILURES" envDefault:"5"`
+	LoginIPMaxFailures  int `env:"LOGIN_IP_MAX_FAILURES" envDefault:"20"`
+	LoginLockoutMinutes int `env:"LOGIN_LOCKOUT_MINUTES" envDefault:"15"`
+
+	// Por quanto tempo a resposta de uma Idempotency-Key e repetida.
+	IdempotencyTTLHours int `env:"IDEMPOTENCY_TTL_HOURS" envDefault:"24"`
+
+	// Traces OpenTelemetry: none | stdout | otlp. O otlp usa as variaveis
+	// padrao OTEL_EXPORTER_OTLP_* (endpoint, headers).
+	TracingExporter    string  `env:"TRACING_EXPORTER" envDefault:"none"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+	ServiceName        string  `env:"OTEL_SERVICE_NAME" envDefault:"saas-api"`
+
+	// Rate limit das rotas autenticadas: requisicoes por minuto por usuario
+	// (ou API key) em cada classe de rota. O tenant inteiro tem o limite x
+	// RATE_LIMIT_TENANT_MULTIPLIER e tudo escala pelo plano do tenant
+	// (tenants.plan, ex.: "trial:1,pro:3"). Store: memory (por instancia) ou
+	// mysql (compartilhado entre instancias).
+	RateLimitEnabled          bool               `env:"RATE_LIMIT_ENABLED" envDefault:"true"`
+	RateLimitStore            string             `env:"RATE_LIMIT_STORE" envDefault:"memory"`
+	RateLimitReadPerMinute    int                `env:"RATE_LIMIT_READ_PER_MINUTE" envDefault:"300"`
+	RateLimitWritePerMinute   int                `env:"RATE_LIMIT_WRITE_PER_MINUTE" envDefault:"60"`
+	RateLimitHeavyPerMinute   int                `env:"RATE_LIMIT_HEAVY_PER_MINUTE" envDefault:"10"`
+	RateLimitSyncPerMinute    int                `env:"RATE_LIMIT_SYNC_PER_MINUTE" envDefault:"2"`
+	RateLimitTenantMultiplier float64            `env:"RATE_LIMIT_TENANT_MULTIPLIER" envDefault:"5"`
+	RateLimitPlanMultipliers  map[string]float64 `env:"RATE_LIMIT_PLAN_MULTIPLIERS" envDefault:"trial:1" envSeparator:"," envKeyValSeparator:":"`
+
+	// Bearer exigido em /metrics; vazio deixa o endpoint aberto.
+	MetricsToken string `env:"METRICS_TOKEN"`
 
 	RunMigrations bool `env:"RUN_MIGRATIONS" envDefault:"true"`
 
@@ -29,6 +99,89 @@ type Config struct {
 }
 
 func Load() (Config, error) {
+	cfg, err := parse()
+	if err != nil {
+		return cfg, err
+	}
+
+	if cfg.JWTSecret == "" && cfg.JWTKeysDir == "" && cfg.JWTSigningKey == "" {
+		return cfg, fmt.Errorf("JWT_KEYS_DIR, JWT_SIGNING_KEY or JWT_SECRET is required")
+	}
+	if cfg.HTTPReadTimeoutSeconds < 1 || cfg.HTTPWriteTimeoutSeconds < 1 || cfg.HTTPIdleTimeoutSeconds < 1 {
+		return cfg, fmt.Errorf("HTTP_READ_TIMEOUT_SECONDS, HTTP_WRITE_TIMEOUT_SECONDS and HTTP_IDLE_TIMEOUT_SECONDS must be >= 1")
+	}
+	if cfg.ShutdownTimeoutSeconds < 1 || cfg.ShutdownTimeoutSeconds > 300 {
+		return cfg, fmt.Errorf("SHUTDOWN_TIMEOUT_SECONDS must be between 1 and 300")
+	}
+	if cfg.JWTRefreshTTLHours < 1 {
+		return cfg, fmt.Errorf("JWT_REFRESH_TTL_HOURS must be >= 1")
+	}
+	if cfg.PasswordResetTTLMinutes < 5 || cfg.PasswordResetTTLMinutes > 1440 {
+		return cfg, fmt.Errorf("PASSWORD_RESET_TTL_MINUTES must be between 5 and 1440")
+	}
+	if cfg.InvitationTTLHours < 1 || cfg.InvitationTTLHours > 720 {
+		return cfg, fmt.Errorf("INVITATION_TTL_HOURS must be between 1 and 720")
+	}
+	if cfg.LoginMaxFailures < 1 || cfg.LoginIPMaxFailures < 1 {
+		return cfg, fmt.Errorf("LOGIN_MAX_FAILURES and LOGIN_IP_MAX_FAILURES must be >= 1")
+	}
+	if cfg.LoginLockoutMinutes < 1 {
+		return cfg, fmt.Errorf("LOGIN_LOCKOUT_MINUTES must be >= 1")
+	}
+	if cfg.IdempotencyTTLHours < 1 || cfg.IdempotencyTTLHours > 168 {
+		return cfg, fmt.Errorf("IDEMPOTENCY_TTL_HOURS must be between 1 and 168")
+	}
+	switch cfg.TracingExporter {
+	case "none", "stdout", "otlp":
+	default:
+		return cfg, fmt.Errorf("TRACING_EXPORTER must be none, stdout or otlp")
+	}
+	if cfg.TracingSampleRatio < 0 || cfg.TracingSampleRatio > 1 {
+		return cfg, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	switch cfg.RateLimitStore {
+	case "memory", "mysql":
+	default:
+		return cfg, fmt.Errorf("RATE_LIMIT_STORE must be memory or mysql")
+	}
+	if cfg.RateLimitReadPerMinute < 1 || cfg.RateLimitWritePerMinute < 1 || cfg.RateLimitHeavyPerMinute < 1 || cfg.RateLimitSyncPerMinute < 1 {
+		return cfg, fmt.Errorf("RATE_LIMIT_*_PER_MINUTE must be >= 1")
+	}
+	if cfg.RateLimitTenantMultiplier < 1 {
+		return cfg, fmt.Errorf("RATE_LIMIT_TENANT_MULTIPLIER must be >= 1")
+	}
+	for plan, f := range cfg.RateLimitPlanMultipliers {
+		if f <= 0 {
+			return cfg, fmt.Errorf("RATE_LIMIT_PLAN_MULTIPLIERS: multiplier for %q must be > 0", plan)
+		}
+	}
+	for _, p := range cfg.TrustedProxies {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if _, err := netip.ParsePrefix(p); err != nil {
+			if _, err := netip.ParseAddr(p); err != nil {
+				return cfg, fmt.Errorf("TRUSTED_PROXIES: invalid IP or CIDR %q", p)
+			}
+		}
+	}
+	if cfg.ClockifyAutoSyncHourUTC < 0 || cfg.ClockifyAutoSyncHourUTC > 23 {
+		return cfg, fmt.Errorf("CLOCKIFY_AUTO_SYNC_HOUR_UTC must be between 0 and 23")
+	}
+	if cfg.ClockifyAutoSyncLookbackDays < 1 || cfg.ClockifyAutoSyncLookbackDays > 30 {
+		return cfg, fmt.Errorf("CLOCKIFY_AUTO_SYNC_LOOKBACK_DAYS must be between 1 and 30")
+	}
+
+	return cfg, nil
+}
+
+// LoadDatabase le so o necessario para conectar no banco, sem exigir as chaves
+// JWT; usado pelas ferramentas de linha de comando.
+func LoadDatabase() (Config, error) {
+	return parse()
+}
+
+func parse() (Config, error) {
 	var cfg Config
 	if err := env.Parse(&cfg); err != nil {
 		return cfg, err
@@ -66,12 +219,5 @@ func Load() (Config, error) {
 		}
 	}
 
-	if cfg.ClockifyAutoSyncHourUTC < 0 || cfg.ClockifyAutoSyncHourUTC > 23 {
-		return cfg, fmt.Errorf("CLOCKIFY_AUTO_SYNC_HOUR_UTC must be between 0 and 23")
-	}
-	if cfg.ClockifyAutoSyncLookbackDays < 1 || cfg.ClockifyAutoSyncLookbackDays > 30 {
-		return cfg, fmt.Errorf("CLOCKIFY_AUTO_SYNC_LOOKBACK_DAYS must be between 1 and 30")
-	}
-
 	return cfg, nil
 }
@@ -2,10 +2,12 @@ package db
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/database"
 
 	"saas-api/migrations"
 )
@@ -20,3 +22,79 @@ func Migrate(ctx context.Context, db *sqlx.DB) error {
 
 	return goose.UpContext(ctx, db.DB, ".")
 }
+
+// MigrationState compara o banco com as migrations embutidas.
+type MigrationState struct {
+	Current int64
+	Latest  int64
+	Pending bool
+}
+
+// MigrationChecker compara o banco com as migrations embutidas sem alterar
+// nada: so le a tabela do goose, sem lock, e nunca a cria. Montado uma vez e
+// reaproveitado a cada probe.
+type MigrationChecker struct {
+	store    database.StoreExtender
+	versions []int64
+}
+
+// NewMigrationChecker le as versoes embutidas (nao toca no banco).
+func NewMigrationChecker(db *sqlx.DB) (*MigrationChecker, error) {
+	p, err := goose.NewProvider(goose.DialectMySQL, db.DB, migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	store, err := database.NewStore(database.DialectMySQL, goose.DefaultTablename)
+	if err != nil {
+		return nil, err
+	}
+	ext, ok := store.(database.StoreExtender)
+	if !ok {
+		return nil, errors.New("goose store cannot check the version table")
+	}
+	c := &MigrationChecker{store: ext}
+	for _, src := range p.ListSources() {
+		c.versions = append(c.versions, src.Version)
+	}
+	return c, nil
+}
+
+// Status diz se ha migrations embutidas ainda nao aplicadas, inclusive buracos
+// (versao antiga nao aplicada). Sem a tabela do goose tudo esta pendente.
+func (c *MigrationChecker) Status(ctx context.Context, db *sqlx.DB) (MigrationState, error) {
+	state := MigrationState{}
+	if n := len(c.versions); n > 0 {
+		state.Latest = c.versions[n-1]
+	}
+	exists, err := c.store.TableExists(ctx, db.DB)
+	if err != nil {
+		return MigrationState{}, err
+	}
+	if !exists {
+		state.Pending = len(c.versions) > 0
+		return state, nil
+	}
+
+	rows, err := c.store.ListMigrations(ctx, db.DB)
+	if err != nil {
+		return MigrationState{}, err
+	}
+	// as linhas vem da mais nova para a mais antiga: a primeira de cada versao vale
+	applied := map[int64]bool{}
+	for _, m := range rows {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		applied[m.Version] = m.IsApplied
+		if m.IsApplied && m.Version > state.Current {
+			state.Current = m.Version
+		}
+	}
+	for _, v := range c.versions {
+		if !applied[v] {
+			state.Pending = true
+			break
+		}
+	}
+	return state, nil
+}
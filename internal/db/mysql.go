@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/go-sql-driver/mysql"
+
+	"saas-api/internal/tracing"
 )
 
 func NewMySQL(host, port, user, pass, name string) (*sqlx.DB, error) {
@@ -13,10 +16,12 @@ func NewMySQL(host, port, user, pass, name string) (*sqlx.DB, error) {
 		user, pass, host, port, name,
 	)
 
-	db, err := sqlx.Open("mysql", dsn)
+	// driver instrumentado: cada statement vira um span do trace da requisicao
+	sqlDB, err := otelsql.Open("mysql", dsn, tracing.SQLOptions()...)
 	if err != nil {
 		return nil, err
 	}
+	db := sqlx.NewDb(sqlDB, "mysql")
 
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(25)
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+)
+
+const (
+	apiKeyPrefixBytes = 4
+	// last_used_at e gravado no maximo uma vez por intervalo, para nao gerar
+	// um UPDATE a cada chamada da integracao.
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeysHandler gerencia as API keys do tenant (owner) e valida as keys
+// recebidas por mw.AuthAPIKey.
+type APIKeysHandler struct {
+	DB *sqlx.DB
+}
+
+type APIKey struct {
+	ID         uint64     `db:"id" json:"id"`
+	Name       string     `db:"name" json:"name"`
+	Prefix     string     `db:"prefix" json:"prefix"`
+	Scopes     []string   `db:"-" json:"scopes"`
+	CreatedBy  uint64     `db:"created_by" json:"created_by"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	LastUsedIP *string    `db:"last_used_ip" json:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+
+	ScopesJSON string `db:"scopes_json" json:"-"`
+}
+
+type createAPIKeyReq struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 = sem expiracao
+}
+
+// createAPIKeyResp devolve a key em claro uma unica vez.
+type createAPIKeyResp struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+const apiKeySelect = `
+	SELECT id, name, prefix, scopes_json, created_by, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+	FROM api_keys`
+
+func (k *APIKey) decodeScopes() {
+	_ = json.Unmarshal([]byte(k.ScopesJSON), &k.Scopes)
+	if k.Scopes == nil {
+		k.Scopes = []string{}
+	}
+}
+
+// newAPIKey gera a key no formato sk_<prefixo>_<segredo>. O prefixo fica
+// visivel na listagem para identificar a key; so o hash da key inteira e salvo.
+func newAPIKey() (raw, prefix, hash string, err error) {
+	b := make([]byte, apiKeyPrefixBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	secret, _, err := newOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	prefix = mw.APIKeyPrefix + hex.EncodeToString(b)
+	raw = prefix + "_" + secret
+	return raw, prefix, hashToken(raw), nil
+}
+
+func (h *APIKeysHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+
+	items := []APIKey{}
+	if err := h.DB.SelectContext(r.Context(), &items, apiKeySelect+` WHERE tenant_id=? ORDER BY id DESC`, tenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToListAPIKeys, "failed to list api keys")
+		return
+	}
+	for i := range items {
+		items[i].decodeScopes()
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h *APIKeysHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+
+	var req createAPIKeyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeFieldError(w, "name", problem.RuleRequired, "name is required")
+		return
+	}
+	if len(req.Name) > 120 {
+		writeError(w, http.StatusBadRequest, problem.CodeNameTooLong, "name too long")
+		return
+	}
+	if len(req.Scopes) == 0 {
+		writeFieldError(w, "scopes", problem.RuleRequired, "scopes is required")
+		return
+	}
+	seen := map[string]struct{}{}
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, s := range req.Scopes {
+		s = strings.TrimSpace(strings.ToLower(s))
+		if !mw.IsValidScope(s) {
+			writeError(w, http.StatusBadRequest, problem.CodeInvalidScope, "invalid scope: "+s, s)
+			return
+		}
+		if _, dup := seen[s]; dup {
+			continue
+		}
+		seen[s] = struct{}{}
+		scopes = append(scopes, s)
+	}
+	sort.Strings(scopes)
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > 3650 {
+		writeFieldError(w, "expires_in_days", problem.RuleBetween, "expires_in_days must be between 0 and 3650", 0, 3650)
+		return
+	}
+
+	raw, prefix, hash, err := newAPIKey()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeKeyError, "key error")
+		return
+	}
+	scopesJSON, _ := json.Marshal(scopes)
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().UTC().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(r.Context(), `
+		INSERT INTO api_keys (tenant_id, name, prefix, key_hash, scopes_json, created_by, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		tenantID, req.Name, prefix, hash, string(scopesJSON), userID, expiresAt)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToCreateAPIKey, "failed to create api key")
+		return
+	}
+	id64, _ := res.LastInsertId()
+
+	var out createAPIKeyResp
+	if err := tx.GetContext(r.Context(), &out.APIKey, apiKeySelect+` WHERE id=?`, id64); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	out.decodeScopes()
+	out.Key = raw
+
+	if err := insertAudit(tx, r, tenantID, userID, "create", "api_keys", id64, nil, out.APIKey); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, out)
+}
+
+func (h *APIKeysHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+
+	id, err := parseUintParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidID, "invalid id")
+		return
+	}
+
+	var key APIKey
+	if err := h.DB.GetContext(r.Context(), &key, apiKeySelect+` WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
+		if err == sql.ErrNoRows {
+			writeError(w, http.StatusNotFound, problem.CodeAPIKeyNotFound, "api key not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToLoadAPIKey, "failed to load api key")
+		return
+	}
+	key.decodeScopes()
+	if key.RevokedAt != nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(r.Context(), `UPDATE api_keys SET revoked_at=UTC_TIMESTAMP() WHERE tenant_id=? AND id=? AND revoked_at IS NULL`, tenantID, id); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToRevokeAPIKey, "failed to revoke api key")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "revoke", "api_keys", int64(id), key, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Authenticate implementa mw.APIKeyLookup: aceita keys ativas e nao expiradas
+// cujo autor ainda e membro do tenant com permissao de gerenciar keys (remover
+// ou rebaixar o autor desliga as keys dele na hora) e registra o ultimo uso.
+func (h *APIKeysHandler) Authenticate(r *http.Request, raw string) (*mw.APIKeyPrincipal, error) {
+	var row struct {
+		ID          uint64         `db:"id"`
+		TenantID    uint64         `db:"tenant_id"`
+		CreatedBy   uint64         `db:"created_by"`
+		ScopesJSON  string         `db:"scopes_json"`
+		LastUsedAt  *time.Time     `db:"last_used_at"`
+		Role        string         `db:"role"`
+		CustomPerms sql.NullString `db:"custom_permissions"`
+	}
+	err := h.DB.GetContext(r.Context(), &row, `
+		SELECT k.id, k.tenant_id, k.created_by, k.scopes_json, k.last_used_at,
+		       m.role, tr.permissions_json AS custom_permissions
+		FROM api_keys k
+		INNER JOIN memberships m ON m.tenant_id = k.tenant_id AND m.user_id = k.created_by
+		LEFT JOIN tenant_roles tr ON tr.tenant_id = m.tenant_id AND tr.role_key = m.role
+		WHERE k.key_hash=? AND k.revoked_at IS NULL
+		  AND (k.expires_at IS NULL OR k.expires_at>UTC_TIMESTAMP())`, hashToken(raw))
+	if err == sql.ErrNoRows {
+		return nil, mw.ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(rolePermissions(row.Role, row.CustomPerms), mw.PermAPIKeysManage) {
+		return nil, mw.ErrAPIKeyInvalid
+	}
+
+	var scopes []string
+	if err := json.Unmarshal([]byte(row.ScopesJSON), &scopes); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if row.LastUsedAt == nil || now.Sub(*row.LastUsedAt) >= apiKeyTouchInterval {
+		_, _ = h.DB.ExecContext(r.Context(), `UPDATE api_keys SET last_used_at=?, last_used_ip=? WHERE id=?`, now, truncate(clientIP(r), 64), row.ID)
+	}
+
+	return &mw.APIKeyPrincipal{
+		KeyID:    row.ID,
+		TenantID: row.TenantID,
+		UserID:   row.CreatedBy,
+		Scopes:   scopes,
+	}, nil
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"saas-api/internal/auditchain"
+	mw "saas-api/internal/http/middleware"
+)
+
+// Toda alteracao grava audit_logs na mesma transacao da mudanca: se a
+// auditoria falhar o handler responde 500 e a transacao e desfeita.
+
+const auditRedacted = "[redacted]"
+
+// campos que nunca vao para before_json/after_json (alem dos sufixos em
+// isSecretAuditKey)
+var auditSecretKeys = map[string]bool{
+	"password":       true,
+	"api_key":        true,
+	"apikey":         true,
+	"secret":         true,
+	"token":          true,
+	"private_key":    true,
+	"signing_key":    true,
+	"recovery_codes": true,
+	"code_verifier":  true,
+	"authorization":  true,
+}
+
+func insertAudit(exec sqlx.ExtContext, r *http.Request, tenantID, userID uint64, action, entity string, entityID int64, before any, after any) error {
+	id := strconv.FormatInt(entityID, 10)
+	return writeAudit(exec, r, auditchain.Entry{
+		TenantID: &tenantID,
+		UserID:   &userID,
+		Action:   action,
+		Entity:   entity,
+		EntityID: &id,
+	}, before, after)
+}
+
+// insertLoginAudit grava tentativas de login em audit_logs. Falhas nao tem
+// tenant e vao para a cadeia da plataforma escolhida pelo email; entity_id e o
+// usuario quando conhecido.
+func insertLoginAudit(exec sqlx.ExtContext, r *http.Request, tenantID, userID *uint64, action, email, reason string) error {
+	after := map[string]any{"email": email}
+	if reason != "" {
+		after["reason"] = reason
+	}
+
+	var entityID *string
+	if userID != nil {
+		id := strconv.FormatUint(*userID, 10)
+		entityID = &id
+	}
+	return writeAudit(exec, r, auditchain.Entry{
+		TenantID: tenantID,
+		UserID:   userID,
+		Action:   action,
+		Entity:   "auth",
+		EntityID: entityID,
+		ChainKey: strings.ToLower(email),
+	}, nil, after)
+}
+
+// writeAudit completa a entrada com IP, user agent e admin (sob
+// impersonation) da requisicao, remove segredos do payload e grava na cadeia.
+// r nil e para rotinas do sistema, que informam IP/UA na propria entrada.
+func writeAudit(exec sqlx.ExtContext, r *http.Request, e auditchain.Entry, before, after any) error {
+	var err error
+	if e.BeforeJSON, err = auditPayload(before); err != nil {
+		return err
+	}
+	if e.AfterJSON, err = auditPayload(after); err != nil {
+		return err
+	}
+
+	if r != nil {
+		ip := clientIP(r)
+		ua := r.UserAgent()
+		e.IP, e.UserAgent = &ip, &ua
+
+		// sob impersonation, user_id e o usuario impersonado e o admin vai em actor_user_id
+		if act := mw.GetActor(r.Context()); act != nil {
+			e.ActorUserID, e.ImpersonationID = &act.UserID, &act.ImpersonationID
+		}
+	}
+
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+	return auditchain.Append(ctx, exec, e)
+}
+
+func auditPayload(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	if decoded == nil {
+		return nil, nil
+	}
+	return json.Marshal(redactAudit(decoded))
+}
+
+// redactAudit troca o valor de campos secretos, em qualquer nivel, por
+// "[redacted]". Valores vazios e flags (ex.: must_change_password) ficam como
+// estao.
+func redactAudit(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, item := range t {
+			if isSecretAuditKey(k) {
+				if _, isFlag := item.(bool); !isFlag && item != nil && item != "" {
+					t[k] = auditRedacted
+				}
+				continue
+			}
+			t[k] = redactAudit(item)
+		}
+	case []any:
+		for i := range t {
+			t[i] = redactAudit(t[i])
+		}
+	}
+	return v
+}
+
+func isSecretAuditKey(key string) bool {
+	k := strings.ToLower(key)
+	if auditSecretKeys[k] {
+		return true
+	}
+	for _, suffix := range []string{"_secret", "_password", "_token", "_hash"} {
+		if strings.HasSuffix(k, suffix) {
+			return true
+		}
+	}
+	return false
+}
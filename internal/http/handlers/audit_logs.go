@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"saas-api/internal/auditchain"
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+)
+
+const (
+	auditDefaultLimit = 50
+	auditMaxLimit     = 200
+	auditExportBatch  = 500
+)
+
+// AuditLogsHandler le a trilha gravada por insertAudit (consulta e exportacao).
+type AuditLogsHandler struct {
+	DB *sqlx.DB
+}
+
+type AuditLog struct {
+	ID              uint64          `db:"id" json:"id"`
+	UserID          *uint64         `db:"user_id" json:"user_id,omitempty"`
+	UserEmail       *string         `db:"user_email" json:"user_email,omitempty"`
+	ActorUserID     *uint64         `db:"actor_user_id" json:"actor_user_id,omitempty"`
+	ImpersonationID *uint64         `db:"impersonation_id" json:"impersonation_id,omitempty"`
+	Action          string          `db:"action" json:"action"`
+	Entity          string          `db:"entity" json:"entity"`
+	EntityID        *string         `db:"entity_id" json:"entity_id,omitempty"`
+	Before          json.RawMessage `db:"-" json:"before,omitempty"`
+	After           json.RawMessage `db:"-" json:"after,omitempty"`
+	Changes         []auditChange   `db:"-" json:"changes"`
+	IP              *string         `db:"ip" json:"ip,omitempty"`
+	UserAgent       *string         `db:"user_agent" json:"user_agent,omitempty"`
+	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
+	ChainSeq        *uint64         `db:"chain_seq" json:"chain_seq,omitempty"`
+	RowHash         *string         `db:"row_hash" json:"row_hash,omitempty"`
+	// Redacted: campos de salario removidos (leitor sem employees:salary).
+	Redacted bool `db:"-" json:"redacted,omitempty"`
+
+	BeforeJSON []byte `db:"before_json" json:"-"`
+	AfterJSON  []byte `db:"after_json" json:"-"`
+}
+
+// auditChange e um campo que mudou entre before_json e after_json. Objetos
+// aninhados viram caminhos com ponto (ex.: "address.city").
+type auditChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+type auditLogPage struct {
+	Items      []AuditLog `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+type auditFilter struct {
+	Entity   string
+	EntityID string
+	Action   string
+	UserID   *uint64
+	From     *time.Time
+	To       *time.Time // exclusivo
+}
+
+const auditLogSelect = `
+	SELECT a.id, a.user_id, u.email AS user_email, a.actor_user_id, a.impersonation_id, a.action, a.entity,
+	       a.entity_id, a.before_json, a.after_json, a.ip, a.user_agent, a.created_at, a.chain_seq, a.row_hash
+	FROM audit_logs a
+	LEFT JOIN users u ON u.id = a.user_id`
+
+// parseAuditFilter le entity, entity_id, action, user_id, from e to. Datas
+// aceitam YYYY-MM-DD (to inclui o dia inteiro) ou RFC3339.
+func parseAuditFilter(r *http.Request) (auditFilter, error) {
+	q := r.URL.Query()
+	f := auditFilter{
+		Entity:   strings.TrimSpace(q.Get("entity")),
+		EntityID: strings.TrimSpace(q.Get("entity_id")),
+		Action:   strings.TrimSpace(q.Get("action")),
+	}
+	if v := strings.TrimSpace(q.Get("user_id")); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return f, problem.New(http.StatusBadRequest, problem.CodeInvalidUserID, "invalid user_id")
+		}
+		f.UserID = &id
+	}
+	if v := strings.TrimSpace(q.Get("from")); v != "" {
+		t, _, err := parseAuditTime(v)
+		if err != nil {
+			return f, problem.Invalid("from", problem.RuleDate, "from must be YYYY-MM-DD or RFC3339")
+		}
+		f.From = &t
+	}
+	if v := strings.TrimSpace(q.Get("to")); v != "" {
+		t, dateOnly, err := parseAuditTime(v)
+		if err != nil {
+			return f, problem.Invalid("to", problem.RuleDate, "to must be YYYY-MM-DD or RFC3339")
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		f.To = &t
+	}
+	if f.From != nil && f.To != nil && !f.To.After(*f.From) {
+		return f, problem.Invalid("to", problem.RuleAfter, "to must be after from", "from")
+	}
+	return f, nil
+}
+
+func parseAuditTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t.UTC(), true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t.UTC(), false, err
+}
+
+func (f auditFilter) where(tenantID uint64) (string, []any) {
+	where := []string{"a.tenant_id=?"}
+	args := []any{tenantID}
+	if f.Entity != "" {
+		where = append(where, "a.entity=?")
+		args = append(args, f.Entity)
+	}
+	if f.EntityID != "" {
+		where = append(where, "a.entity_id=?")
+		args = append(args, f.EntityID)
+	}
+	if f.Action != "" {
+		where = append(where, "a.action=?")
+		args = append(args, f.Action)
+	}
+	if f.UserID != nil {
+		where = append(where, "(a.user_id=? OR a.actor_user_id=?)")
+		args = append(args, *f.UserID, *f.UserID)
+	}
+	if f.From != nil {
+		where = append(where, "a.created_at >= ?")
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		where = append(where, "a.created_at < ?")
+		args = append(args, *f.To)
+	}
+	return strings.Join(where, " AND "), args
+}
+
+func encodeAuditCursor(id uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(id, 10)))
+}
+
+func decodeAuditCursor(s string) (uint64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(b), 10, 64)
+}
+
+// ListAuditLogs pagina do mais novo para o mais antigo; next_cursor vai em
+// ?cursor= para a proxima pagina.
+func (h *AuditLogsHandler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+
+	f, err := parseAuditFilter(r)
+	if err != nil {
+		writeProblem(w, err)
+		return
+	}
+	limit := auditDefaultLimit
+	if v := strings.TrimSpace(r.URL.Query().Get("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > auditMaxLimit {
+			writeFieldError(w, "limit", problem.RuleBetween, fmt.Sprintf("limit must be between 1 and %d", auditMaxLimit), 1, auditMaxLimit)
+			return
+		}
+		limit = n
+	}
+
+	where, args := f.where(tenantID)
+	if v := strings.TrimSpace(r.URL.Query().Get("cursor")); v != "" {
+		before, err := decodeAuditCursor(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, problem.CodeInvalidCursor, "invalid cursor")
+			return
+		}
+		where += " AND a.id < ?"
+		args = append(args, before)
+	}
+	args = append(args, limit+1)
+
+	items := make([]AuditLog, 0, limit+1)
+	if err := h.DB.SelectContext(r.Context(), &items, auditLogSelect+` WHERE `+where+` ORDER BY a.id DESC LIMIT ?`, args...); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	page := auditLogPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = encodeAuditCursor(page.Items[limit-1].ID)
+	}
+	for i := range page.Items {
+		page.Items[i].prepare(r.Context())
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// csvSafeRow evita injecao de formula ao abrir o CSV numa planilha: celula que
+// comeca com =, +, -, @ (ou tab/CR) ganha um apostrofo na frente. user_agent e
+// email de login_failed vem de quem fez a requisicao.
+func csvSafeRow(row []string) []string {
+	for i, cell := range row {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			row[i] = "'" + cell
+		}
+	}
+	return row
+}
+
+// ExportAuditLogs devolve todos os registros do filtro em ordem cronologica,
+// em CSV (padrao) ou NDJSON (?format=ndjson), lendo em lotes. Falha no meio
+// termina o arquivo com um registro export_incomplete.
+func (h *AuditLogsHandler) ExportAuditLogs(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+
+	f, err := parseAuditFilter(r)
+	if err != nil {
+		writeProblem(w, err)
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "ndjson" {
+		writeFieldError(w, "format", problem.RuleChoice, "format must be csv or ndjson", "csv, ndjson")
+		return
+	}
+
+	// a propria exportacao fica na trilha
+	if err := insertAudit(h.DB, r, tenantID, userID, "export", "audit_logs", 0, nil, map[string]any{
+		"format": format,
+		"query":  r.URL.RawQuery,
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	filename := fmt.Sprintf("audit-logs-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+
+	var (
+		csvw *csv.Writer
+		enc  *json.Encoder
+	)
+	if format == "csv" {
+		csvw = csv.NewWriter(w)
+		_ = csvw.Write([]string{
+			"id", "created_at", "user_id", "user_email", "actor_user_id", "impersonation_id",
+			"action", "entity", "entity_id", "ip", "user_agent", "changes", "before_json", "after_json",
+		})
+	} else {
+		enc = json.NewEncoder(w)
+	}
+
+	where, baseArgs := f.where(tenantID)
+	var lastID uint64
+	for {
+		args := append(append([]any{}, baseArgs...), lastID, auditExportBatch)
+		batch := make([]AuditLog, 0, auditExportBatch)
+		if err := h.DB.SelectContext(r.Context(), &batch, auditLogSelect+` WHERE `+where+` AND a.id > ? ORDER BY a.id ASC LIMIT ?`, args...); err != nil {
+			// cabecalho ja enviado: fecha o arquivo com um registro de erro para
+			// ele nao parecer completo
+			mw.Logger(r.Context()).Error().Err(err).Uint64("last_id", lastID).Msg("audit export interrupted")
+			if csvw != nil {
+				_ = csvw.Write([]string{"ERROR", "", "", "", "", "", "export_incomplete", "", strconv.FormatUint(lastID, 10)})
+				csvw.Flush()
+			} else {
+				_ = enc.Encode(map[string]any{"error": "export_incomplete", "last_id": lastID})
+			}
+			return
+		}
+		for i := range batch {
+			item := &batch[i]
+			item.prepare(r.Context())
+			if csvw != nil {
+				changes, _ := json.Marshal(item.Changes)
+				_ = csvw.Write(csvSafeRow([]string{
+					strconv.FormatUint(item.ID, 10),
+					item.CreatedAt.UTC().Format(time.RFC3339),
+					optUint(item.UserID),
+					optString(item.UserEmail),
+					optUint(item.ActorUserID),
+					optUint(item.ImpersonationID),
+					item.Action,
+					item.Entity,
+					optString(item.EntityID),
+					optString(item.IP),
+					optString(item.UserAgent),
+					string(changes),
+					string(item.Before),
+					string(item.After),
+				}))
+			} else {
+				_ = enc.Encode(item)
+			}
+		}
+		if csvw != nil {
+			csvw.Flush()
+		}
+		if len(batch) < auditExportBatch {
+			return
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+// VerifyAuditChain recalcula a cadeia de hashes do tenant e aponta o primeiro
+// elo quebrado (linha alterada, apagada ou inserida por fora da API).
+func (h *AuditLogsHandler) VerifyAuditChain(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+
+	rep, err := auditchain.Verify(r.Context(), h.DB, tenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+// auditSalaryEntities tem o payload inteiro restrito a employees:salary.
+var auditSalaryEntities = map[string]bool{"employee_compensations": true}
+
+// prepare monta before/after/changes para a resposta. Sem employees:salary,
+// salary_cents sai de qualquer payload e o historico de remuneracao fica sem
+// payload, como na leitura dos colaboradores (redactSalary).
+func (a *AuditLog) prepare(ctx context.Context) {
+	if !mw.HasPermission(ctx, mw.PermEmployeesSalary) {
+		if auditSalaryEntities[a.Entity] {
+			a.Redacted = len(a.BeforeJSON) > 0 || len(a.AfterJSON) > 0
+			a.BeforeJSON, a.AfterJSON = nil, nil
+		} else {
+			var rb, ra bool
+			a.BeforeJSON, rb = redactAuditSalary(a.BeforeJSON)
+			a.AfterJSON, ra = redactAuditSalary(a.AfterJSON)
+			a.Redacted = rb || ra
+		}
+	}
+	if len(a.BeforeJSON) > 0 {
+		a.Before = json.RawMessage(a.BeforeJSON)
+	}
+	if len(a.AfterJSON) > 0 {
+		a.After = json.RawMessage(a.AfterJSON)
+	}
+	a.Changes = diffAuditJSON(a.BeforeJSON, a.AfterJSON)
+}
+
+// redactAuditSalary tira salary_cents (em qualquer nivel) do payload.
+func redactAuditSalary(raw []byte) ([]byte, bool) {
+	v := decodeAuditJSON(raw)
+	if v == nil || !stripAuditKey(v, "salary_cents") {
+		return raw, false
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, true
+	}
+	return out, true
+}
+
+func stripAuditKey(v any, key string) bool {
+	removed := false
+	switch t := v.(type) {
+	case map[string]any:
+		if _, ok := t[key]; ok {
+			delete(t, key)
+			removed = true
+		}
+		for _, child := range t {
+			removed = stripAuditKey(child, key) || removed
+		}
+	case []any:
+		for _, child := range t {
+			removed = stripAuditKey(child, key) || removed
+		}
+	}
+	return removed
+}
+
+// diffAuditJSON compara before e after campo a campo. Lados ausentes (create
+// ou delete) aparecem como null.
+func diffAuditJSON(before, after []byte) []auditChange {
+	b := map[string]any{}
+	a := map[string]any{}
+	flattenAuditJSON("", decodeAuditJSON(before), b)
+	flattenAuditJSON("", decodeAuditJSON(after), a)
+
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	out := make([]auditChange, 0)
+	for _, k := range keys {
+		bv, bok := b[k]
+		av, aok := a[k]
+		if bok && aok && reflect.DeepEqual(bv, av) {
+			continue
+		}
+		out = append(out, auditChange{Field: k, Before: bv, After: av})
+	}
+	return out
+}
+
+func decodeAuditJSON(raw []byte) any {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// flattenAuditJSON achata objetos em caminhos com ponto; listas e valores
+// simples sao comparados inteiros. Um valor que nao e objeto fica em "".
+func flattenAuditJSON(prefix string, v any, out map[string]any) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		if v != nil || prefix != "" {
+			out[prefix] = v
+		}
+		return
+	}
+	for k, child := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if _, nested := child.(map[string]any); nested {
+			flattenAuditJSON(key, child, out)
+			continue
+		}
+		out[key] = child
+	}
+}
+
+func optUint(v *uint64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatUint(*v, 10)
+}
+
+func optString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
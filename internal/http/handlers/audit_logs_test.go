@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mw "saas-api/internal/http/middleware"
+)
+
+func TestDiffAuditJSON(t *testing.T) {
+	cases := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{"create", ``, `{"name":"Ana","status":"active"}`,
+			`[{"field":"name","before":null,"after":"Ana"},{"field":"status","before":null,"after":"active"}]`},
+		{"update", `{"name":"Ana","salary_cents":100,"tags":["a"]}`, `{"name":"Ana","salary_cents":150,"tags":["a","b"]}`,
+			`[{"field":"salary_cents","before":100,"after":150},{"field":"tags","before":["a"],"after":["a","b"]}]`},
+		{"nested and removed", `{"address":{"city":"SP","zip":"1"},"note":"x"}`, `{"address":{"city":"RJ","zip":"1"}}`,
+			`[{"field":"address.city","before":"SP","after":"RJ"},{"field":"note","before":"x","after":null}]`},
+		{"delete", `{"id":7}`, ``, `[{"field":"id","before":7,"after":null}]`},
+		{"no payload", ``, ``, `[]`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := json.Marshal(diffAuditJSON([]byte(tc.before), []byte(tc.after)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("diff = %s\nwant  %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAuditCursorRoundTrip(t *testing.T) {
+	id, err := decodeAuditCursor(encodeAuditCursor(12345))
+	if err != nil || id != 12345 {
+		t.Fatalf("cursor round trip = %d, %v", id, err)
+	}
+	if _, err := decodeAuditCursor("not-a-cursor!"); err == nil {
+		t.Fatal("invalid cursor accepted")
+	}
+}
+
+func TestAuditLogSalaryRedaction(t *testing.T) {
+	reader := context.WithValue(context.Background(), mw.CtxPermissions, []string{mw.PermAuditLogsRead})
+	payroll := context.WithValue(context.Background(), mw.CtxPermissions, []string{mw.PermAuditLogsRead, mw.PermEmployeesSalary})
+
+	cases := []struct {
+		name     string
+		ctx      context.Context
+		entity   string
+		before   string
+		after    string
+		redacted bool
+		changes  string
+		after2   string
+	}{
+		{"employee without permission", reader, "employees",
+			`{"name":"Ana","salary_cents":100}`, `{"name":"Bia","salary_cents":150,"manager":{"salary_cents":9}}`, true,
+			`[{"field":"name","before":"Ana","after":"Bia"}]`, `{"manager":{},"name":"Bia"}`},
+		{"compensation without permission", reader, "employee_compensations",
+			``, `{"employee_id":7,"salary_cents":150}`, true, `[]`, ``},
+		{"employee with permission", payroll, "employees",
+			`{"salary_cents":100}`, `{"salary_cents":150}`, false,
+			`[{"field":"salary_cents","before":100,"after":150}]`, `{"salary_cents":150}`},
+		{"other entity untouched", reader, "payables",
+			`{"amount_cents":1}`, `{"amount_cents":2}`, false,
+			`[{"field":"amount_cents","before":1,"after":2}]`, `{"amount_cents":2}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := AuditLog{Entity: tc.entity, BeforeJSON: []byte(tc.before), AfterJSON: []byte(tc.after)}
+			a.prepare(tc.ctx)
+			changes, _ := json.Marshal(a.Changes)
+			if a.Redacted != tc.redacted || string(changes) != tc.changes || string(a.After) != tc.after2 {
+				t.Fatalf("redacted=%v changes=%s after=%s", a.Redacted, changes, a.After)
+			}
+		})
+	}
+}
+
+func TestCSVSafeRow(t *testing.T) {
+	got := csvSafeRow([]string{"=HYPERLINK(\"x\")", "+1", "-2", "@SUM(A1)", "\tx", "Mozilla/5.0", "", "ana@acme.com"})
+	want := []string{"'=HYPERLINK(\"x\")", "'+1", "'-2", "'@SUM(A1)", "'\tx", "Mozilla/5.0", "", "ana@acme.com"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("cell %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
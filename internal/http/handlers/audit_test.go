@@ -0,0 +1,34 @@
+package handlers
+
+import "testing"
+
+func TestAuditPayloadRedactsSecrets(t *testing.T) {
+	type conn struct {
+		WorkspaceID string `json:"workspace_id"`
+		APIKey      string `json:"api_key"`
+	}
+	payload := map[string]any{
+		"email":                "ana@example.com",
+		"password_hash":        "$2a$10$abc",
+		"must_change_password": true,
+		"client_secret":        "",
+		"connection":           conn{WorkspaceID: "ws1", APIKey: "ck_live_123"},
+		"sessions":             []map[string]any{{"id": 1, "refresh_token": "rt"}},
+		"salary_cents":         150000,
+	}
+
+	got, err := auditPayload(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"client_secret":"","connection":{"api_key":"[redacted]","workspace_id":"ws1"},"email":"ana@example.com",` +
+		`"must_change_password":true,"password_hash":"[redacted]","salary_cents":150000,"sessions":[{"id":1,"refresh_token":"[redacted]"}]}`
+	if string(got) != want {
+		t.Fatalf("payload = %s\nwant      %s", got, want)
+	}
+
+	var none *conn
+	if got, err := auditPayload(none); err != nil || got != nil {
+		t.Fatalf("nil payload = %s, %v", got, err)
+	}
+}
@@ -13,13 +13,27 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+	"saas-api/internal/i18n"
+	"saas-api/internal/jwtkeys"
+	"saas-api/internal/lifecycle"
+	"saas-api/internal/mail"
 )
 
 type AuthHandler struct {
 	DB        *sqlx.DB
-	JWTSecret []byte
+	Keys      *jwtkeys.KeySet
 	JWTIssuer string
 	JWTTTL    time.Duration
+
+	RefreshTTL time.Duration
+
+	LoginPolicy LoginPolicy
+
+	Mailer           mail.Mailer
+	Worker           *lifecycle.Worker // envio do reset de senha fora da requisicao
+	AppBaseURL       string
+	PasswordResetTTL time.Duration
 }
 
 type registerReq struct {
@@ -30,16 +44,24 @@ type registerReq struct {
 }
 
 type loginReq struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email      string  `json:"email"`
+	Password   string  `json:"password"`
+	TenantID   *uint64 `json:"tenant_id,omitempty"`   // opcional: tenant desejado
+	TenantSlug *string `json:"tenant_slug,omitempty"` // opcional: alternativa ao tenant_id
 }
 
 type authResp struct {
-	AccessToken string `json:"access_token"`
-	TenantID    uint64 `json:"tenant_id"`
-	TenantName  string `json:"tenant_name,omitempty"`
-	UserID      uint64 `json:"user_id"`
-	Role        string `json:"role"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	TenantID     uint64 `json:"tenant_id"`
+	TenantName   string `json:"tenant_name,omitempty"`
+	TenantSlug   string `json:"tenant_slug,omitempty"`
+	UserID       uint64 `json:"user_id"`
+	Role         string `json:"role"`
+
+	MustChangePassword bool `json:"must_change_password,omitempty"`
+	MFAEnrollRequired  bool `json:"mfa_enroll_required,omitempty"`
 }
 
 var nonSlug = regexp.MustCompile(`[^a-z0-9-]+`)
@@ -58,81 +80,91 @@ func slugify(s string) string {
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req registerReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
 		return
 	}
 	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
 	if req.CompanyName == "" || req.Name == "" || req.Email == "" || len(req.Password) < 8 {
-		http.Error(w, "invalid input (password >= 8)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidInput, "invalid input (password >= 8)")
 		return
 	}
 
 	passHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		http.Error(w, "password error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodePasswordError, "password error")
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	tenantSlug := slugify(req.CompanyName) + "-" + time.Now().Format("20060102150405")
 
-	res, err := tx.Exec(`INSERT INTO tenants (name, slug) VALUES (?, ?)`, req.CompanyName, tenantSlug)
+	res, err := tx.ExecContext(r.Context(), `INSERT INTO tenants (name, slug) VALUES (?, ?)`, req.CompanyName, tenantSlug)
 	if err != nil {
-		http.Error(w, "could not create tenant", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateTenant, "could not create tenant")
 		return
 	}
 	tenantID64, _ := res.LastInsertId()
 	tenantID := uint64(tenantID64)
 
-	res, err = tx.Exec(`INSERT INTO users (email, name, password_hash) VALUES (?, ?, ?)`, req.Email, req.Name, string(passHash))
+	res, err = tx.ExecContext(r.Context(), `INSERT INTO users (email, name, password_hash) VALUES (?, ?, ?)`, req.Email, req.Name, string(passHash))
 	if err != nil {
-		http.Error(w, "could not create user (email may exist)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateUser, "could not create user (email may exist)")
 		return
 	}
 	userID64, _ := res.LastInsertId()
 	userID := uint64(userID64)
 
-	_, err = tx.Exec(`INSERT INTO memberships (tenant_id, user_id, role) VALUES (?, ?, ?)`, tenantID, userID, "owner")
+	_, err = tx.ExecContext(r.Context(), `INSERT INTO memberships (tenant_id, user_id, role) VALUES (?, ?, ?)`, tenantID, userID, "owner")
 	if err != nil {
-		http.Error(w, "could not create membership", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateMembership, "could not create membership")
 		return
 	}
 
-	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", http.StatusInternalServerError)
+	if err := insertAudit(tx, r, tenantID, userID, "register", "tenants", tenantID64, nil, map[string]any{
+		"tenant_name": req.CompanyName,
+		"tenant_slug": tenantSlug,
+		"email":       req.Email,
+		"name":        req.Name,
+		"role":        roleOwner,
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
 		return
 	}
 
-	token, err := h.makeToken(userID, tenantID, "owner")
+	resp, err := h.issueSession(tx, r, userID, tenantID)
 	if err != nil {
-		http.Error(w, "token error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, authResp{
-		AccessToken: token,
-		TenantID:    tenantID,
-		TenantName:  req.CompanyName,
-		UserID:      userID,
-		Role:        "owner",
-	})
+	writeJSON(w, http.StatusCreated, resp)
 }
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req loginReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
 		return
 	}
 	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
 	if req.Email == "" || req.Password == "" {
-		http.Error(w, "invalid input", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidInput, "invalid input")
+		return
+	}
+
+	ip := clientIP(r)
+	if h.checkLoginLock(w, r, req.Email, ip) {
 		return
 	}
 
@@ -140,80 +172,166 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		ID           uint64 `db:"id"`
 		PasswordHash string `db:"password_hash"`
 	}
-	err := h.DB.Get(&user, `SELECT id, password_hash FROM users WHERE email = ?`, req.Email)
+	err := h.DB.GetContext(r.Context(), &user, `SELECT id, password_hash FROM users WHERE email = ?`, req.Email)
 	if err == sql.ErrNoRows {
-		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		h.loginFailed(w, r, req.Email, ip, nil, "unknown_email")
 		return
 	}
 	if err != nil {
-		http.Error(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		h.loginFailed(w, r, req.Email, ip, &user.ID, "invalid_password")
+		return
+	}
+
+	// Sem tenant explicito, usa o primeiro tenant do usuario.
+	tenantID, err := h.resolveMembershipTenant(r.Context(), user.ID, req.TenantID, req.TenantSlug)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusForbidden, problem.CodeNoTenantMembership, "no tenant membership")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 
-	// Pega um tenant padrão do usuário (primeiro). Depois você pode deixar escolher.
-	var m struct {
-		TenantID   uint64 `db:"tenant_id"`
-		TenantName string `db:"tenant_name"`
-		Role       string `db:"role"`
+	// Com MFA ativo o login vira desafio em duas etapas (ver VerifyMFA).
+	mfaEnabled, err := userMFAEnabled(r.Context(), h.DB, user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if mfaEnabled {
+		h.startMFAChallenge(w, r, user.ID, tenantID)
+		return
 	}
-	err = h.DB.Get(&m, `
-		SELECT m.tenant_id, t.name AS tenant_name, m.role
-		FROM memberships m
-		INNER JOIN tenants t ON t.id = m.tenant_id
-		WHERE m.user_id = ?
-		ORDER BY m.id ASC
-		LIMIT 1`, user.ID)
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "no tenant membership", http.StatusForbidden)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
-	m.Role = normalizeRole(m.Role)
+	defer tx.Rollback()
 
-	token, err := h.makeToken(user.ID, m.TenantID, m.Role)
+	resp, err := h.issueSession(tx, r, user.ID, tenantID)
 	if err != nil {
-		http.Error(w, "token error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+	if err := h.loginSucceeded(tx, r, req.Email, user.ID, tenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, authResp{
-		AccessToken: token,
-		TenantID:    m.TenantID,
-		TenantName:  m.TenantName,
-		UserID:      user.ID,
-		Role:        m.Role,
-	})
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 	tenantName := ""
-	_ = h.DB.Get(&tenantName, `SELECT name FROM tenants WHERE id=? LIMIT 1`, tenantID)
+	_ = h.DB.GetContext(r.Context(), &tenantName, `SELECT name FROM tenants WHERE id=? LIMIT 1`, tenantID)
+
+	perms := mw.GetPermissions(r.Context())
+	if perms == nil {
+		perms = []string{}
+	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
+	out := map[string]any{
 		"user_id":     mw.GetUserID(r.Context()),
 		"tenant_id":   tenantID,
 		"tenant_name": tenantName,
 		"role":        normalizeRole(mw.GetRole(r.Context())),
-	})
+		"permissions": perms,
+		"locale":      mw.GetLocale(r.Context()),
+	}
+	// o frontend mostra um aviso enquanto o suporte estiver impersonando
+	if act := mw.GetActor(r.Context()); act != nil {
+		out["impersonation"] = map[string]any{
+			"id":            act.ImpersonationID,
+			"admin_user_id": act.UserID,
+			"read_only":     !act.Write,
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+type localeReq struct {
+	Locale *string `json:"locale"`
 }
 
-func (h *AuthHandler) makeToken(userID, tenantID uint64, role string) (string, error) {
+// UpdateLocale salva o idioma preferido do usuario (null ou "" volta a seguir
+// o Accept-Language). Entra nos proximos tokens (login/refresh).
+func (h *AuthHandler) UpdateLocale(w http.ResponseWriter, r *http.Request) {
+	userID := mw.GetUserID(r.Context())
+	tenantID := mw.GetTenantID(r.Context())
+
+	var req localeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	var next *string
+	if req.Locale != nil && strings.TrimSpace(*req.Locale) != "" {
+		lang, ok := i18n.Normalize(*req.Locale)
+		if !ok {
+			writeFieldError(w, "locale", problem.RuleChoice, "locale must be pt-BR|en|es", "pt-BR, en, es")
+			return
+		}
+		v := string(lang)
+		next = &v
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	var current sql.NullString
+	if err := tx.GetContext(r.Context(), &current, `SELECT locale FROM users WHERE id=? FOR UPDATE`, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if _, err := tx.ExecContext(r.Context(), `UPDATE users SET locale=? WHERE id=?`, next, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+	before := map[string]any{"locale": nil}
+	if current.Valid {
+		before["locale"] = current.String
+	}
+	after := map[string]any{"locale": next}
+	if err := insertAudit(tx, r, tenantID, userID, "update_locale", "users", int64(userID), before, after); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+	writeJSON(w, http.StatusOK, after)
+}
+
+// makeToken assina o access token com a chave ativa (kid no header); os campos
+// de tempo e issuer sao preenchidos aqui.
+func (h *AuthHandler) makeToken(claims mw.Claims) (string, error) {
+	return h.makeTokenTTL(claims, h.JWTTTL)
+}
+
+func (h *AuthHandler) makeTokenTTL(claims mw.Claims, ttl time.Duration) (string, error) {
 	now := time.Now()
-	claims := mw.Claims{
-		UserID:   userID,
-		TenantID: tenantID,
-		Role:     role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    h.JWTIssuer,
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(h.JWTTTL)),
-		},
-	}
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return t.SignedString(h.JWTSecret)
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    h.JWTIssuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+	return h.Keys.Sign(claims)
 }
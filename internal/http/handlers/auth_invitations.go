@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
+
+	"saas-api/internal/http/problem"
+)
+
+type acceptInvitationReq struct {
+	Token    string `json:"token"`
+	Name     string `json:"name"`     // usado so quando o usuario ainda nao existe
+	Password string `json:"password"` // nova senha ou, se o email ja tem conta, a senha atual
+	MFACode  string `json:"mfa_code"` // exigido quando a conta existente tem MFA ativo
+}
+
+type invitationPreview struct {
+	Email        string    `json:"email"`
+	Name         *string   `json:"name,omitempty"`
+	Role         string    `json:"role"`
+	TenantName   string    `json:"tenant_name"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	ExistingUser bool      `json:"existing_user"`
+}
+
+// pendingInvitationByToken busca um convite ainda aceitavel pelo token em claro.
+func pendingInvitationByToken(ctx context.Context, q sqlx.QueryerContext, token string, forUpdate bool) (Invitation, error) {
+	query := invitationSelect + ` WHERE token_hash=? AND status='pending'`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+	var inv Invitation
+	if err := sqlx.GetContext(ctx, q, &inv, query, hashToken(token)); err != nil {
+		return Invitation{}, err
+	}
+	if !inv.ExpiresAt.After(time.Now().UTC()) {
+		return Invitation{}, sql.ErrNoRows
+	}
+	inv.Role = normalizeRole(inv.Role)
+	return inv, nil
+}
+
+// PreviewInvitation devolve os dados do convite para a tela de aceite.
+func (h *AuthHandler) PreviewInvitation(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.URL.Query().Get("token"))
+	if token == "" {
+		writeFieldError(w, "token", problem.RuleRequired, "token is required")
+		return
+	}
+
+	inv, err := pendingInvitationByToken(r.Context(), h.DB, token, false)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, problem.CodeInvalidOrExpiredInvitation, "invalid or expired invitation")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	out := invitationPreview{
+		Email:     inv.Email,
+		Name:      inv.Name,
+		Role:      inv.Role,
+		ExpiresAt: inv.ExpiresAt,
+	}
+	_ = h.DB.GetContext(r.Context(), &out.TenantName, `SELECT name FROM tenants WHERE id=? LIMIT 1`, inv.TenantID)
+	_ = h.DB.GetContext(r.Context(), &out.ExistingUser, `SELECT COUNT(*) > 0 FROM users WHERE email=?`, inv.Email)
+
+	writeJSON(w, http.StatusOK, out)
+}
+
+// AcceptInvitation cria (ou reaproveita) o usuario do email convidado, entra no
+// tenant com o role do convite e ja devolve uma sessao. Se o email ja tem conta,
+// a senha enviada precisa ser a atual.
+func (h *AuthHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req acceptInvitationReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Token == "" {
+		writeFieldError(w, "token", problem.RuleRequired, "token is required")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	inv, err := pendingInvitationByToken(r.Context(), tx, req.Token, true)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidOrExpiredInvitation, "invalid or expired invitation")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	var user struct {
+		ID           uint64 `db:"id"`
+		PasswordHash string `db:"password_hash"`
+	}
+	err = tx.GetContext(r.Context(), &user, `SELECT id, password_hash FROM users WHERE email=?`, inv.Email)
+	switch {
+	case err == sql.ErrNoRows:
+		name := req.Name
+		if name == "" && inv.Name != nil {
+			name = *inv.Name
+		}
+		if name == "" {
+			writeFieldError(w, "name", problem.RuleRequired, "name is required")
+			return
+		}
+		if len(req.Password) < minPasswordLen {
+			writeFieldError(w, "password", problem.RuleMinLength, "password must be at least 8 chars", 8)
+			return
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodePasswordError, "password error")
+			return
+		}
+		res, err := tx.ExecContext(r.Context(), `INSERT INTO users (email, name, password_hash, password_changed_at) VALUES (?, ?, ?, UTC_TIMESTAMP())`, inv.Email, name, string(hash))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateUser, "could not create user")
+			return
+		}
+		id64, _ := res.LastInsertId()
+		user.ID = uint64(id64)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	default:
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+			writeError(w, http.StatusUnauthorized, problem.CodeInvalidCredentials, "invalid credentials")
+			return
+		}
+		// aceitar convite emite sessao: com MFA ativo vale a mesma regra do login
+		ok, err := checkMFACode(r.Context(), tx, user.ID, req.MFACode, "")
+		if err != nil && err != errMFANotEnabled {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+			return
+		}
+		if err == nil && !ok {
+			writeError(w, http.StatusUnauthorized, problem.CodeInvalidMFACode, "invalid mfa code")
+			return
+		}
+	}
+
+	var currentRole string
+	err = tx.GetContext(r.Context(), &currentRole, `SELECT role FROM memberships WHERE tenant_id=? AND user_id=?`, inv.TenantID, user.ID)
+	switch {
+	case err == sql.ErrNoRows:
+		if _, err := tx.ExecContext(r.Context(), `INSERT INTO memberships (tenant_id, user_id, role) VALUES (?, ?, ?)`, inv.TenantID, user.ID, inv.Role); err != nil {
+			writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateMembership, "could not create membership")
+			return
+		}
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	default:
+		// ja e membro (entrou por outro caminho): o role atual e mantido, mas o
+		// vinculo de colaborador nao vale para roles elevados
+		if inv.Role == roleCollaborator && normalizeRole(currentRole) != roleCollaborator {
+			writeError(w, http.StatusConflict, problem.CodeUserAlreadyHasElevatedRole, "user already has elevated role")
+			return
+		}
+	}
+
+	if inv.EmployeeID != nil {
+		conflict, err := linkInvitedEmployee(r.Context(), tx, inv, user.ID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+			return
+		}
+		if conflict != nil {
+			writeProblem(w, conflict)
+			return
+		}
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `
+		UPDATE invitations SET status='accepted', accepted_at=UTC_TIMESTAMP(), accepted_user_id=?
+		WHERE id=?`, user.ID, inv.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+	if err := insertAudit(tx, r, inv.TenantID, user.ID, "accept_invite", "invitations", int64(inv.ID), nil, map[string]any{
+		"user_id": user.ID,
+		"role":    inv.Role,
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	resp, err := h.issueSession(tx, r, user.ID, inv.TenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// linkInvitedEmployee vincula o usuario ao cadastro de funcionario do convite do
+// RH. Devolve o conflito (409) quando o vinculo nao e mais possivel.
+func linkInvitedEmployee(ctx context.Context, tx *sqlx.Tx, inv Invitation, userID uint64) (*problem.Problem, error) {
+	var status string
+	err := tx.GetContext(ctx, &status, `SELECT status FROM employees WHERE tenant_id=? AND id=?`, inv.TenantID, *inv.EmployeeID)
+	if err == sql.ErrNoRows {
+		return problem.New(http.StatusConflict, problem.CodeEmployeeNotFound, "employee not found"), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == "terminated" {
+		return problem.New(http.StatusConflict, problem.CodeEmployeeIsTerminated, "employee is terminated"), nil
+	}
+
+	var linkedEmployeeID uint64
+	err = tx.GetContext(ctx, &linkedEmployeeID, `SELECT employee_id FROM hr_employee_user_links WHERE tenant_id=? AND user_id=?`, inv.TenantID, userID)
+	if err == nil && linkedEmployeeID != *inv.EmployeeID {
+		return problem.New(http.StatusConflict, problem.CodeUserLinkedToOtherEmployee, "user already linked to another employee"), nil
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO hr_employee_user_links (tenant_id, employee_id, user_id, linked_by)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE user_id=VALUES(user_id), linked_by=VALUES(linked_by)
+	`, inv.TenantID, *inv.EmployeeID, userID, inv.InvitedBy)
+	return nil, err
+}
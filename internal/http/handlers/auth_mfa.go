@@ -0,0 +1,598 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+	"saas-api/internal/totp"
+)
+
+const (
+	mfaChallengeTTL   = 5 * time.Minute
+	mfaMaxAttempts    = 5
+	mfaTOTPSkew       = 1
+	recoveryCodeCount = 10
+)
+
+var errMFANotEnabled = errors.New("mfa not enabled")
+
+type mfaChallengeResp struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type mfaVerifyReq struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type mfaCodeReq struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+	Password     string `json:"password"` // desativar e novos codigos de recuperacao
+}
+
+type mfaStatusResp struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+	RequiredByTenant       bool       `json:"required_by_tenant"`
+}
+
+type mfaSetupResp struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+type mfaActivateResp struct {
+	authResp
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type userMFA struct {
+	Secret       string        `db:"totp_secret"`
+	EnabledAt    sql.NullTime  `db:"enabled_at"`
+	LastUsedStep sql.NullInt64 `db:"last_used_step"`
+}
+
+func loadUserMFA(ctx context.Context, q sqlx.QueryerContext, userID uint64, forUpdate bool) (userMFA, error) {
+	query := `SELECT totp_secret, enabled_at, last_used_step FROM user_mfa WHERE user_id=?`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+	var m userMFA
+	err := sqlx.GetContext(ctx, q, &m, query, userID)
+	return m, err
+}
+
+func userMFAEnabled(ctx context.Context, q sqlx.QueryerContext, userID uint64) (bool, error) {
+	var enabled bool
+	err := sqlx.GetContext(ctx, q, &enabled, `SELECT COUNT(*) > 0 FROM user_mfa WHERE user_id=? AND enabled_at IS NOT NULL`, userID)
+	return enabled, err
+}
+
+// startMFAChallenge e a primeira etapa do login com MFA: a senha ja foi
+// conferida e o cliente recebe um mfa_token curto para enviar o codigo.
+func (h *AuthHandler) startMFAChallenge(w http.ResponseWriter, r *http.Request, userID, tenantID uint64) {
+	raw, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+	if _, err := h.DB.ExecContext(r.Context(), `
+		INSERT INTO mfa_challenges (user_id, tenant_id, token_hash, expires_at, ip)
+		VALUES (?, ?, ?, ?, ?)`,
+		userID, tenantID, tokenHash, time.Now().UTC().Add(mfaChallengeTTL), clientIP(r)); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db insert error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mfaChallengeResp{
+		MFARequired: true,
+		MFAToken:    raw,
+		ExpiresIn:   int64(mfaChallengeTTL.Seconds()),
+	})
+}
+
+// VerifyMFA e a segunda etapa do login: troca mfa_token + codigo TOTP (ou
+// codigo de recuperacao) pela sessao.
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req mfaVerifyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.MFAToken = strings.TrimSpace(req.MFAToken)
+	if req.MFAToken == "" || (strings.TrimSpace(req.Code) == "" && strings.TrimSpace(req.RecoveryCode) == "") {
+		writeError(w, http.StatusBadRequest, problem.CodeMFAChallengeRequired, "mfa_token and code or recovery_code are required")
+		return
+	}
+	// tentativa errada precisa ser gravada mesmo se o cliente desconectar
+	r = r.WithContext(context.WithoutCancel(r.Context()))
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	var ch struct {
+		ID        uint64       `db:"id"`
+		UserID    uint64       `db:"user_id"`
+		TenantID  uint64       `db:"tenant_id"`
+		Attempts  int          `db:"attempts"`
+		ExpiresAt time.Time    `db:"expires_at"`
+		UsedAt    sql.NullTime `db:"used_at"`
+	}
+	err = tx.GetContext(r.Context(), &ch, `
+		SELECT id, user_id, tenant_id, attempts, expires_at, used_at
+		FROM mfa_challenges
+		WHERE token_hash=?
+		FOR UPDATE`, hashToken(req.MFAToken))
+	if err != nil && err != sql.ErrNoRows {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if err == sql.ErrNoRows || ch.UsedAt.Valid || !ch.ExpiresAt.After(time.Now().UTC()) || ch.Attempts >= mfaMaxAttempts {
+		writeError(w, http.StatusUnauthorized, problem.CodeInvalidOrExpiredMFAToken, "invalid or expired mfa token")
+		return
+	}
+
+	ok, err := checkMFACode(r.Context(), tx, ch.UserID, req.Code, req.RecoveryCode)
+	if err != nil && err != errMFANotEnabled {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if !ok {
+		// Estourou as tentativas: o desafio morre e o usuario refaz o login.
+		if _, err := tx.ExecContext(r.Context(), `
+			UPDATE mfa_challenges
+			SET attempts=attempts+1, used_at=IF(attempts+1>=?, UTC_TIMESTAMP(), used_at)
+			WHERE id=?`, mfaMaxAttempts, ch.ID); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+			return
+		}
+
+		// codigo errado conta como falha de login: sem isso bastaria refazer o
+		// login para ganhar novas tentativas contra o TOTP
+		var email string
+		if err := h.DB.GetContext(r.Context(), &email, `SELECT email FROM users WHERE id=?`, ch.UserID); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+			return
+		}
+		if err := h.registerMFAFailure(r, ch.TenantID, ch.UserID, email, "login_failed", "invalid_mfa_code"); err != nil {
+			writeProblem(w, err)
+			return
+		}
+
+		writeError(w, http.StatusUnauthorized, problem.CodeInvalidMFACode, "invalid mfa code")
+		return
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `UPDATE mfa_challenges SET used_at=UTC_TIMESTAMP() WHERE id=?`, ch.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+
+	resp, err := h.issueSession(tx, r, ch.UserID, ch.TenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+
+	var email string
+	_ = tx.GetContext(r.Context(), &email, `SELECT email FROM users WHERE id=?`, ch.UserID)
+	if err := h.loginSucceeded(tx, r, email, ch.UserID, ch.TenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *AuthHandler) GetMFAStatus(w http.ResponseWriter, r *http.Request) {
+	userID := mw.GetUserID(r.Context())
+	tenantID := mw.GetTenantID(r.Context())
+
+	var out mfaStatusResp
+	m, err := loadUserMFA(r.Context(), h.DB, userID, false)
+	if err != nil && err != sql.ErrNoRows {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if err == nil && m.EnabledAt.Valid {
+		out.Enabled = true
+		out.EnabledAt = &m.EnabledAt.Time
+		_ = h.DB.GetContext(r.Context(), &out.RecoveryCodesRemaining, `SELECT COUNT(*) FROM user_mfa_recovery_codes WHERE user_id=? AND used_at IS NULL`, userID)
+	}
+
+	var tenantRequired bool
+	_ = h.DB.GetContext(r.Context(), &tenantRequired, `SELECT mfa_required FROM tenants WHERE id=?`, tenantID)
+	out.RequiredByTenant = tenantRequired && isPrivilegedRole(mw.GetRole(r.Context()))
+
+	writeJSON(w, http.StatusOK, out)
+}
+
+// SetupMFA gera um segredo novo (ainda inativo) e a URI otpauth para o QR code.
+// So passa a valer depois de ActivateMFA com um codigo valido.
+func (h *AuthHandler) SetupMFA(w http.ResponseWriter, r *http.Request) {
+	userID := mw.GetUserID(r.Context())
+
+	var email string
+	if err := h.DB.GetContext(r.Context(), &email, `SELECT email FROM users WHERE id=?`, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	// a linha fica travada ate o commit: um ActivateMFA concorrente nao ativa
+	// um segredo que este setup vai trocar
+	m, err := loadUserMFA(r.Context(), tx, userID, true)
+	if err != nil && err != sql.ErrNoRows {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if err == nil && m.EnabledAt.Valid {
+		writeError(w, http.StatusConflict, problem.CodeMFAAlreadyEnabled, "mfa already enabled")
+		return
+	}
+
+	// so troca o segredo enquanto o MFA nao estiver ativo (nunca desativa)
+	if _, err := tx.ExecContext(r.Context(), `
+		INSERT INTO user_mfa (user_id, totp_secret) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE
+			totp_secret=IF(enabled_at IS NULL, VALUES(totp_secret), totp_secret),
+			last_used_step=IF(enabled_at IS NULL, NULL, last_used_step)`,
+		userID, secret); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+	if err := insertAudit(tx, r, mw.GetTenantID(r.Context()), userID, "setup_mfa", "users", int64(userID), nil, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mfaSetupResp{
+		Secret:     secret,
+		OTPAuthURL: totp.ProvisioningURI(h.JWTIssuer, email, secret),
+	})
+}
+
+// ActivateMFA confirma o segredo com um codigo do app, gera os codigos de
+// recuperacao (mostrados uma unica vez) e troca a sessao atual por uma nova.
+func (h *AuthHandler) ActivateMFA(w http.ResponseWriter, r *http.Request) {
+	userID := mw.GetUserID(r.Context())
+	tenantID := mw.GetTenantID(r.Context())
+
+	var req mfaCodeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	m, err := loadUserMFA(r.Context(), tx, userID, true)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusBadRequest, problem.CodeMFASetupRequired, "mfa setup required")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if m.EnabledAt.Valid {
+		writeError(w, http.StatusConflict, problem.CodeMFAAlreadyEnabled, "mfa already enabled")
+		return
+	}
+
+	step, ok := totp.Validate(m.Secret, req.Code, time.Now(), mfaTOTPSkew)
+	if !ok {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidMFACode, "invalid mfa code")
+		return
+	}
+	if _, err := tx.ExecContext(r.Context(), `UPDATE user_mfa SET enabled_at=UTC_TIMESTAMP(), last_used_step=? WHERE user_id=?`, step, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(r.Context(), tx, userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+	if err := revokeAllUserSessions(r.Context(), tx, userID, "mfa_enabled"); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+
+	resp, err := h.issueSession(tx, r, userID, tenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "enable_mfa", "users", int64(userID), nil, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mfaActivateResp{authResp: resp, RecoveryCodes: codes})
+}
+
+// DisableMFA exige senha e um codigo (TOTP ou recuperacao). Se o tenant exigir
+// MFA para o role, a sessao nova volta a ficar restrita ao cadastro do MFA.
+func (h *AuthHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	userID := mw.GetUserID(r.Context())
+	tenantID := mw.GetTenantID(r.Context())
+
+	var req mfaCodeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	if !h.mfaReauth(w, r, tx, tenantID, userID, req.Password, req.Code, req.RecoveryCode) {
+		return
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `DELETE FROM user_mfa_recovery_codes WHERE user_id=?`, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db delete error")
+		return
+	}
+	if _, err := tx.ExecContext(r.Context(), `DELETE FROM user_mfa WHERE user_id=?`, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db delete error")
+		return
+	}
+	if err := revokeAllUserSessions(r.Context(), tx, userID, "mfa_disabled"); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+
+	resp, err := h.issueSession(tx, r, userID, tenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "disable_mfa", "users", int64(userID), nil, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// RegenerateRecoveryCodes invalida os codigos anteriores e devolve novos. Exige
+// senha e codigo TOTP, como DisableMFA.
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID := mw.GetUserID(r.Context())
+	tenantID := mw.GetTenantID(r.Context())
+
+	var req mfaCodeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	if strings.TrimSpace(req.Code) == "" {
+		writeFieldError(w, "code", problem.RuleRequired, "code is required")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	if !h.mfaReauth(w, r, tx, tenantID, userID, req.Password, req.Code, "") {
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(r.Context(), tx, userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "regenerate_recovery_codes", "users", int64(userID), nil, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"recovery_codes": codes})
+}
+
+// mfaReauth confere a senha atual e o codigo MFA antes de desativar o MFA ou
+// trocar os codigos de recuperacao. Usuario sem senha local (criado pelo SSO)
+// passa so pelo codigo. Cada erro conta no mesmo bloqueio do login (email +
+// IP), entao um access token roubado nao da tentativas ilimitadas contra o
+// TOTP. Ja responde quando devolve false.
+func (h *AuthHandler) mfaReauth(w http.ResponseWriter, r *http.Request, tx *sqlx.Tx, tenantID, userID uint64, password, code, recoveryCode string) bool {
+	var user struct {
+		Email        string `db:"email"`
+		PasswordHash string `db:"password_hash"`
+	}
+	if err := tx.GetContext(r.Context(), &user, `SELECT email, password_hash FROM users WHERE id=?`, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return false
+	}
+	if h.checkLoginLock(w, r, user.Email, clientIP(r)) {
+		return false
+	}
+
+	if user.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+			if err := h.registerMFAFailure(r, tenantID, userID, user.Email, "mfa_reauth_failed", "invalid_password"); err != nil {
+				writeProblem(w, err)
+				return false
+			}
+			writeError(w, http.StatusUnauthorized, problem.CodeInvalidCredentials, "invalid credentials")
+			return false
+		}
+	}
+
+	ok, err := checkMFACode(r.Context(), tx, userID, code, recoveryCode)
+	if err == errMFANotEnabled {
+		writeError(w, http.StatusBadRequest, problem.CodeMFANotEnabled, "mfa not enabled")
+		return false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return false
+	}
+	if !ok {
+		if err := h.registerMFAFailure(r, tenantID, userID, user.Email, "mfa_reauth_failed", "invalid_mfa_code"); err != nil {
+			writeProblem(w, err)
+			return false
+		}
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidMFACode, "invalid mfa code")
+		return false
+	}
+	return true
+}
+
+// registerMFAFailure soma a falha no email e no IP e audita, fora da
+// transacao da requisicao (a falha fica gravada mesmo com rollback).
+func (h *AuthHandler) registerMFAFailure(r *http.Request, tenantID, userID uint64, email, action, reason string) error {
+	policy := h.LoginPolicy.withDefaults()
+	r = r.WithContext(context.WithoutCancel(r.Context()))
+	if err := registerLoginFailure(r.Context(), h.DB, throttleScopeEmail, email, policy.MaxFailures, policy.Lockout); err != nil {
+		return problem.New(http.StatusInternalServerError, problem.CodeDatabase, "db error")
+	}
+	if err := registerLoginFailure(r.Context(), h.DB, throttleScopeIP, clientIP(r), policy.IPMaxFailures, policy.Lockout); err != nil {
+		return problem.New(http.StatusInternalServerError, problem.CodeDatabase, "db error")
+	}
+	if err := insertLoginAudit(h.DB, r, &tenantID, &userID, action, email, reason); err != nil {
+		return problem.New(http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+	}
+	return nil
+}
+
+// checkMFACode confere um codigo TOTP (recusando reuso do mesmo passo) ou
+// consome um codigo de recuperacao. Deve rodar dentro de transacao.
+// errMFANotEnabled indica usuario sem MFA ativo.
+func checkMFACode(ctx context.Context, tx *sqlx.Tx, userID uint64, code, recoveryCode string) (bool, error) {
+	m, err := loadUserMFA(ctx, tx, userID, true)
+	if err == sql.ErrNoRows || (err == nil && !m.EnabledAt.Valid) {
+		return false, errMFANotEnabled
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if code = strings.TrimSpace(code); code != "" {
+		step, ok := totp.Validate(m.Secret, code, time.Now(), mfaTOTPSkew)
+		if !ok || (m.LastUsedStep.Valid && step <= m.LastUsedStep.Int64) {
+			return false, nil
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE user_mfa SET last_used_step=? WHERE user_id=?`, step, userID); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	recoveryCode = normalizeRecoveryCode(recoveryCode)
+	if recoveryCode == "" {
+		return false, nil
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE user_mfa_recovery_codes SET used_at=UTC_TIMESTAMP()
+		WHERE user_id=? AND code_hash=? AND used_at IS NULL`, userID, hashToken(recoveryCode))
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
+
+// replaceRecoveryCodes apaga os codigos atuais e grava novos (apenas o hash).
+func replaceRecoveryCodes(ctx context.Context, exec sqlx.ExecerContext, userID uint64) ([]string, error) {
+	if _, err := exec.ExecContext(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id=?`, userID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		code := raw[:4] + "-" + raw[4:]
+		if _, err := exec.ExecContext(ctx, `INSERT INTO user_mfa_recovery_codes (user_id, code_hash) VALUES (?, ?)`, userID, hashToken(normalizeRecoveryCode(code))); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+	"saas-api/internal/mail"
+)
+
+const minPasswordLen = 8
+
+type forgotPasswordReq struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordReq struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type changePasswordReq struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ForgotPassword envia um link de redefinicao se o email existir. A resposta e
+// sempre 202 para nao revelar quais emails tem conta: na requisicao so roda o
+// limite por IP e por email (igual para qualquer email) e a busca do usuario,
+// o token e o envio do email seguem no Worker. Pedidos alem do limite do email
+// tambem recebem 202, mas nao geram email (quem pede nao consegue travar o
+// reset de outra pessoa devolvendo erros a ela).
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req forgotPasswordReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+	if req.Email == "" {
+		writeFieldError(w, "email", problem.RuleRequired, "email is required")
+		return
+	}
+
+	send, err := h.allowPasswordReset(w, r, req.Email, clientIP(r))
+	if err != nil {
+		writeProblem(w, err)
+		return
+	}
+	if !send {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// o pedido continua depois da resposta, sem o contexto da requisicao
+	bg := r.Clone(context.WithoutCancel(r.Context()))
+	logger := mw.Logger(r.Context())
+	queued := h.Worker.Submit(func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, passwordResetTimeout)
+		defer cancel()
+		if err := h.sendPasswordReset(bg.WithContext(ctx), req.Email); err != nil {
+			logger.Error().Err(err).Msg("password reset: request failed")
+		}
+	})
+	if !queued {
+		logger.Error().Msg("password reset: worker queue full, request dropped")
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// passwordResetTimeout limita o trabalho em segundo plano de ForgotPassword.
+const passwordResetTimeout = 30 * time.Second
+
+// allowPasswordReset aplica os limites do login aos pedidos de reset. IP
+// bloqueado recebe 429 com Retry-After (o limite e de quem pede); email
+// bloqueado devolve false sem erro, e o pedido e descartado em silencio. Os
+// contadores so andam fora do bloqueio, para um ataque continuo nao estender
+// o bloqueio do email para sempre.
+func (h *AuthHandler) allowPasswordReset(w http.ResponseWriter, r *http.Request, email, ip string) (bool, error) {
+	policy := h.LoginPolicy.withDefaults()
+	ctx := context.WithoutCancel(r.Context())
+	dbErr := problem.New(http.StatusInternalServerError, problem.CodeDatabase, "db error")
+	now := time.Now().UTC()
+
+	byIP, err := loadLoginThrottle(ctx, h.DB, throttleScopeResetIP, ip, policy.Lockout)
+	if err != nil {
+		return false, dbErr
+	}
+	if lockedUntil := throttleLockedUntil(now, byIP); !lockedUntil.IsZero() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(lockedUntil.Sub(now).Seconds())+1))
+		return false, problem.New(http.StatusTooManyRequests, problem.CodeRateLimited, "too many password reset requests, try again later")
+	}
+	if err := registerLoginFailure(ctx, h.DB, throttleScopeResetIP, ip, policy.IPMaxFailures, policy.Lockout); err != nil {
+		return false, dbErr
+	}
+
+	byEmail, err := loadLoginThrottle(ctx, h.DB, throttleScopeResetEmail, email, policy.Lockout)
+	if err != nil {
+		return false, dbErr
+	}
+	if !throttleLockedUntil(now, byEmail).IsZero() {
+		return false, nil
+	}
+	if err := registerLoginFailure(ctx, h.DB, throttleScopeResetEmail, email, policy.MaxFailures, policy.Lockout); err != nil {
+		return false, dbErr
+	}
+	return true, nil
+}
+
+// sendPasswordReset gera o token e envia o link se o email tiver conta (email
+// desconhecido nao faz nada). r ja vem com o contexto do segundo plano.
+func (h *AuthHandler) sendPasswordReset(r *http.Request, email string) error {
+	ctx := r.Context()
+	var user struct {
+		ID   uint64 `db:"id"`
+		Name string `db:"name"`
+	}
+	err := h.DB.GetContext(ctx, &user, `SELECT id, name FROM users WHERE email=?`, email)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load user: %w", err)
+	}
+
+	raw, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("token: %w", err)
+	}
+
+	tx, err := h.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Um pedido novo invalida os links anteriores ainda nao usados.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE password_reset_tokens SET used_at=UTC_TIMESTAMP()
+		WHERE user_id=? AND used_at IS NULL`, user.ID); err != nil {
+		return fmt.Errorf("invalidate tokens: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, ip)
+		VALUES (?, ?, ?, ?)`,
+		user.ID, tokenHash, time.Now().UTC().Add(h.PasswordResetTTL), clientIP(r)); err != nil {
+		return fmt.Errorf("insert token: %w", err)
+	}
+	if err := insertLoginAudit(tx, r, nil, &user.ID, "password_reset_requested", email, ""); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	msg := mail.Message{
+		To:      email,
+		Subject: "Redefinicao de senha",
+		Text: fmt.Sprintf(
+			"Ola %s,\n\nRecebemos um pedido para redefinir sua senha. Use o link abaixo (valido por %d minutos):\n\n%s\n\nSe voce nao pediu a redefinicao, ignore este email.\n",
+			user.Name, int(h.PasswordResetTTL.Minutes()), appLink(h.AppBaseURL, "/reset-password", raw),
+		),
+	}
+	if err := h.Mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send email (user %d): %w", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword troca a senha usando um token de redefinicao (uso unico) e
+// derruba todas as sessoes do usuario.
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req resetPasswordReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" {
+		writeFieldError(w, "token", problem.RuleRequired, "token is required")
+		return
+	}
+	if len(req.Password) < minPasswordLen {
+		writeFieldError(w, "password", problem.RuleMinLength, "password must be at least 8 chars", 8)
+		return
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodePasswordError, "password error")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	var t struct {
+		ID        uint64       `db:"id"`
+		UserID    uint64       `db:"user_id"`
+		ExpiresAt time.Time    `db:"expires_at"`
+		UsedAt    sql.NullTime `db:"used_at"`
+	}
+	err = tx.GetContext(r.Context(), &t, `
+		SELECT id, user_id, expires_at, used_at
+		FROM password_reset_tokens
+		WHERE token_hash=?
+		FOR UPDATE`, hashToken(req.Token))
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidOrExpiredToken, "invalid or expired token")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if t.UsedAt.Valid || !t.ExpiresAt.After(time.Now().UTC()) {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidOrExpiredToken, "invalid or expired token")
+		return
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `UPDATE password_reset_tokens SET used_at=UTC_TIMESTAMP() WHERE id=?`, t.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+	if err := setUserPassword(r.Context(), tx, t.UserID, string(passHash), false); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+	if err := revokeAllUserSessions(r.Context(), tx, t.UserID, "password_reset"); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+
+	var email string
+	_ = tx.GetContext(r.Context(), &email, `SELECT email FROM users WHERE id=?`, t.UserID)
+	if err := insertLoginAudit(tx, r, nil, &t.UserID, "password_reset", email, ""); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChangePassword troca a senha do usuario autenticado. As demais sessoes sao
+// revogadas e uma sessao nova e devolvida para o cliente atual.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID := mw.GetUserID(r.Context())
+	tenantID := mw.GetTenantID(r.Context())
+
+	var req changePasswordReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	if req.CurrentPassword == "" {
+		writeFieldError(w, "current_password", problem.RuleRequired, "current_password is required")
+		return
+	}
+	if len(req.NewPassword) < minPasswordLen {
+		writeFieldError(w, "new_password", problem.RuleMinLength, "new_password must be at least 8 chars", 8)
+		return
+	}
+	if req.NewPassword == req.CurrentPassword {
+		writeFieldError(w, "new_password", problem.RuleMustDiffer, "new_password must differ from current_password", "current_password")
+		return
+	}
+
+	var currentHash string
+	if err := h.DB.GetContext(r.Context(), &currentHash, `SELECT password_hash FROM users WHERE id=?`, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(currentHash), []byte(req.CurrentPassword)); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidCurrentPassword, "invalid current password")
+		return
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodePasswordError, "password error")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	if err := setUserPassword(r.Context(), tx, userID, string(passHash), false); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+	if err := revokeAllUserSessions(r.Context(), tx, userID, "password_change"); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+
+	resp, err := h.issueSession(tx, r, userID, tenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "change_password", "users", int64(userID), nil, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// setUserPassword grava o hash novo; mustChange marca senha provisoria que o
+// usuario precisa trocar no proximo acesso.
+func setUserPassword(ctx context.Context, exec sqlx.ExecerContext, userID uint64, passHash string, mustChange bool) error {
+	_, err := exec.ExecContext(ctx, `
+		UPDATE users
+		SET password_hash=?, must_change_password=?, password_changed_at=UTC_TIMESTAMP()
+		WHERE id=?`, passHash, mustChange, userID)
+	return err
+}
+
+// appLink monta um link do frontend com o token na query string.
+func appLink(baseURL, path, token string) string {
+	base := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	return base + path + "?token=" + url.QueryEscape(token)
+}
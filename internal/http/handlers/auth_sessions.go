@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+)
+
+const refreshTokenBytes = 32
+
+var errSessionInvalid = errors.New("session invalid")
+
+type refreshReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type authSession struct {
+	ID        uint64       `db:"id"`
+	TenantID  uint64       `db:"tenant_id"`
+	UserID    uint64       `db:"user_id"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	RevokedAt sql.NullTime `db:"revoked_at"`
+}
+
+type sessionMembership struct {
+	TenantName   string         `db:"tenant_name"`
+	TenantSlug   string         `db:"tenant_slug"`
+	Role         string         `db:"role"`
+	TokenVersion uint64         `db:"token_version"`
+	Locale       sql.NullString `db:"locale"`
+
+	MustChangePassword bool `db:"must_change_password"`
+	MFAEnabled         bool `db:"mfa_enabled"`
+	TenantMFARequired  bool `db:"tenant_mfa_required"`
+}
+
+const sessionMembershipQuery = `
+	SELECT t.name AS tenant_name, t.slug AS tenant_slug, m.role, m.token_version, u.locale, u.must_change_password,
+	       (f.enabled_at IS NOT NULL) AS mfa_enabled, t.mfa_required AS tenant_mfa_required
+	FROM memberships m
+	INNER JOIN tenants t ON t.id = m.tenant_id
+	INNER JOIN users u ON u.id = m.user_id
+	LEFT JOIN user_mfa f ON f.user_id = m.user_id
+	WHERE m.tenant_id=? AND m.user_id=?`
+
+// mfaEnrollRequired e o ponto unico em que a exigencia de MFA do tenant e
+// aplicada: todo token passa por claims() antes do makeToken.
+func (m sessionMembership) mfaEnrollRequired() bool {
+	return m.TenantMFARequired && isPrivilegedRole(m.Role) && !m.MFAEnabled
+}
+
+func (m sessionMembership) claims(userID, tenantID, sessionID uint64) mw.Claims {
+	return mw.Claims{
+		UserID:             userID,
+		TenantID:           tenantID,
+		Role:               m.Role,
+		SessionID:          sessionID,
+		TokenVersion:       m.TokenVersion,
+		MustChangePassword: m.MustChangePassword,
+		MFAEnrollRequired:  m.mfaEnrollRequired(),
+		Locale:             m.Locale.String,
+	}
+}
+
+// newOpaqueToken gera um token aleatorio (enviado ao cliente) e o hash que vai
+// para o banco. O valor em claro nunca e persistido.
+func newOpaqueToken() (string, string, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(b)
+	return raw, hashToken(raw), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(raw)))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueSession cria uma sessao de refresh para o par usuario/tenant e devolve
+// o access token ja amarrado a ela (claims sid/tv).
+func (h *AuthHandler) issueSession(exec sqlx.ExtContext, r *http.Request, userID, tenantID uint64) (authResp, error) {
+	var m sessionMembership
+	if err := sqlx.GetContext(r.Context(), exec, &m, sessionMembershipQuery, tenantID, userID); err != nil {
+		return authResp{}, err
+	}
+	m.Role = normalizeRole(m.Role)
+
+	refresh, refreshHash, err := newOpaqueToken()
+	if err != nil {
+		return authResp{}, err
+	}
+
+	now := time.Now().UTC()
+	res, err := exec.ExecContext(r.Context(), `
+		INSERT INTO auth_sessions (tenant_id, user_id, refresh_token_hash, expires_at, last_used_at, ip, user_agent)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		tenantID, userID, refreshHash, now.Add(h.RefreshTTL), now, clientIP(r), truncate(r.UserAgent(), 255),
+	)
+	if err != nil {
+		return authResp{}, err
+	}
+	sid64, _ := res.LastInsertId()
+
+	token, err := h.makeToken(m.claims(userID, tenantID, uint64(sid64)))
+	if err != nil {
+		return authResp{}, err
+	}
+
+	return authResp{
+		AccessToken:  token,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(h.JWTTTL.Seconds()),
+		TenantID:     tenantID,
+		TenantName:   m.TenantName,
+		TenantSlug:   m.TenantSlug,
+		UserID:       userID,
+		Role:         m.Role,
+
+		MustChangePassword: m.MustChangePassword,
+		MFAEnrollRequired:  m.mfaEnrollRequired(),
+	}, nil
+}
+
+// Refresh troca um refresh token valido por um novo par de tokens. O refresh
+// token e rotacionado a cada uso; reapresentar um token ja rotacionado revoga a
+// sessao inteira (indicio de vazamento).
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.RefreshToken = strings.TrimSpace(req.RefreshToken)
+	if req.RefreshToken == "" {
+		writeFieldError(w, "refresh_token", problem.RuleRequired, "refresh_token is required")
+		return
+	}
+	tokenHash := hashToken(req.RefreshToken)
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	var s authSession
+	err = tx.GetContext(r.Context(), &s, `
+		SELECT id, tenant_id, user_id, expires_at, revoked_at
+		FROM auth_sessions
+		WHERE refresh_token_hash=?
+		FOR UPDATE`, tokenHash)
+	if err != nil && err != sql.ErrNoRows {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	var current, reused *authSession
+	if err == nil {
+		current = &s
+	} else {
+		var prev authSession
+		if err := tx.GetContext(r.Context(), &prev, `
+			SELECT id, tenant_id, user_id, expires_at, revoked_at
+			FROM auth_sessions WHERE previous_token_hash=? LIMIT 1`, tokenHash); err == nil {
+			reused = &prev
+		}
+	}
+
+	now := time.Now().UTC()
+	switch classifyRefresh(current, reused, now) {
+	case refreshReuse:
+		// token antigo reapresentado: provavel roubo, derruba a sessao toda
+		if err := revokeSession(r.Context(), tx, reused.ID, "refresh_reuse"); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+			return
+		}
+		if err := insertAudit(tx, r, reused.TenantID, reused.UserID, "refresh_reuse", "auth_sessions", int64(reused.ID), nil, nil); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+			return
+		}
+		writeError(w, http.StatusUnauthorized, problem.CodeInvalidRefreshToken, "invalid refresh token")
+		return
+	case refreshInvalid:
+		writeError(w, http.StatusUnauthorized, problem.CodeInvalidRefreshToken, "invalid refresh token")
+		return
+	}
+
+	var m sessionMembership
+	err = tx.GetContext(r.Context(), &m, sessionMembershipQuery, s.TenantID, s.UserID)
+	if err == sql.ErrNoRows {
+		if err := revokeSession(r.Context(), tx, s.ID, "membership_removed"); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+			return
+		}
+		writeError(w, http.StatusUnauthorized, problem.CodeNoTenantMembership, "no tenant membership")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	m.Role = normalizeRole(m.Role)
+
+	refresh, refreshHash, err := newOpaqueToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `
+		UPDATE auth_sessions
+		SET previous_token_hash=refresh_token_hash, refresh_token_hash=?, expires_at=?, last_used_at=?
+		WHERE id=?`, refreshHash, now.Add(h.RefreshTTL), now, s.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+
+	token, err := h.makeToken(m.claims(s.UserID, s.TenantID, s.ID))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+
+	if err := insertAudit(tx, r, s.TenantID, s.UserID, "refresh", "auth_sessions", int64(s.ID), nil, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, authResp{
+		AccessToken:  token,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(h.JWTTTL.Seconds()),
+		TenantID:     s.TenantID,
+		TenantName:   m.TenantName,
+		TenantSlug:   m.TenantSlug,
+		UserID:       s.UserID,
+		Role:         m.Role,
+
+		MustChangePassword: m.MustChangePassword,
+		MFAEnrollRequired:  m.mfaEnrollRequired(),
+	})
+}
+
+// Logout revoga a sessao do token atual; o access token deixa de valer na hora.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+
+	act := mw.GetActor(r.Context())
+	sessionID := mw.GetSessionID(r.Context())
+	if act == nil && sessionID == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	if act != nil {
+		if err := endImpersonation(r.Context(), tx, act.ImpersonationID); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+			return
+		}
+		err = insertAudit(tx, r, tenantID, userID, "end_impersonation", "impersonation_sessions", int64(act.ImpersonationID), nil, nil)
+	} else {
+		if err := revokeSession(r.Context(), tx, sessionID, "logout"); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+			return
+		}
+		err = insertAudit(tx, r, tenantID, userID, "logout", "auth_sessions", int64(sessionID), nil, nil)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ValidateSession e usado pelo mw.AuthJWT a cada requisicao: o token so vale
+// enquanto a sessao existir, nao estiver revogada e a versao da membership for a
+// mesma gravada no token (troca de role ou remocao invalidam na hora).
+func (h *AuthHandler) ValidateSession(ctx context.Context, claims *mw.Claims) ([]string, error) {
+	if claims.Act != nil {
+		return h.validateImpersonation(ctx, claims)
+	}
+	if claims.SessionID == 0 {
+		return nil, errSessionInvalid
+	}
+
+	var row struct {
+		RevokedAt    sql.NullTime   `db:"revoked_at"`
+		TokenVersion uint64         `db:"token_version"`
+		Role         string         `db:"role"`
+		CustomPerms  sql.NullString `db:"custom_permissions"`
+	}
+	err := h.DB.GetContext(ctx, &row, `
+		SELECT s.revoked_at, m.token_version, m.role, tr.permissions_json AS custom_permissions
+		FROM auth_sessions s
+		INNER JOIN memberships m ON m.tenant_id = s.tenant_id AND m.user_id = s.user_id
+		LEFT JOIN tenant_roles tr ON tr.tenant_id = m.tenant_id AND tr.role_key = m.role
+		WHERE s.id=? AND s.user_id=? AND s.tenant_id=?`,
+		claims.SessionID, claims.UserID, claims.TenantID)
+	if err != nil {
+		return nil, errSessionInvalid
+	}
+	if !sessionCurrent(row.RevokedAt, row.TokenVersion, claims.TokenVersion) {
+		return nil, errSessionInvalid
+	}
+	// permissoes lidas a cada requisicao: mudar um role customizado vale na hora
+	return rolePermissions(row.Role, row.CustomPerms), nil
+}
+
+// refreshOutcome e o destino de um refresh token apresentado.
+type refreshOutcome int
+
+const (
+	refreshRotate  refreshOutcome = iota // sessao ativa: emite um par novo
+	refreshReuse                         // token ja rotacionado: revoga a sessao
+	refreshInvalid                       // desconhecido, expirado ou revogado
+)
+
+// classifyRefresh decide o refresh a partir da sessao achada pelo hash atual
+// (current) ou, sem ela, pelo hash anterior (reused).
+func classifyRefresh(current, reused *authSession, now time.Time) refreshOutcome {
+	switch {
+	case current != nil:
+		if current.RevokedAt.Valid || !current.ExpiresAt.After(now) {
+			return refreshInvalid
+		}
+		return refreshRotate
+	case reused != nil:
+		return refreshReuse
+	default:
+		return refreshInvalid
+	}
+}
+
+// sessionCurrent diz se o access token ainda vale: sessao nao revogada e mesma
+// token_version da membership.
+func sessionCurrent(revokedAt sql.NullTime, version, claimed uint64) bool {
+	return !revokedAt.Valid && version == claimed
+}
+
+func revokeSession(ctx context.Context, exec sqlx.ExecerContext, sessionID uint64, reason string) error {
+	_, err := exec.ExecContext(ctx, `
+		UPDATE auth_sessions
+		SET revoked_at=UTC_TIMESTAMP(), revoked_reason=?
+		WHERE id=? AND revoked_at IS NULL`, reason, sessionID)
+	return err
+}
+
+// revokeUserSessions derruba todas as sessoes do usuario no tenant.
+func revokeUserSessions(ctx context.Context, exec sqlx.ExecerContext, tenantID, userID uint64, reason string) error {
+	_, err := exec.ExecContext(ctx, `
+		UPDATE auth_sessions
+		SET revoked_at=UTC_TIMESTAMP(), revoked_reason=?
+		WHERE tenant_id=? AND user_id=? AND revoked_at IS NULL`, reason, tenantID, userID)
+	return err
+}
+
+// revokeAllUserSessions derruba as sessoes do usuario em todos os tenants
+// (ex.: troca ou redefinicao de senha).
+func revokeAllUserSessions(ctx context.Context, exec sqlx.ExecerContext, userID uint64, reason string) error {
+	_, err := exec.ExecContext(ctx, `
+		UPDATE auth_sessions
+		SET revoked_at=UTC_TIMESTAMP(), revoked_reason=?
+		WHERE user_id=? AND revoked_at IS NULL`, reason, userID)
+	return err
+}
+
+// bumpTokenVersion invalida os access tokens ja emitidos para a membership;
+// o cliente precisa usar o refresh para receber um token com o role atual.
+func bumpTokenVersion(ctx context.Context, exec sqlx.ExecerContext, tenantID, userID uint64) error {
+	_, err := exec.ExecContext(ctx, `UPDATE memberships SET token_version=token_version+1 WHERE tenant_id=? AND user_id=?`, tenantID, userID)
+	return err
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestClassifyRefresh(t *testing.T) {
+	now := time.Now().UTC()
+	active := &authSession{ID: 1, ExpiresAt: now.Add(time.Hour)}
+	expired := &authSession{ID: 2, ExpiresAt: now.Add(-time.Second)}
+	revoked := &authSession{ID: 3, ExpiresAt: now.Add(time.Hour), RevokedAt: sql.NullTime{Time: now, Valid: true}}
+
+	tests := []struct {
+		name    string
+		current *authSession
+		reused  *authSession
+		want    refreshOutcome
+	}{
+		{"active session rotates", active, nil, refreshRotate},
+		{"expires now", &authSession{ExpiresAt: now}, nil, refreshInvalid},
+		{"expired", expired, nil, refreshInvalid},
+		{"revoked", revoked, nil, refreshInvalid},
+		{"previous token reused", nil, active, refreshReuse},
+		{"reuse of revoked session", nil, revoked, refreshReuse},
+		{"unknown token", nil, nil, refreshInvalid},
+	}
+	for _, tc := range tests {
+		if got := classifyRefresh(tc.current, tc.reused, now); got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSessionCurrent(t *testing.T) {
+	revoked := sql.NullTime{Time: time.Now(), Valid: true}
+	tests := []struct {
+		revokedAt        sql.NullTime
+		version, claimed uint64
+		want             bool
+	}{
+		{sql.NullTime{}, 1, 1, true},
+		{sql.NullTime{}, 2, 1, false}, // role trocado ou membro removido e readicionado
+		{revoked, 1, 1, false},        // logout ou reuse
+		{revoked, 2, 1, false},
+	}
+	for _, tc := range tests {
+		if got := sessionCurrent(tc.revokedAt, tc.version, tc.claimed); got != tc.want {
+			t.Errorf("revoked=%v version=%d claimed=%d: got %v, want %v", tc.revokedAt.Valid, tc.version, tc.claimed, got, tc.want)
+		}
+	}
+}
+
+func TestOpaqueTokenRotation(t *testing.T) {
+	raw1, hash1, err := newOpaqueToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw2, hash2, err := newOpaqueToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw1 == raw2 || hash1 == hash2 {
+		t.Fatal("rotation reused a token")
+	}
+	if hash1 == raw1 || hashToken(raw1) != hash1 {
+		t.Fatalf("hash mismatch: %s", hash1)
+	}
+	// o cliente pode mandar o token com espacos/quebra de linha
+	if hashToken(" "+raw1+"\n") != hash1 {
+		t.Fatal("hash must ignore surrounding whitespace")
+	}
+}
+
+func TestSessionClaims(t *testing.T) {
+	m := sessionMembership{Role: roleFinance, TokenVersion: 7, TenantMFARequired: true}
+	c := m.claims(10, 20, 30)
+	if c.UserID != 10 || c.TenantID != 20 || c.SessionID != 30 || c.TokenVersion != 7 {
+		t.Fatalf("claims = %+v", c)
+	}
+	if !c.MFAEnrollRequired {
+		t.Fatal("finance without MFA in a tenant that requires it must enroll")
+	}
+	m.MFAEnabled = true
+	if m.claims(10, 20, 30).MFAEnrollRequired {
+		t.Fatal("MFA already enabled")
+	}
+}
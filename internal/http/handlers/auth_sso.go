@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+	"saas-api/internal/oidc"
+)
+
+const (
+	ssoStateTTL     = 10 * time.Minute
+	ssoLoginCodeTTL = time.Minute
+)
+
+// errSSOLinkRequired: o email do provedor e de uma conta que nao e membro do
+// tenant; o dono da conta precisa vincular a identidade logado (POST
+// /auth/sso/link), senao o owner de qualquer tenant tomaria a conta apontando
+// o issuer para um provedor dele.
+var errSSOLinkRequired = errors.New("sso link required")
+
+// SSOHandler faz o login OIDC por tenant (authorization code + PKCE) e a
+// configuracao do provedor (owner). O callback do provedor volta para a API,
+// que redireciona o navegador ao frontend com um codigo de uso unico; o
+// frontend troca esse codigo pela sessao em POST /auth/sso/exchange.
+type SSOHandler struct {
+	DB         *sqlx.DB
+	Auth       *AuthHandler
+	OIDC       *oidc.Client
+	APIBaseURL string // URL publica da API, base da redirect_uri
+	AppBaseURL string
+	// AllowInsecureIssuer aceita issuer http em localhost (so em dev).
+	AllowInsecureIssuer bool
+}
+
+type ssoExchangeReq struct {
+	Code string `json:"code"`
+}
+
+type ssoLoginState struct {
+	ID           uint64        `db:"id"`
+	TenantID     uint64        `db:"tenant_id"`
+	Nonce        string        `db:"nonce"`
+	CodeVerifier string        `db:"code_verifier"`
+	LinkUserID   sql.NullInt64 `db:"link_user_id"`
+	UserID       sql.NullInt64 `db:"user_id"`
+}
+
+func (h *SSOHandler) redirectURI() string {
+	return strings.TrimRight(h.APIBaseURL, "/") + "/v1/auth/sso/callback"
+}
+
+func (h *SSOHandler) oidcConfig(c tenantSSOConfig) oidc.Config {
+	cfg := oidc.Config{Issuer: c.Issuer, ClientID: c.ClientID, RedirectURI: h.redirectURI()}
+	if c.ClientSecret != nil {
+		cfg.ClientSecret = *c.ClientSecret
+	}
+	return cfg
+}
+
+// StartSSO redireciona o navegador ao provedor do tenant. state, nonce e o
+// code_verifier ficam no banco; so o hash do state e guardado.
+func (h *SSOHandler) StartSSO(w http.ResponseWriter, r *http.Request) {
+	slug := strings.TrimSpace(strings.ToLower(chi.URLParam(r, "tenant_slug")))
+
+	var tenantID uint64
+	err := h.DB.GetContext(r.Context(), &tenantID, `SELECT id FROM tenants WHERE slug=? LIMIT 1`, slug)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, problem.CodeSSONotAvailable, "sso not available")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	c, err := loadTenantSSOConfig(r.Context(), h.DB, tenantID)
+	if err == sql.ErrNoRows || (err == nil && !c.Enabled) {
+		writeError(w, http.StatusNotFound, problem.CodeSSONotAvailable, "sso not available")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	authURL, err := h.newLoginState(r, c, sql.NullInt64{}, r.URL.Query().Get("login_hint"))
+	if errors.Is(err, errSSOProviderUnavailable) {
+		writeError(w, http.StatusBadGateway, problem.CodeIdentityProviderUnavailable, "identity provider unavailable")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db insert error")
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+var errSSOProviderUnavailable = errors.New("identity provider unavailable")
+
+// newLoginState grava state, nonce e code_verifier (so o hash do state) e
+// devolve a URL de autorizacao do provedor. linkUserID marca um vinculo
+// pedido por usuario logado.
+func (h *SSOHandler) newLoginState(r *http.Request, c tenantSSOConfig, linkUserID sql.NullInt64, loginHint string) (string, error) {
+	state, stateHash, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomString(24)
+	if err != nil {
+		return "", err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := h.OIDC.AuthCodeURL(r.Context(), h.oidcConfig(c), state, nonce, challenge, loginHint)
+	if err != nil {
+		return "", errSSOProviderUnavailable
+	}
+
+	if _, err := h.DB.ExecContext(r.Context(), `
+		INSERT INTO sso_login_states (tenant_id, state_hash, nonce, code_verifier, link_user_id, expires_at, ip)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		c.TenantID, stateHash, nonce, verifier, linkUserID, time.Now().UTC().Add(ssoStateTTL), clientIP(r)); err != nil {
+		return "", err
+	}
+	return authURL, nil
+}
+
+// LinkSSO inicia, para o usuario logado, o login no provedor do tenant atual
+// que vincula a identidade (issuer + sub) a conta dele. E o caminho para
+// contas que ja existiam fora do tenant (o callback responde link_required).
+// Devolve a URL do provedor para o frontend abrir.
+func (h *SSOHandler) LinkSSO(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+
+	c, err := loadTenantSSOConfig(r.Context(), h.DB, tenantID)
+	if err == sql.ErrNoRows || (err == nil && !c.Enabled) {
+		writeError(w, http.StatusNotFound, problem.CodeSSONotAvailable, "sso not available")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	authURL, err := h.newLoginState(r, c, sql.NullInt64{Int64: int64(userID), Valid: true}, "")
+	if errors.Is(err, errSSOProviderUnavailable) {
+		writeError(w, http.StatusBadGateway, problem.CodeIdentityProviderUnavailable, "identity provider unavailable")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db insert error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"authorization_url": authURL})
+}
+
+// SSOCallback recebe o code do provedor, valida o id_token e resolve o usuario:
+// identidade ja vinculada (issuer + sub), senao o usuario que pediu o vinculo
+// (LinkSSO), senao um usuario novo. Email de conta existente nunca e vinculado
+// pelo callback (link_required): o owner controla o provedor e poderia afirmar
+// qualquer email. Sem membership no tenant, cria uma com o default_role
+// configurado (JIT). Erros voltam ao frontend em ?error=.
+func (h *SSOHandler) SSOCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	state := strings.TrimSpace(q.Get("state"))
+	if state == "" {
+		h.redirectError(w, r, "invalid_state")
+		return
+	}
+
+	// consome o state antes de falar com o provedor: cada state vale uma vez
+	res, err := h.DB.ExecContext(r.Context(), `
+		UPDATE sso_login_states SET callback_at=UTC_TIMESTAMP()
+		WHERE state_hash=? AND callback_at IS NULL AND expires_at > UTC_TIMESTAMP()`, hashToken(state))
+	if err != nil {
+		h.redirectError(w, r, "server_error")
+		return
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		h.redirectError(w, r, "invalid_state")
+		return
+	}
+
+	var st ssoLoginState
+	if err := h.DB.GetContext(r.Context(), &st, `
+		SELECT id, tenant_id, nonce, code_verifier, link_user_id, user_id
+		FROM sso_login_states WHERE state_hash=?`, hashToken(state)); err != nil {
+		h.redirectError(w, r, "server_error")
+		return
+	}
+
+	if idpErr := q.Get("error"); idpErr != "" {
+		if err := insertLoginAudit(h.DB, r, &st.TenantID, nil, "login_failed", "", "sso_provider_error:"+truncate(idpErr, 60)); err != nil {
+			h.redirectError(w, r, "server_error")
+			return
+		}
+		h.redirectError(w, r, "provider_error")
+		return
+	}
+
+	c, err := loadTenantSSOConfig(r.Context(), h.DB, st.TenantID)
+	if err != nil || !c.Enabled {
+		h.redirectError(w, r, "sso_not_available")
+		return
+	}
+
+	claims, err := h.OIDC.Exchange(r.Context(), h.oidcConfig(c), q.Get("code"), st.CodeVerifier, st.Nonce)
+	if err != nil {
+		if err := insertLoginAudit(h.DB, r, &st.TenantID, nil, "login_failed", "", "sso_invalid_token"); err != nil {
+			h.redirectError(w, r, "server_error")
+			return
+		}
+		h.redirectError(w, r, "invalid_token")
+		return
+	}
+
+	email := strings.TrimSpace(strings.ToLower(claims.Email))
+	reason := ""
+	switch {
+	case email == "":
+		reason = "email_missing"
+	case claims.EmailVerified == nil || !*claims.EmailVerified:
+		// sem a claim o email nao conta como verificado
+		reason = "email_not_verified"
+	case !c.domainAllowed(email):
+		reason = "domain_not_allowed"
+	}
+	if reason != "" {
+		if err := insertLoginAudit(h.DB, r, &st.TenantID, nil, "login_failed", email, "sso_"+reason); err != nil {
+			h.redirectError(w, r, "server_error")
+			return
+		}
+		h.redirectError(w, r, reason)
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		h.redirectError(w, r, "server_error")
+		return
+	}
+	defer tx.Rollback()
+
+	userID, err := h.resolveSSOUser(tx, r, c, st, claims.Subject, email, claims.Name)
+	if errors.Is(err, errSSOLinkRequired) || errors.Is(err, errSSOIdentityLinked) {
+		tx.Rollback()
+		reason := "link_required"
+		if errors.Is(err, errSSOIdentityLinked) {
+			reason = "identity_already_linked"
+		}
+		if err := insertLoginAudit(h.DB, r, &st.TenantID, nil, "login_failed", email, "sso_"+reason); err != nil {
+			h.redirectError(w, r, "server_error")
+			return
+		}
+		h.redirectError(w, r, reason)
+		return
+	}
+	if err != nil {
+		h.redirectError(w, r, "server_error")
+		return
+	}
+
+	code, codeHash, err := newOpaqueToken()
+	if err != nil {
+		h.redirectError(w, r, "server_error")
+		return
+	}
+	if _, err := tx.ExecContext(r.Context(), `
+		UPDATE sso_login_states SET user_id=?, login_code_hash=?, login_code_expires_at=? WHERE id=?`,
+		userID, codeHash, time.Now().UTC().Add(ssoLoginCodeTTL), st.ID); err != nil {
+		h.redirectError(w, r, "server_error")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.redirectError(w, r, "server_error")
+		return
+	}
+
+	http.Redirect(w, r, h.appCallbackURL(url.Values{"code": {code}}), http.StatusFound)
+}
+
+// errSSOIdentityLinked: no vinculo, a identidade do provedor ja pertence a
+// outra conta.
+var errSSOIdentityLinked = errors.New("sso identity linked to another user")
+
+func (h *SSOHandler) resolveSSOUser(tx *sqlx.Tx, r *http.Request, c tenantSSOConfig, st ssoLoginState, subject, email, name string) (uint64, error) {
+	var userID uint64
+	err := tx.GetContext(r.Context(), &userID, `SELECT user_id FROM user_identities WHERE issuer=? AND subject=? FOR UPDATE`, c.Issuer, subject)
+	switch {
+	case err == nil:
+		// vinculo pedido pela conta logada: a identidade nao pode ser de outra
+		if st.LinkUserID.Valid && userID != uint64(st.LinkUserID.Int64) {
+			return 0, errSSOIdentityLinked
+		}
+		if _, err := tx.ExecContext(r.Context(), `UPDATE user_identities SET email=?, last_login_at=UTC_TIMESTAMP() WHERE issuer=? AND subject=?`,
+			email, c.Issuer, subject); err != nil {
+			return 0, err
+		}
+	case err == sql.ErrNoRows && st.LinkUserID.Valid:
+		// a conta logada ja provou ser dona da identidade local; o email do
+		// provedor nao importa
+		userID = uint64(st.LinkUserID.Int64)
+		if err := insertSSOIdentity(tx, r, c, userID, subject, email); err != nil {
+			return 0, err
+		}
+	case err == sql.ErrNoRows:
+		err = tx.GetContext(r.Context(), &userID, `SELECT id FROM users WHERE email=? FOR UPDATE`, email)
+		switch {
+		case err == sql.ErrNoRows:
+			if strings.TrimSpace(name) == "" {
+				name = email[:strings.Index(email, "@")]
+			}
+			// sem senha local: o usuario entra pelo SSO (ou define uma pelo reset)
+			res, err := tx.ExecContext(r.Context(), `INSERT INTO users (email, name, password_hash) VALUES (?, ?, '')`, email, truncate(name, 200))
+			if err != nil {
+				return 0, err
+			}
+			id, _ := res.LastInsertId()
+			userID = uint64(id)
+		case err != nil:
+			return 0, err
+		default:
+			// conta existente: so a propria conta logada vincula (LinkSSO); a
+			// sessao emitida aqui valeria em todos os tenants dela
+			return 0, errSSOLinkRequired
+		}
+		if err := insertSSOIdentity(tx, r, c, userID, subject, email); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, err
+	}
+
+	var n int
+	if err := tx.GetContext(r.Context(), &n, `SELECT COUNT(*) FROM memberships WHERE tenant_id=? AND user_id=?`, c.TenantID, userID); err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		res, err := tx.ExecContext(r.Context(), `INSERT INTO memberships (tenant_id, user_id, role) VALUES (?, ?, ?)`, c.TenantID, userID, c.DefaultRole)
+		if err != nil {
+			return 0, err
+		}
+		membershipID, _ := res.LastInsertId()
+		if err := insertAudit(tx, r, c.TenantID, userID, "sso_join", "memberships", membershipID, nil,
+			map[string]any{"user_id": userID, "role": c.DefaultRole}); err != nil {
+			return 0, err
+		}
+	}
+	return userID, nil
+}
+
+func insertSSOIdentity(tx *sqlx.Tx, r *http.Request, c tenantSSOConfig, userID uint64, subject, email string) error {
+	res, err := tx.ExecContext(r.Context(), `
+		INSERT INTO user_identities (user_id, issuer, subject, email, last_login_at)
+		VALUES (?, ?, ?, ?, UTC_TIMESTAMP())`, userID, c.Issuer, subject, email)
+	if err != nil {
+		return err
+	}
+	identityID, _ := res.LastInsertId()
+	return insertAudit(tx, r, c.TenantID, userID, "link_identity", "user_identities", identityID, nil,
+		map[string]any{"issuer": c.Issuer, "subject": subject, "email": email})
+}
+
+// ExchangeSSOCode troca o codigo de uso unico do callback pela sessao. Usuario
+// com MFA local ativo recebe o desafio, como no login por senha.
+func (h *SSOHandler) ExchangeSSOCode(w http.ResponseWriter, r *http.Request) {
+	var req ssoExchangeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.Code = strings.TrimSpace(req.Code)
+	if req.Code == "" {
+		writeFieldError(w, "code", problem.RuleRequired, "code is required")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	var st ssoLoginState
+	err = tx.GetContext(r.Context(), &st, `
+		SELECT id, tenant_id, nonce, code_verifier, user_id
+		FROM sso_login_states
+		WHERE login_code_hash=? AND used_at IS NULL AND login_code_expires_at > UTC_TIMESTAMP()
+		FOR UPDATE`, hashToken(req.Code))
+	if err == sql.ErrNoRows || (err == nil && !st.UserID.Valid) {
+		writeError(w, http.StatusUnauthorized, problem.CodeInvalidOrExpiredCode, "invalid or expired code")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	userID := uint64(st.UserID.Int64)
+
+	if _, err := tx.ExecContext(r.Context(), `UPDATE sso_login_states SET used_at=UTC_TIMESTAMP() WHERE id=?`, st.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+
+	var email string
+	if err := tx.GetContext(r.Context(), &email, `SELECT email FROM users WHERE id=?`, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	mfaEnabled, err := userMFAEnabled(r.Context(), tx, userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if mfaEnabled {
+		if err := tx.Commit(); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+			return
+		}
+		h.Auth.startMFAChallenge(w, r, userID, st.TenantID)
+		return
+	}
+
+	resp, err := h.Auth.issueSession(tx, r, userID, st.TenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+	if err := h.Auth.loginSucceeded(tx, r, email, userID, st.TenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *SSOHandler) appCallbackURL(q url.Values) string {
+	return strings.TrimRight(h.AppBaseURL, "/") + "/sso/callback?" + q.Encode()
+}
+
+func (h *SSOHandler) redirectError(w http.ResponseWriter, r *http.Request, code string) {
+	http.Redirect(w, r, h.appCallbackURL(url.Values{"error": {code}}), http.StatusFound)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+)
+
+type switchTenantReq struct {
+	TenantID   *uint64 `json:"tenant_id"`
+	TenantSlug *string `json:"tenant_slug"`
+}
+
+type userMembershipRow struct {
+	TenantID   uint64 `db:"tenant_id" json:"tenant_id"`
+	TenantName string `db:"tenant_name" json:"tenant_name"`
+	TenantSlug string `db:"tenant_slug" json:"tenant_slug"`
+	Role       string `db:"role" json:"role"`
+	Current    bool   `db:"-" json:"current"`
+}
+
+// ListMemberships lista todos os tenants em que o usuario autenticado tem acesso.
+func (h *AuthHandler) ListMemberships(w http.ResponseWriter, r *http.Request) {
+	userID := mw.GetUserID(r.Context())
+	currentTenantID := mw.GetTenantID(r.Context())
+
+	items := make([]userMembershipRow, 0)
+	if err := h.DB.SelectContext(r.Context(), &items, `
+		SELECT m.tenant_id, t.name AS tenant_name, t.slug AS tenant_slug, m.role
+		FROM memberships m
+		INNER JOIN tenants t ON t.id = m.tenant_id
+		WHERE m.user_id=?
+		ORDER BY t.name ASC, m.tenant_id ASC`, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	for i := range items {
+		items[i].Role = normalizeRole(items[i].Role)
+		items[i].Current = items[i].TenantID == currentTenantID
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+// SwitchTenant emite uma nova sessao para outro tenant do mesmo usuario.
+// A sessao atual continua valida (ex.: outra aba no tenant anterior).
+func (h *AuthHandler) SwitchTenant(w http.ResponseWriter, r *http.Request) {
+	userID := mw.GetUserID(r.Context())
+
+	var req switchTenantReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	if req.TenantID == nil && (req.TenantSlug == nil || strings.TrimSpace(*req.TenantSlug) == "") {
+		writeError(w, http.StatusBadRequest, problem.CodeTenantRequired, "tenant_id or tenant_slug is required")
+		return
+	}
+
+	tenantID, err := h.resolveMembershipTenant(r.Context(), userID, req.TenantID, req.TenantSlug)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusForbidden, problem.CodeNoTenantMembership, "no tenant membership")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	resp, err := h.issueSession(tx, r, userID, tenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "switch_tenant", "tenants", int64(tenantID), nil, map[string]any{
+		"from_tenant_id": mw.GetTenantID(r.Context()),
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// resolveMembershipTenant devolve o tenant pedido (por id ou slug) se o usuario
+// for membro dele; sem filtro, devolve a membership mais antiga.
+// sql.ErrNoRows indica que o usuario nao tem acesso ao tenant.
+func (h *AuthHandler) resolveMembershipTenant(ctx context.Context, userID uint64, tenantID *uint64, tenantSlug *string) (uint64, error) {
+	query := `
+		SELECT m.tenant_id
+		FROM memberships m
+		INNER JOIN tenants t ON t.id = m.tenant_id
+		WHERE m.user_id=?`
+	args := []any{userID}
+
+	if tenantID != nil {
+		query += " AND m.tenant_id=?"
+		args = append(args, *tenantID)
+	}
+	if tenantSlug != nil {
+		if slug := strings.TrimSpace(strings.ToLower(*tenantSlug)); slug != "" {
+			query += " AND t.slug=?"
+			args = append(args, slug)
+		}
+	}
+	query += " ORDER BY m.id ASC LIMIT 1"
+
+	var id uint64
+	if err := h.DB.GetContext(ctx, &id, query, args...); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
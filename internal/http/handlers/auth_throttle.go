@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+)
+
+const (
+	throttleScopeEmail = "email"
+	throttleScopeIP    = "ip"
+	// pedidos de redefinicao de senha (contam todo pedido, nao so falhas)
+	throttleScopeResetEmail = "reset_email"
+	throttleScopeResetIP    = "reset_ip"
+
+	loginDelayFreeFailures = 2
+	loginDelayBase         = 500 * time.Millisecond
+	loginDelayMax          = 5 * time.Second
+)
+
+// LoginPolicy define o bloqueio de login por excesso de falhas. As falhas sao
+// contadas por email e por IP dentro de uma janela igual ao tempo de bloqueio.
+type LoginPolicy struct {
+	MaxFailures   int           // por email
+	IPMaxFailures int           // por IP (varios emails a partir do mesmo IP)
+	Lockout       time.Duration // duracao do bloqueio e janela de contagem
+}
+
+type loginThrottle struct {
+	Failures    int          `db:"failures"`
+	LockedUntil sql.NullTime `db:"locked_until"`
+}
+
+func (p LoginPolicy) withDefaults() LoginPolicy {
+	if p.MaxFailures <= 0 {
+		p.MaxFailures = 5
+	}
+	if p.IPMaxFailures <= 0 {
+		p.IPMaxFailures = 20
+	}
+	if p.Lockout <= 0 {
+		p.Lockout = 15 * time.Minute
+	}
+	return p
+}
+
+// progressiveDelay atrasa a resposta a partir da terceira falha seguida
+// (0.5s, 1s, 2s, 4s... ate 5s), encarecendo tentativas em sequencia.
+func progressiveDelay(failures int) time.Duration {
+	if failures <= loginDelayFreeFailures {
+		return 0
+	}
+	d := loginDelayBase
+	for i := loginDelayFreeFailures + 1; i < failures; i++ {
+		d *= 2
+		if d >= loginDelayMax {
+			return loginDelayMax
+		}
+	}
+	return d
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+// loadLoginThrottle devolve o estado atual (zero quando nao ha falhas recentes).
+func loadLoginThrottle(ctx context.Context, q sqlx.QueryerContext, scope, key string, window time.Duration) (loginThrottle, error) {
+	var t loginThrottle
+	err := sqlx.GetContext(ctx, q, &t, `
+		SELECT failures, locked_until
+		FROM login_throttles
+		WHERE scope=? AND throttle_key=? AND (last_failure_at>=? OR locked_until>UTC_TIMESTAMP())`,
+		scope, key, time.Now().UTC().Add(-window))
+	if err == sql.ErrNoRows {
+		return loginThrottle{}, nil
+	}
+	return t, err
+}
+
+// registerLoginFailure soma uma falha e bloqueia ao atingir o limite. Falhas
+// antigas (fora da janela) ou um bloqueio ja vencido recomecam a contagem.
+func registerLoginFailure(ctx context.Context, exec sqlx.ExecerContext, scope, key string, max int, lockout time.Duration) error {
+	now := time.Now().UTC()
+	_, err := exec.ExecContext(ctx, `
+		INSERT INTO login_throttles (scope, throttle_key, failures, last_failure_at, locked_until)
+		VALUES (?, ?, 1, ?, IF(1>=?, ?, NULL))
+		ON DUPLICATE KEY UPDATE
+			failures=IF(last_failure_at<? OR (locked_until IS NOT NULL AND locked_until<=?), 1, failures+1),
+			locked_until=IF(failures>=?, ?, NULL),
+			last_failure_at=VALUES(last_failure_at)`,
+		scope, key, now, max, now.Add(lockout),
+		now.Add(-lockout), now,
+		max, now.Add(lockout),
+	)
+	return err
+}
+
+func clearLoginThrottle(ctx context.Context, exec sqlx.ExecerContext, scope, key string) error {
+	_, err := exec.ExecContext(ctx, `DELETE FROM login_throttles WHERE scope=? AND throttle_key=?`, scope, key)
+	return err
+}
+
+// checkLoginLock responde 429 se o email ou o IP estiverem bloqueados; caso
+// contrario aplica o atraso progressivo e devolve false.
+func (h *AuthHandler) checkLoginLock(w http.ResponseWriter, r *http.Request, email, ip string) bool {
+	policy := h.LoginPolicy.withDefaults()
+
+	byEmail, err := loadLoginThrottle(r.Context(), h.DB, throttleScopeEmail, email, policy.Lockout)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return true
+	}
+	byIP, err := loadLoginThrottle(r.Context(), h.DB, throttleScopeIP, ip, policy.Lockout)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return true
+	}
+
+	now := time.Now().UTC()
+	if lockedUntil := throttleLockedUntil(now, byEmail, byIP); !lockedUntil.IsZero() {
+		if err := insertLoginAudit(h.DB, r, nil, nil, "login_locked", email, "locked"); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+			return true
+		}
+		retry := int(lockedUntil.Sub(now).Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.Itoa(retry))
+		writeError(w, http.StatusTooManyRequests, problem.CodeTooManyLoginAttempts, "too many login attempts, try again later")
+		return true
+	}
+
+	sleepCtx(r.Context(), progressiveDelay(max(byEmail.Failures, byIP.Failures)))
+	return false
+}
+
+// throttleLockedUntil devolve o fim do bloqueio mais longo ainda ativo (zero
+// se nenhum estiver bloqueado).
+func throttleLockedUntil(now time.Time, ts ...loginThrottle) time.Time {
+	var until time.Time
+	for _, t := range ts {
+		if t.LockedUntil.Valid && t.LockedUntil.Time.After(now) && t.LockedUntil.Time.After(until) {
+			until = t.LockedUntil.Time
+		}
+	}
+	return until
+}
+
+// loginFailed registra a falha (email + IP), audita e responde 401.
+func (h *AuthHandler) loginFailed(w http.ResponseWriter, r *http.Request, email, ip string, userID *uint64, reason string) {
+	policy := h.LoginPolicy.withDefaults()
+
+	// a falha precisa ser contada mesmo se o cliente desconectar
+	r = r.WithContext(context.WithoutCancel(r.Context()))
+	_ = registerLoginFailure(r.Context(), h.DB, throttleScopeEmail, email, policy.MaxFailures, policy.Lockout)
+	_ = registerLoginFailure(r.Context(), h.DB, throttleScopeIP, ip, policy.IPMaxFailures, policy.Lockout)
+	if err := insertLoginAudit(h.DB, r, nil, userID, "login_failed", email, reason); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	writeError(w, http.StatusUnauthorized, problem.CodeInvalidCredentials, "invalid credentials")
+}
+
+// loginSucceeded zera o contador do email (o do IP continua, para que uma conta
+// valida do atacante nao libere o IP) e audita o acesso, na transacao que
+// emitiu a sessao.
+func (h *AuthHandler) loginSucceeded(exec sqlx.ExtContext, r *http.Request, email string, userID, tenantID uint64) error {
+	_ = clearLoginThrottle(r.Context(), exec, throttleScopeEmail, email)
+	return insertLoginAudit(exec, r, &tenantID, &userID, "login_success", email, "")
+}
+
+// clientIP devolve o IP do cliente resolvido por mw.ClientIP (proxies
+// confiaveis) ou o host de r.RemoteAddr.
+func clientIP(r *http.Request) string {
+	return mw.GetClientIP(r)
+}
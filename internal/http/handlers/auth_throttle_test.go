@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressiveDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, 500 * time.Millisecond},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{6, 4 * time.Second},
+		{7, loginDelayMax},
+		{50, loginDelayMax},
+	}
+
+	for _, tc := range tests {
+		if got := progressiveDelay(tc.failures); got != tc.want {
+			t.Fatalf("failures=%d: expected %s, got %s", tc.failures, tc.want, got)
+		}
+	}
+}
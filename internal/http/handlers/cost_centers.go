@@ -7,6 +7,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
 )
 
 type CostCenterHandler struct {
@@ -33,32 +34,35 @@ func (h *CostCenterHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	var req createCostCenterReq
 	if err := decodeJSON(r, &req); err != nil {
-		http.Error(w, err.Error(), 400); return
+		writeProblem(w, err); return
 	}
 	req.Name = strings.TrimSpace(req.Name)
-	if req.Name == "" { http.Error(w, "name is required", 400); return }
+	if req.Name == "" { writeFieldError(w, "name", problem.RuleRequired, "name is required"); return }
 
-	tx, err := h.DB.Beginx()
-	if err != nil { http.Error(w, "db error", 500); return }
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil { writeError(w, 500, problem.CodeDatabase, "db error"); return }
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO cost_centers (tenant_id, name, code, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?)`,
 		tenantID, req.Name, cleanPtr(req.Code), userID, userID,
 	)
-	if err != nil { http.Error(w, "could not create cost center (name/code may exist)", 400); return }
+	if err != nil { writeError(w, 400, problem.CodeCouldNotCreateCostCenter, "could not create cost center (name/code may exist)"); return }
 	id64, _ := res.LastInsertId()
 
 	var cc CostCenter
-	if err := tx.Get(&cc, `SELECT id, tenant_id, name, code, created_at, updated_at FROM cost_centers WHERE tenant_id=? AND id=?`,
+	if err := tx.GetContext(r.Context(), &cc, `SELECT id, tenant_id, name, code, created_at, updated_at FROM cost_centers WHERE tenant_id=? AND id=?`,
 		tenantID, id64); err != nil {
-		http.Error(w, "db read error", 500); return
+		writeError(w, 500, problem.CodeDatabase, "db read error"); return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "cost_centers", id64, nil, cc)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "cost_centers", id64, nil, cc); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
-	if err := tx.Commit(); err != nil { http.Error(w, "db commit error", 500); return }
+	if err := tx.Commit(); err != nil { writeError(w, 500, problem.CodeDatabase, "db commit error"); return }
 	writeJSON(w, 201, cc)
 }
 
@@ -66,10 +70,10 @@ func (h *CostCenterHandler) List(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 	items := make([]CostCenter, 0)
 
-	if err := h.DB.Select(&items, `
+	if err := h.DB.SelectContext(r.Context(), &items, `
 		SELECT id, tenant_id, name, code, created_at, updated_at
 		FROM cost_centers WHERE tenant_id=? ORDER BY name ASC`, tenantID); err != nil {
-		http.Error(w, "db error", 500); return
+		writeError(w, 500, problem.CodeDatabase, "db error"); return
 	}
 
 	writeJSON(w, 200, items)
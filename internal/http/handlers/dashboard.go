@@ -1,13 +1,13 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -70,7 +70,7 @@ func ccClause(ccParam string) (string, []any, error) {
 	}
 	ccID, err := strconv.ParseUint(ccParam, 10, 64)
 	if err != nil || ccID == 0 {
-		return "", nil, fmt.Errorf("invalid cost_center_id")
+		return "", nil, problem.New(http.StatusBadRequest, problem.CodeInvalidCostCenterID, "invalid cost_center_id")
 	}
 	return " AND cost_center_id = ?", []any{ccID}, nil
 }
@@ -83,7 +83,7 @@ func (h *DashboardHandler) FinanceSummary(w http.ResponseWriter, r *http.Request
 	ccParam := r.URL.Query().Get("cost_center_id")
 	clause, args, err := ccClause(ccParam)
 	if err != nil {
-		http.Error(w, err.Error(), 400)
+		writeProblem(w, err)
 		return
 	}
 
@@ -99,7 +99,7 @@ func (h *DashboardHandler) FinanceSummary(w http.ResponseWriter, r *http.Request
 	// -------------------------
 	{
 		var a agg
-		_ = h.DB.Get(&a,
+		_ = h.DB.GetContext(r.Context(), &a,
 			`SELECT COALESCE(SUM(amount_cents),0) AS sum, COUNT(*) AS count
 			 FROM payables
 			 WHERE tenant_id=? AND status='draft'`+clause,
@@ -110,7 +110,7 @@ func (h *DashboardHandler) FinanceSummary(w http.ResponseWriter, r *http.Request
 	}
 	{
 		var a agg
-		_ = h.DB.Get(&a,
+		_ = h.DB.GetContext(r.Context(), &a,
 			`SELECT COALESCE(SUM(amount_cents),0) AS sum, COUNT(*) AS count
 			 FROM payables
 			 WHERE tenant_id=? AND status='pending_approval'`+clause,
@@ -121,7 +121,7 @@ func (h *DashboardHandler) FinanceSummary(w http.ResponseWriter, r *http.Request
 	}
 	{
 		var a agg
-		_ = h.DB.Get(&a,
+		_ = h.DB.GetContext(r.Context(), &a,
 			`SELECT COALESCE(SUM(amount_cents),0) AS sum, COUNT(*) AS count
 			 FROM payables
 			 WHERE tenant_id=? AND status='approved'`+clause,
@@ -132,7 +132,7 @@ func (h *DashboardHandler) FinanceSummary(w http.ResponseWriter, r *http.Request
 	}
 	{
 		var a agg
-		_ = h.DB.Get(&a,
+		_ = h.DB.GetContext(r.Context(), &a,
 			`SELECT COALESCE(SUM(amount_cents),0) AS sum, COUNT(*) AS count
 			 FROM payables
 			 WHERE tenant_id=? AND status='paid'`+clause,
@@ -143,7 +143,7 @@ func (h *DashboardHandler) FinanceSummary(w http.ResponseWriter, r *http.Request
 	}
 	{
 		var a agg
-		_ = h.DB.Get(&a,
+		_ = h.DB.GetContext(r.Context(), &a,
 			`SELECT COALESCE(SUM(amount_cents),0) AS sum, COUNT(*) AS count
 			 FROM payables
 			 WHERE tenant_id=? AND status IN ('draft','pending_approval','approved') AND due_date < ?`+clause,
@@ -158,7 +158,7 @@ func (h *DashboardHandler) FinanceSummary(w http.ResponseWriter, r *http.Request
 	// -------------------------
 	{
 		var a agg
-		_ = h.DB.Get(&a,
+		_ = h.DB.GetContext(r.Context(), &a,
 			`SELECT COALESCE(SUM(amount_cents),0) AS sum, COUNT(*) AS count
 			 FROM receivables
 			 WHERE tenant_id=? AND status='draft'`+clause,
@@ -169,7 +169,7 @@ func (h *DashboardHandler) FinanceSummary(w http.ResponseWriter, r *http.Request
 	}
 	{
 		var a agg
-		_ = h.DB.Get(&a,
+		_ = h.DB.GetContext(r.Context(), &a,
 			`SELECT COALESCE(SUM(amount_cents),0) AS sum, COUNT(*) AS count
 			 FROM receivables
 			 WHERE tenant_id=? AND status='issued'`+clause,
@@ -180,7 +180,7 @@ func (h *DashboardHandler) FinanceSummary(w http.ResponseWriter, r *http.Request
 	}
 	{
 		var a agg
-		_ = h.DB.Get(&a,
+		_ = h.DB.GetContext(r.Context(), &a,
 			`SELECT COALESCE(SUM(amount_cents),0) AS sum, COUNT(*) AS count
 			 FROM receivables
 			 WHERE tenant_id=? AND status='paid'`+clause,
@@ -191,7 +191,7 @@ func (h *DashboardHandler) FinanceSummary(w http.ResponseWriter, r *http.Request
 	}
 	{
 		var a agg
-		_ = h.DB.Get(&a,
+		_ = h.DB.GetContext(r.Context(), &a,
 			`SELECT COALESCE(SUM(amount_cents),0) AS sum, COUNT(*) AS count
 			 FROM receivables
 			 WHERE tenant_id=? AND status IN ('draft','issued') AND due_date < ?`+clause,
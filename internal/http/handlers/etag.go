@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"saas-api/internal/http/problem"
+)
+
+// resourceETag e o updated_at (DATETIME(6)) entre aspas: muda a cada UPDATE
+// que altera a linha e o cliente tambem consegue montar a partir do JSON.
+func resourceETag(updatedAt time.Time) string {
+	return `"` + updatedAt.UTC().Format(time.RFC3339Nano) + `"`
+}
+
+// checkIfMatch exige If-Match nas edicoes (428 sem o header, 412 quando o
+// recurso mudou). Aceita "*" e lista separada por virgula; ETags fracas
+// (W/) nunca casam.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, current string) bool {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		writeError(w, http.StatusPreconditionRequired, problem.CodePreconditionRequired, "If-Match header is required")
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == current {
+			return true
+		}
+	}
+	w.Header().Set("ETag", current)
+	writeError(w, http.StatusPreconditionFailed, problem.CodePreconditionFailed, "resource was modified by another request")
+	return false
+}
+
+// notModified grava o ETag e responde 304 quando o If-None-Match ja tem a
+// versao atual (comparacao fraca: W/ e ignorado).
+func notModified(w http.ResponseWriter, r *http.Request, current string) bool {
+	w.Header().Set("ETag", current)
+	header := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestETagPreconditions(t *testing.T) {
+	current := resourceETag(time.Date(2026, 3, 1, 12, 30, 0, 123456000, time.UTC))
+	if current != `"2026-03-01T12:30:00.123456Z"` {
+		t.Fatalf("etag = %s", current)
+	}
+
+	ifMatch := []struct {
+		header string
+		ok     bool
+		status int
+	}{
+		{"", false, http.StatusPreconditionRequired},
+		{"*", true, 0},
+		{current, true, 0},
+		{`"old", ` + current, true, 0},
+		{`"2026-03-01T12:29:59Z"`, false, http.StatusPreconditionFailed},
+		{"W/" + current, false, http.StatusPreconditionFailed},
+	}
+	for _, tc := range ifMatch {
+		r := httptest.NewRequest(http.MethodPatch, "/", nil)
+		if tc.header != "" {
+			r.Header.Set("If-Match", tc.header)
+		}
+		w := httptest.NewRecorder()
+		if ok := checkIfMatch(w, r, current); ok != tc.ok || (!ok && w.Code != tc.status) {
+			t.Errorf("If-Match %q: ok=%v status=%d", tc.header, ok, w.Code)
+		}
+	}
+
+	ifNoneMatch := []struct {
+		header string
+		hit    bool
+	}{
+		{"", false},
+		{current, true},
+		{"W/" + current, true},
+		{"*", true},
+		{`"other"`, false},
+	}
+	for _, tc := range ifNoneMatch {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tc.header != "" {
+			r.Header.Set("If-None-Match", tc.header)
+		}
+		w := httptest.NewRecorder()
+		hit := notModified(w, r, current)
+		if hit != tc.hit || w.Header().Get("ETag") != current || (hit && w.Code != http.StatusNotModified) {
+			t.Errorf("If-None-Match %q: hit=%v status=%d", tc.header, hit, w.Code)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/jmoiron/sqlx"
 
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
 )
 
 type FinanceAPHandler struct {
@@ -83,59 +84,80 @@ func (h *FinanceAPHandler) CreateVendor(w http.ResponseWriter, r *http.Request)
 
 	var req createVendorReq
 	if err := decodeJSON(r, &req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		http.Error(w, "name is required", http.StatusBadRequest)
+		writeFieldError(w, "name", problem.RuleRequired, "name is required")
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO vendors (tenant_id, name, document, email, phone, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		tenantID, req.Name, cleanPtr(req.Document), cleanPtrLower(req.Email), cleanPtr(req.Phone), userID, userID,
 	)
 	if err != nil {
-		http.Error(w, "could not create vendor (name may exist)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateVendor, "could not create vendor (name may exist)")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var v Vendor
-	if err := tx.Get(&v, `SELECT id, tenant_id, name, document, email, phone, created_at, updated_at FROM vendors WHERE tenant_id=? AND id=?`,
+	if err := tx.GetContext(r.Context(), &v, `SELECT id, tenant_id, name, document, email, phone, created_at, updated_at FROM vendors WHERE tenant_id=? AND id=?`,
 		tenantID, id64); err != nil {
-		http.Error(w, "db read error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "vendors", id64, nil, v)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "vendors", id64, nil, v); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, http.StatusCreated, v)
 }
 
+var vendorListSpec = listSpec{
+	IDColumn: "id",
+	IDField:  "id",
+	Sorts: map[string]listSort{
+		"id":         {Column: "id", Field: "id", Kind: listInt},
+		"name":       {Column: "name", Field: "name", Kind: listString},
+		"created_at": {Column: "created_at", Field: "created_at", Kind: listTime},
+	},
+	DefaultSort: "name",
+	Search:      []string{"name", "document", "email"},
+}
+
 func (h *FinanceAPHandler) ListVendors(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 
-	items := make([]Vendor, 0)
+	lq, err := parseListQuery(r, &vendorListSpec)
+	if err != nil {
+		writeProblem(w, err)
+		return
+	}
 
-	if err := h.DB.Select(&items, `SELECT id, tenant_id, name, document, email, phone, created_at, updated_at FROM vendors WHERE tenant_id=? ORDER BY name ASC`, tenantID); err != nil {
-		http.Error(w, "db error", 500)
+	query, args := lq.SQL(`SELECT id, tenant_id, name, document, email, phone, created_at, updated_at FROM vendors`, "tenant_id=?", tenantID)
+	items := make([]Vendor, 0)
+	if err := h.DB.SelectContext(r.Context(), &items, query, args...); err != nil {
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
-	writeJSON(w, 200, items)
+	writeListPage(w, r, lq, items)
 }
 
 func (h *FinanceAPHandler) UpdatePayable(w http.ResponseWriter, r *http.Request) {
@@ -145,55 +167,62 @@ func (h *FinanceAPHandler) UpdatePayable(w http.ResponseWriter, r *http.Request)
 
 	var req updatePayableReq
 	if err := decodeJSON(r, &req); err != nil {
-		http.Error(w, err.Error(), 400)
+		writeProblem(w, err)
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var before Payable
-	if err := tx.Get(&before, `SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
-		FROM payables WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		http.Error(w, "payable not found", 404)
+	if err := tx.GetContext(r.Context(), &before, `SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
+		FROM payables WHERE tenant_id=? AND id=? FOR UPDATE`, tenantID, id); err != nil {
+		writeError(w, 404, problem.CodePayableNotFound, "payable not found")
+		return
+	}
+	if !checkIfMatch(w, r, resourceETag(before.UpdatedAt)) {
 		return
 	}
 
 	if before.Status != "draft" {
-		http.Error(w, "only draft payables can be edited", 400)
+		writeError(w, 400, problem.CodePayableNotDraft, "only draft payables can be edited")
 		return
 	}
 
 	// valida CC se veio
 	if req.CostCenterID != nil {
 		var tmp int
-		if err := tx.Get(&tmp, `SELECT 1 FROM cost_centers WHERE tenant_id=? AND id=?`, tenantID, *req.CostCenterID); err != nil {
-			http.Error(w, "cost center not found", 400)
+		if err := tx.GetContext(r.Context(), &tmp, `SELECT 1 FROM cost_centers WHERE tenant_id=? AND id=?`, tenantID, *req.CostCenterID); err != nil {
+			writeError(w, 400, problem.CodeCostCenterNotFound, "cost center not found")
 			return
 		}
 	}
 
-	_, err = tx.Exec(`UPDATE payables SET cost_center_id=?, updated_by=? WHERE tenant_id=? AND id=?`,
+	_, err = tx.ExecContext(r.Context(), `UPDATE payables SET cost_center_id=?, updated_by=? WHERE tenant_id=? AND id=?`,
 		req.CostCenterID, userID, tenantID, id)
 	if err != nil {
-		http.Error(w, "db update error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db update error")
 		return
 	}
 
 	var after Payable
-	_ = tx.Get(&after, `SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
+	_ = tx.GetContext(r.Context(), &after, `SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
 		FROM payables WHERE tenant_id=? AND id=?`, tenantID, id)
 
-	_ = insertAudit(tx, r, tenantID, userID, "update", "payables", toInt64(id), before, after)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "payables", toInt64(id), before, after); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
+	w.Header().Set("ETag", resourceETag(after.UpdatedAt))
 	writeJSON(w, 200, after)
 }
 
@@ -203,20 +232,20 @@ func (h *FinanceAPHandler) CreatePayable(w http.ResponseWriter, r *http.Request)
 
 	var req createPayableReq
 	if err := decodeJSON(r, &req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	if req.VendorID == 0 {
-		http.Error(w, "vendor_id is required", 400)
+		writeFieldError(w, "vendor_id", problem.RuleRequired, "vendor_id is required")
 		return
 	}
 	if req.AmountCents <= 0 {
-		http.Error(w, "amount_cents must be > 0", 400)
+		writeFieldError(w, "amount_cents", problem.RuleGreater, "amount_cents must be > 0", 0)
 		return
 	}
 	due, err := time.Parse("2006-01-02", strings.TrimSpace(req.DueDate))
 	if err != nil {
-		http.Error(w, "due_date must be YYYY-MM-DD", 400)
+		writeFieldError(w, "due_date", problem.RuleDate, "due_date must be YYYY-MM-DD")
 		return
 	}
 
@@ -225,86 +254,112 @@ func (h *FinanceAPHandler) CreatePayable(w http.ResponseWriter, r *http.Request)
 		cur = strings.ToUpper(strings.TrimSpace(*req.Currency))
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	// garante vendor do mesmo tenant
 	var tmp int
-	if err := tx.Get(&tmp, `SELECT 1 FROM vendors WHERE tenant_id=? AND id=?`, tenantID, req.VendorID); err != nil {
-		http.Error(w, "vendor not found", 400)
+	if err := tx.GetContext(r.Context(), &tmp, `SELECT 1 FROM vendors WHERE tenant_id=? AND id=?`, tenantID, req.VendorID); err != nil {
+		writeError(w, 400, problem.CodeVendorNotFound, "vendor not found")
 		return
 	}
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO payables (tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, status, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?, ?, ?, 'draft', ?, ?)`,
 		tenantID, req.VendorID, cleanPtr(req.Reference), cleanPtr(req.Description),
 		req.AmountCents, cur, due, userID, userID,
 	)
 	if err != nil {
-		http.Error(w, "could not create payable", 400)
+		writeError(w, 400, problem.CodeCouldNotCreatePayable, "could not create payable")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
-	_, _ = tx.Exec(`INSERT INTO payable_events (tenant_id, payable_id, type, message, user_id) VALUES (?, ?, 'created', NULL, ?)`,
+	_, _ = tx.ExecContext(r.Context(), `INSERT INTO payable_events (tenant_id, payable_id, type, message, user_id) VALUES (?, ?, 'created', NULL, ?)`,
 		tenantID, id64, userID,
 	)
 
 	var p Payable
-	if err := tx.Get(&p, `
+	if err := tx.GetContext(r.Context(), &p, `
 		SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
 		FROM payables WHERE tenant_id=? AND id=?`, tenantID, id64); err != nil {
-		http.Error(w, "db read error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "payables", id64, nil, p)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "payables", id64, nil, p); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, 201, p)
 }
 
+var payableListSpec = listSpec{
+	IDColumn: "id",
+	IDField:  "id",
+	Sorts: map[string]listSort{
+		"id":           {Column: "id", Field: "id", Kind: listInt},
+		"due_date":     {Column: "due_date", Field: "due_date", Kind: listDate},
+		"amount_cents": {Column: "amount_cents", Field: "amount_cents", Kind: listInt},
+		"created_at":   {Column: "created_at", Field: "created_at", Kind: listTime},
+	},
+	DefaultSort: "-id",
+	Filters: map[string]listFilter{
+		"status":         {Column: "status", Kind: listString, Op: "=", Values: []string{"draft", "pending_approval", "approved", "rejected", "paid", "canceled"}},
+		"vendor_id":      {Column: "vendor_id", Kind: listInt, Op: "="},
+		"cost_center_id": {Column: "cost_center_id", Kind: listInt, Op: "="},
+		"due_from":       {Column: "due_date", Kind: listDate, Op: ">="},
+		"due_to":         {Column: "due_date", Kind: listDate, Op: "<"},
+	},
+	Search: []string{"reference", "description"},
+}
+
 func (h *FinanceAPHandler) ListPayables(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 
-	status := strings.TrimSpace(strings.ToLower(r.URL.Query().Get("status")))
-	if status != "" {
-		ok := map[string]bool{"draft": true, "pending_approval": true, "approved": true, "rejected": true, "paid": true, "canceled": true}
-		if !ok[status] {
-			http.Error(w, "invalid status filter", 400)
-			return
-		}
+	lq, err := parseListQuery(r, &payableListSpec)
+	if err != nil {
+		writeProblem(w, err)
+		return
 	}
 
+	query, args := lq.SQL(`
+		SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
+		FROM payables`, "tenant_id=?", tenantID)
 	items := make([]Payable, 0)
-	if status == "" {
-		if err := h.DB.Select(&items, `
-			SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency,
-       due_date, paid_at, status, cost_center_id, created_at, updated_at
-FROM payables
-WHERE tenant_id=?
-ORDER BY id DESC
-`, tenantID); err != nil {
-			http.Error(w, "db error", 500)
-			return
-		}
-	} else {
-		if err := h.DB.Select(&items, `
-			SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
-			FROM payables WHERE tenant_id=? AND status=? ORDER BY id DESC`, tenantID, status); err != nil {
-			http.Error(w, "db error", 500)
-			return
-		}
+	if err := h.DB.SelectContext(r.Context(), &items, query, args...); err != nil {
+		writeError(w, 500, problem.CodeDatabase, "db error")
+		return
 	}
-	writeJSON(w, 200, items)
+	writeListPage(w, r, lq, items)
+}
+
+// GetPayable devolve o payable com ETag (If-None-Match responde 304).
+func (h *FinanceAPHandler) GetPayable(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var p Payable
+	if err := h.DB.GetContext(r.Context(), &p, `
+		SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
+		FROM payables WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
+		writeError(w, 404, problem.CodePayableNotFound, "payable not found")
+		return
+	}
+	if notModified(w, r, resourceETag(p.UpdatedAt)) {
+		return
+	}
+	writeJSON(w, 200, p)
 }
 
 func (h *FinanceAPHandler) SubmitPayable(w http.ResponseWriter, r *http.Request) {
@@ -325,45 +380,48 @@ func (h *FinanceAPHandler) MarkPaid(w http.ResponseWriter, r *http.Request) {
 	var req eventReq
 	_ = json.NewDecoder(r.Body).Decode(&req)
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var p Payable
-	if err := tx.Get(&p, `
+	if err := tx.GetContext(r.Context(), &p, `
 		SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
 		FROM payables WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		http.Error(w, "payable not found", 404)
+		writeError(w, 404, problem.CodePayableNotFound, "payable not found")
 		return
 	}
 	if p.Status != "approved" {
-		http.Error(w, "only approved payables can be marked paid", 400)
+		writeError(w, 400, problem.CodePayableNotApproved, "only approved payables can be marked paid")
 		return
 	}
 
 	now := time.Now().UTC()
-	_, err = tx.Exec(`UPDATE payables SET status='paid', paid_at=?, updated_by=? WHERE tenant_id=? AND id=?`, now, userID, tenantID, id)
+	_, err = tx.ExecContext(r.Context(), `UPDATE payables SET status='paid', paid_at=?, updated_by=? WHERE tenant_id=? AND id=?`, now, userID, tenantID, id)
 	if err != nil {
-		http.Error(w, "db update error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db update error")
 		return
 	}
 
-	_, _ = tx.Exec(`INSERT INTO payable_events (tenant_id, payable_id, type, message, user_id) VALUES (?, ?, 'paid', ?, ?)`,
+	_, _ = tx.ExecContext(r.Context(), `INSERT INTO payable_events (tenant_id, payable_id, type, message, user_id) VALUES (?, ?, 'paid', ?, ?)`,
 		tenantID, id, cleanPtr(req.Message), userID,
 	)
 
 	var after Payable
-	_ = tx.Get(&after, `
+	_ = tx.GetContext(r.Context(), &after, `
 		SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
 		FROM payables WHERE tenant_id=? AND id=?`, tenantID, id)
 
-	_ = insertAudit(tx, r, tenantID, userID, "update", "payables", toInt64(id), p, after)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "payables", toInt64(id), p, after); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, 200, after)
@@ -377,44 +435,47 @@ func (h *FinanceAPHandler) transition(w http.ResponseWriter, r *http.Request, fr
 	var req eventReq
 	_ = json.NewDecoder(r.Body).Decode(&req)
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var before Payable
-	if err := tx.Get(&before, `
+	if err := tx.GetContext(r.Context(), &before, `
 		SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
 		FROM payables WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		http.Error(w, "payable not found", 404)
+		writeError(w, 404, problem.CodePayableNotFound, "payable not found")
 		return
 	}
 	if before.Status != from {
-		http.Error(w, "invalid status transition", 400)
+		writeError(w, 400, problem.CodeInvalidStatusTransition, "invalid status transition")
 		return
 	}
 
-	_, err = tx.Exec(`UPDATE payables SET status=?, updated_by=? WHERE tenant_id=? AND id=?`, to, userID, tenantID, id)
+	_, err = tx.ExecContext(r.Context(), `UPDATE payables SET status=?, updated_by=? WHERE tenant_id=? AND id=?`, to, userID, tenantID, id)
 	if err != nil {
-		http.Error(w, "db update error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db update error")
 		return
 	}
 
-	_, _ = tx.Exec(`INSERT INTO payable_events (tenant_id, payable_id, type, message, user_id) VALUES (?, ?, ?, ?, ?)`,
+	_, _ = tx.ExecContext(r.Context(), `INSERT INTO payable_events (tenant_id, payable_id, type, message, user_id) VALUES (?, ?, ?, ?, ?)`,
 		tenantID, id, eventType, cleanPtr(req.Message), userID,
 	)
 
 	var after Payable
-	_ = tx.Get(&after, `
+	_ = tx.GetContext(r.Context(), &after, `
 		SELECT id, tenant_id, vendor_id, reference, description, amount_cents, currency, due_date, paid_at, status, cost_center_id, created_at, updated_at
 		FROM payables WHERE tenant_id=? AND id=?`, tenantID, id)
 
-	_ = insertAudit(tx, r, tenantID, userID, "update", "payables", toInt64(id), before, after)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "payables", toInt64(id), before, after); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, 200, after)
@@ -434,13 +495,13 @@ func (h *FinanceAPHandler) ListPayableEvents(w http.ResponseWriter, r *http.Requ
 	id := chi.URLParam(r, "id")
 
 	items := make([]PayableEvent, 0)
-	if err := h.DB.Select(&items, `
+	if err := h.DB.SelectContext(r.Context(), &items, `
 		SELECT id, tenant_id, payable_id, type, message, user_id, created_at
 		FROM payable_events
 		WHERE tenant_id=? AND payable_id=?
 		ORDER BY created_at ASC, id ASC
 	`, tenantID, id); err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	writeJSON(w, 200, items)
@@ -10,6 +10,7 @@ import (
 	"github.com/jmoiron/sqlx"
 
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
 )
 
 type FinanceARHandler struct {
@@ -86,53 +87,60 @@ func (h *FinanceARHandler) UpdateReceivable(w http.ResponseWriter, r *http.Reque
 
 	var req updateReceivableReq
 	if err := decodeJSON(r, &req); err != nil {
-		http.Error(w, err.Error(), 400)
+		writeProblem(w, err)
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var before Receivable
-	if err := tx.Get(&before, `SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
-		FROM receivables WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		http.Error(w, "receivable not found", 404)
+	if err := tx.GetContext(r.Context(), &before, `SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
+		FROM receivables WHERE tenant_id=? AND id=? FOR UPDATE`, tenantID, id); err != nil {
+		writeError(w, 404, problem.CodeReceivableNotFound, "receivable not found")
+		return
+	}
+	if !checkIfMatch(w, r, resourceETag(before.UpdatedAt)) {
 		return
 	}
 	if before.Status != "draft" {
-		http.Error(w, "only draft receivables can be edited", 400)
+		writeError(w, 400, problem.CodeReceivableNotDraft, "only draft receivables can be edited")
 		return
 	}
 
 	if req.CostCenterID != nil {
 		var tmp int
-		if err := tx.Get(&tmp, `SELECT 1 FROM cost_centers WHERE tenant_id=? AND id=?`, tenantID, *req.CostCenterID); err != nil {
-			http.Error(w, "cost center not found", 400)
+		if err := tx.GetContext(r.Context(), &tmp, `SELECT 1 FROM cost_centers WHERE tenant_id=? AND id=?`, tenantID, *req.CostCenterID); err != nil {
+			writeError(w, 400, problem.CodeCostCenterNotFound, "cost center not found")
 			return
 		}
 	}
 
-	_, err = tx.Exec(`UPDATE receivables SET cost_center_id=?, updated_by=? WHERE tenant_id=? AND id=?`,
+	_, err = tx.ExecContext(r.Context(), `UPDATE receivables SET cost_center_id=?, updated_by=? WHERE tenant_id=? AND id=?`,
 		req.CostCenterID, userID, tenantID, id)
 	if err != nil {
-		http.Error(w, "db update error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db update error")
 		return
 	}
 
 	var after Receivable
-	_ = tx.Get(&after, `SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
+	_ = tx.GetContext(r.Context(), &after, `SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
 		FROM receivables WHERE tenant_id=? AND id=?`, tenantID, id)
 
-	_ = insertAudit(tx, r, tenantID, userID, "update", "receivables", toInt64(id), before, after)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "receivables", toInt64(id), before, after); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
+	w.Header().Set("ETag", resourceETag(after.UpdatedAt))
 	writeJSON(w, 200, after)
 }
 
@@ -142,60 +150,82 @@ func (h *FinanceARHandler) CreateCustomer(w http.ResponseWriter, r *http.Request
 
 	var req createCustomerReq
 	if err := decodeJSON(r, &req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		http.Error(w, "name is required", http.StatusBadRequest)
+		writeFieldError(w, "name", problem.RuleRequired, "name is required")
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO customers (tenant_id, name, document, email, phone, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		tenantID, req.Name, cleanPtr(req.Document), cleanPtrLower(req.Email), cleanPtr(req.Phone), userID, userID,
 	)
 	if err != nil {
-		http.Error(w, "could not create customer (name/document may exist)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateCustomer, "could not create customer (name/document may exist)")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var c Customer
-	if err := tx.Get(&c, `SELECT id, tenant_id, name, document, email, phone, created_at, updated_at FROM customers WHERE tenant_id=? AND id=?`,
+	if err := tx.GetContext(r.Context(), &c, `SELECT id, tenant_id, name, document, email, phone, created_at, updated_at FROM customers WHERE tenant_id=? AND id=?`,
 		tenantID, id64); err != nil {
-		http.Error(w, "db read error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "customers", id64, nil, c)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "customers", id64, nil, c); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, http.StatusCreated, c)
 }
 
+var customerListSpec = listSpec{
+	IDColumn: "id",
+	IDField:  "id",
+	Sorts: map[string]listSort{
+		"id":         {Column: "id", Field: "id", Kind: listInt},
+		"name":       {Column: "name", Field: "name", Kind: listString},
+		"created_at": {Column: "created_at", Field: "created_at", Kind: listTime},
+	},
+	DefaultSort: "name",
+	Search:      []string{"name", "document", "email"},
+}
+
 func (h *FinanceARHandler) ListCustomers(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 
-	items := make([]Customer, 0)
-	if err := h.DB.Select(&items, `
+	lq, err := parseListQuery(r, &customerListSpec)
+	if err != nil {
+		writeProblem(w, err)
+		return
+	}
+
+	query, args := lq.SQL(`
 		SELECT id, tenant_id, name, document, email, phone, created_at, updated_at
-		FROM customers WHERE tenant_id=? ORDER BY name ASC`, tenantID); err != nil {
-		http.Error(w, "db error", 500)
+		FROM customers`, "tenant_id=?", tenantID)
+	items := make([]Customer, 0)
+	if err := h.DB.SelectContext(r.Context(), &items, query, args...); err != nil {
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
-	writeJSON(w, 200, items)
+	writeListPage(w, r, lq, items)
 }
 
 func (h *FinanceARHandler) CreateReceivable(w http.ResponseWriter, r *http.Request) {
@@ -204,21 +234,21 @@ func (h *FinanceARHandler) CreateReceivable(w http.ResponseWriter, r *http.Reque
 
 	var req createReceivableReq
 	if err := decodeJSON(r, &req); err != nil {
-		http.Error(w, err.Error(), 400)
+		writeProblem(w, err)
 		return
 	}
 	if req.CustomerID == 0 {
-		http.Error(w, "customer_id is required", 400)
+		writeFieldError(w, "customer_id", problem.RuleRequired, "customer_id is required")
 		return
 	}
 	if req.AmountCents <= 0 {
-		http.Error(w, "amount_cents must be > 0", 400)
+		writeFieldError(w, "amount_cents", problem.RuleGreater, "amount_cents must be > 0", 0)
 		return
 	}
 
 	due, err := time.Parse("2006-01-02", strings.TrimSpace(req.DueDate))
 	if err != nil {
-		http.Error(w, "due_date must be YYYY-MM-DD", 400)
+		writeFieldError(w, "due_date", problem.RuleDate, "due_date must be YYYY-MM-DD")
 		return
 	}
 
@@ -227,81 +257,111 @@ func (h *FinanceARHandler) CreateReceivable(w http.ResponseWriter, r *http.Reque
 		cur = strings.ToUpper(strings.TrimSpace(*req.Currency))
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	// garante customer do mesmo tenant
 	var tmp int
-	if err := tx.Get(&tmp, `SELECT 1 FROM customers WHERE tenant_id=? AND id=?`, tenantID, req.CustomerID); err != nil {
-		http.Error(w, "customer not found", 400)
+	if err := tx.GetContext(r.Context(), &tmp, `SELECT 1 FROM customers WHERE tenant_id=? AND id=?`, tenantID, req.CustomerID); err != nil {
+		writeError(w, 400, problem.CodeCustomerNotFound, "customer not found")
 		return
 	}
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO receivables (tenant_id, customer_id, reference, description, amount_cents, currency, due_date, status, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?, ?, ?, 'draft', ?, ?)`,
 		tenantID, req.CustomerID, cleanPtr(req.Reference), cleanPtr(req.Description),
 		req.AmountCents, cur, due, userID, userID,
 	)
 	if err != nil {
-		http.Error(w, "could not create receivable", 400)
+		writeError(w, 400, problem.CodeCouldNotCreateReceivable, "could not create receivable")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
-	_, _ = tx.Exec(`INSERT INTO receivable_events (tenant_id, receivable_id, type, message, user_id)
+	_, _ = tx.ExecContext(r.Context(), `INSERT INTO receivable_events (tenant_id, receivable_id, type, message, user_id)
 	                VALUES (?, ?, 'created', NULL, ?)`, tenantID, id64, userID)
 
 	var rec Receivable
-	if err := tx.Get(&rec, `
+	if err := tx.GetContext(r.Context(), &rec, `
 		SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
 		FROM receivables WHERE tenant_id=? AND id=?`, tenantID, id64); err != nil {
-		http.Error(w, "db read error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "receivables", id64, nil, rec)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "receivables", id64, nil, rec); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, 201, rec)
 }
 
+var receivableListSpec = listSpec{
+	IDColumn: "id",
+	IDField:  "id",
+	Sorts: map[string]listSort{
+		"id":           {Column: "id", Field: "id", Kind: listInt},
+		"due_date":     {Column: "due_date", Field: "due_date", Kind: listDate},
+		"amount_cents": {Column: "amount_cents", Field: "amount_cents", Kind: listInt},
+		"created_at":   {Column: "created_at", Field: "created_at", Kind: listTime},
+	},
+	DefaultSort: "-id",
+	Filters: map[string]listFilter{
+		"status":         {Column: "status", Kind: listString, Op: "=", Values: []string{"draft", "issued", "paid", "canceled"}},
+		"customer_id":    {Column: "customer_id", Kind: listInt, Op: "="},
+		"cost_center_id": {Column: "cost_center_id", Kind: listInt, Op: "="},
+		"due_from":       {Column: "due_date", Kind: listDate, Op: ">="},
+		"due_to":         {Column: "due_date", Kind: listDate, Op: "<"},
+	},
+	Search: []string{"reference", "description"},
+}
+
 func (h *FinanceARHandler) ListReceivables(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 
-	status := strings.TrimSpace(strings.ToLower(r.URL.Query().Get("status")))
-	if status != "" {
-		ok := map[string]bool{"draft": true, "issued": true, "paid": true, "canceled": true}
-		if !ok[status] {
-			http.Error(w, "invalid status filter", 400)
-			return
-		}
+	lq, err := parseListQuery(r, &receivableListSpec)
+	if err != nil {
+		writeProblem(w, err)
+		return
 	}
 
+	query, args := lq.SQL(`
+		SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
+		FROM receivables`, "tenant_id=?", tenantID)
 	items := make([]Receivable, 0)
-	if status == "" {
-		if err := h.DB.Select(&items, `
-			SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
-			FROM receivables WHERE tenant_id=? ORDER BY id DESC`, tenantID); err != nil {
-			http.Error(w, "db error", 500)
-			return
-		}
-	} else {
-		if err := h.DB.Select(&items, `
-			SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
-			FROM receivables WHERE tenant_id=? AND status=? ORDER BY id DESC`, tenantID, status); err != nil {
-			http.Error(w, "db error", 500)
-			return
-		}
+	if err := h.DB.SelectContext(r.Context(), &items, query, args...); err != nil {
+		writeError(w, 500, problem.CodeDatabase, "db error")
+		return
 	}
-	writeJSON(w, 200, items)
+	writeListPage(w, r, lq, items)
+}
+
+// GetReceivable devolve o recebivel com ETag (If-None-Match responde 304).
+func (h *FinanceARHandler) GetReceivable(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var rec Receivable
+	if err := h.DB.GetContext(r.Context(), &rec, `
+		SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
+		FROM receivables WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
+		writeError(w, 404, problem.CodeReceivableNotFound, "receivable not found")
+		return
+	}
+	if notModified(w, r, resourceETag(rec.UpdatedAt)) {
+		return
+	}
+	writeJSON(w, 200, rec)
 }
 
 func (h *FinanceARHandler) IssueReceivable(w http.ResponseWriter, r *http.Request) {
@@ -317,43 +377,46 @@ func (h *FinanceARHandler) CancelReceivable(w http.ResponseWriter, r *http.Reque
 	var req arEventReq
 	_ = json.NewDecoder(r.Body).Decode(&req)
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var before Receivable
-	if err := tx.Get(&before, `
+	if err := tx.GetContext(r.Context(), &before, `
 		SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
 		FROM receivables WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		http.Error(w, "receivable not found", 404)
+		writeError(w, 404, problem.CodeReceivableNotFound, "receivable not found")
 		return
 	}
 	if before.Status != "draft" && before.Status != "issued" {
-		http.Error(w, "only draft or issued can be canceled", 400)
+		writeError(w, 400, problem.CodeReceivableNotCancelable, "only draft or issued can be canceled")
 		return
 	}
 
-	_, err = tx.Exec(`UPDATE receivables SET status='canceled', updated_by=? WHERE tenant_id=? AND id=?`, userID, tenantID, id)
+	_, err = tx.ExecContext(r.Context(), `UPDATE receivables SET status='canceled', updated_by=? WHERE tenant_id=? AND id=?`, userID, tenantID, id)
 	if err != nil {
-		http.Error(w, "db update error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db update error")
 		return
 	}
 
-	_, _ = tx.Exec(`INSERT INTO receivable_events (tenant_id, receivable_id, type, message, user_id)
+	_, _ = tx.ExecContext(r.Context(), `INSERT INTO receivable_events (tenant_id, receivable_id, type, message, user_id)
 	                VALUES (?, ?, 'canceled', ?, ?)`, tenantID, id, cleanPtr(req.Message), userID)
 
 	var after Receivable
-	_ = tx.Get(&after, `
+	_ = tx.GetContext(r.Context(), &after, `
 		SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
 		FROM receivables WHERE tenant_id=? AND id=?`, tenantID, id)
 
-	_ = insertAudit(tx, r, tenantID, userID, "update", "receivables", toInt64(id), before, after)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "receivables", toInt64(id), before, after); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, 200, after)
@@ -367,47 +430,50 @@ func (h *FinanceARHandler) MarkReceived(w http.ResponseWriter, r *http.Request)
 	var req arEventReq
 	_ = json.NewDecoder(r.Body).Decode(&req)
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var before Receivable
-	if err := tx.Get(&before, `
+	if err := tx.GetContext(r.Context(), &before, `
 		SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
 		FROM receivables WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		http.Error(w, "receivable not found", 404)
+		writeError(w, 404, problem.CodeReceivableNotFound, "receivable not found")
 		return
 	}
 	if before.Status != "issued" {
-		http.Error(w, "only issued receivables can be marked received", 400)
+		writeError(w, 400, problem.CodeReceivableNotIssued, "only issued receivables can be marked received")
 		return
 	}
 
 	now := time.Now().UTC()
 	method := cleanPtr(req.Method)
 
-	_, err = tx.Exec(`UPDATE receivables SET status='paid', received_at=?, received_method=?, updated_by=? WHERE tenant_id=? AND id=?`,
+	_, err = tx.ExecContext(r.Context(), `UPDATE receivables SET status='paid', received_at=?, received_method=?, updated_by=? WHERE tenant_id=? AND id=?`,
 		now, method, userID, tenantID, id)
 	if err != nil {
-		http.Error(w, "db update error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db update error")
 		return
 	}
 
-	_, _ = tx.Exec(`INSERT INTO receivable_events (tenant_id, receivable_id, type, message, user_id)
+	_, _ = tx.ExecContext(r.Context(), `INSERT INTO receivable_events (tenant_id, receivable_id, type, message, user_id)
 	                VALUES (?, ?, 'paid', ?, ?)`, tenantID, id, cleanPtr(req.Message), userID)
 
 	var after Receivable
-	_ = tx.Get(&after, `
+	_ = tx.GetContext(r.Context(), &after, `
 		SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
 		FROM receivables WHERE tenant_id=? AND id=?`, tenantID, id)
 
-	_ = insertAudit(tx, r, tenantID, userID, "update", "receivables", toInt64(id), before, after)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "receivables", toInt64(id), before, after); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, 200, after)
@@ -418,13 +484,13 @@ func (h *FinanceARHandler) ListReceivableEvents(w http.ResponseWriter, r *http.R
 	id := chi.URLParam(r, "id")
 
 	items := make([]ReceivableEvent, 0)
-	if err := h.DB.Select(&items, `
+	if err := h.DB.SelectContext(r.Context(), &items, `
 		SELECT id, tenant_id, receivable_id, type, message, user_id, created_at
 		FROM receivable_events
 		WHERE tenant_id=? AND receivable_id=?
 		ORDER BY created_at ASC, id ASC
 	`, tenantID, id); err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	writeJSON(w, 200, items)
@@ -438,43 +504,46 @@ func (h *FinanceARHandler) transition(w http.ResponseWriter, r *http.Request, fr
 	var req arEventReq
 	_ = json.NewDecoder(r.Body).Decode(&req)
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "db error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var before Receivable
-	if err := tx.Get(&before, `
+	if err := tx.GetContext(r.Context(), &before, `
 		SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
 		FROM receivables WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		http.Error(w, "receivable not found", 404)
+		writeError(w, 404, problem.CodeReceivableNotFound, "receivable not found")
 		return
 	}
 	if before.Status != from {
-		http.Error(w, "invalid status transition", 400)
+		writeError(w, 400, problem.CodeInvalidStatusTransition, "invalid status transition")
 		return
 	}
 
-	_, err = tx.Exec(`UPDATE receivables SET status=?, updated_by=? WHERE tenant_id=? AND id=?`, to, userID, tenantID, id)
+	_, err = tx.ExecContext(r.Context(), `UPDATE receivables SET status=?, updated_by=? WHERE tenant_id=? AND id=?`, to, userID, tenantID, id)
 	if err != nil {
-		http.Error(w, "db update error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db update error")
 		return
 	}
 
-	_, _ = tx.Exec(`INSERT INTO receivable_events (tenant_id, receivable_id, type, message, user_id)
+	_, _ = tx.ExecContext(r.Context(), `INSERT INTO receivable_events (tenant_id, receivable_id, type, message, user_id)
 	                VALUES (?, ?, ?, ?, ?)`, tenantID, id, eventType, cleanPtr(req.Message), userID)
 
 	var after Receivable
-	_ = tx.Get(&after, `
+	_ = tx.GetContext(r.Context(), &after, `
 		SELECT id, tenant_id, customer_id, reference, description, amount_cents, currency, due_date, received_at, received_method, status, cost_center_id, created_at, updated_at
 		FROM receivables WHERE tenant_id=? AND id=?`, tenantID, id)
 
-	_ = insertAudit(tx, r, tenantID, userID, "update", "receivables", toInt64(id), before, after)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "receivables", toInt64(id), before, after); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "db commit error", 500)
+		writeError(w, 500, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, 200, after)
@@ -1,11 +1,164 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"saas-api/internal/db"
+	mw "saas-api/internal/http/middleware"
 )
 
+// Health e o healthcheck legado (estatico, igual ao liveness).
 func Health(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"ok":true}`))
 }
+
+// schedulerStaleAfter e a idade do heartbeat a partir da qual o scheduler do
+// Clockify e considerado travado (ele bate a cada minuto e a cada tenant).
+const schedulerStaleAfter = 15 * time.Minute
+
+// Heartbeat guarda o ultimo sinal de vida de um job em segundo plano. Os
+// metodos aceitam receiver nil (job desligado).
+type Heartbeat struct {
+	last atomic.Int64
+}
+
+// Beat registra um sinal de vida agora.
+func (b *Heartbeat) Beat() {
+	if b != nil {
+		b.last.Store(time.Now().UnixNano())
+	}
+}
+
+// Last devolve o ultimo Beat (zero se nunca bateu).
+func (b *Heartbeat) Last() time.Time {
+	if b == nil {
+		return time.Time{}
+	}
+	if ns := b.last.Load(); ns > 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+// HealthHandler responde as probes do orquestrador e da status page.
+type HealthHandler struct {
+	DB *sqlx.DB
+	// Scheduler e nil quando o sync automatico do Clockify esta desligado.
+	Scheduler *Heartbeat
+	// Timeout das checagens de banco (2s quando zero).
+	Timeout time.Duration
+
+	migrationsOnce sync.Once
+	migrations     *db.MigrationChecker
+	migrationsErr  error
+}
+
+type healthCheck struct {
+	Status         string     `json:"status"`
+	LatencyMS      *int64     `json:"latency_ms,omitempty"`
+	CurrentVersion *int64     `json:"current_version,omitempty"`
+	LatestVersion  *int64     `json:"latest_version,omitempty"`
+	LastHeartbeat  *time.Time `json:"last_heartbeat,omitempty"`
+	AgeSeconds     *int64     `json:"age_seconds,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+type healthResp struct {
+	Status string                 `json:"status"`
+	Time   time.Time              `json:"time"`
+	Checks map[string]healthCheck `json:"checks,omitempty"`
+}
+
+// Live so diz que o processo responde; nao toca no banco para o orquestrador
+// nao reiniciar a API quando o MySQL cai.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, healthResp{Status: "ok", Time: time.Now().UTC()})
+}
+
+// Ready devolve 503 quando o banco nao responde ou ha migrations pendentes.
+// Scheduler travado so rebaixa o status para "degraded" (a API continua
+// atendendo).
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	logger := mw.Logger(r.Context())
+
+	resp := healthResp{Status: "ok", Time: time.Now().UTC(), Checks: map[string]healthCheck{}}
+	status := http.StatusOK
+
+	// erros detalhados so no log: o endpoint e publico
+	started := time.Now()
+	if err := h.DB.PingContext(ctx); err != nil {
+		logger.Warn().Err(err).Msg("readiness: database ping failed")
+		resp.Checks["database"] = healthCheck{Status: "down", Error: "database unreachable"}
+		resp.Checks["migrations"] = healthCheck{Status: "skipped"}
+		status = http.StatusServiceUnavailable
+	} else {
+		latency := time.Since(started).Milliseconds()
+		resp.Checks["database"] = healthCheck{Status: "ok", LatencyMS: &latency}
+
+		state, err := h.migrationStatus(ctx)
+		switch {
+		case err != nil:
+			logger.Warn().Err(err).Msg("readiness: migration status failed")
+			resp.Checks["migrations"] = healthCheck{Status: "down", Error: "could not read migration status"}
+			status = http.StatusServiceUnavailable
+		case state.Pending:
+			resp.Checks["migrations"] = healthCheck{Status: "pending", CurrentVersion: &state.Current, LatestVersion: &state.Latest}
+			status = http.StatusServiceUnavailable
+		default:
+			resp.Checks["migrations"] = healthCheck{Status: "ok", CurrentVersion: &state.Current, LatestVersion: &state.Latest}
+		}
+	}
+
+	resp.Checks["clockify_scheduler"] = schedulerCheck(h.Scheduler, time.Now())
+
+	if status != http.StatusOK {
+		resp.Status = "unavailable"
+	} else if resp.Checks["clockify_scheduler"].Status == "stale" {
+		resp.Status = "degraded"
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, resp)
+}
+
+// migrationStatus monta o checker na primeira probe e reaproveita nas demais.
+func (h *HealthHandler) migrationStatus(ctx context.Context) (db.MigrationState, error) {
+	h.migrationsOnce.Do(func() {
+		h.migrations, h.migrationsErr = db.NewMigrationChecker(h.DB)
+	})
+	if h.migrationsErr != nil {
+		return db.MigrationState{}, h.migrationsErr
+	}
+	return h.migrations.Status(ctx, h.DB)
+}
+
+func schedulerCheck(b *Heartbeat, now time.Time) healthCheck {
+	if b == nil {
+		return healthCheck{Status: "disabled"}
+	}
+	last := b.Last()
+	if last.IsZero() {
+		return healthCheck{Status: "starting"}
+	}
+	last = last.UTC()
+	age := int64(now.Sub(last).Seconds())
+	c := healthCheck{Status: "ok", LastHeartbeat: &last, AgeSeconds: &age}
+	if now.Sub(last) > schedulerStaleAfter {
+		c.Status = "stale"
+	}
+	return c
+}
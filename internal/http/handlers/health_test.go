@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestReadyReportsDatabaseDown(t *testing.T) {
+	// porta fechada: o ping falha na hora
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	dbx, err := sqlx.Open("mysql", "u:p@tcp("+addr+")/x?timeout=1s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbx.Close()
+
+	beat := &Heartbeat{}
+	beat.Beat()
+	h := &HealthHandler{DB: dbx, Scheduler: beat}
+
+	w := httptest.NewRecorder()
+	h.Ready(w, httptest.NewRequest(http.MethodGet, "/v1/health/ready", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var resp healthResp
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "unavailable" || resp.Checks["database"].Status != "down" ||
+		resp.Checks["migrations"].Status != "skipped" || resp.Checks["clockify_scheduler"].Status != "ok" {
+		t.Fatalf("resp = %+v", resp)
+	}
+
+	w = httptest.NewRecorder()
+	h.Live(w, httptest.NewRequest(http.MethodGet, "/v1/health/live", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("live status = %d", w.Code)
+	}
+}
+
+func TestSchedulerCheck(t *testing.T) {
+	now := time.Now()
+	stale := &Heartbeat{}
+	stale.last.Store(now.Add(-schedulerStaleAfter - time.Minute).UnixNano())
+
+	cases := []struct {
+		beat *Heartbeat
+		want string
+	}{
+		{nil, "disabled"},
+		{&Heartbeat{}, "starting"},
+		{stale, "stale"},
+	}
+	for _, tc := range cases {
+		if got := schedulerCheck(tc.beat, now).Status; got != tc.want {
+			t.Errorf("status = %s, want %s", got, tc.want)
+		}
+	}
+}
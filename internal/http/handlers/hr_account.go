@@ -7,23 +7,27 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
 	"golang.org/x/crypto/bcrypt"
 
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
 )
 
 type createEmployeeAccountReq struct {
 	Name     *string `json:"name"`
-	Password *string `json:"password"`
+	Password *string `json:"password"` // vazio para usuario novo: envia convite por email
 }
 
 type employeeAccountResp struct {
 	EmployeeID   uint64 `json:"employee_id"`
 	EmployeeName string `json:"employee_name"`
-	UserID       uint64 `json:"user_id"`
+	UserID       uint64 `json:"user_id,omitempty"`
 	Email        string `json:"email"`
 	Role         string `json:"role"`
 	NewUser      bool   `json:"new_user"`
+
+	Invitation *Invitation `json:"invitation,omitempty"`
 }
 
 type employeeAccountEmployee struct {
@@ -39,38 +43,38 @@ func (h *HRHandler) CreateEmployeeAccount(w http.ResponseWriter, r *http.Request
 
 	employeeID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid employee id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidEmployeeID, "invalid employee id")
 		return
 	}
 
 	var req createEmployeeAccountReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 
 	var emp employeeAccountEmployee
-	if err := h.DB.Get(&emp, `
+	if err := h.DB.GetContext(r.Context(), &emp, `
 		SELECT id, name, email, status
 		FROM employees
 		WHERE tenant_id=? AND id=?
 	`, tenantID, employeeID); err != nil {
 		if err == sql.ErrNoRows {
-			httpError(w, "employee not found", http.StatusNotFound)
+			writeError(w, http.StatusNotFound, problem.CodeEmployeeNotFound, "employee not found")
 			return
 		}
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	if emp.Status == "terminated" {
-		httpError(w, "employee is terminated", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeEmployeeIsTerminated, "employee is terminated")
 		return
 	}
 
 	email := strings.ToLower(strings.TrimSpace(emp.Email.String))
 	if !emp.Email.Valid || email == "" {
-		httpError(w, "employee email is required", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeEmployeeEmailIsRequired, "employee email is required")
 		return
 	}
 
@@ -85,38 +89,39 @@ func (h *HRHandler) CreateEmployeeAccount(w http.ResponseWriter, r *http.Request
 	if req.Password != nil {
 		password = strings.TrimSpace(*req.Password)
 		if password != "" && len(password) < 8 {
-			httpError(w, "password must be at least 8 chars", http.StatusBadRequest)
+			writeFieldError(w, "password", problem.RuleMinLength, "password must be at least 8 chars", 8)
 			return
 		}
 	}
 
 	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var userID uint64
 	newUser := false
-	err = tx.Get(&userID, `SELECT id FROM users WHERE email=?`, email)
+	err = tx.GetContext(r.Context(), &userID, `SELECT id FROM users WHERE email=?`, email)
 	if err != nil {
 		if err != sql.ErrNoRows {
-			httpError(w, "db read error", http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 			return
 		}
-		if len(password) < 8 {
-			httpError(w, "password must be at least 8 chars", http.StatusBadRequest)
+		if password == "" {
+			h.inviteEmployee(w, r, tx, emp, email, accountName)
 			return
 		}
 		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 		if err != nil {
-			httpError(w, "db error", http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 			return
 		}
-		res, err := tx.Exec(`INSERT INTO users (email, name, password_hash) VALUES (?, ?, ?)`, email, accountName, string(hash))
+		// Senha definida pelo RH e provisoria: o colaborador troca no primeiro acesso.
+		res, err := tx.ExecContext(r.Context(), `INSERT INTO users (email, name, password_hash, must_change_password) VALUES (?, ?, ?, 1)`, email, accountName, string(hash))
 		if err != nil {
-			httpError(w, "could not create user", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateUser, "could not create user")
 			return
 		}
 		id64, _ := res.LastInsertId()
@@ -124,85 +129,95 @@ func (h *HRHandler) CreateEmployeeAccount(w http.ResponseWriter, r *http.Request
 		newUser = true
 	} else {
 		var currentRole string
-		roleErr := tx.Get(&currentRole, `SELECT role FROM memberships WHERE tenant_id=? AND user_id=?`, tenantID, userID)
+		roleErr := tx.GetContext(r.Context(), &currentRole, `SELECT role FROM memberships WHERE tenant_id=? AND user_id=?`, tenantID, userID)
 		if roleErr == nil {
 			currentRole = normalizeRole(currentRole)
-			if currentRole == roleOwner || currentRole == roleHR || currentRole == roleFinance {
-				httpError(w, "user already has elevated role", http.StatusBadRequest)
+			// roles embutidos ou customizados do tenant, exceto colaborador
+			elevated, err := roleExists(r.Context(), tx, tenantID, currentRole)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
+				return
+			}
+			if elevated && currentRole != roleCollaborator {
+				writeError(w, http.StatusBadRequest, problem.CodeUserAlreadyHasElevatedRole, "user already has elevated role")
 				return
 			}
 		} else if roleErr != sql.ErrNoRows {
-			httpError(w, "db read error", http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 			return
 		}
 
 		if accountName != "" {
-			if _, err := tx.Exec(`UPDATE users SET name=? WHERE id=?`, accountName, userID); err != nil {
-				httpError(w, "db update error", http.StatusInternalServerError)
+			if _, err := tx.ExecContext(r.Context(), `UPDATE users SET name=? WHERE id=?`, accountName, userID); err != nil {
+				writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 				return
 			}
 		}
 		if password != "" {
 			hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 			if err != nil {
-				httpError(w, "db error", http.StatusInternalServerError)
+				writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+				return
+			}
+			if err := setUserPassword(r.Context(), tx, userID, string(hash), true); err != nil {
+				writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 				return
 			}
-			if _, err := tx.Exec(`UPDATE users SET password_hash=? WHERE id=?`, string(hash), userID); err != nil {
-				httpError(w, "db update error", http.StatusInternalServerError)
+			if err := revokeAllUserSessions(r.Context(), tx, userID, "password_set_by_hr"); err != nil {
+				writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 				return
 			}
 		}
 	}
 
 	var linkedEmployeeID uint64
-	if err := tx.Get(&linkedEmployeeID, `SELECT employee_id FROM hr_employee_user_links WHERE tenant_id=? AND user_id=?`, tenantID, userID); err == nil {
+	if err := tx.GetContext(r.Context(), &linkedEmployeeID, `SELECT employee_id FROM hr_employee_user_links WHERE tenant_id=? AND user_id=?`, tenantID, userID); err == nil {
 		if linkedEmployeeID != employeeID {
-			httpError(w, "user already linked to another employee", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, problem.CodeUserLinkedToOtherEmployee, "user already linked to another employee")
 			return
 		}
 	} else if err != sql.ErrNoRows {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	var membershipRole string
-	err = tx.Get(&membershipRole, `SELECT role FROM memberships WHERE tenant_id=? AND user_id=?`, tenantID, userID)
+	err = tx.GetContext(r.Context(), &membershipRole, `SELECT role FROM memberships WHERE tenant_id=? AND user_id=?`, tenantID, userID)
 	if err == sql.ErrNoRows {
-		if _, err := tx.Exec(`INSERT INTO memberships (tenant_id, user_id, role) VALUES (?, ?, ?)`, tenantID, userID, roleCollaborator); err != nil {
-			httpError(w, "could not create membership", http.StatusBadRequest)
+		if _, err := tx.ExecContext(r.Context(), `INSERT INTO memberships (tenant_id, user_id, role) VALUES (?, ?, ?)`, tenantID, userID, roleCollaborator); err != nil {
+			writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateMembership, "could not create membership")
 			return
 		}
 		membershipRole = roleCollaborator
 	} else if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	} else {
 		membershipRole = normalizeRole(membershipRole)
 		if membershipRole != roleCollaborator {
-			if membershipRole == roleOwner || membershipRole == roleHR || membershipRole == roleFinance {
-				httpError(w, "user already has elevated role", http.StatusBadRequest)
+			elevated, err := roleExists(r.Context(), tx, tenantID, membershipRole)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
+				return
+			}
+			if elevated {
+				writeError(w, http.StatusBadRequest, problem.CodeUserAlreadyHasElevatedRole, "user already has elevated role")
 				return
 			}
-			if _, err := tx.Exec(`UPDATE memberships SET role=? WHERE tenant_id=? AND user_id=?`, roleCollaborator, tenantID, userID); err != nil {
-				httpError(w, "db update error", http.StatusInternalServerError)
+			if _, err := tx.ExecContext(r.Context(), `UPDATE memberships SET role=?, token_version=token_version+1 WHERE tenant_id=? AND user_id=?`, roleCollaborator, tenantID, userID); err != nil {
+				writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 				return
 			}
 			membershipRole = roleCollaborator
 		}
 	}
 
-	if _, err := tx.Exec(`
+	if _, err := tx.ExecContext(r.Context(), `
 		INSERT INTO hr_employee_user_links (tenant_id, employee_id, user_id, linked_by)
 		VALUES (?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE user_id=VALUES(user_id), linked_by=VALUES(linked_by)
 	`, tenantID, employeeID, userID, requesterID); err != nil {
-		httpError(w, "could not link employee account", http.StatusBadRequest)
-		return
-	}
-
-	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotLinkEmployeeAccount, "could not link employee account")
 		return
 	}
 
@@ -214,6 +229,53 @@ func (h *HRHandler) CreateEmployeeAccount(w http.ResponseWriter, r *http.Request
 		Role:         roleCollaborator,
 		NewUser:      newUser,
 	}
-	_ = insertAudit(h.DB, r, tenantID, requesterID, "link_account", "employees", int64(emp.ID), nil, resp)
+	if err := insertAudit(tx, r, tenantID, requesterID, "link_account", "employees", int64(emp.ID), nil, resp); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
 	writeJSON(w, http.StatusCreated, resp)
 }
+
+// inviteEmployee cria um convite de colaborador vinculado ao funcionario; o
+// vinculo e a membership so sao gravados quando o convite for aceito.
+func (h *HRHandler) inviteEmployee(w http.ResponseWriter, r *http.Request, tx *sqlx.Tx, emp employeeAccountEmployee, email, accountName string) {
+	tenantID := mw.GetTenantID(r.Context())
+	requesterID := mw.GetUserID(r.Context())
+
+	if h.Invitations == nil {
+		writeFieldError(w, "password", problem.RuleMinLength, "password must be at least 8 chars", 8)
+		return
+	}
+
+	employeeID := emp.ID
+	inv, token, err := h.Invitations.create(r.Context(), tx, tenantID, requesterID, email, accountName, roleCollaborator, &employeeID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeCouldNotCreateInvitation, "could not create invitation")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, requesterID, "invite", "invitations", int64(inv.ID), nil, inv); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+	h.Invitations.send(r.Context(), tenantID, inv, token)
+
+	writeJSON(w, http.StatusAccepted, employeeAccountResp{
+		EmployeeID:   emp.ID,
+		EmployeeName: emp.Name,
+		Email:        email,
+		Role:         roleCollaborator,
+		NewUser:      true,
+		Invitation:   &inv,
+	})
+}
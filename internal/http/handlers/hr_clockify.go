@@ -15,8 +15,17 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
+	"saas-api/internal/auditchain"
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+	"saas-api/internal/metrics"
+	"saas-api/internal/tracing"
 )
 
 const (
@@ -205,7 +214,7 @@ func newClockifyClient(apiKey string) *clockifyClient {
 	}
 }
 
-func (c *clockifyClient) getJSON(ctx context.Context, path string, query url.Values, dst any) error {
+func (c *clockifyClient) getJSON(ctx context.Context, path string, query url.Values, dst any) (err error) {
 	u, err := url.Parse(c.baseURL + path)
 	if err != nil {
 		return err
@@ -218,19 +227,45 @@ func (c *clockifyClient) getJSON(ctx context.Context, path string, query url.Val
 		c.maxAttempts = 1
 	}
 
+	// um span de cliente por chamada; cada tentativa vira um evento
+	ctx, span := tracing.Tracer().Start(ctx, "clockify GET",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", http.MethodGet),
+			attribute.String("server.address", u.Host),
+			attribute.String("url.path", u.Path),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	var lastErr error
 	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		span.SetAttributes(attribute.Int("clockify.attempts", attempt))
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 		if err != nil {
 			return err
 		}
 		req.Header.Set("X-Api-Key", c.apiKey)
 		req.Header.Set("Accept", "application/json")
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 		resp, err := c.client.Do(req)
 		if err != nil {
 			lastErr = err
-			if c.shouldRetry(0, err, attempt) {
+			retry := c.shouldRetry(0, err, attempt)
+			metrics.ClockifyRequest(0, retry)
+			span.AddEvent("attempt", trace.WithAttributes(
+				attribute.Int("attempt", attempt),
+				attribute.String("error", err.Error()),
+				attribute.Bool("retry", retry),
+			))
+			if retry {
 				delay := c.retryDelay(attempt, "")
 				if sleepErr := sleepWithContext(ctx, delay); sleepErr != nil {
 					return sleepErr
@@ -243,13 +278,21 @@ func (c *clockifyClient) getJSON(ctx context.Context, path string, query url.Val
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
 		resp.Body.Close()
 
+		retry := resp.StatusCode >= 300 && c.shouldRetry(resp.StatusCode, nil, attempt)
+		metrics.ClockifyRequest(resp.StatusCode, retry)
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		span.AddEvent("attempt", trace.WithAttributes(
+			attribute.Int("attempt", attempt),
+			attribute.Int("status", resp.StatusCode),
+			attribute.Bool("retry", retry),
+		))
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			msg := strings.TrimSpace(string(body))
 			if msg == "" {
 				msg = http.StatusText(resp.StatusCode)
 			}
 			lastErr = &clockifyHTTPError{StatusCode: resp.StatusCode, Message: msg}
-			if c.shouldRetry(resp.StatusCode, nil, attempt) {
+			if retry {
 				delay := c.retryDelay(attempt, resp.Header.Get("Retry-After"))
 				if sleepErr := sleepWithContext(ctx, delay); sleepErr != nil {
 					return sleepErr
@@ -388,9 +431,9 @@ func (c *clockifyClient) ListTimeEntries(ctx context.Context, workspaceID, userI
 
 func (h *HRHandler) GetClockifyConfig(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
-	conn, found, err := h.getClockifyConnection(tenantID)
+	conn, found, err := h.getClockifyConnection(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if !found {
@@ -411,9 +454,9 @@ func (h *HRHandler) GetClockifyConfig(w http.ResponseWriter, r *http.Request) {
 func (h *HRHandler) GetClockifyStatus(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 
-	conn, found, err := h.getClockifyConnection(tenantID)
+	conn, found, err := h.getClockifyConnection(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if !found {
@@ -431,7 +474,7 @@ func (h *HRHandler) GetClockifyStatus(w http.ResponseWriter, r *http.Request) {
 		EntriesTotal     int64        `db:"entries_total"`
 		EntriesRunning   int64        `db:"entries_running"`
 	}
-	if err := h.DB.Get(&agg, `
+	if err := h.DB.GetContext(r.Context(), &agg, `
 		SELECT
 			MAX(synced_at) AS last_sync_at,
 			MAX(start_at) AS last_entry_start_at,
@@ -441,54 +484,54 @@ func (h *HRHandler) GetClockifyStatus(w http.ResponseWriter, r *http.Request) {
 		FROM hr_time_entries
 		WHERE tenant_id=?
 	`, tenantID); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	windowStart := dateOnly(time.Now().UTC().AddDate(0, 0, -7))
 	var entriesLast7Days int64
-	if err := h.DB.Get(&entriesLast7Days, `
+	if err := h.DB.GetContext(r.Context(), &entriesLast7Days, `
 		SELECT COUNT(*)
 		FROM hr_time_entries
 		WHERE tenant_id=? AND start_at>=?
 	`, tenantID, windowStart); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	var mappedEmployees int64
-	if err := h.DB.Get(&mappedEmployees, `
+	if err := h.DB.GetContext(r.Context(), &mappedEmployees, `
 		SELECT COUNT(*)
 		FROM hr_clockify_user_links
 		WHERE tenant_id=?
 	`, tenantID); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	var activeEmployees int64
-	if err := h.DB.Get(&activeEmployees, `
+	if err := h.DB.GetContext(r.Context(), &activeEmployees, `
 		SELECT COUNT(*)
 		FROM employees
 		WHERE tenant_id=? AND status <> 'terminated'
 	`, tenantID); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	var activeUnmappedEmployees int64
-	if err := h.DB.Get(&activeUnmappedEmployees, `
+	if err := h.DB.GetContext(r.Context(), &activeUnmappedEmployees, `
 		SELECT COUNT(*)
 		FROM employees e
 		LEFT JOIN hr_clockify_user_links l ON l.tenant_id=e.tenant_id AND l.employee_id=e.id
 		WHERE e.tenant_id=? AND e.status <> 'terminated' AND l.employee_id IS NULL
 	`, tenantID); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	unmapped := make([]clockifyUnmappedPreview, 0, statusUnmappedLimit)
-	if err := h.DB.Select(&unmapped, `
+	if err := h.DB.SelectContext(r.Context(), &unmapped, `
 		SELECT
 			e.id AS employee_id,
 			e.name,
@@ -499,7 +542,7 @@ func (h *HRHandler) GetClockifyStatus(w http.ResponseWriter, r *http.Request) {
 		ORDER BY e.name ASC
 		LIMIT ?
 	`, tenantID, statusUnmappedLimit); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
@@ -537,7 +580,7 @@ func (h *HRHandler) UpsertClockifyConfig(w http.ResponseWriter, r *http.Request)
 
 	var req upsertClockifyConfigReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 
@@ -545,29 +588,28 @@ func (h *HRHandler) UpsertClockifyConfig(w http.ResponseWriter, r *http.Request)
 	req.WorkspaceID = strings.TrimSpace(req.WorkspaceID)
 
 	if req.APIKey == "" {
-		httpError(w, "clockify api_key is required", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeClockifyAPIKeyIsRequired, "clockify api_key is required")
 		return
 	}
 	if req.WorkspaceID == "" {
-		httpError(w, "clockify workspace_id is required", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeClockifyWorkspaceIDIsRequired, "clockify workspace_id is required")
 		return
 	}
 
 	client := newClockifyClient(req.APIKey)
 	if _, err := client.ListUsers(r.Context(), req.WorkspaceID); err != nil {
-		status := mapClockifyError(err)
-		httpError(w, status.Message, status.HTTPStatus)
+		writeProblem(w, mapClockifyError(err))
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec(`
+	_, err = tx.ExecContext(r.Context(), `
 		INSERT INTO hr_clockify_connections (tenant_id, workspace_id, api_key, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE
@@ -577,23 +619,26 @@ func (h *HRHandler) UpsertClockifyConfig(w http.ResponseWriter, r *http.Request)
 			updated_at=CURRENT_TIMESTAMP
 	`, tenantID, req.WorkspaceID, req.APIKey, userID, userID)
 	if err != nil {
-		httpError(w, "db update error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "upsert", "hr_clockify_connections", 0, nil, map[string]any{
+	if err := insertAudit(tx, r, tenantID, userID, "upsert", "hr_clockify_connections", 0, nil, map[string]any{
 		"workspace_id": req.WorkspaceID,
 		"configured":   true,
-	})
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
-	conn, found, err := h.getClockifyConnection(tenantID)
+	conn, found, err := h.getClockifyConnection(r.Context(), tenantID)
 	if err != nil || !found {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	createdAt := conn.CreatedAt
@@ -610,47 +655,49 @@ func (h *HRHandler) UpsertClockifyConfig(w http.ResponseWriter, r *http.Request)
 func (h *HRHandler) SyncClockifyEntries(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 	userID := mw.GetUserID(r.Context())
-	role := mw.GetRole(r.Context())
 
 	var req syncClockifyReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 
 	startDate, endDate, err := parseDateRange(req.StartDate, req.EndDate)
 	if err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
-	if req.AllowClosedPeriod && role != "hr" {
-		httpError(w, "allow_closed_period only for hr", http.StatusForbidden)
+	if req.AllowClosedPeriod && !mw.HasPermission(r.Context(), mw.PermClockifySyncClosed) {
+		writeError(w, http.StatusForbidden, problem.CodeAllowClosedPeriodNotAllowed, "allow_closed_period not allowed")
 		return
 	}
 
-	conn, found, err := h.getClockifyConnection(tenantID)
+	conn, found, err := h.getClockifyConnection(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if !found {
-		httpError(w, "clockify is not configured", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeClockifyIsNotConfigured, "clockify is not configured")
 		return
 	}
 
+	started := time.Now()
 	summary, err := h.syncClockifyTenant(r.Context(), tenantID, conn, startDate, endDate, req.AllowClosedPeriod)
+	metrics.ObserveClockifySync(tenantID, "manual", time.Since(started), summary.EntriesUpserted, err)
 	if err != nil {
 		var internalErr *syncInternalError
 		if errors.As(err, &internalErr) {
-			httpError(w, internalErr.Message, http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, internalErr.Message)
 			return
 		}
-		status := mapClockifyError(err)
-		httpError(w, status.Message, status.HTTPStatus)
+		writeProblem(w, mapClockifyError(err))
 		return
 	}
 
-	_ = insertAudit(h.DB, r, tenantID, userID, "sync", "hr_time_entries", 0, nil, map[string]any{
+	// o sync grava em varias etapas (com chamadas ao Clockify no meio), entao a
+	// trilha vem depois; sem ela o cliente recebe erro e pode repetir (upsert)
+	if err := insertAudit(h.DB, r, tenantID, userID, "sync", "hr_time_entries", 0, nil, map[string]any{
 		"provider":               "clockify",
 		"range_start":            summary.RangeStart,
 		"range_end":              summary.RangeEnd,
@@ -660,7 +707,10 @@ func (h *HRHandler) SyncClockifyEntries(w http.ResponseWriter, r *http.Request)
 		"entries_upserted":       summary.EntriesUpserted,
 		"entries_skipped_closed": summary.EntriesSkippedClosed,
 		"allow_closed_period":    req.AllowClosedPeriod,
-	})
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	writeJSON(w, http.StatusOK, summary)
 }
@@ -672,7 +722,7 @@ func (h *HRHandler) ListTimeEntries(w http.ResponseWriter, r *http.Request) {
 	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
 		parsed, err := strconv.Atoi(raw)
 		if err != nil || parsed <= 0 {
-			httpError(w, "limit must be numeric", http.StatusBadRequest)
+			writeFieldError(w, "limit", problem.RuleNumber, "limit must be numeric")
 			return
 		}
 		if parsed > maxTimeEntriesLimit {
@@ -685,7 +735,7 @@ func (h *HRHandler) ListTimeEntries(w http.ResponseWriter, r *http.Request) {
 	if raw := strings.TrimSpace(r.URL.Query().Get("employee_id")); raw != "" {
 		id, err := strconv.ParseUint(raw, 10, 64)
 		if err != nil {
-			httpError(w, "employee_id must be numeric", http.StatusBadRequest)
+			writeFieldError(w, "employee_id", problem.RuleNumber, "employee_id must be numeric")
 			return
 		}
 		employeeID = &id
@@ -695,7 +745,7 @@ func (h *HRHandler) ListTimeEntries(w http.ResponseWriter, r *http.Request) {
 	if raw := strings.TrimSpace(r.URL.Query().Get("start_date")); raw != "" {
 		parsed, err := parseDate(raw)
 		if err != nil {
-			httpError(w, "start_date must be YYYY-MM-DD", http.StatusBadRequest)
+			writeFieldError(w, "start_date", problem.RuleDate, "start_date must be YYYY-MM-DD")
 			return
 		}
 		startDate = &parsed
@@ -705,7 +755,7 @@ func (h *HRHandler) ListTimeEntries(w http.ResponseWriter, r *http.Request) {
 	if raw := strings.TrimSpace(r.URL.Query().Get("end_date")); raw != "" {
 		parsed, err := parseDate(raw)
 		if err != nil {
-			httpError(w, "end_date must be YYYY-MM-DD", http.StatusBadRequest)
+			writeFieldError(w, "end_date", problem.RuleDate, "end_date must be YYYY-MM-DD")
 			return
 		}
 		endDate = &parsed
@@ -737,30 +787,31 @@ func (h *HRHandler) ListTimeEntries(w http.ResponseWriter, r *http.Request) {
 	args = append(args, limit)
 
 	items := make([]HRTimeEntry, 0, limit)
-	if err := h.DB.Select(&items, query, args...); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+	if err := h.DB.SelectContext(r.Context(), &items, query, args...); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
 }
 
-func (h *HRHandler) RunClockifyAutoSync(ctx context.Context, lookbackDays int) {
+func (h *HRHandler) RunClockifyAutoSync(ctx context.Context, lookbackDays int, beat *Heartbeat) {
 	if lookbackDays < 1 {
 		lookbackDays = 1
 	}
+	logger := mw.Logger(ctx)
 
 	connections := make([]clockifyTenantConnection, 0, 64)
-	if err := h.DB.Select(&connections, `
+	if err := h.DB.SelectContext(ctx, &connections, `
 		SELECT tenant_id, workspace_id, api_key
 		FROM hr_clockify_connections
 		ORDER BY tenant_id ASC
 	`); err != nil {
-		log.Error().Err(err).Msg("clockify auto sync: could not load configured tenants")
+		logger.Error().Err(err).Msg("clockify auto sync: could not load configured tenants")
 		return
 	}
 
 	if len(connections) == 0 {
-		log.Info().Msg("clockify auto sync: no configured tenants")
+		logger.Info().Msg("clockify auto sync: no configured tenants")
 		return
 	}
 
@@ -770,65 +821,87 @@ func (h *HRHandler) RunClockifyAutoSync(ctx context.Context, lookbackDays int) {
 	successCount := 0
 
 	for _, item := range connections {
-		summary, err := h.syncClockifyTenant(ctx, item.TenantID, clockifyConnection{
+		if ctx.Err() != nil {
+			logger.Info().Msg("clockify auto sync: interrupted by shutdown")
+			break
+		}
+		beat.Beat()
+		// logs do sync saem com o tenant do job
+		tenantLog := logger.With().Uint64("tenant_id", item.TenantID).Logger()
+		// cada tenant e um trace proprio (raiz), com as queries e chamadas ao Clockify
+		tenantCtx, span := tracing.Tracer().Start(tenantLog.WithContext(ctx), "clockify.auto_sync",
+			trace.WithNewRoot(),
+			trace.WithAttributes(attribute.Int64("tenant_id", int64(item.TenantID))),
+		)
+		started := time.Now()
+		summary, err := h.syncClockifyTenant(tenantCtx, item.TenantID, clockifyConnection{
 			WorkspaceID: item.WorkspaceID,
 			APIKey:      item.APIKey,
 		}, startDate, endDate, false)
+		metrics.ObserveClockifySync(item.TenantID, "auto", time.Since(started), summary.EntriesUpserted, err)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
 		if err != nil {
-			log.Error().
-				Err(err).
-				Uint64("tenant_id", item.TenantID).
-				Msg("clockify auto sync: tenant sync failed")
+			tenantLog.Error().Err(err).Msg("clockify auto sync: tenant sync failed")
 			continue
 		}
 
 		successCount++
-		log.Info().
-			Uint64("tenant_id", item.TenantID).
-			Int("entries_upserted", summary.EntriesUpserted).
-			Int("entries_processed", summary.EntriesProcessed).
-			Msg("clockify auto sync: tenant synchronized")
-
-		_ = h.insertSystemSyncAudit(item.TenantID, summary, "sync_auto")
+		if err := h.insertSystemSyncAudit(item.TenantID, summary, "sync_auto"); err != nil {
+			tenantLog.Error().Err(err).Msg("clockify auto sync: audit write failed")
+		}
 	}
 
-	log.Info().
+	logger.Info().
 		Int("tenants_total", len(connections)).
 		Int("tenants_success", successCount).
 		Msg("clockify auto sync finished")
 }
 
-func StartClockifyAutoSyncScheduler(ctx context.Context, h *HRHandler, hourUTC, lookbackDays int) {
+// StartClockifyAutoSyncScheduler roda o sync diario ate ctx ser cancelado. beat
+// (pode ser nil) recebe um sinal por minuto e por tenant sincronizado, para o
+// readiness mostrar se o job travou.
+func StartClockifyAutoSyncScheduler(ctx context.Context, h *HRHandler, hourUTC, lookbackDays int, beat *Heartbeat) {
 	if hourUTC < 0 || hourUTC > 23 {
 		hourUTC = 3
 	}
 	if lookbackDays < 1 {
 		lookbackDays = 1
 	}
+	logger := log.With().Str("job", "clockify_auto_sync").Logger()
+	ctx = logger.WithContext(ctx)
 
-	h.RunClockifyAutoSync(ctx, lookbackDays)
+	beat.Beat()
+	h.RunClockifyAutoSync(ctx, lookbackDays, beat)
 
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
 	for {
 		nextRun := nextRunAtUTCHour(time.Now().UTC(), hourUTC)
 		wait := time.Until(nextRun)
 		timer := time.NewTimer(wait)
-		log.Info().
+		logger.Info().
 			Time("next_run_utc", nextRun).
 			Msg("clockify auto sync scheduler waiting")
 
-		select {
-		case <-ctx.Done():
-			if !timer.Stop() {
-				select {
-				case <-timer.C:
-				default:
-				}
+		beat.Beat()
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				logger.Info().Msg("clockify auto sync scheduler stopped")
+				return
+			case <-ticker.C:
+				beat.Beat()
+			case <-timer.C:
+				break wait
 			}
-			log.Info().Msg("clockify auto sync scheduler stopped")
-			return
-		case <-timer.C:
-			h.RunClockifyAutoSync(ctx, lookbackDays)
 		}
+		h.RunClockifyAutoSync(ctx, lookbackDays, beat)
 	}
 }
 
@@ -852,11 +925,15 @@ func (h *HRHandler) insertSystemSyncAudit(tenantID uint64, summary clockifySyncR
 		"entries_skipped_closed": summary.EntriesSkippedClosed,
 	}
 
-	_, err := h.DB.Exec(`
-		INSERT INTO audit_logs (tenant_id, user_id, action, entity, entity_id, before_json, after_json, ip, user_agent)
-		VALUES (?, NULL, ?, 'hr_time_entries', '0', NULL, ?, 'system', 'clockify-auto-sync')
-	`, tenantID, action, nullableJSON(after))
-	return err
+	entityID, ip, ua := "0", "system", "clockify-auto-sync"
+	return writeAudit(h.DB, nil, auditchain.Entry{
+		TenantID:  &tenantID,
+		Action:    action,
+		Entity:    "hr_time_entries",
+		EntityID:  &entityID,
+		IP:        &ip,
+		UserAgent: &ua,
+	}, nil, after)
 }
 
 func (h *HRHandler) syncClockifyTenant(
@@ -873,7 +950,7 @@ func (h *HRHandler) syncClockifyTenant(
 	}
 
 	employees := make([]employeeIdentity, 0, 256)
-	if err := h.DB.Select(&employees, `
+	if err := h.DB.SelectContext(ctx, &employees, `
 		SELECT id, email
 		FROM employees
 		WHERE tenant_id=? AND status <> 'terminated'
@@ -882,7 +959,7 @@ func (h *HRHandler) syncClockifyTenant(
 	}
 
 	links := make([]clockifyUserLink, 0, 256)
-	if err := h.DB.Select(&links, `
+	if err := h.DB.SelectContext(ctx, &links, `
 		SELECT employee_id, clockify_user_id
 		FROM hr_clockify_user_links
 		WHERE tenant_id=?
@@ -929,7 +1006,7 @@ func (h *HRHandler) syncClockifyTenant(
 			}
 		}
 
-		_, err := h.DB.Exec(`
+		_, err := h.DB.ExecContext(ctx, `
 			INSERT INTO hr_clockify_user_links (
 				tenant_id, employee_id, clockify_user_id, clockify_user_name, clockify_user_email, last_synced_at
 			) VALUES (?, ?, ?, ?, ?, ?)
@@ -975,7 +1052,7 @@ func (h *HRHandler) syncClockifyTenant(
 			}
 			if !allowClosedPeriod {
 				closedDate := dateOnly(startAt.UTC())
-				isClosed, closeErr := h.isDateClosedForTimeBank(tenantID, closedDate)
+				isClosed, closeErr := h.isDateClosedForTimeBank(ctx, tenantID, closedDate)
 				if closeErr != nil {
 					return clockifySyncResp{}, &syncInternalError{Message: "db read error", Err: closeErr}
 				}
@@ -998,7 +1075,7 @@ func (h *HRHandler) syncClockifyTenant(
 			rawJSON, _ := json.Marshal(entry)
 			tagJSON, _ := json.Marshal(entry.TagIDs)
 
-			_, err = h.DB.Exec(`
+			_, err = h.DB.ExecContext(ctx, `
 				INSERT INTO hr_time_entries (
 					tenant_id, employee_id, source, external_entry_id, clockify_user_id, workspace_id,
 					project_id, task_id, description, tag_ids_json, start_at, end_at, duration_seconds,
@@ -1045,6 +1122,15 @@ func (h *HRHandler) syncClockifyTenant(
 		}
 	}
 
+	mw.Logger(ctx).Info().
+		Str("workspace_id", conn.WorkspaceID).
+		Int("users_found", len(users)).
+		Int("employees_mapped", len(mappedEmployees)).
+		Int("entries_processed", entriesProcessed).
+		Int("entries_upserted", entriesUpserted).
+		Int("entries_skipped_closed", entriesSkippedClosed).
+		Msg("clockify sync: tenant synchronized")
+
 	return clockifySyncResp{
 		RangeStart:           startDate.Format("2006-01-02"),
 		RangeEnd:             endDate.Format("2006-01-02"),
@@ -1059,9 +1145,9 @@ func (h *HRHandler) syncClockifyTenant(
 	}, nil
 }
 
-func (h *HRHandler) getClockifyConnection(tenantID uint64) (clockifyConnection, bool, error) {
+func (h *HRHandler) getClockifyConnection(ctx context.Context, tenantID uint64) (clockifyConnection, bool, error) {
 	var conn clockifyConnection
-	if err := h.DB.Get(&conn, `
+	if err := h.DB.GetContext(ctx, &conn, `
 		SELECT workspace_id, api_key, created_at, updated_at
 		FROM hr_clockify_connections
 		WHERE tenant_id=?
@@ -1086,7 +1172,7 @@ func parseDateRange(startRaw, endRaw string) (time.Time, time.Time, error) {
 	} else {
 		start, err = parseDate(startRaw)
 		if err != nil {
-			return time.Time{}, time.Time{}, fmt.Errorf("start_date must be YYYY-MM-DD")
+			return time.Time{}, time.Time{}, problem.Invalid("start_date", problem.RuleDate, "start_date must be YYYY-MM-DD")
 		}
 	}
 
@@ -1095,12 +1181,12 @@ func parseDateRange(startRaw, endRaw string) (time.Time, time.Time, error) {
 	} else {
 		end, err = parseDate(endRaw)
 		if err != nil {
-			return time.Time{}, time.Time{}, fmt.Errorf("end_date must be YYYY-MM-DD")
+			return time.Time{}, time.Time{}, problem.Invalid("end_date", problem.RuleDate, "end_date must be YYYY-MM-DD")
 		}
 	}
 
 	if end.Before(start) {
-		return time.Time{}, time.Time{}, fmt.Errorf("end_date must be >= start_date")
+		return time.Time{}, time.Time{}, problem.Invalid("end_date", problem.RuleMin, "end_date must be >= start_date", "start_date")
 	}
 	return start, end, nil
 }
@@ -1196,39 +1282,21 @@ func maskSecret(value string) string {
 	return value[:4] + strings.Repeat("*", len(value)-8) + value[len(value)-4:]
 }
 
-type mappedClockifyErr struct {
-	HTTPStatus int
-	Message    string
-}
-
-func mapClockifyError(err error) mappedClockifyErr {
+// mapClockifyError traduz a falha da API do Clockify no erro devolvido ao
+// cliente.
+func mapClockifyError(err error) *problem.Problem {
 	var reqErr *clockifyHTTPError
 	if errors.As(err, &reqErr) {
 		switch reqErr.StatusCode {
 		case http.StatusUnauthorized, http.StatusForbidden:
-			return mappedClockifyErr{
-				HTTPStatus: http.StatusBadRequest,
-				Message:    "clockify api key is invalid",
-			}
+			return problem.New(http.StatusBadRequest, problem.CodeClockifyAPIKeyIsInvalid, "clockify api key is invalid")
 		case http.StatusNotFound:
-			return mappedClockifyErr{
-				HTTPStatus: http.StatusBadRequest,
-				Message:    "clockify workspace not found",
-			}
+			return problem.New(http.StatusBadRequest, problem.CodeClockifyWorkspaceNotFound, "clockify workspace not found")
 		case http.StatusTooManyRequests:
-			return mappedClockifyErr{
-				HTTPStatus: http.StatusTooManyRequests,
-				Message:    "clockify rate limit exceeded",
-			}
+			return problem.New(http.StatusTooManyRequests, problem.CodeClockifyRateLimitExceeded, "clockify rate limit exceeded")
 		default:
-			return mappedClockifyErr{
-				HTTPStatus: http.StatusBadGateway,
-				Message:    "clockify request failed",
-			}
+			return problem.New(http.StatusBadGateway, problem.CodeClockifyRequestFailed, "clockify request failed")
 		}
 	}
-	return mappedClockifyErr{
-		HTTPStatus: http.StatusBadGateway,
-		Message:    "clockify connection failed",
-	}
+	return problem.New(http.StatusBadGateway, problem.CodeClockifyConnectionFailed, "clockify connection failed")
 }
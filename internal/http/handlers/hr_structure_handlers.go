@@ -9,6 +9,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
 )
 
 func (h *HRHandler) CreateDepartment(w http.ResponseWriter, r *http.Request) {
@@ -17,12 +18,12 @@ func (h *HRHandler) CreateDepartment(w http.ResponseWriter, r *http.Request) {
 
 	var req createDepartmentReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		httpError(w, "name is required", http.StatusBadRequest)
+		writeFieldError(w, "name", problem.RuleRequired, "name is required")
 		return
 	}
 	if req.Code != nil {
@@ -34,34 +35,37 @@ func (h *HRHandler) CreateDepartment(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO departments (tenant_id, name, code, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?)`,
 		tenantID, req.Name, req.Code, userID, userID,
 	)
 	if err != nil {
-		httpError(w, "could not create department (name/code may exist)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateDepartment, "could not create department (name/code may exist)")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var dept Department
-	if err := tx.Get(&dept, `SELECT id, tenant_id, name, code, created_at, updated_at FROM departments WHERE tenant_id=? AND id=?`, tenantID, id64); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+	if err := tx.GetContext(r.Context(), &dept, `SELECT id, tenant_id, name, code, created_at, updated_at FROM departments WHERE tenant_id=? AND id=?`, tenantID, id64); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "departments", id64, nil, dept)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "departments", id64, nil, dept); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
@@ -72,8 +76,8 @@ func (h *HRHandler) ListDepartments(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 
 	var items []Department
-	if err := h.DB.Select(&items, `SELECT id, tenant_id, name, code, created_at, updated_at FROM departments WHERE tenant_id=? ORDER BY name ASC`, tenantID); err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+	if err := h.DB.SelectContext(r.Context(), &items, `SELECT id, tenant_id, name, code, created_at, updated_at FROM departments WHERE tenant_id=? ORDER BY name ASC`, tenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -85,12 +89,12 @@ func (h *HRHandler) CreatePosition(w http.ResponseWriter, r *http.Request) {
 
 	var req createPositionReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	req.Title = strings.TrimSpace(req.Title)
 	if req.Title == "" {
-		httpError(w, "title is required", http.StatusBadRequest)
+		writeFieldError(w, "title", problem.RuleRequired, "title is required")
 		return
 	}
 	if req.Level != nil {
@@ -102,34 +106,37 @@ func (h *HRHandler) CreatePosition(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO positions (tenant_id, department_id, title, level, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?, ?)`,
 		tenantID, req.DepartmentID, req.Title, req.Level, userID, userID,
 	)
 	if err != nil {
-		httpError(w, "could not create position (title may exist, or invalid department_id)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreatePosition, "could not create position (title may exist, or invalid department_id)")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var pos Position
-	if err := tx.Get(&pos, `SELECT id, tenant_id, department_id, title, level, created_at, updated_at FROM positions WHERE tenant_id=? AND id=?`, tenantID, id64); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+	if err := tx.GetContext(r.Context(), &pos, `SELECT id, tenant_id, department_id, title, level, created_at, updated_at FROM positions WHERE tenant_id=? AND id=?`, tenantID, id64); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "positions", id64, nil, pos)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "positions", id64, nil, pos); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
@@ -140,8 +147,8 @@ func (h *HRHandler) ListPositions(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 
 	var items []Position
-	if err := h.DB.Select(&items, `SELECT id, tenant_id, department_id, title, level, created_at, updated_at FROM positions WHERE tenant_id=? ORDER BY title ASC`, tenantID); err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+	if err := h.DB.SelectContext(r.Context(), &items, `SELECT id, tenant_id, department_id, title, level, created_at, updated_at FROM positions WHERE tenant_id=? ORDER BY title ASC`, tenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -153,12 +160,12 @@ func (h *HRHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
 
 	var req createEmployeeReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		httpError(w, "name is required", http.StatusBadRequest)
+		writeFieldError(w, "name", problem.RuleRequired, "name is required")
 		return
 	}
 	if req.Email != nil {
@@ -178,7 +185,7 @@ func (h *HRHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
 		status = strings.TrimSpace(strings.ToLower(*req.Status))
 	}
 	if status != "active" && status != "inactive" && status != "terminated" {
-		httpError(w, "status must be active|inactive|terminated", http.StatusBadRequest)
+		writeFieldError(w, "status", problem.RuleChoice, "status must be active|inactive|terminated", "active, inactive, terminated")
 		return
 	}
 
@@ -186,7 +193,7 @@ func (h *HRHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
 	if req.HireDate != nil && strings.TrimSpace(*req.HireDate) != "" {
 		t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.HireDate))
 		if err != nil {
-			httpError(w, "hire_date must be YYYY-MM-DD", http.StatusBadRequest)
+			writeFieldError(w, "hire_date", problem.RuleDate, "hire_date must be YYYY-MM-DD")
 			return
 		}
 		hireDate = &t
@@ -194,9 +201,13 @@ func (h *HRHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
 
 	salary := int64(0)
 	if req.SalaryCents != nil {
+		if !mw.HasPermission(r.Context(), mw.PermEmployeesSalary) {
+			writeError(w, http.StatusForbidden, problem.CodeSalaryPermissionRequired, "salary_cents requires employees:salary permission")
+			return
+		}
 		salary = *req.SalaryCents
 		if salary < 0 {
-			httpError(w, "salary_cents must be >= 0", http.StatusBadRequest)
+			writeFieldError(w, "salary_cents", problem.RuleMin, "salary_cents must be >= 0", 0)
 			return
 		}
 	}
@@ -205,14 +216,14 @@ func (h *HRHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
 
 	empCode := genCode("EMP")
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO employees (
 			tenant_id, employee_code, name, email, cpf, cbo, ctps, status, hire_date,
 			department_id, position_id, manager_id, salary_cents, created_by, updated_by
@@ -221,84 +232,103 @@ func (h *HRHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
 		req.DepartmentID, req.PositionID, managerID, salary, userID, userID,
 	)
 	if err != nil {
-		httpError(w, "could not create employee (invalid dept/position?)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateEmployee, "could not create employee (invalid dept/position?)")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var emp Employee
-	if err := tx.Get(&emp, `
+	if err := tx.GetContext(r.Context(), &emp, `
 		SELECT id, tenant_id, employee_code, name, email, cpf, cbo, ctps, status, hire_date, termination_date,
 		       department_id, position_id, manager_id, salary_cents, created_at, updated_at
 		FROM employees
 		WHERE tenant_id=? AND id=?`, tenantID, id64); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "employees", id64, nil, emp)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "employees", id64, nil, emp); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
+	redactSalary(r, &emp)
 	writeJSON(w, http.StatusCreated, emp)
 }
 
+var employeeListSpec = listSpec{
+	IDColumn: "id",
+	IDField:  "id",
+	Sorts: map[string]listSort{
+		"id":            {Column: "id", Field: "id", Kind: listInt},
+		"name":          {Column: "name", Field: "name", Kind: listString},
+		"employee_code": {Column: "employee_code", Field: "employee_code", Kind: listString},
+		"created_at":    {Column: "created_at", Field: "created_at", Kind: listTime},
+	},
+	DefaultSort: "-id",
+	Filters: map[string]listFilter{
+		"status":         {Column: "status", Kind: listString, Op: "=", Values: []string{"active", "inactive", "terminated"}},
+		"department_id":  {Column: "department_id", Kind: listInt, Op: "="},
+		"position_id":    {Column: "position_id", Kind: listInt, Op: "="},
+		"manager_id":     {Column: "manager_id", Kind: listInt, Op: "="},
+		"cost_center_id": {Column: "cost_center_id", Kind: listInt, Op: "="},
+		"hire_from":      {Column: "hire_date", Kind: listDate, Op: ">="},
+		"hire_to":        {Column: "hire_date", Kind: listDate, Op: "<"},
+	},
+	Search: []string{"name", "email", "employee_code", "cpf"},
+}
+
 func (h *HRHandler) ListEmployees(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 
-	// filtros simples via querystring
-	status := strings.TrimSpace(strings.ToLower(r.URL.Query().Get("status")))
-	if status != "" && status != "active" && status != "inactive" && status != "terminated" {
-		httpError(w, "status filter must be active|inactive|terminated", http.StatusBadRequest)
+	lq, err := parseListQuery(r, &employeeListSpec)
+	if err != nil {
+		writeProblem(w, err)
 		return
 	}
 
-	var items []Employee
-	if status == "" {
-		if err := h.DB.Select(&items, `
-			SELECT id, tenant_id, employee_code, name, email, cpf, cbo, ctps, status, hire_date, termination_date,
-			       department_id, position_id, manager_id, salary_cents, created_at, updated_at
-			FROM employees
-			WHERE tenant_id=?
-			ORDER BY id DESC`, tenantID); err != nil {
-			httpError(w, "db error", http.StatusInternalServerError)
-			return
-		}
-	} else {
-		if err := h.DB.Select(&items, `
-			SELECT id, tenant_id, employee_code, name, email, cpf, cbo, ctps, status, hire_date, termination_date,
-			       department_id, position_id, manager_id, salary_cents, created_at, updated_at
-			FROM employees
-			WHERE tenant_id=? AND status=?
-			ORDER BY id DESC`, tenantID, status); err != nil {
-			httpError(w, "db error", http.StatusInternalServerError)
-			return
-		}
+	query, args := lq.SQL(`
+		SELECT id, tenant_id, employee_code, name, email, cpf, cbo, ctps, status, hire_date, termination_date,
+		       department_id, position_id, manager_id, salary_cents, created_at, updated_at
+		FROM employees`, "tenant_id=?", tenantID)
+	items := make([]Employee, 0)
+	if err := h.DB.SelectContext(r.Context(), &items, query, args...); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
 	}
 
-	writeJSON(w, http.StatusOK, items)
+	for i := range items {
+		redactSalary(r, &items[i])
+	}
+	writeListPage(w, r, lq, items)
 }
 
 func (h *HRHandler) GetEmployee(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid employee id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidEmployeeID, "invalid employee id")
 		return
 	}
 
 	var emp Employee
-	if err := h.DB.Get(&emp, `
+	if err := h.DB.GetContext(r.Context(), &emp, `
 		SELECT id, tenant_id, employee_code, name, email, cpf, cbo, ctps, status, hire_date, termination_date,
 		       department_id, position_id, manager_id, salary_cents, created_at, updated_at
 		FROM employees
 		WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		httpError(w, "employee not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeNotFound, "employee not found")
+		return
+	}
+	if notModified(w, r, resourceETag(emp.UpdatedAt)) {
 		return
 	}
+	redactSalary(r, &emp)
 	writeJSON(w, http.StatusOK, emp)
 }
 
@@ -308,29 +338,32 @@ func (h *HRHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		httpError(w, "invalid employee id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidEmployeeID, "invalid employee id")
 		return
 	}
 
 	var req updateEmployeeReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var before Employee
-	if err := tx.Get(&before, `
+	if err := tx.GetContext(r.Context(), &before, `
 		SELECT id, tenant_id, employee_code, name, email, cpf, cbo, ctps, status, hire_date, termination_date,
 		       department_id, position_id, manager_id, salary_cents, created_at, updated_at
-		FROM employees WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		httpError(w, "employee not found", http.StatusNotFound)
+		FROM employees WHERE tenant_id=? AND id=? FOR UPDATE`, tenantID, id); err != nil {
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeNotFound, "employee not found")
+		return
+	}
+	if !checkIfMatch(w, r, resourceETag(before.UpdatedAt)) {
 		return
 	}
 
@@ -339,7 +372,7 @@ func (h *HRHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
 	if req.Name != nil {
 		after.Name = strings.TrimSpace(*req.Name)
 		if after.Name == "" {
-			httpError(w, "name cannot be empty", http.StatusBadRequest)
+			writeFieldError(w, "name", problem.RuleRequired, "name cannot be empty")
 			return
 		}
 	}
@@ -361,7 +394,7 @@ func (h *HRHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
 		} else {
 			t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.HireDate))
 			if err != nil {
-				httpError(w, "hire_date must be YYYY-MM-DD", http.StatusBadRequest)
+				writeFieldError(w, "hire_date", problem.RuleDate, "hire_date must be YYYY-MM-DD")
 				return
 			}
 			after.HireDate = &t
@@ -373,7 +406,7 @@ func (h *HRHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
 		} else {
 			t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TerminationDate))
 			if err != nil {
-				httpError(w, "termination_date must be YYYY-MM-DD", http.StatusBadRequest)
+				writeFieldError(w, "termination_date", problem.RuleDate, "termination_date must be YYYY-MM-DD")
 				return
 			}
 			after.TerminationDate = &t
@@ -382,7 +415,7 @@ func (h *HRHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
 	if req.Status != nil {
 		s := strings.TrimSpace(strings.ToLower(*req.Status))
 		if s != "active" && s != "inactive" && s != "terminated" {
-			httpError(w, "status must be active|inactive|terminated", http.StatusBadRequest)
+			writeFieldError(w, "status", problem.RuleChoice, "status must be active|inactive|terminated", "active, inactive, terminated")
 			return
 		}
 		after.Status = s
@@ -403,14 +436,18 @@ func (h *HRHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
 		after.ManagerID = req.ManagerID
 	}
 	if req.SalaryCents != nil {
+		if !mw.HasPermission(r.Context(), mw.PermEmployeesSalary) {
+			writeError(w, http.StatusForbidden, problem.CodeSalaryPermissionRequired, "salary_cents requires employees:salary permission")
+			return
+		}
 		if *req.SalaryCents < 0 {
-			httpError(w, "salary_cents must be >= 0", http.StatusBadRequest)
+			writeFieldError(w, "salary_cents", problem.RuleMin, "salary_cents must be >= 0", 0)
 			return
 		}
-		after.SalaryCents = *req.SalaryCents
+		after.SalaryCents = req.SalaryCents
 	}
 
-	if _, err := tx.Exec(`
+	if _, err := tx.ExecContext(r.Context(), `
 		UPDATE employees
 		SET name=?, email=?, status=?, hire_date=?, termination_date=?,
 		    cpf=?, cbo=?, ctps=?, department_id=?, position_id=?, manager_id=?, salary_cents=?, updated_by=?
@@ -419,23 +456,28 @@ func (h *HRHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
 		after.CPF, after.CBO, after.CTPS, after.DepartmentID, after.PositionID, after.ManagerID, after.SalaryCents, userID,
 		tenantID, id,
 	); err != nil {
-		httpError(w, "db update error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 		return
 	}
 
 	var persisted Employee
-	_ = tx.Get(&persisted, `
+	_ = tx.GetContext(r.Context(), &persisted, `
 		SELECT id, tenant_id, employee_code, name, email, cpf, cbo, ctps, status, hire_date, termination_date,
 		       department_id, position_id, manager_id, salary_cents, created_at, updated_at
 		FROM employees WHERE tenant_id=? AND id=?`, tenantID, id)
 
-	_ = insertAudit(tx, r, tenantID, userID, "update", "employees", int64(id), before, persisted)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "employees", int64(id), before, persisted); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
+	redactSalary(r, &persisted)
+	w.Header().Set("ETag", resourceETag(persisted.UpdatedAt))
 	writeJSON(w, http.StatusOK, persisted)
 }
 
@@ -445,34 +487,37 @@ func (h *HRHandler) UpdateEmployeeStatus(w http.ResponseWriter, r *http.Request)
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		httpError(w, "invalid employee id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidEmployeeID, "invalid employee id")
 		return
 	}
 
 	var req updateEmployeeStatusReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	req.Status = strings.TrimSpace(strings.ToLower(req.Status))
 	if req.Status != "active" && req.Status != "inactive" && req.Status != "terminated" {
-		httpError(w, "status must be active|inactive|terminated", http.StatusBadRequest)
+		writeFieldError(w, "status", problem.RuleChoice, "status must be active|inactive|terminated", "active, inactive, terminated")
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var before Employee
-	if err := tx.Get(&before, `
+	if err := tx.GetContext(r.Context(), &before, `
 		SELECT id, tenant_id, employee_code, name, email, cpf, cbo, ctps, status, hire_date, termination_date,
 		       department_id, position_id, manager_id, salary_cents, created_at, updated_at
-		FROM employees WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		httpError(w, "employee not found", http.StatusNotFound)
+		FROM employees WHERE tenant_id=? AND id=? FOR UPDATE`, tenantID, id); err != nil {
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeNotFound, "employee not found")
+		return
+	}
+	if !checkIfMatch(w, r, resourceETag(before.UpdatedAt)) {
 		return
 	}
 
@@ -481,7 +526,7 @@ func (h *HRHandler) UpdateEmployeeStatus(w http.ResponseWriter, r *http.Request)
 		if req.TerminationDate != nil && strings.TrimSpace(*req.TerminationDate) != "" {
 			t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TerminationDate))
 			if err != nil {
-				httpError(w, "termination_date must be YYYY-MM-DD", http.StatusBadRequest)
+				writeFieldError(w, "termination_date", problem.RuleDate, "termination_date must be YYYY-MM-DD")
 				return
 			}
 			terminationDate = &t
@@ -491,26 +536,31 @@ func (h *HRHandler) UpdateEmployeeStatus(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	if _, err := tx.Exec(`
+	if _, err := tx.ExecContext(r.Context(), `
 		UPDATE employees SET status=?, termination_date=?, updated_by=? WHERE tenant_id=? AND id=?`,
 		req.Status, terminationDate, userID, tenantID, id); err != nil {
-		httpError(w, "db update error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 		return
 	}
 
 	var after Employee
-	_ = tx.Get(&after, `
+	_ = tx.GetContext(r.Context(), &after, `
 		SELECT id, tenant_id, employee_code, name, email, cpf, cbo, ctps, status, hire_date, termination_date,
 		       department_id, position_id, manager_id, salary_cents, created_at, updated_at
 		FROM employees WHERE tenant_id=? AND id=?`, tenantID, id)
 
-	_ = insertAudit(tx, r, tenantID, userID, "update", "employees", int64(id), before, after)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "employees", int64(id), before, after); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
+	redactSalary(r, &after)
+	w.Header().Set("ETag", resourceETag(after.UpdatedAt))
 	writeJSON(w, http.StatusOK, after)
 }
 
@@ -520,63 +570,66 @@ func (h *HRHandler) CreateCompensation(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	empID, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		httpError(w, "invalid employee id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidEmployeeID, "invalid employee id")
 		return
 	}
 
 	var req createCompensationReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	req.EffectiveAt = strings.TrimSpace(req.EffectiveAt)
 	if req.EffectiveAt == "" {
-		httpError(w, "effective_at is required", http.StatusBadRequest)
+		writeFieldError(w, "effective_at", problem.RuleRequired, "effective_at is required")
 		return
 	}
 	eff, err := time.Parse("2006-01-02", req.EffectiveAt)
 	if err != nil {
-		httpError(w, "effective_at must be YYYY-MM-DD", http.StatusBadRequest)
+		writeFieldError(w, "effective_at", problem.RuleDate, "effective_at must be YYYY-MM-DD")
 		return
 	}
 	if req.SalaryCents < 0 {
-		httpError(w, "salary_cents must be >= 0", http.StatusBadRequest)
+		writeFieldError(w, "salary_cents", problem.RuleMin, "salary_cents must be >= 0", 0)
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var empExists int
-	if err := tx.Get(&empExists, `SELECT 1 FROM employees WHERE tenant_id=? AND id=?`, tenantID, empID); err != nil {
-		httpError(w, "employee not found", http.StatusNotFound)
+	if err := tx.GetContext(r.Context(), &empExists, `SELECT 1 FROM employees WHERE tenant_id=? AND id=?`, tenantID, empID); err != nil {
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeNotFound, "employee not found")
 		return
 	}
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO employee_compensations (tenant_id, employee_id, effective_at, salary_cents, adjustment_type, note, created_by)
 		VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		tenantID, empID, eff, req.SalaryCents, cleanPtr(req.AdjustmentType), cleanPtr(req.Note), userID)
 	if err != nil {
-		httpError(w, "could not create compensation", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateCompensation, "could not create compensation")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var comp EmployeeCompensation
-	_ = tx.Get(&comp, `
+	_ = tx.GetContext(r.Context(), &comp, `
 		SELECT id, tenant_id, employee_id, effective_at, salary_cents, adjustment_type, note, created_at, created_by
 		FROM employee_compensations
 		WHERE tenant_id=? AND id=?`, tenantID, id64)
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "employee_compensations", id64, nil, comp)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "employee_compensations", id64, nil, comp); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
@@ -588,17 +641,17 @@ func (h *HRHandler) ListCompensations(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	empID, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		httpError(w, "invalid employee id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidEmployeeID, "invalid employee id")
 		return
 	}
 
 	items := make([]EmployeeCompensation, 0)
-	if err := h.DB.Select(&items, `
+	if err := h.DB.SelectContext(r.Context(), &items, `
 		SELECT id, tenant_id, employee_id, effective_at, salary_cents, adjustment_type, note, created_at, created_by
 		FROM employee_compensations
 		WHERE tenant_id=? AND employee_id=?
 		ORDER BY effective_at ASC, id ASC`, tenantID, empID); err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -610,42 +663,45 @@ func (h *HRHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
 
 	var req createLocationReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		httpError(w, "name is required", http.StatusBadRequest)
+		writeFieldError(w, "name", problem.RuleRequired, "name is required")
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO locations (tenant_id, name, code, kind, country, state, city, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		tenantID, req.Name, cleanPtr(req.Code), cleanPtr(req.Kind), cleanPtr(req.Country),
 		cleanPtr(req.State), cleanPtr(req.City), userID, userID)
 	if err != nil {
-		httpError(w, "could not create location (name/code may exist)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateLocation, "could not create location (name/code may exist)")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var loc Location
-	_ = tx.Get(&loc, `
+	_ = tx.GetContext(r.Context(), &loc, `
 		SELECT id, tenant_id, name, code, kind, country, state, city, created_at, updated_at
 		FROM locations WHERE tenant_id=? AND id=?`, tenantID, id64)
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "locations", id64, nil, loc)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "locations", id64, nil, loc); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, http.StatusCreated, loc)
@@ -654,12 +710,12 @@ func (h *HRHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
 func (h *HRHandler) ListLocations(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 	items := make([]Location, 0)
-	if err := h.DB.Select(&items, `
+	if err := h.DB.SelectContext(r.Context(), &items, `
 		SELECT id, tenant_id, name, code, kind, country, state, city, created_at, updated_at
 		FROM locations
 		WHERE tenant_id=?
 		ORDER BY name ASC`, tenantID); err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -671,41 +727,44 @@ func (h *HRHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 
 	var req createTeamReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		httpError(w, "name is required", http.StatusBadRequest)
+		writeFieldError(w, "name", problem.RuleRequired, "name is required")
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO teams (tenant_id, name, department_id, manager_employee_id, location_id, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		tenantID, req.Name, req.DepartmentID, req.ManagerEmployeeID, req.LocationID, userID, userID)
 	if err != nil {
-		httpError(w, "could not create team (name may exist)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateTeam, "could not create team (name may exist)")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var team Team
-	_ = tx.Get(&team, `
+	_ = tx.GetContext(r.Context(), &team, `
 		SELECT id, tenant_id, name, department_id, manager_employee_id, location_id, created_at, updated_at
 		FROM teams WHERE tenant_id=? AND id=?`, tenantID, id64)
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "teams", id64, nil, team)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "teams", id64, nil, team); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, http.StatusCreated, team)
@@ -714,11 +773,11 @@ func (h *HRHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 func (h *HRHandler) ListTeams(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 	items := make([]Team, 0)
-	if err := h.DB.Select(&items, `
+	if err := h.DB.SelectContext(r.Context(), &items, `
 		SELECT id, tenant_id, name, department_id, manager_employee_id, location_id, created_at, updated_at
 		FROM teams WHERE tenant_id=?
 		ORDER BY name ASC`, tenantID); err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/csv"
 	"fmt"
@@ -13,8 +14,13 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-pdf/fpdf"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+	"saas-api/internal/i18n"
+	"saas-api/internal/tracing"
 )
 
 const (
@@ -202,30 +208,30 @@ const (
 )
 
 type timeCardColumn struct {
-	Title string
+	Title string // chave do catalogo i18n
 	Width float64
 	Align string
 }
 
 var timeCardColumns = []timeCardColumn{
-	{Title: "DATA", Width: 22, Align: "L"},
-	{Title: "DIA", Width: 12, Align: "C"},
-	{Title: "ENT 1", Width: 13, Align: "C"},
-	{Title: "SAI 1", Width: 13, Align: "C"},
-	{Title: "ENT 2", Width: 13, Align: "C"},
-	{Title: "SAI 2", Width: 13, Align: "C"},
-	{Title: "TRAB.", Width: 26, Align: "C"},
-	{Title: "PREV.", Width: 26, Align: "C"},
-	{Title: "AJUSTE", Width: 20, Align: "C"},
-	{Title: "SALDO", Width: 20, Align: "C"},
+	{Title: "timecard.col.date", Width: 22, Align: "L"},
+	{Title: "timecard.col.weekday", Width: 12, Align: "C"},
+	{Title: "timecard.col.in_1", Width: 13, Align: "C"},
+	{Title: "timecard.col.out_1", Width: 13, Align: "C"},
+	{Title: "timecard.col.in_2", Width: 13, Align: "C"},
+	{Title: "timecard.col.out_2", Width: 13, Align: "C"},
+	{Title: "timecard.col.worked", Width: 26, Align: "C"},
+	{Title: "timecard.col.expected", Width: 26, Align: "C"},
+	{Title: "timecard.col.adjustment", Width: 20, Align: "C"},
+	{Title: "timecard.col.balance", Width: 20, Align: "C"},
 }
 
 func (h *HRHandler) GetTimeBankSettings(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 
-	settings, err := h.loadTimeBankSettings(tenantID)
+	settings, err := h.loadTimeBankSettings(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	writeJSON(w, http.StatusOK, settings)
@@ -237,20 +243,20 @@ func (h *HRHandler) UpsertTimeBankSettings(w http.ResponseWriter, r *http.Reques
 
 	var req upsertTimeBankSettingsReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 
-	current, err := h.loadTimeBankSettings(tenantID)
+	current, err := h.loadTimeBankSettings(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	next := current
 	if req.TargetDailyMinutes != nil {
 		if *req.TargetDailyMinutes < 1 || *req.TargetDailyMinutes > maxTimeBankDailyMinutes {
-			httpError(w, "target_daily_minutes must be between 1 and 960", http.StatusBadRequest)
+			writeFieldError(w, "target_daily_minutes", problem.RuleBetween, "target_daily_minutes must be between 1 and 960", 1, 960)
 			return
 		}
 		next.TargetDailyMinutes = *req.TargetDailyMinutes
@@ -259,7 +265,14 @@ func (h *HRHandler) UpsertTimeBankSettings(w http.ResponseWriter, r *http.Reques
 		next.IncludeSaturday = *req.IncludeSaturday
 	}
 
-	if _, err := h.DB.Exec(`
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(r.Context(), `
 		INSERT INTO hr_time_bank_settings (tenant_id, target_daily_minutes, include_saturday, updated_by)
 		VALUES (?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE
@@ -268,17 +281,24 @@ func (h *HRHandler) UpsertTimeBankSettings(w http.ResponseWriter, r *http.Reques
 			updated_by=VALUES(updated_by),
 			updated_at=CURRENT_TIMESTAMP
 	`, tenantID, next.TargetDailyMinutes, next.IncludeSaturday, userID); err != nil {
-		httpError(w, "db update error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 		return
 	}
 
-	after, err := h.loadTimeBankSettings(tenantID)
-	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "hr_time_bank_settings", 0, current, next); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
-	_ = insertAudit(h.DB, r, tenantID, userID, "update", "hr_time_bank_settings", 0, current, after)
+	after, err := h.loadTimeBankSettings(r.Context(), tenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
+		return
+	}
 	writeJSON(w, http.StatusOK, after)
 }
 
@@ -287,19 +307,19 @@ func (h *HRHandler) GetTimeBankSummary(w http.ResponseWriter, r *http.Request) {
 
 	startDate, endDate, err := parseTimeBankRange(r.URL.Query().Get("start_date"), r.URL.Query().Get("end_date"))
 	if err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 
-	settings, err := h.loadTimeBankSettings(tenantID)
+	settings, err := h.loadTimeBankSettings(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	summary, err := h.buildTimeBankSummary(tenantID, startDate, endDate, settings)
+	summary, err := h.buildTimeBankSummary(r.Context(), tenantID, startDate, endDate, settings)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	writeJSON(w, http.StatusOK, summary)
@@ -312,7 +332,7 @@ func (h *HRHandler) ListTimeBankAdjustments(w http.ResponseWriter, r *http.Reque
 	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
 		parsed, err := strconv.Atoi(raw)
 		if err != nil || parsed <= 0 {
-			httpError(w, "limit must be numeric", http.StatusBadRequest)
+			writeFieldError(w, "limit", problem.RuleNumber, "limit must be numeric")
 			return
 		}
 		if parsed > maxTimeBankLimit {
@@ -323,7 +343,7 @@ func (h *HRHandler) ListTimeBankAdjustments(w http.ResponseWriter, r *http.Reque
 
 	startDate, endDate, err := parseTimeBankRange(r.URL.Query().Get("start_date"), r.URL.Query().Get("end_date"))
 	if err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 
@@ -339,7 +359,7 @@ func (h *HRHandler) ListTimeBankAdjustments(w http.ResponseWriter, r *http.Reque
 	if raw := strings.TrimSpace(r.URL.Query().Get("employee_id")); raw != "" {
 		employeeID, parseErr := strconv.ParseUint(raw, 10, 64)
 		if parseErr != nil {
-			httpError(w, "employee_id must be numeric", http.StatusBadRequest)
+			writeFieldError(w, "employee_id", problem.RuleNumber, "employee_id must be numeric")
 			return
 		}
 		query += " AND a.employee_id=?"
@@ -348,7 +368,7 @@ func (h *HRHandler) ListTimeBankAdjustments(w http.ResponseWriter, r *http.Reque
 
 	if raw := strings.TrimSpace(strings.ToLower(r.URL.Query().Get("status"))); raw != "" {
 		if !isValidTimeBankStatus(raw) {
-			httpError(w, "adjustment status must be pending|approved|rejected", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, problem.CodeInvalidAdjustmentStatus, "adjustment status must be pending|approved|rejected")
 			return
 		}
 		query += " AND a.status=?"
@@ -359,8 +379,8 @@ func (h *HRHandler) ListTimeBankAdjustments(w http.ResponseWriter, r *http.Reque
 	args = append(args, limit)
 
 	items := make([]TimeBankAdjustment, 0, limit)
-	if err := h.DB.Select(&items, query, args...); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+	if err := h.DB.SelectContext(r.Context(), &items, query, args...); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -372,83 +392,86 @@ func (h *HRHandler) CreateTimeBankAdjustment(w http.ResponseWriter, r *http.Requ
 
 	var req createTimeBankAdjustmentReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 
 	if req.EmployeeID == 0 {
-		httpError(w, "invalid employee id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidEmployeeID, "invalid employee id")
 		return
 	}
 
 	effectiveDate, err := parseDate(req.EffectiveDate)
 	if err != nil {
-		httpError(w, "effective_date must be YYYY-MM-DD", http.StatusBadRequest)
+		writeFieldError(w, "effective_date", problem.RuleDate, "effective_date must be YYYY-MM-DD")
 		return
 	}
 
 	delta, err := parseTimeBankDelta(req.SecondsDelta, req.MinutesDelta)
 	if err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 
-	closed, err := h.isDateClosedForTimeBank(tenantID, effectiveDate)
+	closed, err := h.isDateClosedForTimeBank(r.Context(), tenantID, effectiveDate)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if closed {
-		httpError(w, "period is closed for this date", http.StatusConflict)
+		writeError(w, http.StatusConflict, problem.CodePeriodIsClosedForThisDate, "period is closed for this date")
 		return
 	}
 
 	reason := normalizeOptionalString(req.Reason)
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var exists int
-	if err := tx.Get(&exists, `SELECT COUNT(*) FROM employees WHERE tenant_id=? AND id=?`, tenantID, req.EmployeeID); err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+	if err := tx.GetContext(r.Context(), &exists, `SELECT COUNT(*) FROM employees WHERE tenant_id=? AND id=?`, tenantID, req.EmployeeID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	if exists == 0 {
-		httpError(w, "employee not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeNotFound, "employee not found")
 		return
 	}
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO hr_time_bank_adjustments (
 			tenant_id, employee_id, effective_date, seconds_delta, status, reason, review_note, created_by, reviewed_by, reviewed_at
 		)
 		VALUES (?, ?, ?, ?, ?, ?, NULL, ?, NULL, NULL)
 	`, tenantID, req.EmployeeID, effectiveDate, delta, timeBankStatusPending, reason, userID)
 	if err != nil {
-		httpError(w, "could not create time bank adjustment", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateTimeBankAdjustment, "could not create time bank adjustment")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var created TimeBankAdjustment
-	if err := tx.Get(&created, `
+	if err := tx.GetContext(r.Context(), &created, `
 		SELECT a.id, a.tenant_id, a.employee_id, e.name AS employee_name, a.effective_date,
 		       a.seconds_delta, a.status, a.reason, a.review_note, a.created_by, a.reviewed_by, a.reviewed_at, a.created_at
 		FROM hr_time_bank_adjustments a
 		JOIN employees e ON e.tenant_id=a.tenant_id AND e.id=a.employee_id
 		WHERE a.tenant_id=? AND a.id=?
 	`, tenantID, id64); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "hr_time_bank_adjustments", id64, nil, created)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "hr_time_bank_adjustments", id64, nil, created); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
@@ -469,63 +492,63 @@ func (h *HRHandler) decideTimeBankAdjustment(w http.ResponseWriter, r *http.Requ
 
 	adjustmentID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid request id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidRequestID, "invalid request id")
 		return
 	}
 
 	var req timeBankDecisionReq
 	if r.ContentLength > 0 {
 		if err := decodeJSON(r, &req); err != nil {
-			httpError(w, err.Error(), http.StatusBadRequest)
+			writeProblem(w, err)
 			return
 		}
 	}
 	note := normalizeOptionalString(req.Note)
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	before, err := h.getTimeBankAdjustmentByID(tx, tenantID, adjustmentID)
+	before, err := h.getTimeBankAdjustmentByID(r.Context(), tx, tenantID, adjustmentID)
 	if err == sql.ErrNoRows {
-		httpError(w, "time bank adjustment not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeTimeBankAdjustmentNotFound, "time bank adjustment not found")
 		return
 	}
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	if before.Status != timeBankStatusPending {
-		httpError(w, "invalid status transition", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidStatusTransition, "invalid status transition")
 		return
 	}
 
-	closed, err := h.isDateClosedForTimeBank(tenantID, before.EffectiveDate)
+	closed, err := h.isDateClosedForTimeBank(r.Context(), tenantID, before.EffectiveDate)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if closed {
-		httpError(w, "period is closed for this date", http.StatusConflict)
+		writeError(w, http.StatusConflict, problem.CodePeriodIsClosedForThisDate, "period is closed for this date")
 		return
 	}
 
-	if _, err := tx.Exec(`
+	if _, err := tx.ExecContext(r.Context(), `
 		UPDATE hr_time_bank_adjustments
 		SET status=?, review_note=?, reviewed_by=?, reviewed_at=UTC_TIMESTAMP
 		WHERE tenant_id=? AND id=?
 	`, targetStatus, note, userID, tenantID, adjustmentID); err != nil {
-		httpError(w, "db update error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 		return
 	}
 
-	after, err := h.getTimeBankAdjustmentByID(tx, tenantID, adjustmentID)
+	after, err := h.getTimeBankAdjustmentByID(r.Context(), tx, tenantID, adjustmentID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
@@ -533,10 +556,13 @@ func (h *HRHandler) decideTimeBankAdjustment(w http.ResponseWriter, r *http.Requ
 	if targetStatus == timeBankStatusRejected {
 		action = "reject"
 	}
-	_ = insertAudit(tx, r, tenantID, userID, action, "hr_time_bank_adjustments", int64(adjustmentID), before, after)
+	if err := insertAudit(tx, r, tenantID, userID, action, "hr_time_bank_adjustments", int64(adjustmentID), before, after); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
@@ -549,51 +575,51 @@ func (h *HRHandler) CloseTimeBankPeriod(w http.ResponseWriter, r *http.Request)
 
 	var req closeTimeBankReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 
 	if strings.TrimSpace(req.StartDate) == "" {
-		httpError(w, "period_start is required", http.StatusBadRequest)
+		writeFieldError(w, "period_start", problem.RuleRequired, "period_start is required")
 		return
 	}
 	if strings.TrimSpace(req.EndDate) == "" {
-		httpError(w, "period_end is required", http.StatusBadRequest)
+		writeFieldError(w, "period_end", problem.RuleRequired, "period_end is required")
 		return
 	}
 
 	startDate, err := parseDate(req.StartDate)
 	if err != nil {
-		httpError(w, "period_start must be YYYY-MM-DD", http.StatusBadRequest)
+		writeFieldError(w, "period_start", problem.RuleDate, "period_start must be YYYY-MM-DD")
 		return
 	}
 	endDate, err := parseDate(req.EndDate)
 	if err != nil {
-		httpError(w, "period_end must be YYYY-MM-DD", http.StatusBadRequest)
+		writeFieldError(w, "period_end", problem.RuleDate, "period_end must be YYYY-MM-DD")
 		return
 	}
 	if endDate.Before(startDate) {
-		httpError(w, "period_end must be >= period_start", http.StatusBadRequest)
+		writeFieldError(w, "period_end", problem.RuleMin, "period_end must be >= period_start", "period_start")
 		return
 	}
 
 	var pendingAdjustments int64
-	if err := h.DB.Get(&pendingAdjustments, `
+	if err := h.DB.GetContext(r.Context(), &pendingAdjustments, `
 		SELECT COUNT(*)
 		FROM hr_time_bank_adjustments
 		WHERE tenant_id=? AND status=? AND effective_date>=? AND effective_date<=?
 	`, tenantID, timeBankStatusPending, startDate, endDate); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if pendingAdjustments > 0 {
-		httpError(w, "there are pending time bank adjustments in selected period", http.StatusConflict)
+		writeError(w, http.StatusConflict, problem.CodePendingAdjustmentsInPeriod, "there are pending time bank adjustments in selected period")
 		return
 	}
 
 	var ignoreID *uint64
 	var samePeriodID uint64
-	findErr := h.DB.Get(&samePeriodID, `
+	findErr := h.DB.GetContext(r.Context(), &samePeriodID, `
 		SELECT id
 		FROM hr_time_bank_closures
 		WHERE tenant_id=? AND period_start=? AND period_end=?
@@ -602,62 +628,62 @@ func (h *HRHandler) CloseTimeBankPeriod(w http.ResponseWriter, r *http.Request)
 	if findErr == nil {
 		ignoreID = &samePeriodID
 	} else if findErr != sql.ErrNoRows {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	hasOverlap, err := h.hasOverlappingClosedPeriod(tenantID, startDate, endDate, ignoreID)
+	hasOverlap, err := h.hasOverlappingClosedPeriod(r.Context(), tenantID, startDate, endDate, ignoreID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if hasOverlap {
-		httpError(w, "another closed period overlaps selected range", http.StatusConflict)
+		writeError(w, http.StatusConflict, problem.CodeClosedPeriodOverlap, "another closed period overlaps selected range")
 		return
 	}
 
-	settings, err := h.loadTimeBankSettings(tenantID)
+	settings, err := h.loadTimeBankSettings(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
-	summary, err := h.buildTimeBankSummary(tenantID, startDate, endDate, settings)
+	summary, err := h.buildTimeBankSummary(r.Context(), tenantID, startDate, endDate, settings)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	note := normalizeOptionalString(req.Note)
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var closureID uint64
-	getErr := tx.Get(&closureID, `
+	getErr := tx.GetContext(r.Context(), &closureID, `
 		SELECT id FROM hr_time_bank_closures
 		WHERE tenant_id=? AND period_start=? AND period_end=?
 		LIMIT 1
 	`, tenantID, startDate, endDate)
 	if getErr == sql.ErrNoRows {
-		res, execErr := tx.Exec(`
+		res, execErr := tx.ExecContext(r.Context(), `
 			INSERT INTO hr_time_bank_closures (
 				tenant_id, period_start, period_end, status, note, closed_at, closed_by, reopened_at, reopened_by
 			) VALUES (?, ?, ?, 'closed', ?, UTC_TIMESTAMP(), ?, NULL, NULL)
 		`, tenantID, startDate, endDate, note, userID)
 		if execErr != nil {
-			httpError(w, "could not close time bank period", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, problem.CodeCouldNotCloseTimeBankPeriod, "could not close time bank period")
 			return
 		}
 		id64, _ := res.LastInsertId()
 		closureID = uint64(id64)
 	} else if getErr != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	} else {
-		if _, execErr := tx.Exec(`
+		if _, execErr := tx.ExecContext(r.Context(), `
 			UPDATE hr_time_bank_closures
 			SET status='closed',
 			    note=?,
@@ -668,36 +694,39 @@ func (h *HRHandler) CloseTimeBankPeriod(w http.ResponseWriter, r *http.Request)
 			    updated_at=CURRENT_TIMESTAMP
 			WHERE tenant_id=? AND id=?
 		`, note, userID, tenantID, closureID); execErr != nil {
-			httpError(w, "could not close time bank period", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, problem.CodeCouldNotCloseTimeBankPeriod, "could not close time bank period")
 			return
 		}
 	}
 
-	if _, err := tx.Exec(`DELETE FROM hr_time_bank_closure_items WHERE tenant_id=? AND closure_id=?`, tenantID, closureID); err != nil {
-		httpError(w, "db delete error", http.StatusInternalServerError)
+	if _, err := tx.ExecContext(r.Context(), `DELETE FROM hr_time_bank_closure_items WHERE tenant_id=? AND closure_id=?`, tenantID, closureID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db delete error")
 		return
 	}
 
 	for _, employee := range summary.Employees {
-		if _, err := tx.Exec(`
+		if _, err := tx.ExecContext(r.Context(), `
 			INSERT INTO hr_time_bank_closure_items (
 				tenant_id, closure_id, employee_id, worked_seconds, expected_seconds, adjustment_seconds, balance_seconds
 			) VALUES (?, ?, ?, ?, ?, ?, ?)
 		`, tenantID, closureID, employee.EmployeeID, employee.WorkedSeconds, employee.ExpectedSeconds, employee.AdjustmentSeconds, employee.BalanceSeconds); err != nil {
-			httpError(w, "db update error", http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 			return
 		}
 	}
 
-	closure, err := h.getTimeBankClosureByID(tx, tenantID, closureID)
+	closure, err := h.getTimeBankClosureByID(r.Context(), tx, tenantID, closureID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "close", "hr_time_bank_closures", int64(closureID), nil, closure)
+	if err := insertAudit(tx, r, tenantID, userID, "close", "hr_time_bank_closures", int64(closureID), nil, closure); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
@@ -711,7 +740,7 @@ func (h *HRHandler) ListTimeBankClosures(w http.ResponseWriter, r *http.Request)
 	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
 		parsed, err := strconv.Atoi(raw)
 		if err != nil || parsed <= 0 {
-			httpError(w, "limit must be numeric", http.StatusBadRequest)
+			writeFieldError(w, "limit", problem.RuleNumber, "limit must be numeric")
 			return
 		}
 		if parsed > maxTimeBankLimit {
@@ -721,14 +750,14 @@ func (h *HRHandler) ListTimeBankClosures(w http.ResponseWriter, r *http.Request)
 	}
 
 	items := make([]TimeBankClosure, 0, limit)
-	if err := h.DB.Select(&items, timeBankClosureSelect+`
+	if err := h.DB.SelectContext(r.Context(), &items, timeBankClosureSelect+`
 		WHERE c.tenant_id=?
 		GROUP BY c.id, c.tenant_id, c.period_start, c.period_end, c.status, c.note,
 		         c.closed_at, c.closed_by, c.reopened_at, c.reopened_by, c.created_at, c.updated_at
 		ORDER BY c.period_end DESC, c.id DESC
 		LIMIT ?
 	`, tenantID, limit); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -739,22 +768,22 @@ func (h *HRHandler) ExportTimeBankClosureCSV(w http.ResponseWriter, r *http.Requ
 
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid request id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidRequestID, "invalid request id")
 		return
 	}
 
-	closure, err := h.getTimeBankClosureByID(h.DB, tenantID, id)
+	closure, err := h.getTimeBankClosureByID(r.Context(), h.DB, tenantID, id)
 	if err == sql.ErrNoRows {
-		httpError(w, "time bank closure not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeTimeBankClosureNotFound, "time bank closure not found")
 		return
 	}
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	items := make([]timeBankClosureItemExport, 0, 200)
-	if err := h.DB.Select(&items, `
+	if err := h.DB.SelectContext(r.Context(), &items, `
 		SELECT i.employee_id, e.name AS employee_name, i.worked_seconds, i.expected_seconds,
 		       i.adjustment_seconds, i.balance_seconds
 		FROM hr_time_bank_closure_items i
@@ -762,7 +791,7 @@ func (h *HRHandler) ExportTimeBankClosureCSV(w http.ResponseWriter, r *http.Requ
 		WHERE i.tenant_id=? AND i.closure_id=?
 		ORDER BY e.name ASC, i.employee_id ASC
 	`, tenantID, id); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
@@ -776,14 +805,15 @@ func (h *HRHandler) ExportTimeBankClosureCSV(w http.ResponseWriter, r *http.Requ
 	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
 	w.WriteHeader(http.StatusOK)
 
+	lang := mw.GetLocale(r.Context())
 	writer := csv.NewWriter(w)
 	_ = writer.Write([]string{
-		"employee_id",
-		"colaborador",
-		"trabalhadas_horas",
-		"previstas_horas",
-		"ajustes_horas",
-		"saldo_horas",
+		i18n.T(lang, "csv.employee_id"),
+		i18n.T(lang, "csv.employee"),
+		i18n.T(lang, "csv.worked_hours"),
+		i18n.T(lang, "csv.expected_hours"),
+		i18n.T(lang, "csv.adjustment_hours"),
+		i18n.T(lang, "csv.balance_hours"),
 	})
 	for _, item := range items {
 		_ = writer.Write([]string{
@@ -803,23 +833,23 @@ func (h *HRHandler) ListTimeBankClosureEmployees(w http.ResponseWriter, r *http.
 
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid request id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidRequestID, "invalid request id")
 		return
 	}
 
-	_, err = h.getTimeBankClosureByID(h.DB, tenantID, id)
+	_, err = h.getTimeBankClosureByID(r.Context(), h.DB, tenantID, id)
 	if err == sql.ErrNoRows {
-		httpError(w, "time bank closure not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeTimeBankClosureNotFound, "time bank closure not found")
 		return
 	}
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	employees, err := h.loadTimeBankClosureCardEmployees(tenantID, id, nil)
+	employees, err := h.loadTimeBankClosureCardEmployees(r.Context(), tenantID, id, nil)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
@@ -843,45 +873,45 @@ func (h *HRHandler) ExportTimeBankClosureCardsPDF(w http.ResponseWriter, r *http
 
 	closureID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid request id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidRequestID, "invalid request id")
 		return
 	}
 
-	closure, err := h.getTimeBankClosureByID(h.DB, tenantID, closureID)
+	closure, err := h.getTimeBankClosureByID(r.Context(), h.DB, tenantID, closureID)
 	if err == sql.ErrNoRows {
-		httpError(w, "time bank closure not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeTimeBankClosureNotFound, "time bank closure not found")
 		return
 	}
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	employees, err := h.loadTimeBankClosureCardEmployees(tenantID, closureID, nil)
+	employees, err := h.loadTimeBankClosureCardEmployees(r.Context(), tenantID, closureID, nil)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if len(employees) == 0 {
-		httpError(w, "employee not found in closure", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeNotFoundInClosure, "employee not found in closure")
 		return
 	}
 
-	settings, err := h.loadTimeBankSettings(tenantID)
+	settings, err := h.loadTimeBankSettings(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	tenantName, err := h.loadTenantName(tenantID)
+	tenantName, err := h.loadTenantName(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	pdfBytes, err := h.buildTimeBankCardsPDF(tenantID, tenantName, closure, employees, settings)
+	pdfBytes, err := h.buildTimeBankCardsPDF(r.Context(), mw.GetLocale(r.Context()), tenantID, tenantName, closure, employees, settings)
 	if err != nil {
-		httpError(w, "could not generate time card pdf", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeTimeCardPDFFailed, "could not generate time card pdf")
 		return
 	}
 
@@ -901,51 +931,51 @@ func (h *HRHandler) ExportTimeBankEmployeeCardPDF(w http.ResponseWriter, r *http
 
 	closureID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid request id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidRequestID, "invalid request id")
 		return
 	}
 	employeeID, err := strconv.ParseUint(chi.URLParam(r, "employee_id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid employee id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidEmployeeID, "invalid employee id")
 		return
 	}
 
-	closure, err := h.getTimeBankClosureByID(h.DB, tenantID, closureID)
+	closure, err := h.getTimeBankClosureByID(r.Context(), h.DB, tenantID, closureID)
 	if err == sql.ErrNoRows {
-		httpError(w, "time bank closure not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeTimeBankClosureNotFound, "time bank closure not found")
 		return
 	}
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	employees, err := h.loadTimeBankClosureCardEmployees(tenantID, closureID, &employeeID)
+	employees, err := h.loadTimeBankClosureCardEmployees(r.Context(), tenantID, closureID, &employeeID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if len(employees) == 0 {
-		httpError(w, "employee not found in closure", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeNotFoundInClosure, "employee not found in closure")
 		return
 	}
 	employee := employees[0]
 
-	settings, err := h.loadTimeBankSettings(tenantID)
+	settings, err := h.loadTimeBankSettings(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	tenantName, err := h.loadTenantName(tenantID)
+	tenantName, err := h.loadTenantName(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	pdfBytes, err := h.buildTimeBankCardsPDF(tenantID, tenantName, closure, employees, settings)
+	pdfBytes, err := h.buildTimeBankCardsPDF(r.Context(), mw.GetLocale(r.Context()), tenantID, tenantName, closure, employees, settings)
 	if err != nil {
-		httpError(w, "could not generate time card pdf", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeTimeCardPDFFailed, "could not generate time card pdf")
 		return
 	}
 
@@ -966,44 +996,45 @@ func (h *HRHandler) ExportTimeBankEmployeeCardCSV(w http.ResponseWriter, r *http
 
 	closureID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid request id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidRequestID, "invalid request id")
 		return
 	}
 	employeeID, err := strconv.ParseUint(chi.URLParam(r, "employee_id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid employee id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidEmployeeID, "invalid employee id")
 		return
 	}
 
-	closure, err := h.getTimeBankClosureByID(h.DB, tenantID, closureID)
+	closure, err := h.getTimeBankClosureByID(r.Context(), h.DB, tenantID, closureID)
 	if err == sql.ErrNoRows {
-		httpError(w, "time bank closure not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeTimeBankClosureNotFound, "time bank closure not found")
 		return
 	}
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	employees, err := h.loadTimeBankClosureCardEmployees(tenantID, closureID, &employeeID)
+	employees, err := h.loadTimeBankClosureCardEmployees(r.Context(), tenantID, closureID, &employeeID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if len(employees) == 0 {
-		httpError(w, "employee not found in closure", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeNotFoundInClosure, "employee not found in closure")
 		return
 	}
 	employee := employees[0]
 
-	settings, err := h.loadTimeBankSettings(tenantID)
+	settings, err := h.loadTimeBankSettings(r.Context(), tenantID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
-	days, err := h.buildTimeCardDays(tenantID, employeeID, closure.PeriodStart, closure.PeriodEnd, settings)
+	lang := mw.GetLocale(r.Context())
+	days, err := h.buildTimeCardDays(r.Context(), lang, tenantID, employeeID, closure.PeriodStart, closure.PeriodEnd, settings)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
@@ -1018,25 +1049,26 @@ func (h *HRHandler) ExportTimeBankEmployeeCardCSV(w http.ResponseWriter, r *http
 	w.WriteHeader(http.StatusOK)
 
 	writer := csv.NewWriter(w)
-	_ = writer.Write([]string{"cartao_ponto"})
-	_ = writer.Write([]string{fmt.Sprintf("colaborador,%s", employee.EmployeeName)})
+	_ = writer.Write([]string{i18n.T(lang, "csv.time_card")})
+	_ = writer.Write([]string{fmt.Sprintf("%s,%s", i18n.T(lang, "csv.employee"), employee.EmployeeName)})
 	_ = writer.Write([]string{fmt.Sprintf(
-		"periodo,%s,%s",
+		"%s,%s,%s",
+		i18n.T(lang, "csv.period"),
 		closure.PeriodStart.Format("2006-01-02"),
 		closure.PeriodEnd.Format("2006-01-02"),
 	)})
 	_ = writer.Write([]string{})
 	_ = writer.Write([]string{
-		"data",
-		"dia_semana",
-		"ent_1",
-		"sai_1",
-		"ent_2",
-		"sai_2",
-		"trabalhadas_horas",
-		"previstas_horas",
-		"ajustes_horas",
-		"saldo_horas",
+		i18n.T(lang, "csv.date"),
+		i18n.T(lang, "csv.weekday"),
+		i18n.T(lang, "csv.in_1"),
+		i18n.T(lang, "csv.out_1"),
+		i18n.T(lang, "csv.in_2"),
+		i18n.T(lang, "csv.out_2"),
+		i18n.T(lang, "csv.worked_hours"),
+		i18n.T(lang, "csv.expected_hours"),
+		i18n.T(lang, "csv.adjustment_hours"),
+		i18n.T(lang, "csv.balance_hours"),
 	})
 
 	for _, day := range days {
@@ -1056,7 +1088,7 @@ func (h *HRHandler) ExportTimeBankEmployeeCardCSV(w http.ResponseWriter, r *http
 
 	_ = writer.Write([]string{})
 	_ = writer.Write([]string{
-		"totais",
+		i18n.T(lang, "csv.totals"),
 		"",
 		"",
 		"",
@@ -1071,7 +1103,7 @@ func (h *HRHandler) ExportTimeBankEmployeeCardCSV(w http.ResponseWriter, r *http
 	writer.Flush()
 }
 
-func (h *HRHandler) loadTimeBankClosureCardEmployees(tenantID, closureID uint64, employeeID *uint64) ([]timeBankCardEmployee, error) {
+func (h *HRHandler) loadTimeBankClosureCardEmployees(ctx context.Context, tenantID, closureID uint64, employeeID *uint64) ([]timeBankCardEmployee, error) {
 	query := `
 		SELECT i.employee_id, e.name AS employee_name, e.employee_code, e.status, e.hire_date,
 		       d.name AS department_name, p.title AS position_title,
@@ -1090,39 +1122,45 @@ func (h *HRHandler) loadTimeBankClosureCardEmployees(tenantID, closureID uint64,
 	query += " ORDER BY e.name ASC, i.employee_id ASC"
 
 	items := make([]timeBankCardEmployee, 0, 200)
-	if err := h.DB.Select(&items, query, args...); err != nil {
+	if err := h.DB.SelectContext(ctx, &items, query, args...); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
-func (h *HRHandler) loadTenantName(tenantID uint64) (string, error) {
+func (h *HRHandler) loadTenantName(ctx context.Context, tenantID uint64) (string, error) {
 	var tenantName string
-	if err := h.DB.Get(&tenantName, `SELECT name FROM tenants WHERE id=? LIMIT 1`, tenantID); err != nil {
+	if err := h.DB.GetContext(ctx, &tenantName, `SELECT name FROM tenants WHERE id=? LIMIT 1`, tenantID); err != nil {
 		return "", err
 	}
 	return tenantName, nil
 }
 
 func (h *HRHandler) buildTimeBankCardsPDF(
+	ctx context.Context,
+	lang i18n.Lang,
 	tenantID uint64,
 	tenantName string,
 	closure TimeBankClosure,
 	employees []timeBankCardEmployee,
 	settings timeBankSettings,
 ) ([]byte, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "timebank.cards_pdf",
+		trace.WithAttributes(attribute.Int("employees", len(employees))))
+	defer span.End()
+
 	pdf := fpdf.New("P", "mm", "A4", "")
 	pdf.SetMargins(10, 10, 10)
 	pdf.SetAutoPageBreak(false, 10)
-	pdf.SetTitle("Cartoes de ponto", false)
+	pdf.SetTitle(i18n.T(lang, "timecard.document_title"), false)
 	generatedAt := time.Now().UTC()
 
 	for idx, employee := range employees {
-		days, err := h.buildTimeCardDays(tenantID, employee.EmployeeID, closure.PeriodStart, closure.PeriodEnd, settings)
+		days, err := h.buildTimeCardDays(ctx, lang, tenantID, employee.EmployeeID, closure.PeriodStart, closure.PeriodEnd, settings)
 		if err != nil {
 			return nil, err
 		}
-		renderTimeCardEmployeePages(pdf, tenantName, closure, employee, days, idx+1, len(employees), generatedAt)
+		renderTimeCardEmployeePages(pdf, lang, tenantName, closure, employee, days, idx+1, len(employees), generatedAt)
 	}
 
 	var out bytes.Buffer
@@ -1138,37 +1176,37 @@ func (h *HRHandler) ReopenTimeBankClosure(w http.ResponseWriter, r *http.Request
 
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		httpError(w, "invalid request id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidRequestID, "invalid request id")
 		return
 	}
 
 	var req reopenTimeBankReq
 	if r.ContentLength > 0 {
 		if err := decodeJSON(r, &req); err != nil {
-			httpError(w, err.Error(), http.StatusBadRequest)
+			writeProblem(w, err)
 			return
 		}
 	}
 	note := normalizeOptionalString(req.Note)
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	before, err := h.getTimeBankClosureByID(tx, tenantID, id)
+	before, err := h.getTimeBankClosureByID(r.Context(), tx, tenantID, id)
 	if err == sql.ErrNoRows {
-		httpError(w, "time bank closure not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeTimeBankClosureNotFound, "time bank closure not found")
 		return
 	}
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	if _, err := tx.Exec(`
+	if _, err := tx.ExecContext(r.Context(), `
 		UPDATE hr_time_bank_closures
 		SET status='reopened',
 		    note=COALESCE(?, note),
@@ -1177,28 +1215,31 @@ func (h *HRHandler) ReopenTimeBankClosure(w http.ResponseWriter, r *http.Request
 		    updated_at=CURRENT_TIMESTAMP
 		WHERE tenant_id=? AND id=?
 	`, note, userID, tenantID, id); err != nil {
-		httpError(w, "could not reopen time bank period", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotReopenTimeBankPeriod, "could not reopen time bank period")
 		return
 	}
 
-	after, err := h.getTimeBankClosureByID(tx, tenantID, id)
+	after, err := h.getTimeBankClosureByID(r.Context(), tx, tenantID, id)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(tx, r, tenantID, userID, "reopen", "hr_time_bank_closures", int64(id), before, after)
+	if err := insertAudit(tx, r, tenantID, userID, "reopen", "hr_time_bank_closures", int64(id), before, after); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
 	writeJSON(w, http.StatusOK, after)
 }
 
-func (h *HRHandler) loadTimeBankSettings(tenantID uint64) (timeBankSettings, error) {
+func (h *HRHandler) loadTimeBankSettings(ctx context.Context, tenantID uint64) (timeBankSettings, error) {
 	var settings timeBankSettings
-	err := h.DB.Get(&settings, `
+	err := h.DB.GetContext(ctx, &settings, `
 		SELECT target_daily_minutes, include_saturday, updated_at
 		FROM hr_time_bank_settings
 		WHERE tenant_id=?
@@ -1215,9 +1256,12 @@ func (h *HRHandler) loadTimeBankSettings(tenantID uint64) (timeBankSettings, err
 	return settings, nil
 }
 
-func (h *HRHandler) buildTimeBankSummary(tenantID uint64, startDate, endDate time.Time, settings timeBankSettings) (TimeBankSummaryResp, error) {
+func (h *HRHandler) buildTimeBankSummary(ctx context.Context, tenantID uint64, startDate, endDate time.Time, settings timeBankSettings) (TimeBankSummaryResp, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "timebank.summary")
+	defer span.End()
+
 	employees := make([]timeBankEmployeeRow, 0, 200)
-	if err := h.DB.Select(&employees, `
+	if err := h.DB.SelectContext(ctx, &employees, `
 		SELECT id, name, status, hire_date, termination_date
 		FROM employees
 		WHERE tenant_id=?
@@ -1229,7 +1273,7 @@ func (h *HRHandler) buildTimeBankSummary(tenantID uint64, startDate, endDate tim
 	}
 
 	workedRows := make([]employeeWorkedSecondsRow, 0, len(employees))
-	if err := h.DB.Select(&workedRows, `
+	if err := h.DB.SelectContext(ctx, &workedRows, `
 		SELECT employee_id,
 		       COALESCE(SUM(
 		         CASE
@@ -1249,7 +1293,7 @@ func (h *HRHandler) buildTimeBankSummary(tenantID uint64, startDate, endDate tim
 	}
 
 	adjustRows := make([]employeeAdjustmentSecondsRow, 0, len(employees))
-	if err := h.DB.Select(&adjustRows, `
+	if err := h.DB.SelectContext(ctx, &adjustRows, `
 		SELECT employee_id, COALESCE(SUM(seconds_delta), 0) AS adjustment_seconds
 		FROM hr_time_bank_adjustments
 		WHERE tenant_id=? AND status=? AND effective_date>=? AND effective_date<=?
@@ -1318,7 +1362,7 @@ func (h *HRHandler) buildTimeBankSummary(tenantID uint64, startDate, endDate tim
 	return resp, nil
 }
 
-func (h *HRHandler) hasOverlappingClosedPeriod(tenantID uint64, startDate, endDate time.Time, ignoreID *uint64) (bool, error) {
+func (h *HRHandler) hasOverlappingClosedPeriod(ctx context.Context, tenantID uint64, startDate, endDate time.Time, ignoreID *uint64) (bool, error) {
 	query := `
 		SELECT COUNT(*)
 		FROM hr_time_bank_closures
@@ -1331,15 +1375,15 @@ func (h *HRHandler) hasOverlappingClosedPeriod(tenantID uint64, startDate, endDa
 	}
 
 	var count int64
-	if err := h.DB.Get(&count, query, args...); err != nil {
+	if err := h.DB.GetContext(ctx, &count, query, args...); err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-func (h *HRHandler) isDateClosedForTimeBank(tenantID uint64, targetDate time.Time) (bool, error) {
+func (h *HRHandler) isDateClosedForTimeBank(ctx context.Context, tenantID uint64, targetDate time.Time) (bool, error) {
 	var count int64
-	if err := h.DB.Get(&count, `
+	if err := h.DB.GetContext(ctx, &count, `
 		SELECT COUNT(*)
 		FROM hr_time_bank_closures
 		WHERE tenant_id=? AND status='closed' AND period_start<=? AND period_end>=?
@@ -1362,9 +1406,9 @@ const timeBankClosureSelect = `
 	       ON i.tenant_id=c.tenant_id AND i.closure_id=c.id
 `
 
-func (h *HRHandler) getTimeBankClosureByID(exec sqlExecutor, tenantID uint64, id uint64) (TimeBankClosure, error) {
+func (h *HRHandler) getTimeBankClosureByID(ctx context.Context, exec sqlExecutor, tenantID uint64, id uint64) (TimeBankClosure, error) {
 	var closure TimeBankClosure
-	if err := exec.Get(&closure, timeBankClosureSelect+`
+	if err := exec.GetContext(ctx, &closure, timeBankClosureSelect+`
 		WHERE c.tenant_id=? AND c.id=?
 		GROUP BY c.id, c.tenant_id, c.period_start, c.period_end, c.status, c.note,
 		         c.closed_at, c.closed_by, c.reopened_at, c.reopened_by, c.created_at, c.updated_at
@@ -1375,9 +1419,9 @@ func (h *HRHandler) getTimeBankClosureByID(exec sqlExecutor, tenantID uint64, id
 	return closure, nil
 }
 
-func (h *HRHandler) getTimeBankAdjustmentByID(exec sqlExecutor, tenantID, id uint64) (TimeBankAdjustment, error) {
+func (h *HRHandler) getTimeBankAdjustmentByID(ctx context.Context, exec sqlExecutor, tenantID, id uint64) (TimeBankAdjustment, error) {
 	var item TimeBankAdjustment
-	if err := exec.Get(&item, `
+	if err := exec.GetContext(ctx, &item, `
 		SELECT a.id, a.tenant_id, a.employee_id, e.name AS employee_name, a.effective_date,
 		       a.seconds_delta, a.status, a.reason, a.review_note, a.created_by, a.reviewed_by, a.reviewed_at, a.created_at
 		FROM hr_time_bank_adjustments a
@@ -1391,7 +1435,7 @@ func (h *HRHandler) getTimeBankAdjustmentByID(exec sqlExecutor, tenantID, id uin
 }
 
 type sqlExecutor interface {
-	Get(dest any, query string, args ...any) error
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
 }
 
 func parseTimeBankRange(startRaw, endRaw string) (time.Time, time.Time, error) {
@@ -1403,13 +1447,13 @@ func parseTimeBankRange(startRaw, endRaw string) (time.Time, time.Time, error) {
 	if strings.TrimSpace(startRaw) != "" {
 		start, err = parseDate(startRaw)
 		if err != nil {
-			return time.Time{}, time.Time{}, errString("start_date must be YYYY-MM-DD")
+			return time.Time{}, time.Time{}, problem.Invalid("start_date", problem.RuleDate, "start_date must be YYYY-MM-DD")
 		}
 	}
 	if strings.TrimSpace(endRaw) != "" {
 		end, err = parseDate(endRaw)
 		if err != nil {
-			return time.Time{}, time.Time{}, errString("end_date must be YYYY-MM-DD")
+			return time.Time{}, time.Time{}, problem.Invalid("end_date", problem.RuleDate, "end_date must be YYYY-MM-DD")
 		}
 	}
 
@@ -1418,7 +1462,7 @@ func parseTimeBankRange(startRaw, endRaw string) (time.Time, time.Time, error) {
 	}
 
 	if end.Before(start) {
-		return time.Time{}, time.Time{}, errString("end_date must be >= start_date")
+		return time.Time{}, time.Time{}, problem.Invalid("end_date", problem.RuleMin, "end_date must be >= start_date", "start_date")
 	}
 
 	return start, end, nil
@@ -1426,7 +1470,7 @@ func parseTimeBankRange(startRaw, endRaw string) (time.Time, time.Time, error) {
 
 func parseTimeBankDelta(secondsDelta, minutesDelta *int64) (int64, error) {
 	if secondsDelta != nil && minutesDelta != nil {
-		return 0, errString("seconds_delta and minutes_delta cannot be used together")
+		return 0, problem.New(http.StatusBadRequest, problem.CodeDeltaConflict, "seconds_delta and minutes_delta cannot be used together")
 	}
 
 	var delta int64
@@ -1436,11 +1480,11 @@ func parseTimeBankDelta(secondsDelta, minutesDelta *int64) (int64, error) {
 	case minutesDelta != nil:
 		delta = *minutesDelta * 60
 	default:
-		return 0, errString("seconds_delta or minutes_delta is required")
+		return 0, problem.New(http.StatusBadRequest, problem.CodeDeltaRequired, "seconds_delta or minutes_delta is required")
 	}
 
 	if delta == 0 {
-		return 0, errString("delta must be non-zero")
+		return 0, problem.Invalid("delta", problem.RuleNonZero, "delta must be non-zero")
 	}
 	return delta, nil
 }
@@ -1501,7 +1545,8 @@ func nullTimePtr(v sql.NullTime) *time.Time {
 	return &t
 }
 
-func (h *HRHandler) buildTimeCardDays(
+func (h *HRHandler) buildTimeCardDays(ctx context.Context,
+	lang i18n.Lang,
 	tenantID uint64,
 	employeeID uint64,
 	startDate time.Time,
@@ -1509,7 +1554,7 @@ func (h *HRHandler) buildTimeCardDays(
 	settings timeBankSettings,
 ) ([]timeBankCardDay, error) {
 	entries := make([]timeBankCardEntry, 0, 128)
-	if err := h.DB.Select(&entries, `
+	if err := h.DB.SelectContext(ctx, &entries, `
 		SELECT start_at, end_at, duration_seconds, is_running
 		FROM hr_time_entries
 		WHERE tenant_id=? AND employee_id=? AND start_at>=? AND start_at<?
@@ -1522,7 +1567,7 @@ func (h *HRHandler) buildTimeCardDays(
 		DayDate           time.Time `db:"day_date"`
 		AdjustmentSeconds int64     `db:"adjustment_seconds"`
 	}, 0, 64)
-	if err := h.DB.Select(&adjustRows, `
+	if err := h.DB.SelectContext(ctx, &adjustRows, `
 		SELECT effective_date AS day_date, COALESCE(SUM(seconds_delta), 0) AS adjustment_seconds
 		FROM hr_time_bank_adjustments
 		WHERE tenant_id=? AND employee_id=? AND status=? AND effective_date>=? AND effective_date<=?
@@ -1551,7 +1596,7 @@ func (h *HRHandler) buildTimeCardDays(
 
 		row := timeBankCardDay{
 			Date:              day,
-			WeekdayLabel:      weekdayPT(day.Weekday()),
+			WeekdayLabel:      weekdayLabel(lang, day.Weekday()),
 			AdjustmentSeconds: adjustByDay[key],
 		}
 
@@ -1610,27 +1655,15 @@ func normalizeDurationForCard(entry timeBankCardEntry) int64 {
 	return 0
 }
 
-func weekdayPT(wd time.Weekday) string {
-	switch wd {
-	case time.Monday:
-		return "seg"
-	case time.Tuesday:
-		return "ter"
-	case time.Wednesday:
-		return "qua"
-	case time.Thursday:
-		return "qui"
-	case time.Friday:
-		return "sex"
-	case time.Saturday:
-		return "sab"
-	default:
-		return "dom"
-	}
+var weekdayKeys = [...]string{"weekday.sun", "weekday.mon", "weekday.tue", "weekday.wed", "weekday.thu", "weekday.fri", "weekday.sat"}
+
+func weekdayLabel(lang i18n.Lang, wd time.Weekday) string {
+	return i18n.T(lang, weekdayKeys[wd])
 }
 
 func renderTimeCardEmployeePages(
 	pdf *fpdf.Fpdf,
+	lang i18n.Lang,
 	tenantName string,
 	closure TimeBankClosure,
 	employee timeBankCardEmployee,
@@ -1651,6 +1684,7 @@ func renderTimeCardEmployeePages(
 		pdf.AddPage()
 		drawTimeCardHeader(
 			pdf,
+			lang,
 			tenantName,
 			closure,
 			employee,
@@ -1660,7 +1694,7 @@ func renderTimeCardEmployeePages(
 			totalPages,
 			generatedAt,
 		)
-		drawTimeCardTableHeader(pdf)
+		drawTimeCardTableHeader(pdf, lang)
 
 		start := page * timeCardRowsPerPage
 		end := start + timeCardRowsPerPage
@@ -1669,22 +1703,23 @@ func renderTimeCardEmployeePages(
 		}
 
 		if start >= len(days) {
-			drawTimeCardEmptyRow(pdf, "Sem registros no periodo.")
+			drawTimeCardEmptyRow(pdf, i18n.T(lang, "timecard.empty"))
 		} else {
 			for rowIdx, day := range days[start:end] {
-				drawTimeCardDayRow(pdf, day, (start+rowIdx)%2 == 1)
+				drawTimeCardDayRow(pdf, lang, day, (start+rowIdx)%2 == 1)
 			}
 		}
 
 		if page == totalPages-1 {
-			drawTimeCardTotalsRow(pdf, employee)
-			drawTimeCardSignatureArea(pdf, employee.EmployeeName)
+			drawTimeCardTotalsRow(pdf, lang, employee)
+			drawTimeCardSignatureArea(pdf, lang, employee.EmployeeName)
 		}
 	}
 }
 
 func drawTimeCardHeader(
 	pdf *fpdf.Fpdf,
+	lang i18n.Lang,
 	tenantName string,
 	closure TimeBankClosure,
 	employee timeBankCardEmployee,
@@ -1695,17 +1730,18 @@ func drawTimeCardHeader(
 	generatedAt time.Time,
 ) {
 	pdf.SetFont(timeCardFontName, "B", 16)
-	pdf.CellFormat(0, 8, "CARTAO PONTO", "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 8, i18n.T(lang, "timecard.title"), "", 1, "L", false, 0, "")
 
 	pdf.SetFont(timeCardFontName, "", 10)
-	pdf.CellFormat(0, 5, fmt.Sprintf("Periodo: %s ate %s", formatDateBR(closure.PeriodStart), formatDateBR(closure.PeriodEnd)), "", 1, "L", false, 0, "")
-	pdf.CellFormat(0, 5, fmt.Sprintf("Empresa: %s", tenantName), "", 1, "L", false, 0, "")
-	pdf.CellFormat(0, 5, fmt.Sprintf("Colaborador: %s", employee.EmployeeName), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, i18n.T(lang, "timecard.period", formatDate(lang, closure.PeriodStart), formatDate(lang, closure.PeriodEnd)), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, i18n.T(lang, "timecard.company", tenantName), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, i18n.T(lang, "timecard.employee", employee.EmployeeName), "", 1, "L", false, 0, "")
 	pdf.CellFormat(
 		0,
 		5,
-		fmt.Sprintf(
-			"Codigo: %s   Departamento: %s   Funcao: %s",
+		i18n.T(
+			lang,
+			"timecard.details",
 			defaultOrDash(strings.TrimSpace(employee.EmployeeCode)),
 			nullStringOrDash(employee.DepartmentName),
 			nullStringOrDash(employee.PositionTitle),
@@ -1720,10 +1756,11 @@ func drawTimeCardHeader(
 	pdf.CellFormat(
 		0,
 		5,
-		fmt.Sprintf(
-			"Admissao: %s   Status: %s",
-			nullDateOrDash(employee.HireDate),
-			statusLabelPT(employee.Status),
+		i18n.T(
+			lang,
+			"timecard.hire",
+			nullDateOrDash(lang, employee.HireDate),
+			employeeStatusLabel(lang, employee.Status),
 		),
 		"",
 		1,
@@ -1735,9 +1772,10 @@ func drawTimeCardHeader(
 	pdf.CellFormat(
 		0,
 		5,
-		fmt.Sprintf(
-			"Emitido em %s UTC   Colaborador %d/%d   Pagina %d/%d",
-			generatedAt.Format("02/01/2006 15:04"),
+		i18n.T(
+			lang,
+			"timecard.issued",
+			generatedAt.Format(i18n.DateLayout(lang)+" 15:04"),
 			employeeIndex,
 			totalEmployees,
 			page,
@@ -1757,25 +1795,25 @@ func drawTimeCardHeader(
 	pdf.SetY(y + 3)
 }
 
-func drawTimeCardTableHeader(pdf *fpdf.Fpdf) {
+func drawTimeCardTableHeader(pdf *fpdf.Fpdf, lang i18n.Lang) {
 	pdf.SetFont(timeCardFontName, "B", 8.5)
 	pdf.SetTextColor(255, 255, 255)
 	pdf.SetFillColor(28, 38, 56)
 	for _, col := range timeCardColumns {
-		pdf.CellFormat(col.Width, timeCardTableRowH+0.5, col.Title, "1", 0, "C", true, 0, "")
+		pdf.CellFormat(col.Width, timeCardTableRowH+0.5, i18n.T(lang, col.Title), "1", 0, "C", true, 0, "")
 	}
 	pdf.Ln(-1)
 	pdf.SetTextColor(0, 0, 0)
 }
 
-func drawTimeCardDayRow(pdf *fpdf.Fpdf, day timeBankCardDay, fill bool) {
+func drawTimeCardDayRow(pdf *fpdf.Fpdf, lang i18n.Lang, day timeBankCardDay, fill bool) {
 	pdf.SetFont(timeCardFontName, "", 8.5)
 	if fill {
 		pdf.SetFillColor(245, 248, 252)
 	}
 
 	values := []string{
-		formatDateBR(day.Date),
+		formatDate(lang, day.Date),
 		strings.ToUpper(day.WeekdayLabel),
 		day.Entry1,
 		day.Exit1,
@@ -1792,12 +1830,12 @@ func drawTimeCardDayRow(pdf *fpdf.Fpdf, day timeBankCardDay, fill bool) {
 	pdf.Ln(-1)
 }
 
-func drawTimeCardTotalsRow(pdf *fpdf.Fpdf, employee timeBankCardEmployee) {
+func drawTimeCardTotalsRow(pdf *fpdf.Fpdf, lang i18n.Lang, employee timeBankCardEmployee) {
 	pdf.SetFont(timeCardFontName, "B", 8.8)
 	pdf.SetFillColor(228, 236, 247)
 
 	labelWidth := timeCardColumnsWidth(0, 6)
-	pdf.CellFormat(labelWidth, timeCardTableRowH+0.3, "TOTAIS", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(labelWidth, timeCardTableRowH+0.3, i18n.T(lang, "timecard.totals"), "1", 0, "L", true, 0, "")
 	pdf.CellFormat(timeCardColumns[6].Width, timeCardTableRowH+0.3, formatDurationClock(employee.WorkedSeconds, false), "1", 0, timeCardColumns[6].Align, true, 0, "")
 	pdf.CellFormat(timeCardColumns[7].Width, timeCardTableRowH+0.3, formatDurationClock(employee.ExpectedSeconds, false), "1", 0, timeCardColumns[7].Align, true, 0, "")
 	pdf.CellFormat(timeCardColumns[8].Width, timeCardTableRowH+0.3, formatDurationClock(employee.AdjustmentSeconds, true), "1", 0, timeCardColumns[8].Align, true, 0, "")
@@ -1805,10 +1843,10 @@ func drawTimeCardTotalsRow(pdf *fpdf.Fpdf, employee timeBankCardEmployee) {
 	pdf.Ln(-1)
 }
 
-func drawTimeCardSignatureArea(pdf *fpdf.Fpdf, employeeName string) {
+func drawTimeCardSignatureArea(pdf *fpdf.Fpdf, lang i18n.Lang, employeeName string) {
 	pdf.Ln(6)
 	pdf.SetFont(timeCardFontName, "", 8.5)
-	pdf.CellFormat(0, 5, "Declaro que as marcacoes acima conferem com a jornada realizada.", "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, i18n.T(lang, "timecard.declaration"), "", 1, "L", false, 0, "")
 
 	signY := pdf.GetY() + 12
 	signStartX := 20.0
@@ -1822,13 +1860,13 @@ func drawTimeCardSignatureArea(pdf *fpdf.Fpdf, employeeName string) {
 
 	pdf.SetY(signY + 1)
 	pdf.SetX(signStartX)
-	pdf.CellFormat(signEndX-signStartX, 4, "Assinatura do colaborador", "", 0, "C", false, 0, "")
+	pdf.CellFormat(signEndX-signStartX, 4, i18n.T(lang, "timecard.signature"), "", 0, "C", false, 0, "")
 
 	pdf.SetX(dateStartX)
-	pdf.CellFormat(dateEndX-dateStartX, 4, "Data", "", 1, "C", false, 0, "")
+	pdf.CellFormat(dateEndX-dateStartX, 4, i18n.T(lang, "timecard.date"), "", 1, "C", false, 0, "")
 
 	pdf.SetX(signStartX)
-	pdf.CellFormat(signEndX-signStartX, 4, fmt.Sprintf("Nome: %s", defaultOrDash(employeeName)), "", 1, "L", false, 0, "")
+	pdf.CellFormat(signEndX-signStartX, 4, i18n.T(lang, "timecard.name", defaultOrDash(employeeName)), "", 1, "L", false, 0, "")
 }
 
 func drawTimeCardEmptyRow(pdf *fpdf.Fpdf, message string) {
@@ -1850,8 +1888,8 @@ func timeCardColumnsWidth(start, end int) float64 {
 	return width
 }
 
-func formatDateBR(v time.Time) string {
-	return v.UTC().Format("02/01/2006")
+func formatDate(lang i18n.Lang, v time.Time) string {
+	return v.UTC().Format(i18n.DateLayout(lang))
 }
 
 func formatDurationClock(seconds int64, signed bool) string {
@@ -1877,11 +1915,11 @@ func nullStringOrDash(v sql.NullString) string {
 	return defaultOrDash(v.String)
 }
 
-func nullDateOrDash(v sql.NullTime) string {
+func nullDateOrDash(lang i18n.Lang, v sql.NullTime) string {
 	if !v.Valid {
 		return "-"
 	}
-	return formatDateBR(v.Time)
+	return formatDate(lang, v.Time)
 }
 
 func defaultOrDash(v string) string {
@@ -1892,19 +1930,11 @@ func defaultOrDash(v string) string {
 	return value
 }
 
-func statusLabelPT(status string) string {
-	switch strings.ToLower(strings.TrimSpace(status)) {
-	case "active":
-		return "Ativo"
-	case "inactive":
-		return "Inativo"
-	case "terminated":
-		return "Desligado"
+func employeeStatusLabel(lang i18n.Lang, status string) string {
+	switch s := strings.ToLower(strings.TrimSpace(status)); s {
+	case "active", "inactive", "terminated":
+		return i18n.T(lang, "employee_status."+s)
 	default:
 		return defaultOrDash(status)
 	}
 }
-
-type errString string
-
-func (e errString) Error() string { return string(e) }
@@ -10,6 +10,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
 )
 
 func (h *HRHandler) CreateTimeOffType(w http.ResponseWriter, r *http.Request) {
@@ -18,12 +19,12 @@ func (h *HRHandler) CreateTimeOffType(w http.ResponseWriter, r *http.Request) {
 
 	var req createTimeOffTypeReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		httpError(w, "name is required", http.StatusBadRequest)
+		writeFieldError(w, "name", problem.RuleRequired, "name is required")
 		return
 	}
 	requires := true
@@ -31,32 +32,35 @@ func (h *HRHandler) CreateTimeOffType(w http.ResponseWriter, r *http.Request) {
 		requires = *req.RequiresApproval
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO time_off_types (tenant_id, name, description, requires_approval, created_by, updated_by)
 		VALUES (?, ?, ?, ?, ?, ?)`,
 		tenantID, req.Name, cleanPtr(req.Description), requires, userID, userID)
 	if err != nil {
-		httpError(w, "could not create time off type (name may exist)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateTimeOffType, "could not create time off type (name may exist)")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var item TimeOffType
-	_ = tx.Get(&item, `
+	_ = tx.GetContext(r.Context(), &item, `
 		SELECT id, tenant_id, name, description, requires_approval, created_at, updated_at
 		FROM time_off_types WHERE tenant_id=? AND id=?`, tenantID, id64)
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "time_off_types", id64, nil, item)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "time_off_types", id64, nil, item); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 
@@ -66,11 +70,11 @@ func (h *HRHandler) CreateTimeOffType(w http.ResponseWriter, r *http.Request) {
 func (h *HRHandler) ListTimeOffTypes(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 	items := make([]TimeOffType, 0)
-	if err := h.DB.Select(&items, `
+	if err := h.DB.SelectContext(r.Context(), &items, `
 		SELECT id, tenant_id, name, description, requires_approval, created_at, updated_at
 		FROM time_off_types WHERE tenant_id=?
 		ORDER BY name ASC`, tenantID); err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -82,61 +86,64 @@ func (h *HRHandler) CreateTimeOffRequest(w http.ResponseWriter, r *http.Request)
 
 	var req createTimeOffRequestReq
 	if err := decodeJSON(r, &req); err != nil {
-		httpError(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, err)
 		return
 	}
 	start, err := time.Parse("2006-01-02", strings.TrimSpace(req.StartDate))
 	if err != nil {
-		httpError(w, "start_date must be YYYY-MM-DD", http.StatusBadRequest)
+		writeFieldError(w, "start_date", problem.RuleDate, "start_date must be YYYY-MM-DD")
 		return
 	}
 	end, err := time.Parse("2006-01-02", strings.TrimSpace(req.EndDate))
 	if err != nil {
-		httpError(w, "end_date must be YYYY-MM-DD", http.StatusBadRequest)
+		writeFieldError(w, "end_date", problem.RuleDate, "end_date must be YYYY-MM-DD")
 		return
 	}
 	if end.Before(start) {
-		httpError(w, "end_date must be >= start_date", http.StatusBadRequest)
+		writeFieldError(w, "end_date", problem.RuleMin, "end_date must be >= start_date", "start_date")
 		return
 	}
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var empExists int
-	if err := tx.Get(&empExists, `SELECT 1 FROM employees WHERE tenant_id=? AND id=?`, tenantID, req.EmployeeID); err != nil {
-		httpError(w, "employee not found", http.StatusNotFound)
+	if err := tx.GetContext(r.Context(), &empExists, `SELECT 1 FROM employees WHERE tenant_id=? AND id=?`, tenantID, req.EmployeeID); err != nil {
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeNotFound, "employee not found")
 		return
 	}
 	var typeExists int
-	if err := tx.Get(&typeExists, `SELECT 1 FROM time_off_types WHERE tenant_id=? AND id=?`, tenantID, req.TypeID); err != nil {
-		httpError(w, "time_off_type not found", http.StatusNotFound)
+	if err := tx.GetContext(r.Context(), &typeExists, `SELECT 1 FROM time_off_types WHERE tenant_id=? AND id=?`, tenantID, req.TypeID); err != nil {
+		writeError(w, http.StatusNotFound, problem.CodeTimeOffTypeNotFound, "time_off_type not found")
 		return
 	}
 
-	res, err := tx.Exec(`
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO time_off_requests (tenant_id, employee_id, type_id, status, start_date, end_date, reason, created_by, updated_by)
 		VALUES (?, ?, ?, 'pending', ?, ?, ?, ?, ?)`,
 		tenantID, req.EmployeeID, req.TypeID, start, end, cleanPtr(req.Reason), userID, userID)
 	if err != nil {
-		httpError(w, "could not create time off request", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateTimeOffRequest, "could not create time off request")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var item TimeOffRequest
-	_ = tx.Get(&item, `
+	_ = tx.GetContext(r.Context(), &item, `
 		SELECT id, tenant_id, employee_id, type_id, status, start_date, end_date, reason, decision_note, approver_id, reviewed_at, created_at, updated_at
 		FROM time_off_requests WHERE tenant_id=? AND id=?`, tenantID, id64)
 
-	_ = insertAudit(tx, r, tenantID, userID, "create", "time_off_requests", id64, nil, item)
+	if err := insertAudit(tx, r, tenantID, userID, "create", "time_off_requests", id64, nil, item); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, http.StatusCreated, item)
@@ -146,7 +153,7 @@ func (h *HRHandler) ListTimeOffRequests(w http.ResponseWriter, r *http.Request)
 	tenantID := mw.GetTenantID(r.Context())
 	status := strings.TrimSpace(strings.ToLower(r.URL.Query().Get("status")))
 	if status != "" && status != "pending" && status != "approved" && status != "rejected" && status != "canceled" {
-		httpError(w, "status filter must be pending|approved|rejected|canceled", http.StatusBadRequest)
+		writeFieldError(w, "status", problem.RuleChoice, "status filter must be pending|approved|rejected|canceled", "pending, approved, rejected, canceled")
 		return
 	}
 
@@ -166,7 +173,7 @@ func (h *HRHandler) ListTimeOffRequests(w http.ResponseWriter, r *http.Request)
 	if empIDStr != "" {
 		empID, err := strconv.ParseUint(empIDStr, 10, 64)
 		if err != nil {
-			httpError(w, "employee_id must be numeric", http.StatusBadRequest)
+			writeFieldError(w, "employee_id", problem.RuleNumber, "employee_id must be numeric")
 			return
 		}
 		query += " AND employee_id=?"
@@ -177,7 +184,7 @@ func (h *HRHandler) ListTimeOffRequests(w http.ResponseWriter, r *http.Request)
 	if typeIDStr != "" {
 		tid, err := strconv.ParseUint(typeIDStr, 10, 64)
 		if err != nil {
-			httpError(w, "type_id must be numeric", http.StatusBadRequest)
+			writeFieldError(w, "type_id", problem.RuleNumber, "type_id must be numeric")
 			return
 		}
 		query += " AND type_id=?"
@@ -187,8 +194,8 @@ func (h *HRHandler) ListTimeOffRequests(w http.ResponseWriter, r *http.Request)
 	query += " ORDER BY created_at DESC, id DESC"
 
 	items := make([]TimeOffRequest, 0)
-	if err := h.DB.Select(&items, query, args...); err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+	if err := h.DB.SelectContext(r.Context(), &items, query, args...); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -210,25 +217,25 @@ func (h *HRHandler) changeTimeOffStatus(w http.ResponseWriter, r *http.Request,
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		httpError(w, "invalid request id", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidRequestID, "invalid request id")
 		return
 	}
 
 	var req decisionReq
 	_ = json.NewDecoder(r.Body).Decode(&req)
 
-	tx, err := h.DB.Beginx()
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		httpError(w, "db error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
 		return
 	}
 	defer tx.Rollback()
 
 	var before TimeOffRequest
-	if err := tx.Get(&before, `
+	if err := tx.GetContext(r.Context(), &before, `
 		SELECT id, tenant_id, employee_id, type_id, status, start_date, end_date, reason, decision_note, approver_id, reviewed_at, created_at, updated_at
 		FROM time_off_requests WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
-		httpError(w, "time off request not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeTimeOffRequestNotFound, "time off request not found")
 		return
 	}
 
@@ -240,29 +247,32 @@ func (h *HRHandler) changeTimeOffStatus(w http.ResponseWriter, r *http.Request,
 	} else if before.Status == "approved" && to == "canceled" {
 		// ok
 	} else {
-		httpError(w, "invalid status transition", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidStatusTransition, "invalid status transition")
 		return
 	}
 
 	now := time.Now().UTC()
-	if _, err := tx.Exec(`
+	if _, err := tx.ExecContext(r.Context(), `
 		UPDATE time_off_requests
 		SET status=?, decision_note=?, approver_id=?, reviewed_at=?, updated_by=?
 		WHERE tenant_id=? AND id=?`,
 		to, cleanPtr(req.Note), userID, now, userID, tenantID, id); err != nil {
-		httpError(w, "db update error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
 		return
 	}
 
 	var after TimeOffRequest
-	_ = tx.Get(&after, `
+	_ = tx.GetContext(r.Context(), &after, `
 		SELECT id, tenant_id, employee_id, type_id, status, start_date, end_date, reason, decision_note, approver_id, reviewed_at, created_at, updated_at
 		FROM time_off_requests WHERE tenant_id=? AND id=?`, tenantID, id)
 
-	_ = insertAudit(tx, r, tenantID, userID, "update", "time_off_requests", int64(id), before, after)
+	if err := insertAudit(tx, r, tenantID, userID, "update", "time_off_requests", int64(id), before, after); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
 
 	if err := tx.Commit(); err != nil {
-		httpError(w, "db commit error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
 		return
 	}
 	writeJSON(w, http.StatusOK, after)
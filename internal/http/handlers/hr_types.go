@@ -7,6 +7,9 @@ import (
 
 type HRHandler struct {
 	DB *sqlx.DB
+
+	// Invitations envia o convite quando a conta do colaborador e criada sem senha.
+	Invitations *InvitationsHandler
 }
 
 type Department struct {
@@ -43,7 +46,7 @@ type Employee struct {
 	DepartmentID    *uint64    `db:"department_id" json:"department_id,omitempty"`
 	PositionID      *uint64    `db:"position_id" json:"position_id,omitempty"`
 	ManagerID       *uint64    `db:"manager_id" json:"manager_id,omitempty"`
-	SalaryCents     int64      `db:"salary_cents" json:"salary_cents"`
+	SalaryCents     *int64     `db:"salary_cents" json:"salary_cents,omitempty"` // omitido sem employees:salary
 	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
 }
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+)
+
+// IdempotencyStore guarda em idempotency_keys as respostas do middleware
+// mw.Idempotency.
+type IdempotencyStore struct {
+	DB *sqlx.DB
+}
+
+var _ mw.IdempotencyStore = (*IdempotencyStore)(nil)
+
+// idempotencyPurgeBatch limita a limpeza de keys expiradas feita a cada reserva.
+const idempotencyPurgeBatch = 100
+
+func idempotencyScopeHash(s mw.IdempotencyScope) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\n%d\n%s\n%s\n%s", s.TenantID, s.UserID, s.Method, s.Path, s.Key)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (st *IdempotencyStore) Reserve(ctx context.Context, s mw.IdempotencyScope, requestHash string, ttl time.Duration) (*mw.IdempotencyRecord, error) {
+	scope := idempotencyScopeHash(s)
+	_, _ = st.DB.ExecContext(ctx, `
+		DELETE FROM idempotency_keys WHERE expires_at <= UTC_TIMESTAMP() ORDER BY expires_at LIMIT ?`, idempotencyPurgeBatch)
+
+	// a key expirada pode ter ficado fora do lote acima
+	for attempt := 0; attempt < 2; attempt++ {
+		if _, err := st.DB.ExecContext(ctx, `
+			DELETE FROM idempotency_keys WHERE scope_hash=? AND expires_at <= UTC_TIMESTAMP()`, scope); err != nil {
+			return nil, err
+		}
+		res, err := st.DB.ExecContext(ctx, `
+			INSERT IGNORE INTO idempotency_keys (scope_hash, tenant_id, user_id, method, path, idem_key, request_hash, expires_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			scope, s.TenantID, s.UserID, s.Method, truncate(s.Path, 255), s.Key, requestHash, time.Now().UTC().Add(ttl))
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			return nil, nil
+		}
+
+		var row struct {
+			RequestHash string         `db:"request_hash"`
+			Status      sql.NullInt64  `db:"status_code"`
+			HeadersJSON sql.NullString `db:"headers_json"`
+			Body        []byte         `db:"response_body"`
+		}
+		err = st.DB.GetContext(ctx, &row, `
+			SELECT request_hash, status_code, headers_json, response_body
+			FROM idempotency_keys WHERE scope_hash=?`, scope)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue // liberada entre o INSERT e o SELECT
+		}
+		if err != nil {
+			return nil, err
+		}
+		rec := &mw.IdempotencyRecord{RequestHash: row.RequestHash, Status: int(row.Status.Int64), Body: row.Body}
+		if row.HeadersJSON.Valid {
+			_ = json.Unmarshal([]byte(row.HeadersJSON.String), &rec.Header)
+		}
+		return rec, nil
+	}
+	return nil, errors.New("idempotency key reservation failed")
+}
+
+func (st *IdempotencyStore) Complete(ctx context.Context, s mw.IdempotencyScope, rec mw.IdempotencyRecord) error {
+	headers, err := json.Marshal(http.Header(rec.Header))
+	if err != nil {
+		return err
+	}
+	_, err = st.DB.ExecContext(ctx, `
+		UPDATE idempotency_keys SET status_code=?, headers_json=?, response_body=?
+		WHERE scope_hash=? AND status_code IS NULL`,
+		rec.Status, string(headers), rec.Body, idempotencyScopeHash(s))
+	return err
+}
+
+func (st *IdempotencyStore) Release(ctx context.Context, s mw.IdempotencyScope) error {
+	_, err := st.DB.ExecContext(ctx, `
+		DELETE FROM idempotency_keys WHERE scope_hash=? AND status_code IS NULL`, idempotencyScopeHash(s))
+	return err
+}
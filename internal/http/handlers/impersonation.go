@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+)
+
+const (
+	impersonationDefaultTTL = 30 * time.Minute
+	impersonationMaxTTL     = 2 * time.Hour
+)
+
+// ImpersonationHandler deixa admins da plataforma (suporte) agirem como um
+// usuario de um tenant sem saber a senha dele. O token e curto, sem refresh,
+// somente leitura por padrao e cada requisicao feita com ele vai para audit_logs.
+type ImpersonationHandler struct {
+	DB   *sqlx.DB
+	Auth *AuthHandler
+}
+
+type startImpersonationReq struct {
+	TenantID   uint64 `json:"tenant_id"`
+	UserID     uint64 `json:"user_id"`
+	Email      string `json:"email"` // alternativa ao user_id
+	Reason     string `json:"reason"`
+	Write      bool   `json:"write"`
+	TTLMinutes int    `json:"ttl_minutes"` // default 30, maximo 120
+}
+
+type impersonationResp struct {
+	ImpersonationID uint64    `json:"impersonation_id"`
+	AccessToken     string    `json:"access_token"`
+	ExpiresIn       int64     `json:"expires_in"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	TenantID        uint64    `json:"tenant_id"`
+	UserID          uint64    `json:"user_id"`
+	Role            string    `json:"role"`
+	ReadOnly        bool      `json:"read_only"`
+}
+
+type ImpersonationSession struct {
+	ID          uint64     `db:"id" json:"id"`
+	AdminUserID uint64     `db:"admin_user_id" json:"admin_user_id"`
+	TenantID    uint64     `db:"tenant_id" json:"tenant_id"`
+	UserID      uint64     `db:"user_id" json:"user_id"`
+	Reason      string     `db:"reason" json:"reason"`
+	ReadOnly    bool       `db:"read_only" json:"read_only"`
+	ExpiresAt   time.Time  `db:"expires_at" json:"expires_at"`
+	EndedAt     *time.Time `db:"ended_at" json:"ended_at,omitempty"`
+	IP          *string    `db:"ip" json:"ip,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+const impersonationSelect = `
+	SELECT id, admin_user_id, tenant_id, user_id, reason, read_only, expires_at, ended_at, ip, created_at
+	FROM impersonation_sessions`
+
+func isPlatformAdmin(ctx context.Context, q sqlx.QueryerContext, userID uint64) (bool, error) {
+	var n int
+	err := sqlx.GetContext(ctx, q, &n, `SELECT COUNT(*) FROM platform_admins WHERE user_id=?`, userID)
+	return n > 0, err
+}
+
+// IsPlatformAdmin e o mw.PlatformAdminCheck das rotas /admin.
+func (h *ImpersonationHandler) IsPlatformAdmin(ctx context.Context, userID uint64) (bool, error) {
+	return isPlatformAdmin(ctx, h.DB, userID)
+}
+
+// StartImpersonation emite o token de impersonation. Exige motivo e MFA ativo
+// na conta do admin.
+func (h *ImpersonationHandler) StartImpersonation(w http.ResponseWriter, r *http.Request) {
+	adminID := mw.GetUserID(r.Context())
+
+	var req startImpersonationReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+	if req.TenantID == 0 || (req.UserID == 0 && req.Email == "") {
+		writeError(w, http.StatusBadRequest, problem.CodeTenantAndUserRequired, "tenant_id and user_id or email are required")
+		return
+	}
+	if len(req.Reason) < 10 || len(req.Reason) > 500 {
+		writeFieldError(w, "reason", problem.RuleRequired, "reason is required (10-500 chars)")
+		return
+	}
+	ttl := impersonationDefaultTTL
+	if req.TTLMinutes != 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+		if req.TTLMinutes < 1 || ttl > impersonationMaxTTL {
+			writeFieldError(w, "ttl_minutes", problem.RuleBetween, "ttl_minutes must be between 1 and 120", 1, 120)
+			return
+		}
+	}
+
+	mfaEnabled, err := userMFAEnabled(r.Context(), h.DB, adminID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if !mfaEnabled {
+		writeError(w, http.StatusForbidden, problem.CodeMFARequiredToImpersonate, "enable mfa on your account before impersonating")
+		return
+	}
+
+	if req.UserID == 0 {
+		err := h.DB.GetContext(r.Context(), &req.UserID, `SELECT id FROM users WHERE email=?`, req.Email)
+		if err == sql.ErrNoRows {
+			writeError(w, http.StatusNotFound, problem.CodeUserNotFound, "user not found")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+			return
+		}
+	}
+	if req.UserID == adminID {
+		writeError(w, http.StatusBadRequest, problem.CodeCannotImpersonateYourself, "cannot impersonate yourself")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	var m sessionMembership
+	err = tx.GetContext(r.Context(), &m, sessionMembershipQuery, req.TenantID, req.UserID)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, problem.CodeMembershipNotFound, "membership not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	m.Role = normalizeRole(m.Role)
+
+	expiresAt := time.Now().UTC().Add(ttl)
+	res, err := tx.ExecContext(r.Context(), `
+		INSERT INTO impersonation_sessions (admin_user_id, tenant_id, user_id, reason, read_only, expires_at, ip)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		adminID, req.TenantID, req.UserID, req.Reason, !req.Write, expiresAt, clientIP(r))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db insert error")
+		return
+	}
+	impID64, _ := res.LastInsertId()
+	impID := uint64(impID64)
+
+	// sem sessao de refresh; troca de senha/MFA pendentes do usuario nao se
+	// aplicam ao admin
+	claims := mw.Claims{
+		UserID:       req.UserID,
+		TenantID:     req.TenantID,
+		Role:         m.Role,
+		TokenVersion: m.TokenVersion,
+		Act:          &mw.Actor{UserID: adminID, ImpersonationID: impID, Write: req.Write},
+	}
+	token, err := h.Auth.makeTokenTTL(claims, ttl)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+
+	if err := insertAudit(tx, r, req.TenantID, adminID, "start_impersonation", "impersonation_sessions", impID64, nil, map[string]any{
+		"user_id":    req.UserID,
+		"reason":     req.Reason,
+		"read_only":  !req.Write,
+		"expires_at": expiresAt,
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, impersonationResp{
+		ImpersonationID: impID,
+		AccessToken:     token,
+		ExpiresIn:       int64(ttl.Seconds()),
+		ExpiresAt:       expiresAt,
+		TenantID:        req.TenantID,
+		UserID:          req.UserID,
+		Role:            m.Role,
+		ReadOnly:        !req.Write,
+	})
+}
+
+// ListImpersonations lista as impersonations recentes (filtros: tenant_id, active=true).
+func (h *ImpersonationHandler) ListImpersonations(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	where := []string{"1=1"}
+	args := []any{}
+	if v := strings.TrimSpace(q.Get("tenant_id")); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, problem.CodeInvalidTenantID, "invalid tenant_id")
+			return
+		}
+		where = append(where, "tenant_id=?")
+		args = append(args, id)
+	}
+	if q.Get("active") == "true" {
+		where = append(where, "ended_at IS NULL AND expires_at > UTC_TIMESTAMP()")
+	}
+
+	items := make([]ImpersonationSession, 0)
+	if err := h.DB.SelectContext(r.Context(), &items, impersonationSelect+` WHERE `+strings.Join(where, " AND ")+` ORDER BY id DESC LIMIT 200`, args...); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// EndImpersonation encerra a impersonation; o token para de valer na hora.
+func (h *ImpersonationHandler) EndImpersonation(w http.ResponseWriter, r *http.Request) {
+	adminID := mw.GetUserID(r.Context())
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidID, "invalid id")
+		return
+	}
+
+	var s ImpersonationSession
+	err = h.DB.GetContext(r.Context(), &s, impersonationSelect+` WHERE id=?`, id)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, problem.CodeImpersonationNotFound, "impersonation not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if s.EndedAt == nil {
+		tx, err := h.DB.BeginTxx(r.Context(), nil)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+			return
+		}
+		defer tx.Rollback()
+
+		if err := endImpersonation(r.Context(), tx, id); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+			return
+		}
+		if err := insertAudit(tx, r, s.TenantID, adminID, "end_impersonation", "impersonation_sessions", int64(id), nil, nil); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecordRequest e o mw.ImpersonationRecorder: uma linha em audit_logs por
+// requisicao, no tenant impersonado.
+func (h *ImpersonationHandler) RecordRequest(r *http.Request, status int) {
+	after := map[string]any{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"status": status,
+	}
+	if r.URL.RawQuery != "" {
+		after["query"] = truncate(r.URL.RawQuery, 500)
+	}
+	act := mw.GetActor(r.Context())
+	// a resposta ja foi enviada (o cliente pode ter desconectado); alteracoes
+	// feitas pela requisicao ja tem a propria linha, gravada na transacao do
+	// handler
+	r = r.WithContext(context.WithoutCancel(r.Context()))
+	if err := insertAudit(h.DB, r, mw.GetTenantID(r.Context()), mw.GetUserID(r.Context()),
+		"impersonated_request", "impersonation_sessions", int64(act.ImpersonationID), nil, after); err != nil {
+		mw.Logger(r.Context()).Error().Err(err).Uint64("impersonation_id", act.ImpersonationID).Msg("impersonation request audit failed")
+	}
+}
+
+func endImpersonation(ctx context.Context, exec sqlx.ExecerContext, id uint64) error {
+	_, err := exec.ExecContext(ctx, `UPDATE impersonation_sessions SET ended_at=UTC_TIMESTAMP() WHERE id=? AND ended_at IS NULL`, id)
+	return err
+}
+
+// validateImpersonation e o ValidateSession dos tokens com "act": a
+// impersonation precisa estar ativa, o emissor continuar admin e a membership
+// do usuario na mesma versao. Permissoes exclusivas do owner nunca valem sob
+// impersonation.
+func (h *AuthHandler) validateImpersonation(ctx context.Context, claims *mw.Claims) ([]string, error) {
+	var row struct {
+		ReadOnly     bool           `db:"read_only"`
+		TokenVersion uint64         `db:"token_version"`
+		Role         string         `db:"role"`
+		CustomPerms  sql.NullString `db:"custom_permissions"`
+	}
+	err := h.DB.GetContext(ctx, &row, `
+		SELECT i.read_only, m.token_version, m.role, tr.permissions_json AS custom_permissions
+		FROM impersonation_sessions i
+		INNER JOIN platform_admins pa ON pa.user_id = i.admin_user_id
+		INNER JOIN memberships m ON m.tenant_id = i.tenant_id AND m.user_id = i.user_id
+		LEFT JOIN tenant_roles tr ON tr.tenant_id = m.tenant_id AND tr.role_key = m.role
+		WHERE i.id=? AND i.admin_user_id=? AND i.tenant_id=? AND i.user_id=?
+		  AND i.ended_at IS NULL AND i.expires_at > UTC_TIMESTAMP()`,
+		claims.Act.ImpersonationID, claims.Act.UserID, claims.TenantID, claims.UserID)
+	if err != nil {
+		return nil, errSessionInvalid
+	}
+	if row.TokenVersion != claims.TokenVersion || row.ReadOnly == claims.Act.Write {
+		return nil, errSessionInvalid
+	}
+
+	perms := []string{}
+	for _, p := range rolePermissions(row.Role, row.CustomPerms) {
+		if info, ok := mw.LookupPermission(p); ok && info.OwnerOnly {
+			continue
+		}
+		perms = append(perms, p)
+	}
+	return perms, nil
+}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+	"saas-api/internal/mail"
+)
+
+// InvitationsHandler gerencia convites por email para entrar no tenant. O
+// convidado define a propria senha ao aceitar (ver AuthHandler.AcceptInvitation).
+type InvitationsHandler struct {
+	DB         *sqlx.DB
+	Mailer     mail.Mailer
+	AppBaseURL string
+	TTL        time.Duration
+}
+
+type Invitation struct {
+	ID         uint64     `db:"id" json:"id"`
+	TenantID   uint64     `db:"tenant_id" json:"tenant_id"`
+	Email      string     `db:"email" json:"email"`
+	Name       *string    `db:"name" json:"name,omitempty"`
+	Role       string     `db:"role" json:"role"`
+	EmployeeID *uint64    `db:"employee_id" json:"employee_id,omitempty"`
+	Status     string     `db:"status" json:"status"`
+	InvitedBy  uint64     `db:"invited_by" json:"invited_by"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	LastSentAt time.Time  `db:"last_sent_at" json:"last_sent_at"`
+	SendCount  int        `db:"send_count" json:"send_count"`
+	AcceptedAt *time.Time `db:"accepted_at" json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+type createInvitationReq struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Role  string `json:"role"` // owner/hr/finance (colaborador e convidado pelo RH)
+}
+
+const invitationSelect = `
+	SELECT id, tenant_id, email, name, role, employee_id, status, invited_by, expires_at,
+	       last_sent_at, send_count, accepted_at, revoked_at, created_at
+	FROM invitations`
+
+// status "expired" e derivado: o banco so guarda pending/accepted/revoked.
+func (inv *Invitation) normalize(now time.Time) {
+	inv.Role = normalizeRole(inv.Role)
+	if inv.Status == "pending" && !inv.ExpiresAt.After(now) {
+		inv.Status = "expired"
+	}
+}
+
+func (h *InvitationsHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+
+	query := invitationSelect + ` WHERE tenant_id=?`
+	args := []any{tenantID}
+
+	// sem members:manage (ex.: RH) so enxerga convites de colaborador
+	if !mw.HasPermission(r.Context(), mw.PermMembersManage) {
+		query += " AND role=?"
+		args = append(args, roleCollaborator)
+	}
+
+	status := strings.TrimSpace(strings.ToLower(r.URL.Query().Get("status")))
+	switch status {
+	case "":
+	case "pending":
+		query += " AND status='pending' AND expires_at>UTC_TIMESTAMP()"
+	case "expired":
+		query += " AND status='pending' AND expires_at<=UTC_TIMESTAMP()"
+	case "accepted", "revoked":
+		query += " AND status=?"
+		args = append(args, status)
+	default:
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidStatus, "invalid status")
+		return
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+
+	items := make([]Invitation, 0)
+	if err := h.DB.SelectContext(r.Context(), &items, query, args...); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToListInvitations, "failed to list invitations")
+		return
+	}
+
+	now := time.Now().UTC()
+	for i := range items {
+		items[i].normalize(now)
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h *InvitationsHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	requesterID := mw.GetUserID(r.Context())
+
+	var req createInvitationReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+	req.Name = strings.TrimSpace(req.Name)
+	req.Role = normalizeRole(req.Role)
+
+	if req.Email == "" || !strings.Contains(req.Email, "@") {
+		writeError(w, http.StatusBadRequest, problem.CodeValidEmailIsRequired, "valid email is required")
+		return
+	}
+	if req.Role == "" {
+		req.Role = roleFinance
+	}
+	if ok, err := roleExists(r.Context(), h.DB, tenantID, req.Role); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToLoadRole, "failed to load role")
+		return
+	} else if !ok {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidRole, "invalid role")
+		return
+	}
+	if req.Role == roleCollaborator {
+		writeError(w, http.StatusBadRequest, problem.CodeCollaboratorRoleViaHR, "colaborador role must be provisioned by hr")
+		return
+	}
+
+	var isMember bool
+	if err := h.DB.GetContext(r.Context(), &isMember, `
+		SELECT COUNT(*) > 0
+		FROM memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.tenant_id=? AND u.email=?`, tenantID, req.Email); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToLookupMember, "failed to lookup member")
+		return
+	}
+	if isMember {
+		writeError(w, http.StatusConflict, problem.CodeUserIsAlreadyAMember, "user is already a member")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "tx begin failed")
+		return
+	}
+	defer tx.Rollback()
+
+	inv, token, err := h.create(r.Context(), tx, tenantID, requesterID, req.Email, req.Name, req.Role, nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToCreateInvitation, "failed to create invitation")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, requesterID, "invite", "invitations", int64(inv.ID), nil, inv); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "tx commit failed")
+		return
+	}
+
+	h.send(r.Context(), tenantID, inv, token)
+	writeJSON(w, http.StatusCreated, inv)
+}
+
+// ResendInvitation gera um token novo (o anterior deixa de valer), renova a
+// validade e reenvia o email.
+func (h *InvitationsHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	requesterID := mw.GetUserID(r.Context())
+
+	inv, ok := h.loadInvitation(w, r)
+	if !ok {
+		return
+	}
+	if inv.Status != "pending" && inv.Status != "expired" {
+		writeError(w, http.StatusConflict, problem.CodeInvitationIsNotPending, "invitation is not pending")
+		return
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeTokenError, "token error")
+		return
+	}
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "tx begin failed")
+		return
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(r.Context(), `
+		UPDATE invitations
+		SET token_hash=?, expires_at=?, last_sent_at=?, send_count=send_count+1
+		WHERE tenant_id=? AND id=? AND status='pending'`,
+		tokenHash, now.Add(h.TTL), now, tenantID, inv.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToUpdateInvitation, "failed to update invitation")
+		return
+	}
+	// revogado ou aceito entre a leitura e o UPDATE: o token novo nao foi gravado
+	if n, _ := res.RowsAffected(); n == 0 {
+		writeError(w, http.StatusConflict, problem.CodeInvitationIsNotPending, "invitation is not pending")
+		return
+	}
+
+	updated, err := getInvitation(r.Context(), tx, tenantID, inv.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToLoadInvitation, "failed to load invitation")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, requesterID, "resend_invite", "invitations", int64(inv.ID), inv, updated); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "tx commit failed")
+		return
+	}
+
+	h.send(r.Context(), tenantID, updated, token)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *InvitationsHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	requesterID := mw.GetUserID(r.Context())
+
+	inv, ok := h.loadInvitation(w, r)
+	if !ok {
+		return
+	}
+	if inv.Status == "accepted" || inv.Status == "revoked" {
+		writeError(w, http.StatusConflict, problem.CodeInvitationIsNotPending, "invitation is not pending")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "tx begin failed")
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(r.Context(), `
+		UPDATE invitations SET status='revoked', revoked_at=UTC_TIMESTAMP()
+		WHERE tenant_id=? AND id=? AND status='pending'`, tenantID, inv.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToRevokeInvitation, "failed to revoke invitation")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, requesterID, "revoke_invite", "invitations", int64(inv.ID), inv, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "tx commit failed")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadInvitation carrega o convite da rota respeitando o escopo do RH.
+func (h *InvitationsHandler) loadInvitation(w http.ResponseWriter, r *http.Request) (Invitation, bool) {
+	tenantID := mw.GetTenantID(r.Context())
+
+	id, err := parseUintParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidID, "invalid id")
+		return Invitation{}, false
+	}
+
+	inv, err := getInvitation(r.Context(), h.DB, tenantID, id)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, problem.CodeInvitationNotFound, "invitation not found")
+		return Invitation{}, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToLoadInvitation, "failed to load invitation")
+		return Invitation{}, false
+	}
+	if !mw.HasPermission(r.Context(), mw.PermMembersManage) && inv.Role != roleCollaborator {
+		writeError(w, http.StatusNotFound, problem.CodeInvitationNotFound, "invitation not found")
+		return Invitation{}, false
+	}
+	return inv, true
+}
+
+// create grava um convite pendente; convites pendentes anteriores para o mesmo
+// email no tenant sao revogados. Devolve o token em claro para o email.
+func (h *InvitationsHandler) create(ctx context.Context, exec sqlx.ExtContext, tenantID, invitedBy uint64, email, name, role string, employeeID *uint64) (Invitation, string, error) {
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return Invitation{}, "", err
+	}
+
+	if _, err := exec.ExecContext(ctx, `
+		UPDATE invitations SET status='revoked', revoked_at=UTC_TIMESTAMP()
+		WHERE tenant_id=? AND email=? AND status='pending'`, tenantID, email); err != nil {
+		return Invitation{}, "", err
+	}
+
+	var namePtr *string
+	if name != "" {
+		namePtr = &name
+	}
+	now := time.Now().UTC()
+	res, err := exec.ExecContext(ctx, `
+		INSERT INTO invitations (tenant_id, email, name, role, employee_id, token_hash, invited_by, expires_at, last_sent_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		tenantID, email, namePtr, role, employeeID, tokenHash, invitedBy, now.Add(h.TTL), now)
+	if err != nil {
+		return Invitation{}, "", err
+	}
+	id64, _ := res.LastInsertId()
+
+	inv, err := getInvitation(ctx, exec, tenantID, uint64(id64))
+	if err != nil {
+		return Invitation{}, "", err
+	}
+	return inv, token, nil
+}
+
+// send dispara o email do convite. Falha de envio nao desfaz o convite: o
+// owner/RH pode usar o reenvio.
+func (h *InvitationsHandler) send(ctx context.Context, tenantID uint64, inv Invitation, token string) {
+	tenantName := ""
+	_ = h.DB.GetContext(ctx, &tenantName, `SELECT name FROM tenants WHERE id=? LIMIT 1`, tenantID)
+
+	greeting := "Ola"
+	if inv.Name != nil && *inv.Name != "" {
+		greeting = "Ola " + *inv.Name
+	}
+	msg := mail.Message{
+		To:      inv.Email,
+		Subject: fmt.Sprintf("Convite para %s", tenantName),
+		Text: fmt.Sprintf(
+			"%s,\n\nVoce foi convidado(a) para acessar %s. Para aceitar e definir sua senha, use o link abaixo (valido ate %s UTC):\n\n%s\n",
+			greeting, tenantName, inv.ExpiresAt.Format("02/01/2006 15:04"), appLink(h.AppBaseURL, "/accept-invite", token),
+		),
+	}
+	if err := h.Mailer.Send(ctx, msg); err != nil {
+		mw.Logger(ctx).Error().Err(err).Uint64("invitation_id", inv.ID).Msg("invitation: could not send email")
+	}
+}
+
+func getInvitation(ctx context.Context, q sqlx.QueryerContext, tenantID, id uint64) (Invitation, error) {
+	var inv Invitation
+	if err := sqlx.GetContext(ctx, q, &inv, invitationSelect+` WHERE tenant_id=? AND id=?`, tenantID, id); err != nil {
+		return Invitation{}, err
+	}
+	inv.normalize(time.Now().UTC())
+	return inv, nil
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"saas-api/internal/http/problem"
 )
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
@@ -13,3 +16,24 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	enc.SetEscapeHTML(false)
 	_ = enc.Encode(v)
 }
+
+// writeError responde application/problem+json com o code explicito; msg e
+// o detail em ingles e args preenchem a traducao (ver problem.New).
+func writeError(w http.ResponseWriter, status int, code problem.Code, msg string, args ...any) {
+	problem.Error(w, status, code, msg, args...)
+}
+
+// writeFieldError responde 400 validation_failed apontando o campo.
+func writeFieldError(w http.ResponseWriter, field string, rule problem.Rule, msg string, args ...any) {
+	problem.Write(w, problem.Invalid(field, rule, msg, args...))
+}
+
+// writeProblem responde o erro de um helper de validacao (*problem.Problem).
+// Qualquer outro erro vira 500.
+func writeProblem(w http.ResponseWriter, err error) {
+	var p *problem.Problem
+	if !errors.As(err, &p) {
+		p = problem.New(http.StatusInternalServerError, problem.CodeInternal, "")
+	}
+	problem.Write(w, p)
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"net/http"
+
+	"saas-api/internal/jwtkeys"
+)
+
+// JWKS publica as chaves publicas de verificacao dos access tokens.
+func JWKS(keys *jwtkeys.KeySet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		writeJSON(w, http.StatusOK, keys.JWKS())
+	}
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"saas-api/internal/http/problem"
+)
+
+const (
+	listDefaultLimit = 100
+	listMaxLimit     = 500
+)
+
+// listKind diz como o valor e lido da query/cursor e passado ao MySQL.
+type listKind int
+
+const (
+	listInt listKind = iota
+	listString
+	listTime // DATETIME; cursor em RFC3339
+	listDate // DATE; YYYY-MM-DD
+)
+
+// listSort e um campo aceito em ?sort=. Field e a tag db da struct de onde o
+// valor da ultima linha e lido para montar o cursor.
+type listSort struct {
+	Column string
+	Field  string
+	Kind   listKind
+}
+
+// listFilter e um parametro de filtro. Op: "=" (aceita lista separada por
+// virgula), ">=" ou "<". Para listDate, "<" inclui o dia informado (to).
+type listFilter struct {
+	Column string
+	Kind   listKind
+	Op     string
+	Values []string                // valores permitidos (vazio = qualquer um)
+	Expand func(v string) []string // so "=": valores gravados equivalentes a v
+}
+
+// listSpec descreve uma listagem: ordenacoes, filtros e colunas do ?q=.
+// IDColumn/IDField sao o desempate do keyset.
+type listSpec struct {
+	IDColumn    string
+	IDField     string
+	Sorts       map[string]listSort
+	DefaultSort string // ex.: "-id" (desc) ou "name" (asc)
+	Filters     map[string]listFilter
+	Search      []string
+}
+
+// listQuery e o pedido ja validado: ?sort=, ?limit=, ?cursor=, ?q= e filtros.
+type listQuery struct {
+	spec  *listSpec
+	sort  string
+	field listSort
+	desc  bool
+	limit int
+	where []string
+	args  []any
+}
+
+type listCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint64 `json:"id"`
+}
+
+// parseListQuery valida os parametros contra o spec. O erro (400) e um
+// *problem.Problem pronto para writeProblem.
+func parseListQuery(r *http.Request, spec *listSpec) (*listQuery, error) {
+	q := r.URL.Query()
+	lq := &listQuery{spec: spec, limit: listDefaultLimit}
+
+	lq.sort = strings.TrimSpace(q.Get("sort"))
+	if lq.sort == "" {
+		lq.sort = spec.DefaultSort
+	}
+	name, desc := strings.CutPrefix(lq.sort, "-")
+	field, ok := spec.Sorts[name]
+	if !ok {
+		names := spec.sortNames()
+		return nil, problem.Invalid("sort", problem.RuleChoice, "sort must be "+strings.Join(names, "|"), strings.Join(names, ", "))
+	}
+	lq.field, lq.desc = field, desc
+
+	if v := strings.TrimSpace(q.Get("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > listMaxLimit {
+			return nil, problem.Invalid("limit", problem.RuleBetween, fmt.Sprintf("limit must be between 1 and %d", listMaxLimit), 1, listMaxLimit)
+		}
+		lq.limit = n
+	}
+
+	params := make([]string, 0, len(spec.Filters))
+	for p := range spec.Filters {
+		params = append(params, p)
+	}
+	sort.Strings(params)
+	for _, p := range params {
+		raw := strings.TrimSpace(q.Get(p))
+		if raw == "" {
+			continue
+		}
+		if err := lq.addFilter(p, spec.Filters[p], raw); err != nil {
+			return nil, err
+		}
+	}
+
+	if s := strings.TrimSpace(q.Get("q")); s != "" && len(spec.Search) > 0 {
+		like := "%" + escapeLike(s) + "%"
+		parts := make([]string, len(spec.Search))
+		for i, col := range spec.Search {
+			parts[i] = col + " LIKE ?"
+			lq.args = append(lq.args, like)
+		}
+		lq.where = append(lq.where, "("+strings.Join(parts, " OR ")+")")
+	}
+
+	if v := strings.TrimSpace(q.Get("cursor")); v != "" {
+		if err := lq.addCursor(v); err != nil {
+			return nil, err
+		}
+	}
+	return lq, nil
+}
+
+func (lq *listQuery) addFilter(param string, f listFilter, raw string) error {
+	switch f.Op {
+	case "=":
+		values := strings.Split(raw, ",")
+		args := make([]any, 0, len(values))
+		for _, v := range values {
+			v = strings.TrimSpace(v)
+			if len(f.Values) > 0 && !containsString(f.Values, v) {
+				return problem.Invalid(param, problem.RuleChoice, param+" filter must be "+strings.Join(f.Values, "|"), strings.Join(f.Values, ", "))
+			}
+			stored := []string{v}
+			if f.Expand != nil {
+				stored = f.Expand(v)
+			}
+			for _, sv := range stored {
+				arg, err := parseListValue(param, f.Kind, sv)
+				if err != nil {
+					return err
+				}
+				args = append(args, arg)
+			}
+		}
+		if len(args) == 1 {
+			lq.where = append(lq.where, f.Column+"=?")
+		} else {
+			lq.where = append(lq.where, f.Column+" IN (?"+strings.Repeat(",?", len(args)-1)+")")
+		}
+		lq.args = append(lq.args, args...)
+	case ">=", "<":
+		arg, err := parseListValue(param, f.Kind, raw)
+		if err != nil {
+			return err
+		}
+		if f.Op == "<" && f.Kind == listDate {
+			d, _ := time.Parse("2006-01-02", raw)
+			arg = d.AddDate(0, 0, 1).Format("2006-01-02")
+		}
+		lq.where = append(lq.where, f.Column+" "+f.Op+" ?")
+		lq.args = append(lq.args, arg)
+	}
+	return nil
+}
+
+func (lq *listQuery) addCursor(raw string) error {
+	invalid := problem.New(http.StatusBadRequest, problem.CodeInvalidCursor, "invalid cursor")
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return invalid
+	}
+	var c listCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return invalid
+	}
+	if c.Sort != lq.sort {
+		return problem.New(http.StatusBadRequest, problem.CodeCursorDoesNotMatchSort, "cursor does not match sort")
+	}
+	v, err := parseListValue("cursor", lq.field.Kind, c.Value)
+	if err != nil {
+		return invalid
+	}
+	op := ">"
+	if lq.desc {
+		op = "<"
+	}
+	col, id := lq.field.Column, lq.spec.IDColumn
+	if col == id {
+		lq.where = append(lq.where, id+" "+op+" ?")
+		lq.args = append(lq.args, c.ID)
+		return nil
+	}
+	lq.where = append(lq.where, fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", col, op, col, id, op))
+	lq.args = append(lq.args, v, v, c.ID)
+	return nil
+}
+
+// SQL completa o select com os filtros, o keyset e ORDER BY/LIMIT. base e a
+// condicao fixa da rota (ex.: "p.tenant_id=?"). Busca uma linha a mais para
+// saber se existe proxima pagina.
+func (lq *listQuery) SQL(selectFrom, base string, baseArgs ...any) (string, []any) {
+	where := append([]string{base}, lq.where...)
+	args := append(append([]any{}, baseArgs...), lq.args...)
+	args = append(args, lq.limit+1)
+
+	dir := "ASC"
+	if lq.desc {
+		dir = "DESC"
+	}
+	order := lq.field.Column + " " + dir
+	if lq.field.Column != lq.spec.IDColumn {
+		order += ", " + lq.spec.IDColumn + " " + dir
+	}
+	return selectFrom + " WHERE " + strings.Join(where, " AND ") + " ORDER BY " + order + " LIMIT ?", args
+}
+
+// writeListPage corta a linha extra, grava Link/X-Next-Cursor quando existe
+// proxima pagina e responde o array.
+func writeListPage[T any](w http.ResponseWriter, r *http.Request, lq *listQuery, items []T) {
+	if len(items) > lq.limit {
+		items = items[:lq.limit]
+		if cursor, ok := lq.nextCursor(items[len(items)-1]); ok {
+			q := r.URL.Query()
+			q.Set("cursor", cursor)
+			next := r.URL.Path + "?" + q.Encode()
+			w.Header().Set("Link", "<"+next+`>; rel="next"`)
+			w.Header().Set("X-Next-Cursor", cursor)
+		}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (lq *listQuery) nextCursor(last any) (string, bool) {
+	v, ok := dbFieldValue(last, lq.field.Field)
+	if !ok {
+		return "", false
+	}
+	idv, ok := dbFieldValue(last, lq.spec.IDField)
+	if !ok {
+		return "", false
+	}
+	id, ok := idv.(uint64)
+	if !ok {
+		return "", false
+	}
+	c := listCursor{Sort: lq.sort, Value: formatListValue(lq.field.Kind, v), ID: id}
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b), true
+}
+
+func (s *listSpec) sortNames() []string {
+	names := make([]string, 0, len(s.Sorts))
+	for name := range s.Sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseListValue(param string, kind listKind, v string) (any, error) {
+	switch kind {
+	case listInt:
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, problem.Invalid(param, problem.RuleNumber, param+" must be numeric")
+		}
+		return n, nil
+	case listDate:
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return nil, problem.Invalid(param, problem.RuleDate, param+" must be YYYY-MM-DD")
+		}
+		return v, nil
+	case listTime:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, problem.Invalid(param, problem.RuleDate, param+" must be RFC3339")
+		}
+		return t.UTC(), nil
+	default:
+		return v, nil
+	}
+}
+
+func formatListValue(kind listKind, v any) string {
+	switch x := v.(type) {
+	case time.Time:
+		if kind == listDate {
+			return x.UTC().Format("2006-01-02")
+		}
+		return x.UTC().Format(time.RFC3339Nano)
+	case string:
+		return x
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// dbFieldValue le o campo com a tag db informada (desreferenciando ponteiros).
+func dbFieldValue(item any, tag string) (any, bool) {
+	v := reflect.Indirect(reflect.ValueOf(item))
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("db"), ","); name != tag {
+			continue
+		}
+		f := v.Field(i)
+		if f.Kind() == reflect.Pointer {
+			if f.IsNil() {
+				return nil, false
+			}
+			f = f.Elem()
+		}
+		return f.Interface(), true
+	}
+	return nil, false
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
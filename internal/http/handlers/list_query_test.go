@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseListQuery(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		sql   string
+		args  []any
+		msg   string
+	}{
+		{"default", "",
+			"SELECT * FROM payables WHERE tenant_id=? ORDER BY id DESC LIMIT ?",
+			[]any{uint64(1), listDefaultLimit + 1}, ""},
+		{"filters", "status=draft,paid&vendor_id=7&due_from=2026-01-01&due_to=2026-01-31&q=50%25&limit=20",
+			"SELECT * FROM payables WHERE tenant_id=? AND due_date >= ? AND due_date < ? AND status IN (?,?) AND vendor_id=? AND (reference LIKE ? OR description LIKE ?) ORDER BY id DESC LIMIT ?",
+			[]any{uint64(1), "2026-01-01", "2026-02-01", "draft", "paid", uint64(7), `%50\%%`, `%50\%%`, 21}, ""},
+		{"sort asc", "sort=due_date",
+			"SELECT * FROM payables WHERE tenant_id=? ORDER BY due_date ASC, id ASC LIMIT ?",
+			[]any{uint64(1), listDefaultLimit + 1}, ""},
+		{"bad sort", "sort=vendor", "", nil, "sort must be amount_cents|created_at|due_date|id"},
+		{"bad status", "status=open", "", nil, "status filter must be draft|pending_approval|approved|rejected|paid|canceled"},
+		{"bad id", "vendor_id=x", "", nil, "vendor_id must be numeric"},
+		{"bad date", "due_from=01/02/2026", "", nil, "due_from must be YYYY-MM-DD"},
+		{"bad limit", "limit=0", "", nil, "limit must be between 1 and 500"},
+		{"bad cursor", "cursor=%21%21", "", nil, "invalid cursor"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/payables?"+tc.query, nil)
+			lq, err := parseListQuery(r, &payableListSpec)
+			var msg string
+			if err != nil {
+				msg = err.Error()
+			}
+			if msg != tc.msg {
+				t.Fatalf("msg = %q, want %q", msg, tc.msg)
+			}
+			if err != nil {
+				return
+			}
+			sql, args := lq.SQL("SELECT * FROM payables", "tenant_id=?", uint64(1))
+			if sql != tc.sql {
+				t.Fatalf("sql = %s\nwant  %s", sql, tc.sql)
+			}
+			if !reflect.DeepEqual(args, tc.args) {
+				t.Fatalf("args = %#v\nwant   %#v", args, tc.args)
+			}
+		})
+	}
+}
+
+func TestListPageCursor(t *testing.T) {
+	items := []Payable{
+		{ID: 9, DueDate: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{ID: 4, DueDate: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, DueDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	r := httptest.NewRequest(http.MethodGet, "/v1/payables?sort=-due_date&limit=2&status=draft", nil)
+	lq, err := parseListQuery(r, &payableListSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	writeListPage(rec, r, lq, items)
+
+	cursor := rec.Header().Get("X-Next-Cursor")
+	link := rec.Header().Get("Link")
+	if cursor == "" || !strings.HasPrefix(link, "</v1/payables?cursor="+cursor+"&limit=2&sort=-due_date&status=draft>") {
+		t.Fatalf("link = %q, cursor = %q", link, cursor)
+	}
+
+	next := httptest.NewRequest(http.MethodGet, "/v1/payables?sort=-due_date&limit=2&cursor="+cursor, nil)
+	lq, err = parseListQuery(next, &payableListSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, args := lq.SQL("SELECT * FROM payables", "tenant_id=?", uint64(1))
+	if !strings.Contains(sql, "(due_date < ? OR (due_date = ? AND id < ?)) ORDER BY due_date DESC, id DESC") {
+		t.Fatalf("sql = %s", sql)
+	}
+	if want := []any{uint64(1), "2026-03-05", "2026-03-05", uint64(4), 3}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %#v", args)
+	}
+
+	other := httptest.NewRequest(http.MethodGet, "/v1/payables?sort=id&cursor="+cursor, nil)
+	if _, err := parseListQuery(other, &payableListSpec); err == nil || err.Error() != "cursor does not match sort" {
+		t.Fatalf("err = %v", err)
+	}
+
+	last := httptest.NewRecorder()
+	writeListPage(last, r, lq, items[:1])
+	if last.Header().Get("Link") != "" {
+		t.Fatal("last page must not have Link")
+	}
+}
+
+func TestMemberRoleFilterMatchesLegacySpelling(t *testing.T) {
+	cases := []struct {
+		query string
+		where string
+		args  []any
+	}{
+		{"role=colaborador", "m.role IN (?,?)", []any{uint64(1), "colaborador", "member"}},
+		{"role=Member", "m.role IN (?,?)", []any{uint64(1), "colaborador", "member"}},
+		{"role=hr,finance", "m.role IN (?,?)", []any{uint64(1), "hr", "finance"}},
+		{"role=hr", "m.role=?", []any{uint64(1), "hr"}},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/v1/members?"+tc.query, nil)
+		lq, err := parseListQuery(r, &memberListSpec)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.query, err)
+		}
+		sql, args := lq.SQL("SELECT * FROM memberships m", "m.tenant_id=?", uint64(1))
+		if !strings.Contains(sql, "AND "+tc.where+" ") {
+			t.Fatalf("%s: sql = %s", tc.query, sql)
+		}
+		if !reflect.DeepEqual(args[:len(tc.args)], tc.args) {
+			t.Fatalf("%s: args = %#v", tc.query, args)
+		}
+	}
+}
@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jmoiron/sqlx"
@@ -16,6 +19,9 @@ import (
 
 type MembersHandler struct {
 	DB *sqlx.DB
+
+	// Invitations e usado quando o email ainda nao tem conta e nenhuma senha foi informada.
+	Invitations *InvitationsHandler
 }
 
 type memberRow struct {
@@ -24,31 +30,80 @@ type memberRow struct {
 	Name      string `db:"name" json:"name"`
 	Role      string `db:"role" json:"role"`
 	CreatedAt string `db:"created_at" json:"created_at"`
+
+	LockedUntil *time.Time `db:"locked_until" json:"locked_until,omitempty"`
 }
 
 type createMemberReq struct {
 	Email    string `json:"email"`
 	Name     string `json:"name"`
-	Password string `json:"password"` // obrigatorio se o user ainda nao existir
-	Role     string `json:"role"`     // owner/hr/finance (colaborador e provisionado pelo RH)
+	Password string `json:"password"` // opcional: sem senha, usuario novo recebe convite por email
+	Role     string `json:"role"`     // owner/hr/finance ou role customizado (colaborador e provisionado pelo RH)
 }
 
 type updateRoleReq struct {
 	Role string `json:"role"`
 }
 
+// memberAudit e o estado do membership gravado em audit_logs.
+type memberAudit struct {
+	ID     uint64 `db:"id" json:"id"`
+	UserID uint64 `db:"user_id" json:"user_id"`
+	Email  string `db:"email" json:"email"`
+	Role   string `db:"role" json:"role"`
+}
+
+func loadMemberAudit(ctx context.Context, q sqlx.QueryerContext, tenantID, userID uint64) (*memberAudit, error) {
+	var m memberAudit
+	err := sqlx.GetContext(ctx, q, &m, `
+		SELECT m.id, m.user_id, u.email, m.role
+		FROM memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.tenant_id=? AND m.user_id=?`, tenantID, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.Role = normalizeRole(m.Role)
+	return &m, nil
+}
+
+var memberListSpec = listSpec{
+	IDColumn: "u.id",
+	IDField:  "user_id",
+	Sorts: map[string]listSort{
+		"email":      {Column: "u.email", Field: "email", Kind: listString},
+		"name":       {Column: "u.name", Field: "name", Kind: listString},
+		"created_at": {Column: "m.created_at", Field: "created_at", Kind: listTime},
+	},
+	DefaultSort: "email",
+	Filters: map[string]listFilter{
+		"role": {Column: "m.role", Kind: listString, Op: "=", Expand: roleSpellings},
+	},
+	Search: []string{"u.email", "u.name"},
+}
+
 func (h *MembersHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 
-	var items []memberRow
-	if err := h.DB.Select(&items, `
-		SELECT u.id AS user_id, u.email, u.name, m.role, DATE_FORMAT(m.created_at, '%Y-%m-%dT%H:%i:%sZ') AS created_at
+	lq, err := parseListQuery(r, &memberListSpec)
+	if err != nil {
+		writeProblem(w, err)
+		return
+	}
+
+	query, args := lq.SQL(`
+		SELECT u.id AS user_id, u.email, u.name, m.role, DATE_FORMAT(m.created_at, '%Y-%m-%dT%H:%i:%sZ') AS created_at,
+		       lt.locked_until
 		FROM memberships m
 		JOIN users u ON u.id = m.user_id
-		WHERE m.tenant_id=?
-		ORDER BY u.email ASC
-	`, tenantID); err != nil {
-		http.Error(w, "failed to list members", 500)
+		LEFT JOIN login_throttles lt ON lt.scope='email' AND lt.throttle_key=u.email AND lt.locked_until>UTC_TIMESTAMP()`,
+		"m.tenant_id=?", tenantID)
+	items := make([]memberRow, 0)
+	if err := h.DB.SelectContext(r.Context(), &items, query, args...); err != nil {
+		writeError(w, 500, problem.CodeFailedToListMembers, "failed to list members")
 		return
 	}
 
@@ -56,7 +111,7 @@ func (h *MembersHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
 		items[i].Role = normalizeRole(items[i].Role)
 	}
 
-	writeJSON(w, 200, items)
+	writeListPage(w, r, lq, items)
 }
 
 func (h *MembersHandler) CreateMember(w http.ResponseWriter, r *http.Request) {
@@ -65,7 +120,7 @@ func (h *MembersHandler) CreateMember(w http.ResponseWriter, r *http.Request) {
 
 	var req createMemberReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", 400)
+		writeError(w, 400, problem.CodeInvalidJSON, "invalid json")
 		return
 	}
 
@@ -74,84 +129,115 @@ func (h *MembersHandler) CreateMember(w http.ResponseWriter, r *http.Request) {
 	req.Role = normalizeRole(req.Role)
 
 	if req.Email == "" {
-		http.Error(w, "email is required", 400)
+		writeFieldError(w, "email", problem.RuleRequired, "email is required")
 		return
 	}
 
 	if req.Role == "" {
 		req.Role = roleFinance
 	}
-	if !isValidRole(req.Role) {
-		http.Error(w, "invalid role", 400)
-		return
-	}
 	if req.Role == roleCollaborator {
-		http.Error(w, "colaborador role must be provisioned by hr", 400)
+		writeError(w, 400, problem.CodeCollaboratorRoleViaHR, "colaborador role must be provisioned by hr")
 		return
 	}
 
 	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "tx begin failed", 500)
+		writeError(w, 500, problem.CodeDatabase, "tx begin failed")
 		return
 	}
 	defer tx.Rollback()
 
+	if ok, err := roleExists(r.Context(), tx, tenantID, req.Role); err != nil {
+		writeError(w, 500, problem.CodeFailedToLoadRole, "failed to load role")
+		return
+	} else if !ok {
+		writeError(w, 400, problem.CodeInvalidRole, "invalid role")
+		return
+	}
+
 	var userID uint64
-	err = tx.Get(&userID, `SELECT id FROM users WHERE email=?`, req.Email)
+	err = tx.GetContext(r.Context(), &userID, `SELECT id FROM users WHERE email=?`, req.Email)
 	if err != nil {
 		if err != sql.ErrNoRows {
-			http.Error(w, "failed to lookup user", 500)
+			writeError(w, 500, problem.CodeFailedToLookupUser, "failed to lookup user")
+			return
+		}
+		if req.Password == "" && h.Invitations != nil {
+			h.inviteMember(w, r, tx, req)
 			return
 		}
 		if req.Name == "" {
-			http.Error(w, "name is required for new user", 400)
+			writeError(w, 400, problem.CodeNameIsRequiredForNewUser, "name is required for new user")
 			return
 		}
 		if len(req.Password) < 8 {
-			http.Error(w, "password must be at least 8 chars", 400)
+			writeFieldError(w, "password", problem.RuleMinLength, "password must be at least 8 chars", 8)
 			return
 		}
 
 		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 		if err != nil {
-			http.Error(w, "failed to hash password", 500)
+			writeError(w, 500, problem.CodeFailedToHashPassword, "failed to hash password")
 			return
 		}
 
-		res, err := tx.Exec(`INSERT INTO users (email, name, password_hash) VALUES (?,?,?)`, req.Email, req.Name, string(hash))
+		// senha escolhida pelo owner e provisoria
+		res, err := tx.ExecContext(r.Context(), `INSERT INTO users (email, name, password_hash, must_change_password) VALUES (?,?,?,1)`, req.Email, req.Name, string(hash))
 		if err != nil {
-			http.Error(w, "failed to create user", 500)
+			writeError(w, 500, problem.CodeFailedToCreateUser, "failed to create user")
 			return
 		}
 		id64, _ := res.LastInsertId()
 		userID = uint64(id64)
 	} else if req.Name != "" {
-		_, _ = tx.Exec(`UPDATE users SET name=? WHERE id=?`, req.Name, userID)
+		_, _ = tx.ExecContext(r.Context(), `UPDATE users SET name=? WHERE id=?`, req.Name, userID)
 	}
 
 	if userID == requesterID && req.Role != roleOwner {
-		http.Error(w, "cannot change your own role here", 400)
+		writeError(w, 400, problem.CodeCannotChangeYourOwnRoleHere, "cannot change your own role here")
 		return
 	}
 
-	_, err = tx.Exec(`
+	before, err := loadMemberAudit(r.Context(), tx, tenantID, userID)
+	if err != nil {
+		writeError(w, 500, problem.CodeFailedToLoadMembership, "failed to load membership")
+		return
+	}
+
+	_, err = tx.ExecContext(r.Context(), `
 		INSERT INTO memberships (tenant_id, user_id, role)
 		VALUES (?,?,?)
-		ON DUPLICATE KEY UPDATE role=VALUES(role)
+		ON DUPLICATE KEY UPDATE
+			token_version=IF(role<>VALUES(role), token_version+1, token_version),
+			role=VALUES(role)
 	`, tenantID, userID, req.Role)
 	if err != nil {
-		http.Error(w, "failed to upsert membership", 500)
+		writeError(w, 500, problem.CodeFailedToUpsertMembership, "failed to upsert membership")
+		return
+	}
+
+	after, err := loadMemberAudit(r.Context(), tx, tenantID, userID)
+	if err != nil || after == nil {
+		writeError(w, 500, problem.CodeFailedToLoadMembership, "failed to load membership")
+		return
+	}
+	action := "create"
+	if before != nil {
+		action = "update_role"
+	}
+	if err := insertAudit(tx, r, tenantID, requesterID, action, "memberships", int64(after.ID), before, after); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
 		return
 	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "tx commit failed", 500)
+		writeError(w, 500, problem.CodeDatabase, "tx commit failed")
 		return
 	}
 
 	var out memberRow
-	_ = h.DB.Get(&out, `
+	_ = h.DB.GetContext(r.Context(), &out, `
 		SELECT u.id AS user_id, u.email, u.name, m.role, DATE_FORMAT(m.created_at, '%Y-%m-%dT%H:%i:%sZ') AS created_at
 		FROM memberships m
 		JOIN users u ON u.id = m.user_id
@@ -168,63 +254,80 @@ func (h *MembersHandler) UpdateMemberRole(w http.ResponseWriter, r *http.Request
 
 	userID, err := parseUintParam(r, "user_id")
 	if err != nil {
-		http.Error(w, "invalid user_id", 400)
+		writeError(w, 400, problem.CodeInvalidUserID, "invalid user_id")
 		return
 	}
 	if userID == requesterID {
-		http.Error(w, "cannot change your own role", 400)
+		writeError(w, 400, problem.CodeCannotChangeYourOwnRole, "cannot change your own role")
 		return
 	}
 
 	var req updateRoleReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", 400)
+		writeError(w, 400, problem.CodeInvalidJSON, "invalid json")
 		return
 	}
 	req.Role = normalizeRole(req.Role)
-	if !isValidRole(req.Role) {
-		http.Error(w, "invalid role", 400)
-		return
-	}
 	if req.Role == roleCollaborator {
-		http.Error(w, "colaborador role must be provisioned by hr", 400)
+		writeError(w, 400, problem.CodeCollaboratorRoleViaHR, "colaborador role must be provisioned by hr")
 		return
 	}
 
 	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "tx begin failed", 500)
+		writeError(w, 500, problem.CodeDatabase, "tx begin failed")
 		return
 	}
 	defer tx.Rollback()
 
+	if ok, err := roleExists(r.Context(), tx, tenantID, req.Role); err != nil {
+		writeError(w, 500, problem.CodeFailedToLoadRole, "failed to load role")
+		return
+	} else if !ok {
+		writeError(w, 400, problem.CodeInvalidRole, "invalid role")
+		return
+	}
+
 	var currentRole string
-	if err := tx.Get(&currentRole, `SELECT role FROM memberships WHERE tenant_id=? AND user_id=?`, tenantID, userID); err != nil {
+	if err := tx.GetContext(r.Context(), &currentRole, `SELECT role FROM memberships WHERE tenant_id=? AND user_id=?`, tenantID, userID); err != nil {
 		if err == sql.ErrNoRows {
-			http.Error(w, "member not found", 404)
+			writeError(w, 404, problem.CodeMemberNotFound, "member not found")
 			return
 		}
-		http.Error(w, "failed to load membership", 500)
+		writeError(w, 500, problem.CodeFailedToLoadMembership, "failed to load membership")
 		return
 	}
 
 	currentRole = normalizeRole(currentRole)
 	if currentRole == roleOwner && req.Role != roleOwner {
 		var owners int64
-		_ = tx.Get(&owners, `SELECT COUNT(*) FROM memberships WHERE tenant_id=? AND role='owner'`, tenantID)
+		_ = tx.GetContext(r.Context(), &owners, `SELECT COUNT(*) FROM memberships WHERE tenant_id=? AND role='owner'`, tenantID)
 		if owners <= 1 {
-			http.Error(w, "cannot demote the last owner", 400)
+			writeError(w, 400, problem.CodeCannotDemoteTheLastOwner, "cannot demote the last owner")
 			return
 		}
 	}
 
-	if _, err := tx.Exec(`UPDATE memberships SET role=? WHERE tenant_id=? AND user_id=?`, req.Role, tenantID, userID); err != nil {
-		http.Error(w, "failed to update role", 500)
+	// token_version invalida na hora os tokens emitidos com o role antigo
+	if _, err := tx.ExecContext(r.Context(), `UPDATE memberships SET role=?, token_version=token_version+1 WHERE tenant_id=? AND user_id=?`, req.Role, tenantID, userID); err != nil {
+		writeError(w, 500, problem.CodeFailedToUpdateRole, "failed to update role")
+		return
+	}
+
+	after, err := loadMemberAudit(r.Context(), tx, tenantID, userID)
+	if err != nil || after == nil {
+		writeError(w, 500, problem.CodeFailedToLoadMembership, "failed to load membership")
+		return
+	}
+	before := *after
+	before.Role = currentRole
+	if err := insertAudit(tx, r, tenantID, requesterID, "update_role", "memberships", int64(after.ID), before, after); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
 		return
 	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "tx commit failed", 500)
+		writeError(w, 500, problem.CodeDatabase, "tx commit failed")
 		return
 	}
 
@@ -237,54 +340,142 @@ func (h *MembersHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 
 	userID, err := parseUintParam(r, "user_id")
 	if err != nil {
-		http.Error(w, "invalid user_id", 400)
+		writeError(w, 400, problem.CodeInvalidUserID, "invalid user_id")
 		return
 	}
 	if userID == requesterID {
-		http.Error(w, "cannot remove yourself", 400)
+		writeError(w, 400, problem.CodeCannotRemoveYourself, "cannot remove yourself")
 		return
 	}
 
 	tx, err := h.DB.BeginTxx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "tx begin failed", 500)
+		writeError(w, 500, problem.CodeDatabase, "tx begin failed")
 		return
 	}
 	defer tx.Rollback()
 
 	var currentRole string
-	if err := tx.Get(&currentRole, `SELECT role FROM memberships WHERE tenant_id=? AND user_id=?`, tenantID, userID); err != nil {
+	if err := tx.GetContext(r.Context(), &currentRole, `SELECT role FROM memberships WHERE tenant_id=? AND user_id=?`, tenantID, userID); err != nil {
 		if err == sql.ErrNoRows {
-			http.Error(w, "member not found", 404)
+			writeError(w, 404, problem.CodeMemberNotFound, "member not found")
 			return
 		}
-		http.Error(w, "failed to load membership", 500)
+		writeError(w, 500, problem.CodeFailedToLoadMembership, "failed to load membership")
 		return
 	}
 
 	currentRole = normalizeRole(currentRole)
 	if currentRole == roleOwner {
 		var owners int64
-		_ = tx.Get(&owners, `SELECT COUNT(*) FROM memberships WHERE tenant_id=? AND role='owner'`, tenantID)
+		_ = tx.GetContext(r.Context(), &owners, `SELECT COUNT(*) FROM memberships WHERE tenant_id=? AND role='owner'`, tenantID)
 		if owners <= 1 {
-			http.Error(w, "cannot remove the last owner", 400)
+			writeError(w, 400, problem.CodeCannotRemoveTheLastOwner, "cannot remove the last owner")
+			return
+		}
+	}
+
+	before, err := loadMemberAudit(r.Context(), tx, tenantID, userID)
+	if err != nil || before == nil {
+		writeError(w, 500, problem.CodeFailedToLoadMembership, "failed to load membership")
+		return
+	}
+
+	if err := revokeUserSessions(r.Context(), tx, tenantID, userID, "membership_removed"); err != nil {
+		writeError(w, 500, problem.CodeFailedToRevokeSessions, "failed to revoke sessions")
+		return
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `DELETE FROM memberships WHERE tenant_id=? AND user_id=?`, tenantID, userID); err != nil {
+		writeError(w, 500, problem.CodeFailedToRemoveMember, "failed to remove member")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, requesterID, "delete", "memberships", int64(before.ID), before, nil); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, 500, problem.CodeDatabase, "tx commit failed")
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+// UnlockMember libera o login de um membro bloqueado por excesso de falhas.
+func (h *MembersHandler) UnlockMember(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	requesterID := mw.GetUserID(r.Context())
+
+	userID, err := parseUintParam(r, "user_id")
+	if err != nil {
+		writeError(w, 400, problem.CodeInvalidUserID, "invalid user_id")
+		return
+	}
+
+	var email string
+	if err := h.DB.GetContext(r.Context(), &email, `
+		SELECT u.email
+		FROM memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.tenant_id=? AND m.user_id=?`, tenantID, userID); err != nil {
+		if err == sql.ErrNoRows {
+			writeError(w, 404, problem.CodeMemberNotFound, "member not found")
 			return
 		}
+		writeError(w, 500, problem.CodeFailedToLoadMembership, "failed to load membership")
+		return
 	}
 
-	if _, err := tx.Exec(`DELETE FROM memberships WHERE tenant_id=? AND user_id=?`, tenantID, userID); err != nil {
-		http.Error(w, "failed to remove member", 500)
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, 500, problem.CodeDatabase, "tx begin failed")
+		return
+	}
+	defer tx.Rollback()
+
+	if err := clearLoginThrottle(r.Context(), tx, throttleScopeEmail, email); err != nil {
+		writeError(w, 500, problem.CodeFailedToUnlockMember, "failed to unlock member")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, requesterID, "unlock_login", "users", int64(userID), nil, map[string]any{"email": email}); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
 		return
 	}
 
 	if err := tx.Commit(); err != nil {
-		http.Error(w, "tx commit failed", 500)
+		writeError(w, 500, problem.CodeDatabase, "tx commit failed")
 		return
 	}
 
 	w.WriteHeader(204)
 }
 
+// inviteMember cria um convite em vez de uma conta com senha definida pelo owner.
+func (h *MembersHandler) inviteMember(w http.ResponseWriter, r *http.Request, tx *sqlx.Tx, req createMemberReq) {
+	tenantID := mw.GetTenantID(r.Context())
+	requesterID := mw.GetUserID(r.Context())
+
+	inv, token, err := h.Invitations.create(r.Context(), tx, tenantID, requesterID, req.Email, req.Name, req.Role, nil)
+	if err != nil {
+		writeError(w, 500, problem.CodeFailedToCreateInvitation, "failed to create invitation")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, requesterID, "invite", "invitations", int64(inv.ID), nil, inv); err != nil {
+		writeError(w, 500, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, 500, problem.CodeDatabase, "tx commit failed")
+		return
+	}
+	h.Invitations.send(r.Context(), tenantID, inv, token)
+
+	writeJSON(w, 202, inv)
+}
+
 func parseUintParam(r *http.Request, name string) (uint64, error) {
 	v := strings.TrimSpace(chi.URLParam(r, name))
 	return strconv.ParseUint(v, 10, 64)
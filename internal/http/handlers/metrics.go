@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"saas-api/internal/http/problem"
+	"saas-api/internal/metrics"
+)
+
+// Metrics expoe as metricas Prometheus. Com token configurado, o scrape
+// precisa mandar Authorization: Bearer <token>.
+func Metrics(token string) http.HandlerFunc {
+	h := metrics.Handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				writeError(w, http.StatusUnauthorized, problem.CodeInvalidMetricsToken, "invalid metrics token")
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	}
+}
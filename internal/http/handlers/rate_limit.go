@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+)
+
+// RateLimitStore guarda os buckets do mw.RateLimit em rate_limit_buckets, para
+// varias instancias da API dividirem o mesmo limite.
+//
+// Custo: cada Take e uma transacao com SELECT ... FOR UPDATE na linha do
+// bucket. O bucket do tenant ("classe:tN") e a mesma linha para todos os
+// usuarios do tenant, entao as requisicoes de um tenant na mesma classe se
+// enfileiram nesse lock (uma ida e volta ao banco cada). Para tenants com
+// muito trafego concorrente a linha vira gargalo; nesse caso prefira o store
+// em memoria (limite por instancia).
+type RateLimitStore struct {
+	DB *sqlx.DB
+
+	lastPurge atomic.Int64
+}
+
+var _ mw.RateLimitStore = (*RateLimitStore)(nil)
+
+const (
+	// buckets parados ha mais que isso ja estao cheios e podem sair da tabela
+	rateLimitIdleTTL    = time.Hour
+	rateLimitPurgeBatch = 500
+)
+
+func (st *RateLimitStore) Take(ctx context.Context, key string, rule mw.RateLimitRule) (mw.RateLimitResult, error) {
+	st.purge(ctx)
+
+	tx, err := st.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return mw.RateLimitResult{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT IGNORE INTO rate_limit_buckets (bucket_key, tokens, updated_at)
+		VALUES (?, ?, UTC_TIMESTAMP(6))`, key, rule.Limit); err != nil {
+		return mw.RateLimitResult{}, err
+	}
+	// o relogio e o do banco: as instancias podem ter horarios diferentes
+	var row struct {
+		Tokens    float64   `db:"tokens"`
+		UpdatedAt time.Time `db:"updated_at"`
+		Now       time.Time `db:"now"`
+	}
+	if err := tx.GetContext(ctx, &row, `
+		SELECT tokens, updated_at, UTC_TIMESTAMP(6) AS now
+		FROM rate_limit_buckets WHERE bucket_key=? FOR UPDATE`, key); err != nil {
+		return mw.RateLimitResult{}, err
+	}
+
+	tokens, res := mw.TakeToken(row.Tokens, row.Now.Sub(row.UpdatedAt), rule)
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE rate_limit_buckets SET tokens=?, updated_at=? WHERE bucket_key=?`,
+		tokens, row.Now, key); err != nil {
+		return mw.RateLimitResult{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return mw.RateLimitResult{}, err
+	}
+	return res, nil
+}
+
+func (st *RateLimitStore) Refund(ctx context.Context, key string, rule mw.RateLimitRule) error {
+	_, err := st.DB.ExecContext(ctx, `
+		UPDATE rate_limit_buckets SET tokens=LEAST(tokens+1, ?) WHERE bucket_key=?`, rule.Limit, key)
+	return err
+}
+
+// purge apaga buckets ociosos no maximo uma vez por minuto por instancia.
+func (st *RateLimitStore) purge(ctx context.Context) {
+	now := time.Now().Unix()
+	last := st.lastPurge.Load()
+	if now-last < 60 || !st.lastPurge.CompareAndSwap(last, now) {
+		return
+	}
+	_, _ = st.DB.ExecContext(ctx, `
+		DELETE FROM rate_limit_buckets WHERE updated_at < ? ORDER BY updated_at LIMIT ?`,
+		time.Now().UTC().Add(-rateLimitIdleTTL), rateLimitPurgeBatch)
+}
+
+// TenantPlan e o mw.RateLimitPlanLookup: le tenants.plan.
+func TenantPlan(db sqlx.QueryerContext) mw.RateLimitPlanLookup {
+	return func(ctx context.Context, tenantID uint64) (string, error) {
+		var plan string
+		err := sqlx.GetContext(ctx, db, &plan, `SELECT plan FROM tenants WHERE id=?`, tenantID)
+		return plan, err
+	}
+}
@@ -1,6 +1,19 @@
 package handlers
 
-import "strings"
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+)
 
 const (
 	roleOwner        = "owner"
@@ -10,6 +23,45 @@ const (
 	roleLegacyMember = "member"
 )
 
+// chave de role customizado: minusculas, digitos, '-' e '_'
+var customRoleKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,39}$`)
+
+var hrPermissions = []string{
+	mw.PermHRStructureRead, mw.PermHRStructureWrite,
+	mw.PermEmployeesRead, mw.PermEmployeesWrite, mw.PermEmployeesSalary, mw.PermEmployeeAccounts,
+	mw.PermBenefitsRead, mw.PermBenefitsWrite,
+	mw.PermTimeOffRead, mw.PermTimeOffWrite, mw.PermTimeOffApprove,
+	mw.PermTimeEntriesRead, mw.PermClockifyManage, mw.PermClockifySync, mw.PermClockifySyncClosed,
+	mw.PermTimeBankRead, mw.PermTimeBankWrite, mw.PermTimeBankApprove, mw.PermTimeBankClose,
+	mw.PermInvitationsManage,
+}
+
+var financePermissions = []string{
+	mw.PermVendorsRead, mw.PermVendorsWrite,
+	mw.PermPayablesRead, mw.PermPayablesWrite, mw.PermPayablesApprove, mw.PermPayablesPay,
+	mw.PermCustomersRead, mw.PermCustomersWrite,
+	mw.PermReceivablesRead, mw.PermReceivablesWrite, mw.PermReceivablesReceive,
+	mw.PermCostCentersRead, mw.PermCostCentersWrite, mw.PermFinanceDashboardRead,
+}
+
+// builtinRolePermissions reproduz o acesso que cada role tinha pelos grupos de
+// rotas: owner tem tudo, menos o que era exclusivo do RH (provisionar conta de
+// colaborador e sincronizar periodo fechado).
+var builtinRolePermissions = map[string][]string{
+	roleOwner: func() []string {
+		var out []string
+		for _, p := range mw.PermissionCatalog {
+			if p.Key != mw.PermEmployeeAccounts && p.Key != mw.PermClockifySyncClosed {
+				out = append(out, p.Key)
+			}
+		}
+		return out
+	}(),
+	roleHR:           hrPermissions,
+	roleFinance:      financePermissions,
+	roleCollaborator: {},
+}
+
 func normalizeRole(role string) string {
 	normalized := strings.TrimSpace(strings.ToLower(role))
 	if normalized == roleLegacyMember {
@@ -18,6 +70,26 @@ func normalizeRole(role string) string {
 	return normalized
 }
 
+// roleSpellings devolve os valores de memberships.role que normalizeRole trata
+// como role (colaborador tambem e gravado como member em linhas antigas).
+func roleSpellings(role string) []string {
+	role = normalizeRole(role)
+	if role == roleCollaborator {
+		return []string{roleCollaborator, roleLegacyMember}
+	}
+	return []string{role}
+}
+
+func isBuiltinRole(role string) bool {
+	switch normalizeRole(role) {
+	case roleOwner, roleHR, roleFinance, roleCollaborator, roleLegacyMember:
+		return true
+	default:
+		return false
+	}
+}
+
+// isValidRole aceita so os roles embutidos; para roles customizados use roleExists.
 func isValidRole(role string) bool {
 	switch normalizeRole(role) {
 	case roleOwner, roleHR, roleFinance, roleCollaborator:
@@ -26,3 +98,58 @@ func isValidRole(role string) bool {
 		return false
 	}
 }
+
+// roleExists aceita roles embutidos e os customizados do tenant.
+func roleExists(ctx context.Context, q sqlx.QueryerContext, tenantID uint64, role string) (bool, error) {
+	if isValidRole(role) {
+		return true, nil
+	}
+	var n int
+	err := sqlx.GetContext(ctx, q, &n, `SELECT COUNT(*) FROM tenant_roles WHERE tenant_id=? AND role_key=?`, tenantID, normalizeRole(role))
+	return n > 0, err
+}
+
+// isPrivilegedRole indica roles que aprovam pagamentos ou veem salarios: todos
+// menos colaborador (roles customizados contam como privilegiados).
+func isPrivilegedRole(role string) bool {
+	role = normalizeRole(role)
+	return role != "" && role != roleCollaborator
+}
+
+// rolePermissions resolve as permissoes do role. customJSON vem de
+// tenant_roles.permissions_json (NULL para roles embutidos ou inexistentes).
+func rolePermissions(role string, customJSON sql.NullString) []string {
+	if perms, ok := builtinRolePermissions[normalizeRole(role)]; ok {
+		return perms
+	}
+	if !customJSON.Valid {
+		return nil
+	}
+	var perms []string
+	_ = json.Unmarshal([]byte(customJSON.String), &perms)
+	return perms
+}
+
+// normalizePermissions valida a lista de um role customizado: so permissoes do
+// catalogo e nenhuma exclusiva do owner. Devolve a lista ordenada sem repeticao.
+func normalizePermissions(in []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(in))
+	for _, p := range in {
+		p = strings.TrimSpace(strings.ToLower(p))
+		info, ok := mw.LookupPermission(p)
+		if !ok {
+			return nil, problem.New(http.StatusBadRequest, problem.CodeInvalidPermission, "invalid permission: "+p, p)
+		}
+		if info.OwnerOnly {
+			return nil, problem.New(http.StatusBadRequest, problem.CodePermissionReservedToOwner, "permission reserved to owner: "+p, p)
+		}
+		if _, dup := seen[p]; dup {
+			continue
+		}
+		seen[p] = struct{}{}
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"testing"
+
+	mw "saas-api/internal/http/middleware"
+)
+
+func TestNormalizePermissions(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		{"sorted and deduplicated", []string{"payables:write", " Payables:Read ", "payables:write"}, []string{"payables:read", "payables:write"}, false},
+		{"unknown permission", []string{"payables:delete"}, nil, true},
+		{"owner only", []string{mw.PermMembersManage}, nil, true},
+		{"empty", nil, []string{}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := normalizePermissions(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("got %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuiltinRolePermissions(t *testing.T) {
+	has := func(role, perm string) bool {
+		for _, p := range builtinRolePermissions[role] {
+			if p == perm {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !has(roleOwner, mw.PermMembersManage) || !has(roleOwner, mw.PermPayablesApprove) {
+		t.Fatal("owner must keep admin and finance permissions")
+	}
+	if has(roleOwner, mw.PermEmployeeAccounts) || !has(roleHR, mw.PermEmployeeAccounts) {
+		t.Fatal("employee accounts must stay hr-only")
+	}
+	if has(roleFinance, mw.PermEmployeesSalary) || has(roleHR, mw.PermPayablesRead) {
+		t.Fatal("hr and finance must not share permissions")
+	}
+	if len(builtinRolePermissions[roleCollaborator]) != 0 {
+		t.Fatal("colaborador has no permissions")
+	}
+}
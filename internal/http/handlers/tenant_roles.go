@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+)
+
+// RolesHandler expoe o catalogo de permissoes e gerencia os roles customizados
+// do tenant (owner).
+type RolesHandler struct {
+	DB *sqlx.DB
+}
+
+type TenantRole struct {
+	ID          uint64     `db:"id" json:"id,omitempty"`
+	Key         string     `db:"role_key" json:"key"`
+	Name        string     `db:"name" json:"name"`
+	Description *string    `db:"description" json:"description,omitempty"`
+	Builtin     bool       `db:"-" json:"builtin"`
+	Permissions []string   `db:"-" json:"permissions"`
+	CreatedAt   *time.Time `db:"created_at" json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+
+	PermissionsJSON string `db:"permissions_json" json:"-"`
+}
+
+type tenantRoleReq struct {
+	Key         string   `json:"key"` // so na criacao
+	Name        string   `json:"name"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+const tenantRoleSelect = `
+	SELECT id, role_key, name, description, permissions_json, created_at, updated_at
+	FROM tenant_roles`
+
+var builtinRoleNames = []struct{ key, name string }{
+	{roleOwner, "Owner"},
+	{roleHR, "RH"},
+	{roleFinance, "Financeiro"},
+	{roleCollaborator, "Colaborador"},
+}
+
+func (h *RolesHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, mw.PermissionCatalog)
+}
+
+// ListRoles devolve os roles embutidos seguidos dos customizados.
+func (h *RolesHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+
+	var custom []TenantRole
+	if err := h.DB.SelectContext(r.Context(), &custom, tenantRoleSelect+` WHERE tenant_id=? ORDER BY role_key`, tenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToListRoles, "failed to list roles")
+		return
+	}
+
+	out := make([]TenantRole, 0, len(builtinRoleNames)+len(custom))
+	for _, b := range builtinRoleNames {
+		out = append(out, TenantRole{
+			Key:         b.key,
+			Name:        b.name,
+			Builtin:     true,
+			Permissions: builtinRolePermissions[b.key],
+		})
+	}
+	for _, c := range custom {
+		c.Permissions = rolePermissions(c.Key, sql.NullString{String: c.PermissionsJSON, Valid: true})
+		out = append(out, c)
+	}
+
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (h *RolesHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+
+	var req tenantRoleReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.Key = strings.TrimSpace(strings.ToLower(req.Key))
+	if !customRoleKeyRe.MatchString(req.Key) {
+		writeFieldError(w, "key", problem.RuleRoleKey, "key must be 2-40 chars: a-z, 0-9, '-' or '_'")
+		return
+	}
+	if isBuiltinRole(req.Key) {
+		writeError(w, http.StatusBadRequest, problem.CodeKeyIsReserved, "key is reserved")
+		return
+	}
+	perms, err := h.validate(&req)
+	if err != nil {
+		writeProblem(w, err)
+		return
+	}
+	permsJSON, _ := json.Marshal(perms)
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	var exists int
+	_ = tx.GetContext(r.Context(), &exists, `SELECT COUNT(*) FROM tenant_roles WHERE tenant_id=? AND role_key=?`, tenantID, req.Key)
+	if exists > 0 {
+		writeError(w, http.StatusConflict, problem.CodeRoleAlreadyExists, "role already exists")
+		return
+	}
+
+	res, err := tx.ExecContext(r.Context(), `
+		INSERT INTO tenant_roles (tenant_id, role_key, name, description, permissions_json, created_by, updated_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		tenantID, req.Key, req.Name, req.Description, string(permsJSON), userID, userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToCreateRole, "failed to create role")
+		return
+	}
+	id64, _ := res.LastInsertId()
+
+	out, err := getTenantRole(r.Context(), tx, tenantID, req.Key)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "create", "tenant_roles", id64, nil, out); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, out)
+}
+
+// UpdateRole troca nome, descricao e permissoes. As permissoes sao lidas a cada
+// requisicao, entao a mudanca vale na hora para quem ja tem o role.
+func (h *RolesHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+	key := strings.TrimSpace(strings.ToLower(chi.URLParam(r, "key")))
+
+	if isBuiltinRole(key) {
+		writeError(w, http.StatusBadRequest, problem.CodeBuiltinRolesCannotBeChanged, "builtin roles cannot be changed")
+		return
+	}
+
+	var req tenantRoleReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	perms, err := h.validate(&req)
+	if err != nil {
+		writeProblem(w, err)
+		return
+	}
+	permsJSON, _ := json.Marshal(perms)
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := getTenantRole(r.Context(), tx, tenantID, key)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, problem.CodeRoleNotFound, "role not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `
+		UPDATE tenant_roles SET name=?, description=?, permissions_json=?, updated_by=?
+		WHERE tenant_id=? AND role_key=?`,
+		req.Name, req.Description, string(permsJSON), userID, tenantID, key); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToUpdateRole, "failed to update role")
+		return
+	}
+
+	after, err := getTenantRole(r.Context(), tx, tenantID, key)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "update", "tenant_roles", int64(before.ID), before, after); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, after)
+}
+
+// DeleteRole remove um role customizado sem membros nem convites pendentes.
+func (h *RolesHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+	key := strings.TrimSpace(strings.ToLower(chi.URLParam(r, "key")))
+
+	if isBuiltinRole(key) {
+		writeError(w, http.StatusBadRequest, problem.CodeBuiltinRolesCannotBeDeleted, "builtin roles cannot be deleted")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := getTenantRole(r.Context(), tx, tenantID, key)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, problem.CodeRoleNotFound, "role not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	var inUse int
+	if err := tx.GetContext(r.Context(), &inUse, `
+		SELECT (SELECT COUNT(*) FROM memberships WHERE tenant_id=? AND role=?)
+		     + (SELECT COUNT(*) FROM invitations WHERE tenant_id=? AND role=? AND status='pending')
+		     + (SELECT COUNT(*) FROM tenant_sso_configs WHERE tenant_id=? AND default_role=?)`,
+		tenantID, key, tenantID, key, tenantID, key); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if inUse > 0 {
+		writeError(w, http.StatusConflict, problem.CodeRoleInUse, "role is assigned to members, pending invitations or sso default role")
+		return
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `DELETE FROM tenant_roles WHERE tenant_id=? AND role_key=?`, tenantID, key); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeFailedToDeleteRole, "failed to delete role")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "delete", "tenant_roles", int64(before.ID), before, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RolesHandler) validate(req *tenantRoleReq) ([]string, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = cleanPtr(req.Description)
+	if req.Name == "" {
+		return nil, problem.Invalid("name", problem.RuleRequired, "name is required")
+	}
+	if len(req.Name) > 120 {
+		return nil, problem.New(http.StatusBadRequest, problem.CodeNameTooLong, "name too long")
+	}
+	if req.Description != nil && len(*req.Description) > 500 {
+		return nil, problem.New(http.StatusBadRequest, problem.CodeDescriptionTooLong, "description too long")
+	}
+	return normalizePermissions(req.Permissions)
+}
+
+func getTenantRole(ctx context.Context, q sqlx.QueryerContext, tenantID uint64, key string) (TenantRole, error) {
+	var role TenantRole
+	if err := sqlx.GetContext(ctx, q, &role, tenantRoleSelect+` WHERE tenant_id=? AND role_key=?`, tenantID, key); err != nil {
+		return TenantRole{}, err
+	}
+	role.Permissions = rolePermissions(role.Key, sql.NullString{String: role.PermissionsJSON, Valid: true})
+	return role, nil
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+)
+
+// TenantSecurityHandler cuida das politicas de seguranca do tenant (owner).
+type TenantSecurityHandler struct {
+	DB *sqlx.DB
+}
+
+type tenantSecuritySettings struct {
+	MFARequired bool `db:"mfa_required" json:"mfa_required"`
+}
+
+type updateTenantSecurityReq struct {
+	MFARequired *bool `json:"mfa_required"`
+}
+
+func (h *TenantSecurityHandler) GetSecuritySettings(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+
+	var out tenantSecuritySettings
+	if err := h.DB.GetContext(r.Context(), &out, `SELECT mfa_required FROM tenants WHERE id=?`, tenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// UpdateSecuritySettings liga/desliga a exigencia de MFA para todos os roles
+// exceto colaborador. Ao ligar, os tokens dos privilegiados sem MFA sao
+// invalidados na hora; o refresh devolve um token que so permite cadastrar o MFA.
+func (h *TenantSecurityHandler) UpdateSecuritySettings(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+
+	var req updateTenantSecurityReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	if req.MFARequired == nil {
+		writeFieldError(w, "mfa_required", problem.RuleRequired, "mfa_required is required")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	var before tenantSecuritySettings
+	if err := tx.GetContext(r.Context(), &before, `SELECT mfa_required FROM tenants WHERE id=? FOR UPDATE`, tenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	if *req.MFARequired && !before.MFARequired {
+		// evita o owner se trancar fora das configuracoes
+		enabled, err := userMFAEnabled(r.Context(), tx, userID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+			return
+		}
+		if !enabled {
+			writeError(w, http.StatusConflict, problem.CodeMFARequiredToEnforce, "enable mfa on your account before requiring it")
+			return
+		}
+
+		if _, err := tx.ExecContext(r.Context(), `
+			UPDATE memberships m
+			LEFT JOIN user_mfa f ON f.user_id = m.user_id AND f.enabled_at IS NOT NULL
+			SET m.token_version = m.token_version + 1
+			WHERE m.tenant_id=? AND m.role NOT IN (?, ?) AND f.user_id IS NULL`,
+			tenantID, roleCollaborator, roleLegacyMember); err != nil {
+			writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+			return
+		}
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `UPDATE tenants SET mfa_required=? WHERE id=?`, *req.MFARequired, tenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db update error")
+		return
+	}
+
+	after := tenantSecuritySettings{MFARequired: *req.MFARequired}
+	if err := insertAudit(tx, r, tenantID, userID, "update_security", "tenants", int64(tenantID), before, after); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, after)
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+)
+
+var domainRe = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,}$`)
+
+type tenantSSOConfig struct {
+	TenantID           uint64    `db:"tenant_id" json:"-"`
+	Issuer             string    `db:"issuer" json:"issuer"`
+	ClientID           string    `db:"client_id" json:"client_id"`
+	ClientSecret       *string   `db:"client_secret" json:"-"`
+	ClientSecretMasked string    `db:"-" json:"client_secret_masked,omitempty"`
+	AllowedDomains     []string  `db:"-" json:"allowed_domains"`
+	DefaultRole        string    `db:"default_role" json:"default_role"`
+	Enabled            bool      `db:"enabled" json:"enabled"`
+	RedirectURI        string    `db:"-" json:"redirect_uri"`
+	UpdatedAt          time.Time `db:"updated_at" json:"updated_at"`
+
+	AllowedDomainsJSON string `db:"allowed_domains_json" json:"-"`
+}
+
+type updateTenantSSOReq struct {
+	Issuer         string   `json:"issuer"`
+	ClientID       string   `json:"client_id"`
+	ClientSecret   *string  `json:"client_secret"` // omitido: mantem o atual
+	AllowedDomains []string `json:"allowed_domains"`
+	DefaultRole    string   `json:"default_role"`
+	Enabled        *bool    `json:"enabled"`
+}
+
+func loadTenantSSOConfig(ctx context.Context, q sqlx.QueryerContext, tenantID uint64) (tenantSSOConfig, error) {
+	var c tenantSSOConfig
+	err := sqlx.GetContext(ctx, q, &c, `
+		SELECT tenant_id, issuer, client_id, client_secret, allowed_domains_json, default_role, enabled, updated_at
+		FROM tenant_sso_configs WHERE tenant_id=?`, tenantID)
+	if err != nil {
+		return c, err
+	}
+	_ = json.Unmarshal([]byte(c.AllowedDomainsJSON), &c.AllowedDomains)
+	if c.AllowedDomains == nil {
+		c.AllowedDomains = []string{}
+	}
+	return c, nil
+}
+
+func (c tenantSSOConfig) domainAllowed(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, d := range c.AllowedDomains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDomains exige ao menos um dominio: o vinculo por email so e seguro
+// para dominios que o tenant controla no provedor.
+func normalizeDomains(in []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(in))
+	for _, d := range in {
+		d = strings.TrimPrefix(strings.TrimSpace(strings.ToLower(d)), "@")
+		if !domainRe.MatchString(d) {
+			return nil, problem.New(http.StatusBadRequest, problem.CodeInvalidDomain, "invalid domain: "+d, d)
+		}
+		if _, dup := seen[d]; dup {
+			continue
+		}
+		seen[d] = struct{}{}
+		out = append(out, d)
+	}
+	if len(out) == 0 {
+		return nil, problem.Invalid("allowed_domains", problem.RuleRequired, "allowed_domains is required")
+	}
+	return out, nil
+}
+
+// validIssuer exige https. allowLocal aceita tambem http em localhost
+// (provedor de teste local, SSO_ALLOW_INSECURE_ISSUER).
+func validIssuer(issuer string, allowLocal bool) bool {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return false
+	}
+	if u.Scheme == "https" {
+		return true
+	}
+	host := u.Hostname()
+	return allowLocal && u.Scheme == "http" && (host == "localhost" || host == "127.0.0.1")
+}
+
+func (h *SSOHandler) GetSSOConfig(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+
+	c, err := loadTenantSSOConfig(r.Context(), h.DB, tenantID)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, problem.CodeSSONotConfigured, "sso not configured")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	writeJSON(w, http.StatusOK, h.presentConfig(c))
+}
+
+// UpdateSSOConfig cria ou substitui a configuracao OIDC do tenant. O discovery
+// do issuer e conferido antes de salvar.
+func (h *SSOHandler) UpdateSSOConfig(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+
+	var req updateTenantSSOReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidJSON, "invalid json")
+		return
+	}
+	req.Issuer = strings.TrimSuffix(strings.TrimSpace(req.Issuer), "/")
+	req.ClientID = strings.TrimSpace(req.ClientID)
+	req.DefaultRole = normalizeRole(req.DefaultRole)
+	if req.DefaultRole == "" {
+		req.DefaultRole = roleCollaborator
+	}
+	if !validIssuer(req.Issuer, h.AllowInsecureIssuer) {
+		writeFieldError(w, "issuer", problem.RuleHTTPSURL, "issuer must be an https url")
+		return
+	}
+	if req.ClientID == "" {
+		writeFieldError(w, "client_id", problem.RuleRequired, "client_id is required")
+		return
+	}
+	domains, err := normalizeDomains(req.AllowedDomains)
+	if err != nil {
+		writeProblem(w, err)
+		return
+	}
+	if req.DefaultRole == roleOwner {
+		writeError(w, http.StatusBadRequest, problem.CodeDefaultRoleCannotBeOwner, "default_role cannot be owner")
+		return
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	if _, err := h.OIDC.Discover(r.Context(), req.Issuer); err != nil {
+		// o detalhe (resposta do host, erro de rede) fica so no log
+		mw.Logger(r.Context()).Warn().Err(err).Str("issuer", req.Issuer).Msg("sso: issuer discovery failed")
+		writeError(w, http.StatusBadRequest, problem.CodeIssuerDiscoveryFailed, "issuer discovery failed")
+		return
+	}
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	ok, err := roleExists(r.Context(), tx, tenantID, req.DefaultRole)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusBadRequest, problem.CodeInvalidDefaultRole, "invalid default_role")
+		return
+	}
+
+	before, err := loadTenantSSOConfig(r.Context(), tx, tenantID)
+	exists := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	secret := before.ClientSecret
+	if req.ClientSecret != nil {
+		s := strings.TrimSpace(*req.ClientSecret)
+		secret = &s
+		if s == "" {
+			secret = nil // client publico, so PKCE
+		}
+	}
+	domainsJSON, _ := json.Marshal(domains)
+
+	if _, err := tx.ExecContext(r.Context(), `
+		INSERT INTO tenant_sso_configs (tenant_id, issuer, client_id, client_secret, allowed_domains_json, default_role, enabled, updated_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE issuer=VALUES(issuer), client_id=VALUES(client_id), client_secret=VALUES(client_secret),
+			allowed_domains_json=VALUES(allowed_domains_json), default_role=VALUES(default_role),
+			enabled=VALUES(enabled), updated_by=VALUES(updated_by)`,
+		tenantID, req.Issuer, req.ClientID, secret, string(domainsJSON), req.DefaultRole, enabled, userID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db write error")
+		return
+	}
+
+	after, err := loadTenantSSOConfig(r.Context(), tx, tenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	var beforeAudit any
+	if exists {
+		beforeAudit = h.presentConfig(before)
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "update_sso", "tenant_sso_configs", int64(tenantID), beforeAudit, h.presentConfig(after)); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.presentConfig(after))
+}
+
+func (h *SSOHandler) DeleteSSOConfig(w http.ResponseWriter, r *http.Request) {
+	tenantID := mw.GetTenantID(r.Context())
+	userID := mw.GetUserID(r.Context())
+
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := loadTenantSSOConfig(r.Context(), tx, tenantID)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, problem.CodeSSONotConfigured, "sso not configured")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `DELETE FROM tenant_sso_configs WHERE tenant_id=?`, tenantID); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db delete error")
+		return
+	}
+	if err := insertAudit(tx, r, tenantID, userID, "delete_sso", "tenant_sso_configs", int64(tenantID), h.presentConfig(before), nil); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// presentConfig mascara o client secret e informa a redirect_uri que deve ser
+// cadastrada no provedor.
+func (h *SSOHandler) presentConfig(c tenantSSOConfig) tenantSSOConfig {
+	if c.ClientSecret != nil {
+		c.ClientSecretMasked = maskSecret(*c.ClientSecret)
+	}
+	c.RedirectURI = h.redirectURI()
+	return c
+}
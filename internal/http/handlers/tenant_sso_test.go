@@ -0,0 +1,47 @@
+package handlers
+
+import "testing"
+
+func TestSSODomainsAndIssuer(t *testing.T) {
+	domains, err := normalizeDomains([]string{" @Acme.com ", "acme.com", "sub.acme.com.br"})
+	if err != nil || len(domains) != 2 || domains[0] != "acme.com" || domains[1] != "sub.acme.com.br" {
+		t.Fatalf("unexpected domains %v (%v)", domains, err)
+	}
+	if _, err := normalizeDomains(nil); err == nil {
+		t.Fatal("empty allowed_domains accepted")
+	}
+	if _, err := normalizeDomains([]string{"acme"}); err == nil {
+		t.Fatal("invalid domain accepted")
+	}
+
+	c := tenantSSOConfig{AllowedDomains: domains}
+	for email, want := range map[string]bool{
+		"ana@acme.com":        true,
+		"ana@evil-acme.com":   false,
+		"ana@acme.com.evil":   false,
+		"ana@sub.acme.com.br": true,
+		"acme.com":            false,
+	} {
+		if got := c.domainAllowed(email); got != want {
+			t.Errorf("domainAllowed(%q) = %v, want %v", email, got, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		issuer     string
+		allowLocal bool
+		want       bool
+	}{
+		{"https://login.acme.com", false, true},
+		{"http://localhost:9000", false, false},
+		{"http://localhost:9000", true, true},
+		{"http://127.0.0.1:9000", true, true},
+		{"http://idp.acme.com", true, false},
+		{"https://idp.acme.com?x=1", false, false},
+		{"login.acme.com", false, false},
+	} {
+		if got := validIssuer(tc.issuer, tc.allowLocal); got != tc.want {
+			t.Errorf("validIssuer(%q, %v) = %v, want %v", tc.issuer, tc.allowLocal, got, tc.want)
+		}
+	}
+}
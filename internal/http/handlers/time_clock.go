@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
 )
 
 const (
@@ -37,13 +39,13 @@ func (h *HRHandler) GetMyTimeEntries(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 	userID := mw.GetUserID(r.Context())
 
-	emp, found, err := h.resolveEmployeeForUser(tenantID, userID)
+	emp, found, err := h.resolveEmployeeForUser(r.Context(), tenantID, userID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if !found {
-		httpError(w, "employee profile not linked to user", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeProfileNotLinked, "employee profile not linked to user")
 		return
 	}
 
@@ -51,7 +53,7 @@ func (h *HRHandler) GetMyTimeEntries(w http.ResponseWriter, r *http.Request) {
 	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
 		parsed, convErr := strconv.Atoi(raw)
 		if convErr != nil || parsed <= 0 {
-			httpError(w, "limit must be numeric", http.StatusBadRequest)
+			writeFieldError(w, "limit", problem.RuleNumber, "limit must be numeric")
 			return
 		}
 		if parsed > maxMyEntriesLimit {
@@ -65,7 +67,7 @@ func (h *HRHandler) GetMyTimeEntries(w http.ResponseWriter, r *http.Request) {
 	tomorrowStart := todayStart.Add(24 * time.Hour)
 
 	var todaySeconds int64
-	if err := h.DB.Get(&todaySeconds, `
+	if err := h.DB.GetContext(r.Context(), &todaySeconds, `
 		SELECT COALESCE(SUM(
 			CASE WHEN is_running=1
 				THEN GREATEST(TIMESTAMPDIFF(SECOND, start_at, UTC_TIMESTAMP()), 0)
@@ -75,18 +77,18 @@ func (h *HRHandler) GetMyTimeEntries(w http.ResponseWriter, r *http.Request) {
 		FROM hr_time_entries
 		WHERE tenant_id=? AND employee_id=? AND source='internal' AND start_at>=? AND start_at<?
 	`, tenantID, emp.ID, todayStart, tomorrowStart); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	openEntry, err := h.findOpenInternalEntry(tenantID, emp.ID)
+	openEntry, err := h.findOpenInternalEntry(r.Context(), tenantID, emp.ID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
 	items := make([]HRTimeEntry, 0, limit)
-	if err := h.DB.Select(&items, `
+	if err := h.DB.SelectContext(r.Context(), &items, `
 		SELECT id, tenant_id, employee_id, source, external_entry_id, clockify_user_id, workspace_id,
 		       project_id, task_id, description, start_at, end_at, duration_seconds, is_running, billable,
 		       synced_at, created_at, updated_at
@@ -95,7 +97,7 @@ func (h *HRHandler) GetMyTimeEntries(w http.ResponseWriter, r *http.Request) {
 		ORDER BY start_at DESC, id DESC
 		LIMIT ?
 	`, tenantID, emp.ID, limit); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
@@ -122,44 +124,51 @@ func (h *HRHandler) ClockIn(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 	userID := mw.GetUserID(r.Context())
 
-	emp, found, err := h.resolveEmployeeForUser(tenantID, userID)
+	emp, found, err := h.resolveEmployeeForUser(r.Context(), tenantID, userID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if !found {
-		httpError(w, "employee profile not linked to user", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeProfileNotLinked, "employee profile not linked to user")
 		return
 	}
 	if emp.Status != "active" {
-		httpError(w, "employee is not active", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeEmployeeIsNotActive, "employee is not active")
 		return
 	}
 
-	openEntry, err := h.findOpenInternalEntry(tenantID, emp.ID)
+	openEntry, err := h.findOpenInternalEntry(r.Context(), tenantID, emp.ID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if openEntry != nil {
-		httpError(w, "you already have an open time entry", http.StatusConflict)
+		writeError(w, http.StatusConflict, problem.CodeTimeEntryAlreadyOpen, "you already have an open time entry")
 		return
 	}
 
 	now := time.Now().UTC()
 	closedDate := dateOnly(now)
-	closed, err := h.isDateClosedForTimeBank(tenantID, closedDate)
+	closed, err := h.isDateClosedForTimeBank(r.Context(), tenantID, closedDate)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if closed {
-		httpError(w, "period is closed for this date", http.StatusConflict)
+		writeError(w, http.StatusConflict, problem.CodePeriodIsClosedForThisDate, "period is closed for this date")
 		return
 	}
 	externalID := genCode("punch")
 
-	res, err := h.DB.Exec(`
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(r.Context(), `
 		INSERT INTO hr_time_entries (
 			tenant_id, employee_id, source, external_entry_id, clockify_user_id, workspace_id,
 			project_id, task_id, description, tag_ids_json, start_at, end_at, duration_seconds,
@@ -167,24 +176,31 @@ func (h *HRHandler) ClockIn(w http.ResponseWriter, r *http.Request) {
 		) VALUES (?, ?, 'internal', ?, ?, 'internal', NULL, NULL, NULL, NULL, ?, NULL, 0, 1, 0, NULL, ?)
 	`, tenantID, emp.ID, externalID, fmt.Sprintf("internal-user-%d", userID), now, now)
 	if err != nil {
-		httpError(w, "could not create time entry", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCreateTimeEntry, "could not create time entry")
 		return
 	}
 	id64, _ := res.LastInsertId()
 
 	var entry HRTimeEntry
-	if err := h.DB.Get(&entry, `
+	if err := tx.GetContext(r.Context(), &entry, `
 		SELECT id, tenant_id, employee_id, source, external_entry_id, clockify_user_id, workspace_id,
 		       project_id, task_id, description, start_at, end_at, duration_seconds, is_running, billable,
 		       synced_at, created_at, updated_at
 		FROM hr_time_entries
 		WHERE tenant_id=? AND id=?
 	`, tenantID, id64); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(h.DB, r, tenantID, userID, "clock_in", "hr_time_entries", id64, nil, entry)
+	if err := insertAudit(tx, r, tenantID, userID, "clock_in", "hr_time_entries", id64, nil, entry); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
 	writeJSON(w, http.StatusCreated, entry)
 }
 
@@ -192,33 +208,33 @@ func (h *HRHandler) ClockOut(w http.ResponseWriter, r *http.Request) {
 	tenantID := mw.GetTenantID(r.Context())
 	userID := mw.GetUserID(r.Context())
 
-	emp, found, err := h.resolveEmployeeForUser(tenantID, userID)
+	emp, found, err := h.resolveEmployeeForUser(r.Context(), tenantID, userID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if !found {
-		httpError(w, "employee profile not linked to user", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeEmployeeProfileNotLinked, "employee profile not linked to user")
 		return
 	}
 
-	openEntry, err := h.findOpenInternalEntry(tenantID, emp.ID)
+	openEntry, err := h.findOpenInternalEntry(r.Context(), tenantID, emp.ID)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if openEntry == nil {
-		httpError(w, "no open time entry found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, problem.CodeNoOpenTimeEntryFound, "no open time entry found")
 		return
 	}
 	closedDate := dateOnly(openEntry.StartAt.UTC())
-	periodClosed, err := h.isDateClosedForTimeBank(tenantID, closedDate)
+	periodClosed, err := h.isDateClosedForTimeBank(r.Context(), tenantID, closedDate)
 	if err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 	if periodClosed {
-		httpError(w, "period is closed for this date", http.StatusConflict)
+		writeError(w, http.StatusConflict, problem.CodePeriodIsClosedForThisDate, "period is closed for this date")
 		return
 	}
 
@@ -228,34 +244,48 @@ func (h *HRHandler) ClockOut(w http.ResponseWriter, r *http.Request) {
 		durationSeconds = 0
 	}
 
-	if _, err := h.DB.Exec(`
+	tx, err := h.DB.BeginTxx(r.Context(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(r.Context(), `
 		UPDATE hr_time_entries
 		SET end_at=?, duration_seconds=?, is_running=0, synced_at=?, updated_at=CURRENT_TIMESTAMP
 		WHERE tenant_id=? AND id=? AND source='internal'
 	`, now, durationSeconds, now, tenantID, openEntry.ID); err != nil {
-		httpError(w, "could not close time entry", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, problem.CodeCouldNotCloseTimeEntry, "could not close time entry")
 		return
 	}
 
 	var closed HRTimeEntry
-	if err := h.DB.Get(&closed, `
+	if err := tx.GetContext(r.Context(), &closed, `
 		SELECT id, tenant_id, employee_id, source, external_entry_id, clockify_user_id, workspace_id,
 		       project_id, task_id, description, start_at, end_at, duration_seconds, is_running, billable,
 		       synced_at, created_at, updated_at
 		FROM hr_time_entries
 		WHERE tenant_id=? AND id=?
 	`, tenantID, openEntry.ID); err != nil {
-		httpError(w, "db read error", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db read error")
 		return
 	}
 
-	_ = insertAudit(h.DB, r, tenantID, userID, "clock_out", "hr_time_entries", int64(openEntry.ID), openEntry, closed)
+	if err := insertAudit(tx, r, tenantID, userID, "clock_out", "hr_time_entries", int64(openEntry.ID), openEntry, closed); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeAuditWriteFailed, "audit write failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeError(w, http.StatusInternalServerError, problem.CodeDatabase, "db commit error")
+		return
+	}
 	writeJSON(w, http.StatusOK, closed)
 }
 
-func (h *HRHandler) resolveEmployeeForUser(tenantID, userID uint64) (employeeForClock, bool, error) {
+func (h *HRHandler) resolveEmployeeForUser(ctx context.Context, tenantID, userID uint64) (employeeForClock, bool, error) {
 	var linked employeeForClock
-	linkErr := h.DB.Get(&linked, `
+	linkErr := h.DB.GetContext(ctx, &linked, `
 		SELECT e.id, e.name, e.email, e.status
 		FROM hr_employee_user_links l
 		JOIN employees e ON e.tenant_id = l.tenant_id AND e.id = l.employee_id
@@ -274,7 +304,7 @@ func (h *HRHandler) resolveEmployeeForUser(tenantID, userID uint64) (employeeFor
 	}
 
 	var userEmail string
-	if err := h.DB.Get(&userEmail, `SELECT email FROM users WHERE id=?`, userID); err != nil {
+	if err := h.DB.GetContext(ctx, &userEmail, `SELECT email FROM users WHERE id=?`, userID); err != nil {
 		if err == sql.ErrNoRows {
 			return employeeForClock{}, false, nil
 		}
@@ -287,7 +317,7 @@ func (h *HRHandler) resolveEmployeeForUser(tenantID, userID uint64) (employeeFor
 	}
 
 	var emp employeeForClock
-	if err := h.DB.Get(&emp, `
+	if err := h.DB.GetContext(ctx, &emp, `
 		SELECT id, name, email, status
 		FROM employees
 		WHERE tenant_id=? AND email IS NOT NULL AND LOWER(TRIM(email))=? AND status<>'terminated'
@@ -302,9 +332,9 @@ func (h *HRHandler) resolveEmployeeForUser(tenantID, userID uint64) (employeeFor
 	return emp, true, nil
 }
 
-func (h *HRHandler) findOpenInternalEntry(tenantID, employeeID uint64) (*HRTimeEntry, error) {
+func (h *HRHandler) findOpenInternalEntry(ctx context.Context, tenantID, employeeID uint64) (*HRTimeEntry, error) {
 	var entry HRTimeEntry
-	if err := h.DB.Get(&entry, `
+	if err := h.DB.GetContext(ctx, &entry, `
 		SELECT id, tenant_id, employee_id, source, external_entry_id, clockify_user_id, workspace_id,
 		       project_id, task_id, description, start_at, end_at, duration_seconds, is_running, billable,
 		       synced_at, created_at, updated_at
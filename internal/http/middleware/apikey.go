@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"saas-api/internal/http/problem"
+)
+
+const (
+	CtxAPIKeyID ctxKey = "api_key_id"
+
+	// APIKeyPrefix identifica API keys no header Authorization (Bearer sk_...).
+	APIKeyPrefix = "sk_"
+)
+
+// Permissoes que podem ser dadas a API keys (scopes).
+var apiKeyScopes = map[string]struct{}{
+	PermTimeEntriesRead: {},
+	PermClockifySync:    {},
+	PermPayablesRead:    {},
+	PermPayablesWrite:   {},
+}
+
+func IsValidScope(scope string) bool {
+	_, ok := apiKeyScopes[scope]
+	return ok
+}
+
+// APIKeyPrincipal e o resultado da validacao de uma API key. UserID e quem
+// criou a key (usado como autor em auditoria).
+type APIKeyPrincipal struct {
+	KeyID    uint64
+	TenantID uint64
+	UserID   uint64
+	Scopes   []string
+}
+
+// ErrAPIKeyInvalid e o erro do APIKeyLookup para key desconhecida, revogada,
+// expirada ou cujo autor perdeu o acesso (401). Outro erro vira 500.
+var ErrAPIKeyInvalid = errors.New("invalid api key")
+
+// APIKeyLookup valida a key em claro; erro rejeita a requisicao.
+type APIKeyLookup func(r *http.Request, rawKey string) (*APIKeyPrincipal, error)
+
+// AuthAPIKey autentica integracoes por API key (X-API-Key ou Bearer sk_...).
+// Sem key na requisicao, segue para o proximo middleware (AuthJWT).
+func AuthAPIKey(lookup APIKeyLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := strings.TrimSpace(r.Header.Get("X-API-Key"))
+			if raw == "" {
+				if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer "+APIKeyPrefix) {
+					raw = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+				}
+			}
+			if raw == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			p, err := lookup(r, raw)
+			if err != nil && !errors.Is(err, ErrAPIKeyInvalid) {
+				Logger(r.Context()).Error().Err(err).Msg("api key lookup failed")
+				writeJSONError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+				return
+			}
+			if err != nil || p == nil {
+				writeJSONError(w, http.StatusUnauthorized, problem.CodeInvalidAPIKey, "invalid api key")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), CtxUserID, p.UserID)
+			ctx = context.WithValue(ctx, CtxTenantID, p.TenantID)
+			ctx = context.WithValue(ctx, CtxRole, "")
+			ctx = context.WithValue(ctx, CtxAPIKeyID, p.KeyID)
+			ctx = context.WithValue(ctx, CtxPermissions, p.Scopes)
+			logIdentity(ctx, p.TenantID, p.UserID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func GetAPIKeyID(ctx context.Context) uint64 {
+	v, _ := ctx.Value(CtxAPIKeyID).(uint64)
+	return v
+}
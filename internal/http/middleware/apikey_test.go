@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthAPIKeyPermissions(t *testing.T) {
+	lookup := func(_ *http.Request, raw string) (*APIKeyPrincipal, error) {
+		switch raw {
+		case "sk_test_secret":
+		case "sk_test_dbdown":
+			return nil, errors.New("connection refused")
+		default:
+			return nil, ErrAPIKeyInvalid
+		}
+		return &APIKeyPrincipal{KeyID: 1, TenantID: 2, UserID: 3, Scopes: []string{PermTimeEntriesRead}}, nil
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetTenantID(r.Context()) != 2 || GetAPIKeyID(r.Context()) != 1 {
+			t.Errorf("principal not in context")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	cases := []struct {
+		name   string
+		header string
+		value  string
+		scope  string
+		want   int
+	}{
+		{"x-api-key with scope", "X-API-Key", "sk_test_secret", PermTimeEntriesRead, http.StatusNoContent},
+		{"bearer with scope", "Authorization", "Bearer sk_test_secret", PermTimeEntriesRead, http.StatusNoContent},
+		{"missing scope", "X-API-Key", "sk_test_secret", PermPayablesWrite, http.StatusForbidden},
+		{"unknown key", "X-API-Key", "sk_test_other", PermTimeEntriesRead, http.StatusUnauthorized},
+		{"lookup failure", "X-API-Key", "sk_test_dbdown", PermTimeEntriesRead, http.StatusInternalServerError},
+		{"no key", "", "", PermTimeEntriesRead, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := AuthAPIKey(lookup)(RequirePermission(tc.scope)(ok))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
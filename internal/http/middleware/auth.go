@@ -2,58 +2,119 @@ package middleware
 
 import (
 	"context"
-	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"saas-api/internal/http/problem"
+	"saas-api/internal/i18n"
 )
 
 type ctxKey string
 
 const (
-	CtxUserID   ctxKey = "user_id"
-	CtxTenantID ctxKey = "tenant_id"
-	CtxRole     ctxKey = "role"
+	CtxUserID    ctxKey = "user_id"
+	CtxTenantID  ctxKey = "tenant_id"
+	CtxRole      ctxKey = "role"
+	CtxSessionID ctxKey = "session_id"
+
+	CtxMustChangePassword ctxKey = "must_change_password"
+	CtxMFAEnrollRequired  ctxKey = "mfa_enroll_required"
+	CtxActor              ctxKey = "actor"
 )
 
+// Actor identifica o admin da plataforma por tras de um token de impersonation
+// (claim "act", RFC 8693). UserID/TenantID do token sao os do usuario impersonado.
+type Actor struct {
+	UserID          uint64 `json:"uid"`
+	ImpersonationID uint64 `json:"imp"`
+	Write           bool   `json:"rw,omitempty"`
+}
+
 type Claims struct {
-	UserID   uint64 `json:"uid"`
-	TenantID uint64 `json:"tid"`
-	Role     string `json:"role"`
+	UserID       uint64 `json:"uid"`
+	TenantID     uint64 `json:"tid"`
+	Role         string `json:"role"`
+	SessionID    uint64 `json:"sid,omitempty"`
+	TokenVersion uint64 `json:"tv,omitempty"`
+
+	// MustChangePassword limita o token as rotas de troca de senha.
+	MustChangePassword bool `json:"mcp,omitempty"`
+	// MFAEnrollRequired: tenant exige MFA para o role e o usuario ainda nao ativou;
+	// o token so serve para cadastrar o MFA.
+	MFAEnrollRequired bool `json:"mfa_enroll,omitempty"`
+	// Locale e o idioma preferido do usuario (vazio = Accept-Language).
+	Locale string `json:"lng,omitempty"`
+	// Act so existe em tokens de impersonation (sem sessao de refresh).
+	Act *Actor `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func AuthJWT(secret []byte) func(http.Handler) http.Handler {
+// SessionValidator confirma no servidor que a sessao do token continua valida
+// (nao revogada e com a mesma versao de membership) e devolve as permissoes
+// atuais do role. Erro rejeita o token.
+type SessionValidator func(ctx context.Context, claims *Claims) ([]string, error)
+
+// TokenVerifier resolve a chave de verificacao pelo kid do token (ver
+// jwtkeys.KeySet).
+type TokenVerifier interface {
+	Keyfunc(t *jwt.Token) (any, error)
+	ValidMethods() []string
+}
+
+func AuthJWT(keys TokenVerifier, validate SessionValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// ja autenticado por AuthAPIKey
+			if GetAPIKeyID(r.Context()) != 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			auth := r.Header.Get("Authorization")
 			if auth == "" || !strings.HasPrefix(auth, "Bearer ") {
-				http.Error(w, "missing bearer token", http.StatusUnauthorized)
+				writeJSONError(w, http.StatusUnauthorized, problem.CodeMissingBearerToken, "missing bearer token")
 				return
 			}
 			tokenStr := strings.TrimPrefix(auth, "Bearer ")
 
-			token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (any, error) {
-				if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, errors.New("invalid signing method")
-				}
-				return secret, nil
-			})
+			token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, keys.Keyfunc, jwt.WithValidMethods(keys.ValidMethods()))
 			if err != nil || !token.Valid {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
+				writeJSONError(w, http.StatusUnauthorized, problem.CodeInvalidToken, "invalid token")
 				return
 			}
 
 			claims, ok := token.Claims.(*Claims)
 			if !ok {
-				http.Error(w, "invalid token claims", http.StatusUnauthorized)
+				writeJSONError(w, http.StatusUnauthorized, problem.CodeInvalidTokenClaims, "invalid token claims")
 				return
 			}
 
+			var perms []string
+			if validate != nil {
+				perms, err = validate(r.Context(), claims)
+				if err != nil {
+					writeJSONError(w, http.StatusUnauthorized, problem.CodeSessionRevoked, "session revoked")
+					return
+				}
+			}
+
+			if lang, ok := i18n.Normalize(claims.Locale); ok {
+				r = withLocale(w, r, lang)
+			}
+
 			ctx := context.WithValue(r.Context(), CtxUserID, claims.UserID)
 			ctx = context.WithValue(ctx, CtxTenantID, claims.TenantID)
 			ctx = context.WithValue(ctx, CtxRole, claims.Role)
+			ctx = context.WithValue(ctx, CtxSessionID, claims.SessionID)
+			ctx = context.WithValue(ctx, CtxMustChangePassword, claims.MustChangePassword)
+			ctx = context.WithValue(ctx, CtxMFAEnrollRequired, claims.MFAEnrollRequired)
+			ctx = context.WithValue(ctx, CtxPermissions, perms)
+			if claims.Act != nil {
+				ctx = context.WithValue(ctx, CtxActor, claims.Act)
+			}
+			logIdentity(ctx, claims.TenantID, claims.UserID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -71,3 +132,40 @@ func GetRole(ctx context.Context) string {
 	v, _ := ctx.Value(CtxRole).(string)
 	return v
 }
+func GetSessionID(ctx context.Context) uint64 {
+	v, _ := ctx.Value(CtxSessionID).(uint64)
+	return v
+}
+func GetMustChangePassword(ctx context.Context) bool {
+	v, _ := ctx.Value(CtxMustChangePassword).(bool)
+	return v
+}
+
+// RequirePasswordChanged bloqueia tokens emitidos para contas que ainda precisam
+// trocar a senha provisoria (primeiro acesso).
+func RequirePasswordChanged(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetMustChangePassword(r.Context()) {
+			writeJSONError(w, http.StatusForbidden, problem.CodePasswordChangeRequired, "password change required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func GetMFAEnrollRequired(ctx context.Context) bool {
+	v, _ := ctx.Value(CtxMFAEnrollRequired).(bool)
+	return v
+}
+
+// RequireMFAEnrolled bloqueia tokens de usuarios privilegiados que ainda
+// precisam ativar o MFA exigido pelo tenant.
+func RequireMFAEnrolled(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetMFAEnrollRequired(r.Context()) {
+			writeJSONError(w, http.StatusForbidden, problem.CodeMFAEnrollmentRequired, "mfa enrollment required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
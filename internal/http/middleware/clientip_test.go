@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		remote string
+		xff    string
+		realIP string
+		want   string
+	}{
+		{"direct client ignores headers", "203.0.113.7:5000", "1.2.3.4", "1.2.3.5", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:443", "198.51.100.9", "", "198.51.100.9"},
+		{"spoofed left entry", "10.0.0.2:443", "6.6.6.6, 198.51.100.9, 10.0.0.5", "", "198.51.100.9"},
+		{"only internal hops", "127.0.0.1:80", "10.1.1.1, 10.0.0.5", "", "10.1.1.1"},
+		{"garbage hop", "10.0.0.2:443", "198.51.100.9, nope", "", "10.0.0.2"},
+		{"real ip header", "127.0.0.1:80", "", "2001:db8::1", "2001:db8::1"},
+		{"no headers", "10.0.0.2:443", "", "", "10.0.0.2"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			h := ClientIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetClientIP(r)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remote
+			if tc.xff != "" {
+				req.Header.Set("X-Forwarded-For", tc.xff)
+			}
+			if tc.realIP != "" {
+				req.Header.Set("X-Real-IP", tc.realIP)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tc.want {
+				t.Fatalf("client ip = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("invalid cidr accepted")
+	}
+}
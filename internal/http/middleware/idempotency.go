@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"saas-api/internal/http/problem"
+)
+
+const (
+	IdempotencyKeyHeader    = "Idempotency-Key"
+	IdempotentReplayHeader  = "Idempotent-Replayed"
+	idempotencyMaxKeyLen    = 255
+	idempotencyMaxBodyBytes = 1 << 20 // respostas maiores nao sao guardadas
+)
+
+// headers da resposta que voltam no replay
+var idempotencyReplayHeaders = []string{"Content-Type", "Content-Disposition", "ETag", "Location"}
+
+// IdempotencyScope identifica a key: tenant + usuario + metodo + rota.
+type IdempotencyScope struct {
+	TenantID uint64
+	UserID   uint64
+	Method   string
+	Path     string
+	Key      string
+}
+
+// IdempotencyRecord e o que ja foi gravado para a key. Status 0 indica que a
+// primeira requisicao ainda esta em andamento.
+type IdempotencyRecord struct {
+	RequestHash string
+	Status      int
+	Header      http.Header
+	Body        []byte
+}
+
+// IdempotencyStore guarda as respostas por key (implementado em handlers).
+type IdempotencyStore interface {
+	// Reserve marca a key como em andamento. Se ela ja existe e nao expirou,
+	// devolve o registro existente (sem reservar).
+	Reserve(ctx context.Context, s IdempotencyScope, requestHash string, ttl time.Duration) (*IdempotencyRecord, error)
+	// Complete grava a resposta da requisicao reservada.
+	Complete(ctx context.Context, s IdempotencyScope, rec IdempotencyRecord) error
+	// Release apaga a reserva para que a key possa ser usada de novo.
+	Release(ctx context.Context, s IdempotencyScope) error
+}
+
+// Idempotency atende o header Idempotency-Key em POST/PUT/PATCH/DELETE: a
+// primeira resposta fica guardada por ttl e e repetida nas retentativas.
+// Mesma key com corpo diferente responde 422. Respostas 5xx, 401, 403 e 429
+// nao sao guardadas (a key fica livre para tentar de novo). Precisa rodar
+// depois da autenticacao.
+//
+// skip lista rotas ("METODO /caminho", sem parametros) que nunca passam pelo
+// store: as que devolvem segredos (tokens, recovery codes, API key em claro),
+// que ficariam em texto puro em idempotency_keys e poderiam ser repetidos.
+func Idempotency(store IdempotencyStore, ttl time.Duration, skip ...string) func(http.Handler) http.Handler {
+	skipped := make(map[string]bool, len(skip))
+	for _, s := range skip {
+		skipped[s] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || !isMutating(r.Method) || skipped[r.Method+" "+r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > idempotencyMaxKeyLen || !printableASCII(key) {
+				writeJSONError(w, http.StatusBadRequest, problem.CodeInvalidIdempotencyKey, "invalid idempotency key")
+				return
+			}
+			scope := IdempotencyScope{
+				TenantID: GetTenantID(r.Context()),
+				UserID:   GetUserID(r.Context()),
+				Method:   r.Method,
+				Path:     r.URL.Path,
+				Key:      key,
+			}
+			if scope.TenantID == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, problem.CodeCouldNotReadRequestBody, "could not read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			sum := sha256.Sum256(body)
+			hash := hex.EncodeToString(sum[:])
+
+			// gravacoes da key nao podem ser canceladas pela queda do cliente
+			ctx := context.WithoutCancel(r.Context())
+			prev, err := store.Reserve(ctx, scope, hash, ttl)
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+				return
+			}
+			if prev != nil {
+				replayIdempotent(w, prev, hash)
+				return
+			}
+
+			cw := &captureWriter{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				if !completed {
+					_ = store.Release(ctx, scope)
+				}
+			}()
+			next.ServeHTTP(cw, r)
+
+			if !storableStatus(cw.status) || cw.overflow {
+				return
+			}
+			header := http.Header{}
+			for _, h := range idempotencyReplayHeaders {
+				if v := cw.Header().Get(h); v != "" {
+					header.Set(h, v)
+				}
+			}
+			rec := IdempotencyRecord{RequestHash: hash, Status: cw.status, Header: header, Body: cw.body.Bytes()}
+			if err := store.Complete(ctx, scope, rec); err == nil {
+				completed = true
+			}
+		})
+	}
+}
+
+func replayIdempotent(w http.ResponseWriter, prev *IdempotencyRecord, hash string) {
+	switch {
+	case prev.RequestHash != hash:
+		writeJSONError(w, http.StatusUnprocessableEntity, problem.CodeIdempotencyKeyReused, "idempotency key reused with different request")
+	case prev.Status == 0:
+		writeJSONError(w, http.StatusConflict, problem.CodeIdempotencyInProgress, "request with this idempotency key is in progress")
+	default:
+		for k, v := range prev.Header {
+			w.Header()[k] = v
+		}
+		w.Header().Set(IdempotentReplayHeader, "true")
+		w.WriteHeader(prev.Status)
+		_, _ = w.Write(prev.Body)
+	}
+}
+
+// storableStatus descarta falhas transitorias ou de acesso, que podem mudar
+// na retentativa.
+func storableStatus(status int) bool {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return false
+	}
+	return status < 500
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func printableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// captureWriter repassa a resposta e guarda uma copia para o replay.
+type captureWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	overflow    bool
+}
+
+func (w *captureWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	if !w.overflow {
+		if w.body.Len()+len(b) > idempotencyMaxBodyBytes {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
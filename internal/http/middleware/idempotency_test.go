@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memIdempotencyStore struct {
+	mu   sync.Mutex
+	recs map[IdempotencyScope]*IdempotencyRecord
+}
+
+func (m *memIdempotencyStore) Reserve(_ context.Context, s IdempotencyScope, hash string, _ time.Duration) (*IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rec, ok := m.recs[s]; ok {
+		return rec, nil
+	}
+	m.recs[s] = &IdempotencyRecord{RequestHash: hash}
+	return nil, nil
+}
+
+func (m *memIdempotencyStore) Complete(_ context.Context, s IdempotencyScope, rec IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recs[s] = &rec
+	return nil
+}
+
+func (m *memIdempotencyStore) Release(_ context.Context, s IdempotencyScope) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.recs, s)
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	store := &memIdempotencyStore{recs: map[IdempotencyScope]*IdempotencyRecord{}}
+	calls := 0
+	status := http.StatusCreated
+	h := Idempotency(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"id":` + strconv.Itoa(calls) + `}`))
+	}))
+
+	send := func(method, key, body string, tenant uint64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/payables", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		ctx := context.WithValue(req.Context(), CtxTenantID, tenant)
+		ctx = context.WithValue(ctx, CtxUserID, uint64(7))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	cases := []struct {
+		name     string
+		method   string
+		key      string
+		body     string
+		tenant   uint64
+		want     int
+		wantBody string
+		replayed bool
+		calls    int
+	}{
+		{"first", http.MethodPost, "k1", `{"a":1}`, 1, 201, `{"id":1}`, false, 1},
+		{"retry replays", http.MethodPost, "k1", `{"a":1}`, 1, 201, `{"id":1}`, true, 1},
+		{"different body", http.MethodPost, "k1", `{"a":2}`, 1, 422, "", false, 1},
+		{"other tenant", http.MethodPost, "k1", `{"a":1}`, 2, 201, `{"id":2}`, false, 2},
+		{"no key", http.MethodPost, "", `{"a":1}`, 1, 201, `{"id":3}`, false, 3},
+		{"get ignored", http.MethodGet, "k1", ``, 1, 201, `{"id":4}`, false, 4},
+		{"bad key", http.MethodPost, "k\n1", `{}`, 1, 400, "", false, 4},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := send(tc.method, tc.key, tc.body, tc.tenant)
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tc.want, rec.Body.String())
+			}
+			if tc.wantBody != "" && rec.Body.String() != tc.wantBody {
+				t.Fatalf("body = %q, want %q", rec.Body.String(), tc.wantBody)
+			}
+			if got := rec.Header().Get(IdempotentReplayHeader) == "true"; got != tc.replayed {
+				t.Fatalf("replayed = %v, want %v", got, tc.replayed)
+			}
+			if calls != tc.calls {
+				t.Fatalf("handler calls = %d, want %d", calls, tc.calls)
+			}
+		})
+	}
+
+	t.Run("server error is not stored", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		send(http.MethodPost, "k2", `{}`, 1)
+		status = http.StatusCreated
+		before := calls
+		if rec := send(http.MethodPost, "k2", `{}`, 1); rec.Code != 201 || calls != before+1 {
+			t.Fatalf("retry after 500: status %d, calls %d", rec.Code, calls-before)
+		}
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		scope := IdempotencyScope{TenantID: 1, UserID: 7, Method: http.MethodPost, Path: "/v1/payables", Key: "k3"}
+		_, _ = store.Reserve(context.Background(), scope, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", time.Hour)
+		if rec := send(http.MethodPost, "k3", ``, 1); rec.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want 409", rec.Code)
+		}
+	})
+}
+
+func TestIdempotencySkipsSecretRoutes(t *testing.T) {
+	store := &memIdempotencyStore{recs: map[IdempotencyScope]*IdempotencyRecord{}}
+	calls := 0
+	h := Idempotency(store, time.Hour, "POST /v1/api-keys")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"key":"sk_secret"}`))
+	}))
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/v1/api-keys", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		ctx := context.WithValue(req.Context(), CtxTenantID, uint64(1))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req.WithContext(ctx))
+		if rec.Header().Get(IdempotentReplayHeader) != "" {
+			t.Fatal("secret response replayed")
+		}
+	}
+	if calls != 2 || len(store.recs) != 0 {
+		t.Fatalf("calls = %d, stored = %d", calls, len(store.recs))
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"saas-api/internal/http/problem"
+)
+
+// PlatformAdminCheck diz se o usuario e admin da plataforma (tabela
+// platform_admins, independente de tenant).
+type PlatformAdminCheck func(ctx context.Context, userID uint64) (bool, error)
+
+// ImpersonationRecorder grava uma requisicao feita sob impersonation, ja com o
+// status da resposta.
+type ImpersonationRecorder func(r *http.Request, status int)
+
+func GetActor(ctx context.Context) *Actor {
+	v, _ := ctx.Value(CtxActor).(*Actor)
+	return v
+}
+
+// RequirePlatformAdmin libera rotas de suporte da plataforma. Tokens de
+// impersonation nunca passam (sem impersonation encadeada).
+func RequirePlatformAdmin(check PlatformAdminCheck) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if GetActor(r.Context()) != nil {
+				writeJSONError(w, http.StatusForbidden, problem.CodeNotAllowedWhileImpersonating, "not allowed while impersonating")
+				return
+			}
+			ok, err := check(r.Context(), GetUserID(r.Context()))
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, problem.CodeDatabase, "db error")
+				return
+			}
+			if !ok {
+				writeJSONError(w, http.StatusForbidden, problem.CodePlatformAdminRequired, "platform admin required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ImpersonationReadOnly bloqueia metodos de escrita em tokens de impersonation
+// emitidos sem permissao de escrita (o padrao).
+func ImpersonationReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if act := GetActor(r.Context()); act != nil && !act.Write {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				writeJSONError(w, http.StatusForbidden, problem.CodeImpersonationIsReadOnly, "impersonation is read-only")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// DenyImpersonation protege rotas da conta do usuario (senha, MFA, troca de
+// tenant), que nem o admin com escrita pode usar.
+func DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetActor(r.Context()) != nil {
+			writeJSONError(w, http.StatusForbidden, problem.CodeNotAllowedWhileImpersonating, "not allowed while impersonating")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AuditImpersonation registra toda requisicao feita com token de impersonation,
+// inclusive leituras e tentativas bloqueadas.
+func AuditImpersonation(record ImpersonationRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if GetActor(r.Context()) == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			record(r, sw.status)
+		})
+	}
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	bytes       int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
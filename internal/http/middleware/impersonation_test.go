@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestImpersonationReadOnlyAndAudit(t *testing.T) {
+	var recorded []int
+	record := func(_ *http.Request, status int) { recorded = append(recorded, status) }
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := AuditImpersonation(record)(ImpersonationReadOnly(ok))
+
+	cases := []struct {
+		name     string
+		actor    *Actor
+		method   string
+		want     int
+		recorded bool
+	}{
+		{"regular user write", nil, http.MethodPost, http.StatusNoContent, false},
+		{"read-only get", &Actor{UserID: 9, ImpersonationID: 1}, http.MethodGet, http.StatusNoContent, true},
+		{"read-only post", &Actor{UserID: 9, ImpersonationID: 1}, http.MethodPost, http.StatusForbidden, true},
+		{"write delete", &Actor{UserID: 9, ImpersonationID: 2, Write: true}, http.MethodDelete, http.StatusNoContent, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorded = nil
+			req := httptest.NewRequest(tc.method, "/", nil)
+			if tc.actor != nil {
+				req = req.WithContext(context.WithValue(req.Context(), CtxActor, tc.actor))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.want)
+			}
+			if tc.recorded != (len(recorded) == 1) {
+				t.Fatalf("recorded = %v, want recorded %v", recorded, tc.recorded)
+			}
+			if tc.recorded && recorded[0] != tc.want {
+				t.Fatalf("recorded status %d, want %d", recorded[0], tc.want)
+			}
+		})
+	}
+}
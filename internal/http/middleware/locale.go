@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"saas-api/internal/i18n"
+)
+
+const CtxLocale ctxKey = "locale"
+
+// Locale escolhe o idioma da resposta pelo Accept-Language (padrao pt-BR).
+// A preferencia salva do usuario, quando existe, e aplicada depois por
+// AuthJWT. O idioma vai no Content-Language, que o problem.Write usa para
+// traduzir os erros.
+func Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang, ok := i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+		if !ok {
+			lang = i18n.Default
+		}
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, withLocale(w, r, lang))
+	})
+}
+
+func withLocale(w http.ResponseWriter, r *http.Request, lang i18n.Lang) *http.Request {
+	w.Header().Set("Content-Language", string(lang))
+	return r.WithContext(context.WithValue(r.Context(), CtxLocale, lang))
+}
+
+func GetLocale(ctx context.Context) i18n.Lang {
+	if v, ok := ctx.Value(CtxLocale).(i18n.Lang); ok {
+		return v
+	}
+	return i18n.Default
+}
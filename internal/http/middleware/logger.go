@@ -1,22 +1,99 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"runtime/debug"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+
+	"saas-api/internal/http/problem"
 )
 
-func AccessLog(log zerolog.Logger) func(http.Handler) http.Handler {
+// AccessLog coloca no contexto um logger com o request_id (os handlers usam
+// Logger(ctx)) e registra cada requisicao com rota, status, bytes e latencia.
+// tenant_id e user_id entram no logger quando a autenticacao roda. Precisa
+// vir depois de RequestID (e de Tracing, para levar o trace_id).
+func AccessLog(base zerolog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
-			next.ServeHTTP(w, r)
-			log.Info().
-				Str("method", r.Method).
+			lc := base.With().Str("request_id", GetRequestID(r.Context()))
+			if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+				lc = lc.Str("trace_id", sc.TraceID().String())
+			}
+			l := lc.Logger()
+			r = r.WithContext(l.WithContext(r.Context()))
+			// o ponteiro no contexto e o mesmo que a autenticacao atualiza
+			reqLog := zerolog.Ctx(r.Context())
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			ev := reqLog.Info()
+			switch {
+			case sw.status >= 500:
+				ev = reqLog.Error()
+			case sw.status >= 400:
+				ev = reqLog.Warn()
+			}
+			ev.Str("method", r.Method).
+				Str("route", route).
 				Str("path", r.URL.Path).
-				Dur("took", time.Since(start)).
+				Int("status", sw.status).
+				Int("bytes", sw.bytes).
+				Dur("latency", time.Since(start)).
 				Msg("request")
 		})
 	}
 }
+
+// Recoverer transforma panic em 500 (problem+json com o request_id) e loga
+// a pilha. Fica depois de AccessLog para a requisicao aparecer com status 500.
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			Logger(r.Context()).Error().
+				Interface("panic", rec).
+				Str("stack", string(debug.Stack())).
+				Msg("panic recovered")
+			// com a resposta ja iniciada so resta cortar a conexao
+			if !sw.wroteHeader {
+				writeJSONError(sw, http.StatusInternalServerError, problem.CodeInternal, "")
+			}
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+// Logger devolve o logger da requisicao ou, fora de uma (jobs), o global.
+func Logger(ctx context.Context) *zerolog.Logger {
+	if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+		return l
+	}
+	return &log.Logger
+}
+
+// logIdentity acrescenta tenant_id e user_id ao logger da requisicao (que
+// tambem e o usado pelo AccessLog).
+func logIdentity(ctx context.Context, tenantID, userID uint64) {
+	zerolog.Ctx(ctx).UpdateContext(func(c zerolog.Context) zerolog.Context {
+		return c.Uint64("tenant_id", tenantID).Uint64("user_id", userID)
+	})
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"saas-api/internal/http/problem"
+)
+
+func TestAccessLogAndRecoverer(t *testing.T) {
+	var buf bytes.Buffer
+	r := chi.NewRouter()
+	r.Use(RequestID, AccessLog(zerolog.New(&buf)), Recoverer)
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), CtxTenantID, uint64(3))
+			logIdentity(ctx, 3, 9)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	r.With(auth).Get("/v1/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		Logger(r.Context()).Info().Msg("handler")
+		_, _ = w.Write([]byte("ok"))
+	})
+	r.Get("/boom", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+
+	cases := []struct {
+		name   string
+		path   string
+		status int
+		route  string
+		tenant bool
+	}{
+		{"ok", "/v1/items/42", 200, "/v1/items/{id}", true},
+		{"panic", "/boom", 500, "/boom", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set(problem.RequestIDHeader, "req-"+tc.name+"-0001")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.status)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			for _, line := range lines {
+				if !strings.Contains(line, `"request_id":"req-`+tc.name+`-0001"`) {
+					t.Fatalf("log without request_id: %s", line)
+				}
+			}
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry["route"] != tc.route || entry["status"] != float64(tc.status) {
+				t.Fatalf("access log = %v", entry)
+			}
+			if _, ok := entry["tenant_id"]; ok != tc.tenant {
+				t.Fatalf("tenant_id present = %v, want %v", ok, tc.tenant)
+			}
+		})
+	}
+
+	t.Run("panic body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/boom", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		var p problem.Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Code != "internal_error" || p.RequestID == "" || p.RequestID != rec.Header().Get(problem.RequestIDHeader) {
+			t.Fatalf("problem = %+v", p)
+		}
+	})
+}
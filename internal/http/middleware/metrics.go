@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"saas-api/internal/metrics"
+)
+
+// Metrics alimenta o histograma HTTP com o padrao da rota do chi. Rotas nao
+// encontradas entram como "unmatched" para nao criar uma serie por path.
+// Fica antes de Recoverer para contar os panics como 500.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		metrics.ObserveHTTP(r.Method, route, sw.status, time.Since(start))
+	})
+}
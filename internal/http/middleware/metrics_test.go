@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"saas-api/internal/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Metrics, Recoverer)
+	r.Get("/v1/things/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.Get("/v1/crash", func(http.ResponseWriter, *http.Request) {
+		panic("crash")
+	})
+
+	for _, path := range []string{"/v1/things/1", "/v1/things/2", "/v1/crash", "/v1/nope/123"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`http_request_duration_seconds_count{method="GET",route="/v1/things/{id}",status="204"} 2`,
+		`http_request_duration_seconds_count{method="GET",route="/v1/crash",status="500"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+	if strings.Contains(string(body), "/v1/nope/123") {
+		t.Error("unmatched path leaked into labels")
+	}
+}
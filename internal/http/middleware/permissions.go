@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"saas-api/internal/http/problem"
+)
+
+const CtxPermissions ctxKey = "permissions"
+
+// Catalogo de permissoes (recurso:acao). Roles embutidos e customizados do
+// tenant sao conjuntos destas permissoes; API keys recebem um subconjunto.
+const (
+	PermHRStructureRead  = "hr-structure:read"
+	PermHRStructureWrite = "hr-structure:write"
+
+	PermEmployeesRead    = "employees:read"
+	PermEmployeesWrite   = "employees:write"
+	PermEmployeesSalary  = "employees:salary"
+	PermEmployeeAccounts = "employee-accounts:write"
+
+	PermBenefitsRead  = "benefits:read"
+	PermBenefitsWrite = "benefits:write"
+
+	PermTimeOffRead    = "time-off:read"
+	PermTimeOffWrite   = "time-off:write"
+	PermTimeOffApprove = "time-off:approve"
+
+	PermTimeEntriesRead    = "time-entries:read"
+	PermClockifyManage     = "clockify:manage"
+	PermClockifySync       = "clockify:sync"
+	PermClockifySyncClosed = "clockify:sync-closed-period"
+
+	PermTimeBankRead    = "time-bank:read"
+	PermTimeBankWrite   = "time-bank:write"
+	PermTimeBankApprove = "time-bank:approve"
+	PermTimeBankClose   = "time-bank:close"
+
+	PermVendorsRead     = "vendors:read"
+	PermVendorsWrite    = "vendors:write"
+	PermPayablesRead    = "payables:read"
+	PermPayablesWrite   = "payables:write"
+	PermPayablesApprove = "payables:approve"
+	PermPayablesPay     = "payables:pay"
+
+	PermCustomersRead      = "customers:read"
+	PermCustomersWrite     = "customers:write"
+	PermReceivablesRead    = "receivables:read"
+	PermReceivablesWrite   = "receivables:write"
+	PermReceivablesReceive = "receivables:receive"
+
+	PermCostCentersRead      = "cost-centers:read"
+	PermCostCentersWrite     = "cost-centers:write"
+	PermFinanceDashboardRead = "finance-dashboard:read"
+
+	PermAuditLogsRead = "audit-logs:read"
+
+	PermInvitationsManage    = "invitations:manage"
+	PermMembersManage        = "members:manage"
+	PermRolesManage          = "roles:manage"
+	PermAPIKeysManage        = "api-keys:manage"
+	PermTenantSecurityManage = "tenant-security:manage"
+)
+
+type PermissionInfo struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	// OwnerOnly: administrativa, nao pode ir para roles customizados.
+	OwnerOnly bool `json:"owner_only,omitempty"`
+}
+
+var PermissionCatalog = []PermissionInfo{
+	{Key: PermHRStructureRead, Description: "Ver departamentos, cargos, locais e times"},
+	{Key: PermHRStructureWrite, Description: "Cadastrar departamentos, cargos, locais e times"},
+	{Key: PermEmployeesRead, Description: "Ver funcionarios, beneficios vinculados e documentos"},
+	{Key: PermEmployeesWrite, Description: "Cadastrar e alterar funcionarios, beneficios vinculados e documentos"},
+	{Key: PermEmployeesSalary, Description: "Ver e alterar salary_cents e historico de remuneracao"},
+	{Key: PermEmployeeAccounts, Description: "Provisionar conta de colaborador para funcionario"},
+	{Key: PermBenefitsRead, Description: "Ver catalogo de beneficios"},
+	{Key: PermBenefitsWrite, Description: "Cadastrar beneficios"},
+	{Key: PermTimeOffRead, Description: "Ver tipos e solicitacoes de ausencia"},
+	{Key: PermTimeOffWrite, Description: "Cadastrar tipos e criar/cancelar solicitacoes de ausencia"},
+	{Key: PermTimeOffApprove, Description: "Aprovar e rejeitar ausencias"},
+	{Key: PermTimeEntriesRead, Description: "Ver batidas de ponto"},
+	{Key: PermClockifyManage, Description: "Configurar integracao Clockify"},
+	{Key: PermClockifySync, Description: "Sincronizar Clockify e ver status"},
+	{Key: PermClockifySyncClosed, Description: "Sincronizar Clockify em periodo fechado"},
+	{Key: PermTimeBankRead, Description: "Ver banco de horas, fechamentos e exportacoes"},
+	{Key: PermTimeBankWrite, Description: "Configurar banco de horas e lancar ajustes"},
+	{Key: PermTimeBankApprove, Description: "Aprovar e rejeitar ajustes de banco de horas"},
+	{Key: PermTimeBankClose, Description: "Fechar e reabrir periodos de banco de horas"},
+	{Key: PermVendorsRead, Description: "Ver fornecedores"},
+	{Key: PermVendorsWrite, Description: "Cadastrar fornecedores"},
+	{Key: PermPayablesRead, Description: "Ver contas a pagar e historico"},
+	{Key: PermPayablesWrite, Description: "Criar, editar e enviar contas a pagar"},
+	{Key: PermPayablesApprove, Description: "Aprovar e rejeitar contas a pagar"},
+	{Key: PermPayablesPay, Description: "Marcar contas a pagar como pagas"},
+	{Key: PermCustomersRead, Description: "Ver clientes"},
+	{Key: PermCustomersWrite, Description: "Cadastrar clientes"},
+	{Key: PermReceivablesRead, Description: "Ver contas a receber e historico"},
+	{Key: PermReceivablesWrite, Description: "Criar, editar, emitir e cancelar contas a receber"},
+	{Key: PermReceivablesReceive, Description: "Marcar contas a receber como recebidas"},
+	{Key: PermCostCentersRead, Description: "Ver centros de custo"},
+	{Key: PermCostCentersWrite, Description: "Cadastrar centros de custo"},
+	{Key: PermFinanceDashboardRead, Description: "Ver dashboard financeiro"},
+	{Key: PermAuditLogsRead, Description: "Consultar e exportar a trilha de auditoria"},
+	{Key: PermInvitationsManage, Description: "Listar, reenviar e revogar convites de colaborador"},
+	{Key: PermMembersManage, Description: "Gerenciar membros e convites de qualquer role", OwnerOnly: true},
+	{Key: PermRolesManage, Description: "Gerenciar roles customizados", OwnerOnly: true},
+	{Key: PermAPIKeysManage, Description: "Gerenciar API keys", OwnerOnly: true},
+	{Key: PermTenantSecurityManage, Description: "Alterar politicas de seguranca do tenant", OwnerOnly: true},
+}
+
+var permissionIndex = func() map[string]PermissionInfo {
+	m := make(map[string]PermissionInfo, len(PermissionCatalog))
+	for _, p := range PermissionCatalog {
+		m[p.Key] = p
+	}
+	return m
+}()
+
+func LookupPermission(key string) (PermissionInfo, bool) {
+	p, ok := permissionIndex[key]
+	return p, ok
+}
+
+func GetPermissions(ctx context.Context) []string {
+	v, _ := ctx.Value(CtxPermissions).([]string)
+	return v
+}
+
+func HasPermission(ctx context.Context, perm string) bool {
+	for _, p := range GetPermissions(ctx) {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission libera a rota se o usuario (pelo role) ou a API key (pelos
+// scopes) tiver a permissao.
+func RequirePermission(perm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasPermission(r.Context(), perm) {
+				writeJSONError(w, http.StatusForbidden, problem.CodeMissingPermission, "missing permission "+perm, perm)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
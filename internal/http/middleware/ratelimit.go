@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"saas-api/internal/http/problem"
+	"saas-api/internal/metrics"
+)
+
+// Classes de rota do rate limit. Sem entrada em RateLimitOptions.Routes a
+// classe sai do metodo (GET/HEAD = read, resto = write).
+const (
+	RateClassRead  = "read"
+	RateClassWrite = "write"
+	RateClassHeavy = "heavy" // listagens grandes e exportacoes
+	RateClassSync  = "sync"  // sync manual do Clockify
+)
+
+// RateLimitRule e um token bucket: ate Limit requisicoes de uma vez, repostas
+// continuamente ao longo de Window.
+type RateLimitRule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitResult e o estado do bucket depois de Take.
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter e o tempo ate o proximo token (so quando !Allowed).
+	RetryAfter time.Duration
+	// Reset e o tempo ate o bucket encher de novo.
+	Reset time.Duration
+}
+
+// RateLimitStore consome um token do bucket da key (memoria ou MySQL, este em
+// handlers). Refund devolve um token consumido, sem passar de rule.Limit.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error)
+	Refund(ctx context.Context, key string, rule RateLimitRule) error
+}
+
+// RateLimitPlanLookup devolve o plano do tenant (tenants.plan).
+type RateLimitPlanLookup func(ctx context.Context, tenantID uint64) (string, error)
+
+type RateLimitOptions struct {
+	Store RateLimitStore
+	// Rules por classe; o limite vale por usuario (ou API key).
+	Rules map[string]RateLimitRule
+	// Routes troca a classe de rotas especificas: "METODO /padrao/do/chi".
+	Routes map[string]string
+	// TenantMultiplier da o bucket do tenant inteiro: limite do usuario x fator.
+	TenantMultiplier float64
+	// PlanMultipliers escala os limites pelo plano (plano ausente = 1).
+	PlanMultipliers map[string]float64
+	Plan            RateLimitPlanLookup
+	// PlanCacheTTL evita uma query por requisicao (1 min quando zero).
+	PlanCacheTTL time.Duration
+}
+
+// RateLimit limita as requisicoes autenticadas por usuario e por tenant, na
+// classe da rota. Estourou: 429 com Retry-After, e o token ja tirado do bucket
+// do usuario volta (o 429 do tenant nao gasta a cota de quem nao abusou). Toda resposta leva
+// RateLimit-Limit/Remaining/Reset do bucket mais apertado. Falha no store
+// deixa passar (o limite nao derruba a API). Precisa rodar depois da
+// autenticacao e antes de Idempotency.
+func RateLimit(opts RateLimitOptions) func(http.Handler) http.Handler {
+	if opts.TenantMultiplier <= 0 {
+		opts.TenantMultiplier = 1
+	}
+	plans := &planCache{lookup: opts.Plan, ttl: opts.PlanCacheTTL, items: map[uint64]planEntry{}}
+	if plans.ttl <= 0 {
+		plans.ttl = time.Minute
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			tenantID := GetTenantID(ctx)
+			if tenantID == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			class := rateLimitClass(r, opts.Routes)
+			rule, ok := opts.Rules[class]
+			if !ok || rule.Limit <= 0 || rule.Window <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			plan := plans.get(ctx, tenantID)
+			if f, ok := opts.PlanMultipliers[plan]; ok && f > 0 {
+				rule.Limit = scaleLimit(rule.Limit, f)
+			}
+			tenantRule := RateLimitRule{Limit: scaleLimit(rule.Limit, opts.TenantMultiplier), Window: rule.Window}
+
+			subject := fmt.Sprintf("u%d", GetUserID(ctx))
+			if keyID := GetAPIKeyID(ctx); keyID != 0 {
+				subject = fmt.Sprintf("k%d", keyID)
+			}
+			buckets := []struct {
+				key  string
+				rule RateLimitRule
+			}{
+				{fmt.Sprintf("%s:t%d:%s", class, tenantID, subject), rule},
+				{fmt.Sprintf("%s:t%d", class, tenantID), tenantRule},
+			}
+
+			var shown *RateLimitResult
+			var shownRule RateLimitRule
+			for i, b := range buckets {
+				res, err := opts.Store.Take(ctx, b.key, b.rule)
+				if err != nil {
+					Logger(ctx).Warn().Err(err).Str("bucket", b.key).Msg("rate limit store failed")
+					continue
+				}
+				if shown == nil || !res.Allowed || res.Remaining < shown.Remaining {
+					shown, shownRule = &res, b.rule
+				}
+				if !res.Allowed {
+					// a requisicao nao passa: devolve o que os buckets anteriores cobraram
+					for _, prev := range buckets[:i] {
+						if err := opts.Store.Refund(ctx, prev.key, prev.rule); err != nil {
+							Logger(ctx).Warn().Err(err).Str("bucket", prev.key).Msg("rate limit refund failed")
+						}
+					}
+					break
+				}
+			}
+			if shown == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(shownRule.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(shown.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(shown.Reset)))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", shownRule.Limit, ceilSeconds(shownRule.Window)))
+			if !shown.Allowed {
+				h.Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(shown.RetryAfter))))
+				metrics.RateLimited(class)
+				writeJSONError(w, http.StatusTooManyRequests, problem.CodeRateLimited, "rate limit")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func rateLimitClass(r *http.Request, routes map[string]string) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if c, ok := routes[r.Method+" "+rctx.RoutePattern()]; ok {
+			return c
+		}
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return RateClassRead
+	}
+	return RateClassWrite
+}
+
+func scaleLimit(limit int, f float64) int {
+	return max(1, int(math.Round(float64(limit)*f)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// TakeToken aplica o token bucket: tokens e o saldo gravado ha elapsed. Devolve
+// o novo saldo e o resultado (usado pelos stores).
+func TakeToken(tokens float64, elapsed time.Duration, rule RateLimitRule) (float64, RateLimitResult) {
+	limit := float64(rule.Limit)
+	perSecond := limit / rule.Window.Seconds()
+	if elapsed > 0 {
+		tokens = math.Min(limit, tokens+elapsed.Seconds()*perSecond)
+	}
+	var res RateLimitResult
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - tokens) / perSecond * float64(time.Second))
+	}
+	res.Remaining = int(math.Floor(tokens))
+	res.Reset = time.Duration((limit - tokens) / perSecond * float64(time.Second))
+	return tokens, res
+}
+
+type planEntry struct {
+	plan    string
+	expires time.Time
+}
+
+type planCache struct {
+	lookup RateLimitPlanLookup
+	ttl    time.Duration
+	mu     sync.Mutex
+	items  map[uint64]planEntry
+}
+
+func (c *planCache) get(ctx context.Context, tenantID uint64) string {
+	if c.lookup == nil {
+		return ""
+	}
+	now := time.Now()
+	c.mu.Lock()
+	e, ok := c.items[tenantID]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.plan
+	}
+	plan, err := c.lookup(ctx, tenantID)
+	if err != nil {
+		// sem plano vale o limite base; tenta de novo na proxima
+		Logger(ctx).Warn().Err(err).Msg("rate limit plan lookup failed")
+		return ""
+	}
+	c.mu.Lock()
+	c.items[tenantID] = planEntry{plan: plan, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return plan
+}
+
+// MemoryRateLimitStore guarda os buckets no processo (cada instancia conta
+// sozinha).
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+	window  time.Duration
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: map[string]memoryBucket{}, lastSweep: time.Now()}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// bucket parado por uma janela inteira ja esta cheio: pode sair do mapa
+	if now.Sub(s.lastSweep) >= time.Minute {
+		for k, b := range s.buckets {
+			if now.Sub(b.updated) >= b.window {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = memoryBucket{tokens: float64(rule.Limit), updated: now}
+	}
+	tokens, res := TakeToken(b.tokens, now.Sub(b.updated), rule)
+	s.buckets[key] = memoryBucket{tokens: tokens, updated: now, window: rule.Window}
+	return res, nil
+}
+
+func (s *MemoryRateLimitStore) Refund(_ context.Context, key string, rule RateLimitRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.buckets[key]; ok {
+		b.tokens = math.Min(float64(rule.Limit), b.tokens+1)
+		s.buckets[key] = b
+	}
+	return nil
+}
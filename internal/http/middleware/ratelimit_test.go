@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRateLimit(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit(RateLimitOptions{
+		Store: store,
+		Rules: map[string]RateLimitRule{
+			RateClassRead:  {Limit: 100, Window: time.Minute},
+			RateClassHeavy: {Limit: 2, Window: time.Minute},
+		},
+		Routes:           map[string]string{"GET /v1/time-entries": RateClassHeavy},
+		TenantMultiplier: 1.5,
+		PlanMultipliers:  map[string]float64{"pro": 2},
+		Plan: func(_ context.Context, tenantID uint64) (string, error) {
+			if tenantID == 2 {
+				return "pro", nil
+			}
+			return "trial", nil
+		},
+	})
+
+	r := chi.NewRouter()
+	r.Route("/v1", func(v1 chi.Router) {
+		v1.Group(func(g chi.Router) {
+			g.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					tenant, _ := strconv.ParseUint(r.Header.Get("X-Tenant"), 10, 64)
+					user, _ := strconv.ParseUint(r.Header.Get("X-User"), 10, 64)
+					ctx := context.WithValue(r.Context(), CtxTenantID, tenant)
+					ctx = context.WithValue(ctx, CtxUserID, user)
+					next.ServeHTTP(w, r.WithContext(ctx))
+				})
+			})
+			g.Use(limit)
+			ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+			g.Get("/time-entries", ok)
+			g.Get("/employees", ok)
+		})
+	})
+
+	get := func(path, tenant, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Tenant", tenant)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// usuario 1: 2 requisicoes pesadas por minuto
+	for i, want := range []string{"1", "0"} {
+		w := get("/v1/time-entries", "1", "1")
+		if w.Code != http.StatusOK || w.Header().Get("RateLimit-Remaining") != want {
+			t.Fatalf("call %d: status=%d remaining=%s", i, w.Code, w.Header().Get("RateLimit-Remaining"))
+		}
+	}
+	w := get("/v1/time-entries", "1", "1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" || w.Header().Get("RateLimit-Limit") != "2" {
+		t.Fatalf("limited: status=%d retry=%s limit=%s", w.Code, w.Header().Get("Retry-After"), w.Header().Get("RateLimit-Limit"))
+	}
+	if !strings.Contains(w.Body.String(), `"code":"rate_limited"`) {
+		t.Fatalf("body = %s", w.Body.String())
+	}
+
+	// outra classe tem bucket proprio
+	if w := get("/v1/employees", "1", "1"); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "100" {
+		t.Fatalf("read class: status=%d limit=%s", w.Code, w.Header().Get("RateLimit-Limit"))
+	}
+
+	// o tenant inteiro tem 3 (2 x 1.5): usuario 2 leva so 1
+	if w := get("/v1/time-entries", "1", "2"); w.Code != http.StatusOK {
+		t.Fatalf("user 2 first call: %d", w.Code)
+	}
+	if w := get("/v1/time-entries", "1", "2"); w.Code != http.StatusTooManyRequests || w.Header().Get("RateLimit-Limit") != "3" {
+		t.Fatalf("tenant bucket: status=%d limit=%s", w.Code, w.Header().Get("RateLimit-Limit"))
+	}
+	// o 429 do tenant devolve o token do usuario 2
+	if b := store.buckets["heavy:t1:u2"]; b.tokens < 1 {
+		t.Fatalf("user 2 bucket not refunded: %v", b.tokens)
+	}
+
+	// plano pro dobra o limite
+	for i := 0; i < 4; i++ {
+		if w := get("/v1/time-entries", "2", "1"); w.Code != http.StatusOK {
+			t.Fatalf("pro call %d: %d", i, w.Code)
+		}
+	}
+	if w := get("/v1/time-entries", "2", "1"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("pro over limit: %d", w.Code)
+	}
+}
+
+func TestTakeTokenRefill(t *testing.T) {
+	rule := RateLimitRule{Limit: 60, Window: time.Minute}
+	tokens, res := TakeToken(0, 0, rule)
+	if res.Allowed || res.RetryAfter != time.Second {
+		t.Fatalf("empty bucket: %+v", res)
+	}
+	// 10s repoem 10 tokens, nunca acima do limite
+	if tokens, res = TakeToken(tokens, 10*time.Second, rule); !res.Allowed || res.Remaining != 9 {
+		t.Fatalf("after 10s: %+v", res)
+	}
+	if _, res = TakeToken(tokens, time.Hour, rule); res.Remaining != 59 || res.Reset != time.Second {
+		t.Fatalf("after 1h: %+v", res)
+	}
+}
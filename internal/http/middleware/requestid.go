@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	"saas-api/internal/http/problem"
+)
+
+const CtxRequestID ctxKey = "request_id"
+
+// aceita o id do proxy/cliente so se for curto e sem caracteres estranhos
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{8,64}$`)
+
+// RequestID reaproveita o X-Request-ID recebido ou gera um novo, devolve no
+// header da resposta e guarda no contexto. O header e gravado antes do
+// handler rodar para que as respostas de erro possam incluir o id.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(problem.RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(problem.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), CtxRequestID, id)))
+	})
+}
+
+func GetRequestID(ctx context.Context) string {
+	v, _ := ctx.Value(CtxRequestID).(string)
+	return v
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
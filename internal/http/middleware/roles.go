@@ -1,37 +1,12 @@
 package middleware
 
 import (
-	"encoding/json"
 	"net/http"
-)
-
-func writeJSONError(w http.ResponseWriter, status int, msg string) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"error": msg,
-	})
-}
 
-// RequireRoles permite acessar a rota apenas se o role do usuário estiver na lista.
-func RequireRoles(allowed ...string) func(http.Handler) http.Handler {
-	allowedSet := make(map[string]struct{}, len(allowed))
-	for _, r := range allowed {
-		allowedSet[r] = struct{}{}
-	}
+	"saas-api/internal/http/problem"
+)
 
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			role := GetRole(r.Context())
-			if role == "" {
-				writeJSONError(w, http.StatusUnauthorized, "missing role")
-				return
-			}
-			if _, ok := allowedSet[role]; !ok {
-				writeJSONError(w, http.StatusForbidden, "forbidden")
-				return
-			}
-			next.ServeHTTP(w, r)
-		})
-	}
+// writeJSONError responde no formato problem+json (RFC 7807).
+func writeJSONError(w http.ResponseWriter, status int, code problem.Code, msg string, args ...any) {
+	problem.Error(w, status, code, msg, args...)
 }
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+
+	var handlerTrace trace.SpanContext
+	r := chi.NewRouter()
+	r.Use(RequestID, Tracing)
+	r.Get("/v1/employees/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerTrace = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/employees/7", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	s := spans[0]
+	if s.Name() != "GET /v1/employees/{id}" || s.SpanKind() != trace.SpanKindServer {
+		t.Fatalf("span = %q kind %v", s.Name(), s.SpanKind())
+	}
+	if s.Parent().SpanID().String() != "00f067aa0ba902b7" || s.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("traceparent not continued: parent %s trace %s", s.Parent().SpanID(), s.SpanContext().TraceID())
+	}
+	if handlerTrace.SpanID() != s.SpanContext().SpanID() {
+		t.Fatal("handler context does not carry the server span")
+	}
+	want := map[attribute.Key]attribute.Value{
+		"http.route":                attribute.StringValue("/v1/employees/{id}"),
+		"http.response.status_code": attribute.IntValue(502),
+	}
+	for _, kv := range s.Attributes() {
+		if v, ok := want[kv.Key]; ok && v != kv.Value {
+			t.Fatalf("%s = %v, want %v", kv.Key, kv.Value.Emit(), v.Emit())
+		}
+		delete(want, kv.Key)
+	}
+	if len(want) > 0 {
+		t.Fatalf("missing attributes %v", want)
+	}
+	if s.Status().Code.String() != "Error" {
+		t.Fatalf("status = %v, want Error", s.Status().Code)
+	}
+}
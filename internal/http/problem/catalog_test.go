@@ -0,0 +1,120 @@
+package problem
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"saas-api/internal/i18n"
+)
+
+// posicao do code/regra nas funcoes que respondem erro
+var codeArgs = map[string]int{
+	"writeError":      2,
+	"writeJSONError":  2,
+	"writeFieldError": 2,
+	"problem.New":     1,
+	"problem.Invalid": 1,
+	"problem.Error":   2,
+}
+
+// TestCatalogCoversCodes garante que todo code e regra tem traducao nos tres
+// idiomas e que os handlers e middlewares passam sempre uma constante.
+func TestCatalogCoversCodes(t *testing.T) {
+	fset := token.NewFileSet()
+	keys := map[string]string{}
+	for _, path := range []string{"codes.go", "problem.go"} {
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				typ, _ := vs.Type.(*ast.Ident)
+				if typ == nil || (typ.Name != "Code" && typ.Name != "Rule") {
+					continue
+				}
+				prefix := "error."
+				if typ.Name == "Rule" {
+					prefix = "field."
+				}
+				for i, name := range vs.Names {
+					v, _ := strconv.Unquote(vs.Values[i].(*ast.BasicLit).Value)
+					keys[name.Name] = prefix + v
+				}
+			}
+		}
+	}
+
+	used := map[string]bool{}
+	for _, dir := range []string{"..", "../handlers", "../middleware"} {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, decl := range f.Decls {
+				// os proprios wrappers so repassam o parametro
+				if fd, ok := decl.(*ast.FuncDecl); ok && codeArgs[fd.Name.Name] > 0 {
+					continue
+				}
+				ast.Inspect(decl, func(n ast.Node) bool {
+					call, ok := n.(*ast.CallExpr)
+					if !ok {
+						return true
+					}
+					idx, ok := codeArgs[funcName(call.Fun)]
+					if !ok || idx >= len(call.Args) {
+						return true
+					}
+					sel, ok := call.Args[idx].(*ast.SelectorExpr)
+					if !ok || funcName(sel.X) != "problem" {
+						t.Errorf("%s: code must be a problem constant", fset.Position(call.Pos()))
+						return true
+					}
+					used[sel.Sel.Name] = true
+					return true
+				})
+			}
+		}
+	}
+	if len(used) == 0 {
+		t.Fatal("no call sites found")
+	}
+
+	for name := range used {
+		if _, ok := keys[name]; !ok {
+			t.Errorf("problem.%s is not a Code or Rule constant", name)
+		}
+	}
+	for name, key := range keys {
+		for _, lang := range i18n.Supported {
+			if _, ok := i18n.Lookup(lang, key); !ok {
+				t.Errorf("%s: missing %q (%s)", lang, key, name)
+			}
+		}
+	}
+}
+
+func funcName(e ast.Expr) string {
+	switch x := e.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return funcName(x.X) + "." + x.Sel.Name
+	}
+	return ""
+}
@@ -0,0 +1,240 @@
+package problem
+
+// Code identifica o erro na resposta (campo code e sufixo do type) e e a chave
+// da traducao no catalogo i18n (error.<code>). O frontend trata erros pelo
+// code: nunca renomeie um valor existente.
+type Code string
+
+// Erros gerais
+const (
+	CodeValidation              Code = "validation_failed"
+	CodeInternal                Code = "internal_error"
+	CodeRouteNotFound           Code = "route_not_found"
+	CodeMethodNotAllowed        Code = "method_not_allowed"
+	CodeInvalidJSON             Code = "invalid_json"
+	CodeInvalidFieldType        Code = "invalid_field_type"
+	CodeUnknownField            Code = "unknown_field"
+	CodeInvalidInput            Code = "invalid_input"
+	CodeInvalidID               Code = "invalid_id"
+	CodeInvalidStatus           Code = "invalid_status"
+	CodeInvalidStatusTransition Code = "invalid_status_transition"
+	CodeInvalidCursor           Code = "invalid_cursor"
+	CodeCursorDoesNotMatchSort  Code = "cursor_does_not_match_sort"
+	CodeRelationNotFound        Code = "relation_not_found"
+	CodeDatabase                Code = "database_error"
+	CodeAuditWriteFailed        Code = "audit_write_failed"
+	CodeRateLimited             Code = "rate_limited"
+	CodePreconditionRequired    Code = "precondition_required"
+	CodePreconditionFailed      Code = "precondition_failed"
+	CodeCouldNotReadRequestBody Code = "could_not_read_request_body"
+	CodeInvalidIdempotencyKey   Code = "invalid_idempotency_key"
+	CodeIdempotencyKeyReused    Code = "idempotency_key_reused"
+	CodeIdempotencyInProgress   Code = "idempotency_in_progress"
+	CodeInvalidMetricsToken     Code = "invalid_metrics_token"
+)
+
+// Autenticacao e sessao
+const (
+	CodeMissingBearerToken     Code = "missing_bearer_token"
+	CodeInvalidToken           Code = "invalid_token"
+	CodeInvalidTokenClaims     Code = "invalid_token_claims"
+	CodeSessionRevoked         Code = "session_revoked"
+	CodeInvalidAPIKey          Code = "invalid_api_key"
+	CodeInvalidCredentials     Code = "invalid_credentials"
+	CodeTooManyLoginAttempts   Code = "too_many_login_attempts"
+	CodeKeyError               Code = "key_error"
+	CodeTokenError             Code = "token_error"
+	CodePasswordError          Code = "password_error"
+	CodeFailedToHashPassword   Code = "failed_to_hash_password"
+	CodePasswordChangeRequired Code = "password_change_required"
+	CodeInvalidCurrentPassword Code = "invalid_current_password"
+	CodeInvalidRefreshToken    Code = "invalid_refresh_token"
+	CodeInvalidOrExpiredToken  Code = "invalid_or_expired_token"
+	CodeInvalidOrExpiredCode   Code = "invalid_or_expired_code"
+	CodeValidEmailIsRequired   Code = "valid_email_is_required"
+	CodeNoTenantMembership     Code = "no_tenant_membership"
+	CodeTenantRequired         Code = "tenant_required"
+	CodeInvalidTenantID        Code = "invalid_tenant_id"
+	CodeCouldNotCreateTenant   Code = "could_not_create_tenant"
+	CodeCouldNotCreateUser     Code = "could_not_create_user"
+	CodeFailedToCreateUser     Code = "failed_to_create_user"
+	CodeFailedToLookupUser     Code = "failed_to_lookup_user"
+	CodeFailedToRevokeSessions Code = "failed_to_revoke_sessions"
+	CodeUserNotFound           Code = "user_not_found"
+)
+
+// MFA
+const (
+	CodeInvalidMFACode           Code = "invalid_mfa_code"
+	CodeInvalidOrExpiredMFAToken Code = "invalid_or_expired_mfa_token"
+	CodeMFAChallengeRequired     Code = "mfa_token_and_code_or_recovery_code_are_required"
+	CodeMFAAlreadyEnabled        Code = "mfa_already_enabled"
+	CodeMFANotEnabled            Code = "mfa_not_enabled"
+	CodeMFASetupRequired         Code = "mfa_setup_required"
+	CodeMFAEnrollmentRequired    Code = "mfa_enrollment_required"
+	CodeMFARequiredToEnforce     Code = "enable_mfa_on_your_account_before_requiring_it"
+	CodeMFARequiredToImpersonate Code = "enable_mfa_on_your_account_before_impersonating"
+)
+
+// SSO
+const (
+	CodeSSONotAvailable             Code = "sso_not_available"
+	CodeSSONotConfigured            Code = "sso_not_configured"
+	CodeIdentityProviderUnavailable Code = "identity_provider_unavailable"
+	CodeIssuerDiscoveryFailed       Code = "issuer_discovery_failed"
+	CodeInvalidDomain               Code = "invalid_domain"
+	CodeDefaultRoleCannotBeOwner    Code = "default_role_cannot_be_owner"
+)
+
+// Permissoes e roles
+const (
+	CodeMissingPermission           Code = "missing_permission"
+	CodePlatformAdminRequired       Code = "platform_admin_required"
+	CodeSalaryPermissionRequired    Code = "salary_permission_required"
+	CodeInvalidPermission           Code = "invalid_permission"
+	CodePermissionReservedToOwner   Code = "permission_reserved_to_owner"
+	CodeInvalidRole                 Code = "invalid_role"
+	CodeInvalidDefaultRole          Code = "invalid_default_role"
+	CodeKeyIsReserved               Code = "key_is_reserved"
+	CodeNameTooLong                 Code = "name_too_long"
+	CodeDescriptionTooLong          Code = "description_too_long"
+	CodeRoleNotFound                Code = "role_not_found"
+	CodeRoleAlreadyExists           Code = "role_already_exists"
+	CodeRoleInUse                   Code = "role_is_assigned_to_members_pending_invitations_or_sso_default_role"
+	CodeBuiltinRolesCannotBeChanged Code = "builtin_roles_cannot_be_changed"
+	CodeBuiltinRolesCannotBeDeleted Code = "builtin_roles_cannot_be_deleted"
+	CodeFailedToListRoles           Code = "failed_to_list_roles"
+	CodeFailedToLoadRole            Code = "failed_to_load_role"
+	CodeFailedToCreateRole          Code = "failed_to_create_role"
+	CodeFailedToUpdateRole          Code = "failed_to_update_role"
+	CodeFailedToDeleteRole          Code = "failed_to_delete_role"
+)
+
+// Membros e convites
+const (
+	CodeMemberNotFound              Code = "member_not_found"
+	CodeMembershipNotFound          Code = "membership_not_found"
+	CodeInvalidUserID               Code = "invalid_user_id"
+	CodeUserIsAlreadyAMember        Code = "user_is_already_a_member"
+	CodeUserAlreadyHasElevatedRole  Code = "user_already_has_elevated_role"
+	CodeCollaboratorRoleViaHR       Code = "colaborador_role_must_be_provisioned_by_hr"
+	CodeCannotChangeYourOwnRole     Code = "cannot_change_your_own_role"
+	CodeCannotChangeYourOwnRoleHere Code = "cannot_change_your_own_role_here"
+	CodeCannotDemoteTheLastOwner    Code = "cannot_demote_the_last_owner"
+	CodeCannotRemoveTheLastOwner    Code = "cannot_remove_the_last_owner"
+	CodeCannotRemoveYourself        Code = "cannot_remove_yourself"
+	CodeTenantAndUserRequired       Code = "tenant_id_and_user_id_or_email_are_required"
+	CodeNameIsRequiredForNewUser    Code = "name_is_required_for_new_user"
+	CodeCouldNotCreateMembership    Code = "could_not_create_membership"
+	CodeFailedToListMembers         Code = "failed_to_list_members"
+	CodeFailedToLookupMember        Code = "failed_to_lookup_member"
+	CodeFailedToLoadMembership      Code = "failed_to_load_membership"
+	CodeFailedToUpsertMembership    Code = "failed_to_upsert_membership"
+	CodeFailedToRemoveMember        Code = "failed_to_remove_member"
+	CodeFailedToUnlockMember        Code = "failed_to_unlock_member"
+	CodeInvitationNotFound          Code = "invitation_not_found"
+	CodeInvitationIsNotPending      Code = "invitation_is_not_pending"
+	CodeInvalidOrExpiredInvitation  Code = "invalid_or_expired_invitation"
+	CodeCouldNotCreateInvitation    Code = "could_not_create_invitation"
+	CodeFailedToCreateInvitation    Code = "failed_to_create_invitation"
+	CodeFailedToListInvitations     Code = "failed_to_list_invitations"
+	CodeFailedToLoadInvitation      Code = "failed_to_load_invitation"
+	CodeFailedToUpdateInvitation    Code = "failed_to_update_invitation"
+	CodeFailedToRevokeInvitation    Code = "failed_to_revoke_invitation"
+)
+
+// API keys e impersonacao
+const (
+	CodeAPIKeyNotFound               Code = "api_key_not_found"
+	CodeInvalidScope                 Code = "invalid_scope"
+	CodeFailedToCreateAPIKey         Code = "failed_to_create_api_key"
+	CodeFailedToListAPIKeys          Code = "failed_to_list_api_keys"
+	CodeFailedToLoadAPIKey           Code = "failed_to_load_api_key"
+	CodeFailedToRevokeAPIKey         Code = "failed_to_revoke_api_key"
+	CodeImpersonationNotFound        Code = "impersonation_not_found"
+	CodeImpersonationIsReadOnly      Code = "impersonation_is_read_only"
+	CodeNotAllowedWhileImpersonating Code = "not_allowed_while_impersonating"
+	CodeCannotImpersonateYourself    Code = "cannot_impersonate_yourself"
+)
+
+// RH
+const (
+	CodeEmployeeNotFound             Code = "employee_not_found"
+	CodeInvalidEmployeeID            Code = "invalid_employee_id"
+	CodeEmployeeEmailIsRequired      Code = "employee_email_is_required"
+	CodeEmployeeIsNotActive          Code = "employee_is_not_active"
+	CodeEmployeeIsTerminated         Code = "employee_is_terminated"
+	CodeEmployeeProfileNotLinked     Code = "employee_profile_not_linked_to_user"
+	CodeUserLinkedToOtherEmployee    Code = "user_already_linked_to_another_employee"
+	CodeCouldNotLinkEmployeeAccount  Code = "could_not_link_employee_account"
+	CodeCouldNotCreateEmployee       Code = "could_not_create_employee"
+	CodeCouldNotCreateCompensation   Code = "could_not_create_compensation"
+	CodeCouldNotCreateDepartment     Code = "could_not_create_department"
+	CodeCouldNotCreatePosition       Code = "could_not_create_position"
+	CodeCouldNotCreateTeam           Code = "could_not_create_team"
+	CodeCouldNotCreateLocation       Code = "could_not_create_location"
+	CodeCouldNotCreateDocument       Code = "could_not_create_document"
+	CodeBenefitNotFound              Code = "benefit_not_found"
+	CodeInvalidBenefitID             Code = "invalid_benefit_id"
+	CodeCouldNotCreateBenefit        Code = "could_not_create_benefit"
+	CodeCouldNotAssignBenefit        Code = "could_not_assign_benefit"
+	CodeTimeOffTypeNotFound          Code = "time_off_type_not_found"
+	CodeCouldNotCreateTimeOffType    Code = "could_not_create_time_off_type"
+	CodeTimeOffRequestNotFound       Code = "time_off_request_not_found"
+	CodeCouldNotCreateTimeOffRequest Code = "could_not_create_time_off_request"
+	CodeInvalidRequestID             Code = "invalid_request_id"
+	CodeNoOpenTimeEntryFound         Code = "no_open_time_entry_found"
+	CodeTimeEntryAlreadyOpen         Code = "you_already_have_an_open_time_entry"
+	CodeCouldNotCreateTimeEntry      Code = "could_not_create_time_entry"
+	CodeCouldNotCloseTimeEntry       Code = "could_not_close_time_entry"
+)
+
+// Banco de horas
+const (
+	CodePeriodIsClosedForThisDate        Code = "period_is_closed_for_this_date"
+	CodeInvalidAdjustmentStatus          Code = "adjustment_status_must_be_pending_approved_rejected"
+	CodeDeltaConflict                    Code = "seconds_delta_and_minutes_delta_cannot_be_used_together"
+	CodeDeltaRequired                    Code = "seconds_delta_or_minutes_delta_is_required"
+	CodeTimeBankAdjustmentNotFound       Code = "time_bank_adjustment_not_found"
+	CodeCouldNotCreateTimeBankAdjustment Code = "could_not_create_time_bank_adjustment"
+	CodePendingAdjustmentsInPeriod       Code = "there_are_pending_time_bank_adjustments_in_selected_period"
+	CodeClosedPeriodOverlap              Code = "another_closed_period_overlaps_selected_range"
+	CodeTimeBankClosureNotFound          Code = "time_bank_closure_not_found"
+	CodeEmployeeNotFoundInClosure        Code = "employee_not_found_in_closure"
+	CodeCouldNotCloseTimeBankPeriod      Code = "could_not_close_time_bank_period"
+	CodeCouldNotReopenTimeBankPeriod     Code = "could_not_reopen_time_bank_period"
+	CodeTimeCardPDFFailed                Code = "could_not_generate_time_card_pdf"
+)
+
+// Clockify
+const (
+	CodeClockifyIsNotConfigured       Code = "clockify_is_not_configured"
+	CodeClockifyAPIKeyIsRequired      Code = "clockify_api_key_is_required"
+	CodeClockifyWorkspaceIDIsRequired Code = "clockify_workspace_id_is_required"
+	CodeClockifyAPIKeyIsInvalid       Code = "clockify_api_key_is_invalid"
+	CodeClockifyWorkspaceNotFound     Code = "clockify_workspace_not_found"
+	CodeClockifyRateLimitExceeded     Code = "clockify_rate_limit_exceeded"
+	CodeClockifyRequestFailed         Code = "clockify_request_failed"
+	CodeClockifyConnectionFailed      Code = "clockify_connection_failed"
+	CodeAllowClosedPeriodNotAllowed   Code = "allow_closed_period_not_allowed"
+)
+
+// Financeiro
+const (
+	CodeCostCenterNotFound       Code = "cost_center_not_found"
+	CodeInvalidCostCenterID      Code = "invalid_cost_center_id"
+	CodeCouldNotCreateCostCenter Code = "could_not_create_cost_center"
+	CodeCustomerNotFound         Code = "customer_not_found"
+	CodeCouldNotCreateCustomer   Code = "could_not_create_customer"
+	CodeReceivableNotFound       Code = "receivable_not_found"
+	CodeCouldNotCreateReceivable Code = "could_not_create_receivable"
+	CodeReceivableNotDraft       Code = "only_draft_receivables_can_be_edited"
+	CodeReceivableNotCancelable  Code = "only_draft_or_issued_can_be_canceled"
+	CodeReceivableNotIssued      Code = "only_issued_receivables_can_be_marked_received"
+	CodeVendorNotFound           Code = "vendor_not_found"
+	CodeCouldNotCreateVendor     Code = "could_not_create_vendor"
+	CodePayableNotFound          Code = "payable_not_found"
+	CodeCouldNotCreatePayable    Code = "could_not_create_payable"
+	CodePayableNotDraft          Code = "only_draft_payables_can_be_edited"
+	CodePayableNotApproved       Code = "only_approved_payables_can_be_marked_paid"
+)
@@ -0,0 +1,160 @@
+// Package problem escreve erros da API no formato RFC 7807
+// (application/problem+json) com um code estavel para o frontend, detalhes
+// por campo em erros de validacao e o id da requisicao.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"saas-api/internal/i18n"
+)
+
+const (
+	ContentType     = "application/problem+json"
+	RequestIDHeader = "X-Request-ID"
+	typePrefix      = "urn:saas-api:problem:"
+)
+
+// Rule e a regra de validacao que falhou num campo. O valor e a chave da
+// traducao (field.<regra>); regras parecidas dividem o mesmo code na resposta.
+type Rule string
+
+const (
+	RuleRequired     Rule = "required"
+	RuleUnknownField Rule = "unknown_field"
+	RuleInvalidType  Rule = "invalid_type"
+	RuleDate         Rule = "invalid_date"
+	RuleNumber       Rule = "invalid_number"
+	RuleChoice       Rule = "invalid_choice" // args: opcoes separadas por virgula
+	RuleMinLength    Rule = "min_length"     // args: tamanho minimo
+	RuleMin          Rule = "min"            // args: limite (>=)
+	RuleGreater      Rule = "greater"        // args: limite (>)
+	RuleBetween      Rule = "between"        // args: minimo, maximo
+	RuleAfter        Rule = "after"          // args: outro campo
+	RuleMustDiffer   Rule = "must_differ"    // args: outro campo
+	RuleNonZero      Rule = "non_zero"
+	RuleHTTPSURL     Rule = "https_url"
+	RuleRoleKey      Rule = "role_key"
+)
+
+// ruleCodes agrupa as regras no code do campo; regra fora do mapa usa o
+// proprio nome.
+var ruleCodes = map[Rule]string{
+	RuleMinLength: "too_short",
+	RuleMin:       "out_of_range",
+	RuleGreater:   "out_of_range",
+	RuleBetween:   "out_of_range",
+	RuleAfter:     "invalid",
+	RuleNonZero:   "invalid",
+	RuleHTTPSURL:  "invalid_format",
+	RuleRoleKey:   "invalid_format",
+}
+
+// FieldError aponta o campo do corpo (ou query) que falhou na validacao.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	rule Rule
+	args []any
+}
+
+// Problem e o corpo de erro. Type, Title, Status, Detail e Instance sao os
+// membros da RFC 7807; Code, RequestID e Errors sao extensoes.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+
+	// argumentos da traducao de Detail (error.<code>)
+	args []any
+}
+
+// New monta o problema. detail e a mensagem em ingles devolvida quando o
+// idioma nao tem traducao; args preenchem a traducao do catalogo.
+func New(status int, code Code, detail string, args ...any) *Problem {
+	return &Problem{Status: status, Code: code, Detail: detail, args: args}
+}
+
+// Invalid monta o erro de validacao (400) de um campo. A traducao recebe o
+// nome do campo seguido de args.
+func Invalid(field string, rule Rule, detail string, args ...any) *Problem {
+	code, ok := ruleCodes[rule]
+	if !ok {
+		code = string(rule)
+	}
+	return &Problem{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidation,
+		Detail: detail,
+		Errors: []FieldError{{Field: field, Code: code, Message: detail, rule: rule, args: args}},
+	}
+}
+
+// Error deixa helpers de validacao devolverem o problema como error.
+func (p *Problem) Error() string { return p.Detail }
+
+// Error substitui http.Error com code explicito.
+func Error(w http.ResponseWriter, status int, code Code, detail string, args ...any) {
+	Write(w, New(status, code, detail, args...))
+}
+
+// Write completa type/title/request_id e envia o problema.
+func Write(w http.ResponseWriter, p *Problem) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Code == "" {
+		p.Code = CodeInternal
+	}
+	if p.Type == "" {
+		p.Type = typePrefix + string(p.Code)
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.RequestID == "" {
+		// o middleware RequestID grava o header antes de chamar o handler
+		p.RequestID = w.Header().Get(RequestIDHeader)
+	}
+	// idioma escolhido pelo middleware Locale
+	if lang, ok := i18n.Normalize(w.Header().Get("Content-Language")); ok {
+		p.localize(lang)
+	}
+
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", ContentType+"; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(p)
+}
+
+// localize troca Detail e as mensagens dos campos pelo texto do catalogo.
+// Sem traducao fica a mensagem em ingles do handler. Erro de validacao usa
+// a mensagem do primeiro campo como Detail.
+func (p *Problem) localize(lang i18n.Lang) {
+	for i := range p.Errors {
+		fe := &p.Errors[i]
+		if msg, ok := i18n.Lookup(lang, "field."+string(fe.rule), append([]any{fe.Field}, fe.args...)...); ok {
+			fe.Message = msg
+		}
+	}
+	if p.Code == CodeValidation && len(p.Errors) > 0 {
+		p.Detail = p.Errors[0].Message
+		return
+	}
+	if msg, ok := i18n.Lookup(lang, "error."+string(p.Code), p.args...); ok {
+		p.Detail = msg
+	}
+}
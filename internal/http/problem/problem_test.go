@@ -0,0 +1,96 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestInvalid(t *testing.T) {
+	cases := []struct {
+		rule Rule
+		code string
+	}{
+		{RuleRequired, "required"},
+		{RuleDate, "invalid_date"},
+		{RuleChoice, "invalid_choice"},
+		{RuleMinLength, "too_short"},
+		{RuleMin, "out_of_range"},
+		{RuleBetween, "out_of_range"},
+		{RuleHTTPSURL, "invalid_format"},
+		{RuleNonZero, "invalid"},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.rule), func(t *testing.T) {
+			p := Invalid("name", tc.rule, "name is wrong")
+			want := []FieldError{{Field: "name", Code: tc.code, Message: "name is wrong"}}
+			for i := range p.Errors {
+				p.Errors[i].rule, p.Errors[i].args = "", nil
+			}
+			if p.Status != http.StatusBadRequest || p.Code != CodeValidation || !reflect.DeepEqual(p.Errors, want) {
+				t.Fatalf("problem = %+v", p)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set(RequestIDHeader, "req-12345678")
+	Error(rec, http.StatusNotFound, CodeEmployeeNotFound, "employee not found")
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json; charset=utf-8" {
+		t.Fatalf("content-type = %q", ct)
+	}
+	var got Problem
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{
+		Type:      "urn:saas-api:problem:employee_not_found",
+		Title:     "Not Found",
+		Status:    404,
+		Detail:    "employee not found",
+		Code:      "employee_not_found",
+		RequestID: "req-12345678",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("problem = %+v\nwant      %+v", got, want)
+	}
+}
+
+func TestWriteLocalized(t *testing.T) {
+	cases := []struct {
+		lang          string
+		p             *Problem
+		detail, field string
+	}{
+		{"pt-BR", New(404, CodeEmployeeNotFound, "employee not found"), "colaborador nao encontrado", ""},
+		{"pt-BR", Invalid("name", RuleRequired, "name is required"), "name e obrigatorio", "name e obrigatorio"},
+		{"es", Invalid("foo", RuleUnknownField, "unknown field: foo"), "campo no permitido: foo", "campo no permitido: foo"},
+		{"pt-BR", Invalid("limit", RuleBetween, "limit must be between 1 and 500", 1, 500), "limit deve ficar entre 1 e 500", "limit deve ficar entre 1 e 500"},
+		{"pt-BR", New(403, CodeMissingPermission, "missing permission finance:write", "finance:write"), "permissao ausente: finance:write", ""},
+		{"en", New(404, CodeEmployeeNotFound, "employee not found"), "employee not found", ""},
+		{"pt-BR", New(400, Code("no_such_code"), "some message without key"), "some message without key", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.lang+"/"+tc.p.Detail, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			rec.Header().Set("Content-Language", tc.lang)
+			Write(rec, tc.p)
+
+			var got Problem
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Detail != tc.detail {
+				t.Fatalf("detail = %q, want %q", got.Detail, tc.detail)
+			}
+			if tc.field != "" && (len(got.Errors) != 1 || got.Errors[0].Message != tc.field) {
+				t.Fatalf("errors = %+v, want message %q", got.Errors, tc.field)
+			}
+		})
+	}
+}
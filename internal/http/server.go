@@ -9,21 +9,44 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog"
 
+	"saas-api/internal/config"
 	"saas-api/internal/http/handlers"
 	mw "saas-api/internal/http/middleware"
+	"saas-api/internal/http/problem"
+	"saas-api/internal/jwtkeys"
+	"saas-api/internal/lifecycle"
+	"saas-api/internal/mail"
+	"saas-api/internal/oidc"
 )
 
-func NewRouter(db *sqlx.DB, log zerolog.Logger, jwtSecret []byte, jwtIssuer string, jwtTTLMinutes int) http.Handler {
+func NewRouter(db *sqlx.DB, log zerolog.Logger, cfg config.Config, mailer mail.Mailer, keys *jwtkeys.KeySet, schedulerBeat *handlers.Heartbeat, worker *lifecycle.Worker) http.Handler {
 	r := chi.NewRouter()
+	r.Use(mw.RequestID)
+	r.Use(mw.Tracing)
+	r.Use(mw.AccessLog(log))
+	r.Use(mw.Metrics)
+	r.Use(mw.Recoverer)
+	r.Use(mw.Locale)
+
+	// validado em config.Load
+	trustedProxies, _ := mw.ParseTrustedProxies(cfg.TrustedProxies)
+	r.Use(mw.ClientIP(trustedProxies))
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "Idempotency-Key", "If-Match", "If-None-Match", "X-API-Key", "X-Request-ID", "traceparent", "tracestate"},
+		ExposedHeaders:   []string{"ETag", "Idempotent-Replayed", "Link", "RateLimit-Limit", "RateLimit-Policy", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Next-Cursor", "X-Request-ID"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
+	r.NotFound(func(w http.ResponseWriter, _ *http.Request) {
+		problem.Error(w, http.StatusNotFound, problem.CodeRouteNotFound, "route not found")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, _ *http.Request) {
+		problem.Error(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "method not allowed")
+	})
+
 	// Responde preflight para qualquer rota
 	r.Options("/*", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
@@ -36,157 +59,329 @@ func NewRouter(db *sqlx.DB, log zerolog.Logger, jwtSecret []byte, jwtIssuer stri
 		sw.Handle("/*", http.StripPrefix("/swagger", fs))
 	})
 
+	// chaves publicas para outros servicos validarem os access tokens
+	r.Get("/.well-known/jwks.json", handlers.JWKS(keys))
+
+	// scrape do Prometheus (METRICS_TOKEN protege quando definido)
+	r.Get("/metrics", handlers.Metrics(cfg.MetricsToken))
+
 	r.Route("/v1", func(v1 chi.Router) {
 		v1.Get("/health", handlers.Health)
 
+		// probes do orquestrador: live nao toca no banco, ready checa banco,
+		// migrations e o scheduler do Clockify
+		health := &handlers.HealthHandler{DB: db, Scheduler: schedulerBeat}
+		v1.Get("/health/live", health.Live)
+		v1.Get("/health/ready", health.Ready)
+
 		authH := &handlers.AuthHandler{
-			DB:        db,
-			JWTSecret: jwtSecret,
-			JWTIssuer: jwtIssuer,
-			JWTTTL:    time.Duration(jwtTTLMinutes) * time.Minute,
+			DB:         db,
+			Keys:       keys,
+			JWTIssuer:  cfg.JWTIssuer,
+			JWTTTL:     time.Duration(cfg.JWTTTLMinutes) * time.Minute,
+			RefreshTTL: time.Duration(cfg.JWTRefreshTTLHours) * time.Hour,
+
+			LoginPolicy: handlers.LoginPolicy{
+				MaxFailures:   cfg.LoginMaxFailures,
+				IPMaxFailures: cfg.LoginIPMaxFailures,
+				Lockout:       time.Duration(cfg.LoginLockoutMinutes) * time.Minute,
+			},
+
+			Mailer:           mailer,
+			Worker:           worker,
+			AppBaseURL:       cfg.AppBaseURL,
+			PasswordResetTTL: time.Duration(cfg.PasswordResetTTLMinutes) * time.Minute,
 		}
 
 		// auth publicas
 		v1.Post("/auth/register", authH.Register)
 		v1.Post("/auth/login", authH.Login)
+		v1.Post("/auth/refresh", authH.Refresh)
+		v1.Post("/auth/mfa/verify", authH.VerifyMFA)
+		v1.Post("/auth/password/forgot", authH.ForgotPassword)
+		v1.Post("/auth/password/reset", authH.ResetPassword)
+		v1.Get("/auth/invitations/preview", authH.PreviewInvitation)
+		v1.Post("/auth/invitations/accept", authH.AcceptInvitation)
+
+		sso := &handlers.SSOHandler{
+			DB:                  db,
+			Auth:                authH,
+			OIDC:                oidc.NewClient(nil),
+			APIBaseURL:          cfg.APIBaseURL,
+			AppBaseURL:          cfg.AppBaseURL,
+			AllowInsecureIssuer: cfg.SSOAllowInsecureIssuer,
+		}
+		v1.Get("/auth/sso/{tenant_slug}/start", sso.StartSSO)
+		v1.Get("/auth/sso/callback", sso.SSOCallback)
+		v1.Post("/auth/sso/exchange", sso.ExchangeSSOCode)
+
+		invH := &handlers.InvitationsHandler{
+			DB:         db,
+			Mailer:     mailer,
+			AppBaseURL: cfg.AppBaseURL,
+			TTL:        time.Duration(cfg.InvitationTTLHours) * time.Hour,
+		}
 
-		// protegidas
-		v1.Group(func(pr chi.Router) {
-			pr.Use(mw.AuthJWT(jwtSecret))
-
-			// qualquer usuario autenticado
-			pr.Get("/me", authH.Me)
-			hr := &handlers.HRHandler{DB: db}
-			pr.Get("/time-entries/me", hr.GetMyTimeEntries)
-			pr.Post("/time-entries/clock-in", hr.ClockIn)
-			pr.Post("/time-entries/clock-out", hr.ClockOut)
-
-			// -------------------
-			// RH: owner + hr
-			// -------------------
-			pr.Group(func(r chi.Router) {
-				r.Use(mw.RequireRoles("owner", "hr"))
-
-				r.Post("/departments", hr.CreateDepartment)
-				r.Get("/departments", hr.ListDepartments)
-
-				r.Post("/positions", hr.CreatePosition)
-				r.Get("/positions", hr.ListPositions)
-
-				r.Post("/employees", hr.CreateEmployee)
-				r.Get("/employees", hr.ListEmployees)
-				r.Get("/employees/{id}", hr.GetEmployee)
-				r.Patch("/employees/{id}", hr.UpdateEmployee)
-				r.Patch("/employees/{id}/status", hr.UpdateEmployeeStatus)
-				r.Post("/employees/{id}/compensations", hr.CreateCompensation)
-				r.Get("/employees/{id}/compensations", hr.ListCompensations)
-				r.Post("/employees/{id}/benefits", hr.AssignBenefitToEmployee)
-				r.Get("/employees/{id}/benefits", hr.ListEmployeeBenefits)
-				r.Delete("/employees/{id}/benefits/{benefit_id}", hr.RemoveBenefitFromEmployee)
-				r.Post("/employees/{id}/documents", hr.CreateEmployeeDocument)
-				r.Get("/employees/{id}/documents", hr.ListEmployeeDocuments)
-
-				r.Post("/locations", hr.CreateLocation)
-				r.Get("/locations", hr.ListLocations)
-
-				r.Post("/teams", hr.CreateTeam)
-				r.Get("/teams", hr.ListTeams)
-
-				r.Post("/time-off-types", hr.CreateTimeOffType)
-				r.Get("/time-off-types", hr.ListTimeOffTypes)
-				r.Post("/time-off-requests", hr.CreateTimeOffRequest)
-				r.Get("/time-off-requests", hr.ListTimeOffRequests)
-				r.Patch("/time-off-requests/{id}/approve", hr.ApproveTimeOff)
-				r.Patch("/time-off-requests/{id}/reject", hr.RejectTimeOff)
-				r.Patch("/time-off-requests/{id}/cancel", hr.CancelTimeOff)
-
-				r.Post("/benefits", hr.CreateBenefit)
-				r.Get("/benefits", hr.ListBenefits)
-
-				r.Get("/integrations/clockify", hr.GetClockifyConfig)
-				r.Get("/integrations/clockify/status", hr.GetClockifyStatus)
-				r.Post("/integrations/clockify", hr.UpsertClockifyConfig)
-				r.Post("/integrations/clockify/sync", hr.SyncClockifyEntries)
-				r.Get("/time-entries", hr.ListTimeEntries)
-
-				r.Get("/time-bank/settings", hr.GetTimeBankSettings)
-				r.Put("/time-bank/settings", hr.UpsertTimeBankSettings)
-				r.Get("/time-bank/summary", hr.GetTimeBankSummary)
-				r.Get("/time-bank/adjustments", hr.ListTimeBankAdjustments)
-				r.Post("/time-bank/adjustments", hr.CreateTimeBankAdjustment)
-				r.Post("/time-bank/adjustments/{id}/approve", hr.ApproveTimeBankAdjustment)
-				r.Post("/time-bank/adjustments/{id}/reject", hr.RejectTimeBankAdjustment)
-				r.Get("/time-bank/closures", hr.ListTimeBankClosures)
-				r.Get("/time-bank/closures/{id}/export.csv", hr.ExportTimeBankClosureCSV)
-				r.Get("/time-bank/closures/{id}/cards.pdf", hr.ExportTimeBankClosureCardsPDF)
-				r.Get("/time-bank/closures/{id}/employees", hr.ListTimeBankClosureEmployees)
-				r.Get("/time-bank/closures/{id}/employees/{employee_id}/card.pdf", hr.ExportTimeBankEmployeeCardPDF)
-				r.Get("/time-bank/closures/{id}/employees/{employee_id}/card.csv", hr.ExportTimeBankEmployeeCardCSV)
-				r.Post("/time-bank/closures/close", hr.CloseTimeBankPeriod)
-				r.Post("/time-bank/closures/{id}/reopen", hr.ReopenTimeBankClosure)
+		hr := &handlers.HRHandler{DB: db, Invitations: invH}
+		fin := &handlers.FinanceAPHandler{DB: db}
+		ar := &handlers.FinanceARHandler{DB: db}
+		cc := &handlers.CostCenterHandler{DB: db}
+		dash := &handlers.DashboardHandler{DB: db}
+		mem := &handlers.MembersHandler{DB: db, Invitations: invH}
+		sec := &handlers.TenantSecurityHandler{DB: db}
+		apiKeys := &handlers.APIKeysHandler{DB: db}
+		roles := &handlers.RolesHandler{DB: db}
+		imp := &handlers.ImpersonationHandler{DB: db, Auth: authH}
+		audit := &handlers.AuditLogsHandler{DB: db}
+
+		// limite por usuario e tenant nas rotas autenticadas; rotas caras tem
+		// classe propria (o resto e read/write pelo metodo)
+		rateLimit := func(next http.Handler) http.Handler { return next }
+		if cfg.RateLimitEnabled {
+			var store mw.RateLimitStore = mw.NewMemoryRateLimitStore()
+			if cfg.RateLimitStore == "mysql" {
+				store = &handlers.RateLimitStore{DB: db}
+			}
+			rateLimit = mw.RateLimit(mw.RateLimitOptions{
+				Store: store,
+				Rules: map[string]mw.RateLimitRule{
+					mw.RateClassRead:  {Limit: cfg.RateLimitReadPerMinute, Window: time.Minute},
+					mw.RateClassWrite: {Limit: cfg.RateLimitWritePerMinute, Window: time.Minute},
+					mw.RateClassHeavy: {Limit: cfg.RateLimitHeavyPerMinute, Window: time.Minute},
+					mw.RateClassSync:  {Limit: cfg.RateLimitSyncPerMinute, Window: time.Minute},
+				},
+				Routes: map[string]string{
+					"GET /v1/time-entries":                                             mw.RateClassHeavy,
+					"POST /v1/integrations/clockify/sync":                              mw.RateClassSync,
+					"GET /v1/time-bank/summary":                                        mw.RateClassHeavy,
+					"POST /v1/time-bank/closures/close":                                mw.RateClassHeavy,
+					"GET /v1/time-bank/closures/{id}/export.csv":                       mw.RateClassHeavy,
+					"GET /v1/time-bank/closures/{id}/cards.pdf":                        mw.RateClassHeavy,
+					"GET /v1/time-bank/closures/{id}/employees/{employee_id}/card.pdf": mw.RateClassHeavy,
+					"GET /v1/time-bank/closures/{id}/employees/{employee_id}/card.csv": mw.RateClassHeavy,
+					"GET /v1/audit-logs/export":                                        mw.RateClassHeavy,
+					"GET /v1/audit-logs/verify":                                        mw.RateClassHeavy,
+				},
+				TenantMultiplier: cfg.RateLimitTenantMultiplier,
+				PlanMultipliers:  cfg.RateLimitPlanMultipliers,
+				Plan:             handlers.TenantPlan(db),
 			})
+		}
 
-			// RH-only: provisionar conta de colaborador vinculada ao cadastro de funcionario
-			pr.Group(func(r chi.Router) {
-				r.Use(mw.RequireRoles("hr"))
-				r.Post("/employees/{id}/account", hr.CreateEmployeeAccount)
-			})
+		// retentativas com o mesmo Idempotency-Key repetem a primeira resposta
+		// (menos as rotas cuja resposta traz segredo: nunca gravadas)
+		idempotent := mw.Idempotency(&handlers.IdempotencyStore{DB: db}, time.Duration(cfg.IdempotencyTTLHours)*time.Hour,
+			"POST /v1/auth/switch-tenant",
+			"POST /v1/auth/mfa/recovery-codes",
+			"POST /v1/auth/sso/link",
+			"POST /v1/api-keys",
+			"POST /v1/admin/impersonations",
+		)
+
+		// integracoes: aceitam API key (permissoes = scopes) ou JWT (permissoes do role)
+		v1.Group(func(ak chi.Router) {
+			ak.Use(mw.AuthAPIKey(apiKeys.Authenticate))
+			ak.Use(mw.AuthJWT(keys, authH.ValidateSession))
+			ak.Use(mw.AuditImpersonation(imp.RecordRequest))
+			ak.Use(rateLimit)
+			ak.Use(mw.ImpersonationReadOnly)
+			ak.Use(mw.RequirePasswordChanged)
+			ak.Use(mw.RequireMFAEnrolled)
+			ak.Use(idempotent)
+			can := func(perm string) chi.Router { return ak.With(mw.RequirePermission(perm)) }
+
+			can(mw.PermTimeEntriesRead).Get("/time-entries", hr.ListTimeEntries)
+			can(mw.PermClockifySync).Get("/integrations/clockify/status", hr.GetClockifyStatus)
+			can(mw.PermClockifySync).Post("/integrations/clockify/sync", hr.SyncClockifyEntries)
+
+			can(mw.PermPayablesRead).Get("/payables", fin.ListPayables)
+			can(mw.PermPayablesWrite).Post("/payables", fin.CreatePayable)
+			can(mw.PermPayablesRead).Get("/payables/{id}", fin.GetPayable)
+			can(mw.PermPayablesWrite).Patch("/payables/{id}", fin.UpdatePayable)
+		})
 
-			// -------------------
-			// FINANCEIRO: owner + finance
-			// -------------------
-			pr.Group(func(r chi.Router) {
-				r.Use(mw.RequireRoles("owner", "finance"))
+		// logout fora do bloqueio de escrita: tambem encerra a impersonation
+		v1.Group(func(lo chi.Router) {
+			lo.Use(mw.AuthJWT(keys, authH.ValidateSession))
+			lo.Use(mw.AuditImpersonation(imp.RecordRequest))
+			lo.Post("/auth/logout", authH.Logout)
+		})
 
-				// AP
-				fin := &handlers.FinanceAPHandler{DB: db}
-				r.Post("/vendors", fin.CreateVendor)
-				r.Get("/vendors", fin.ListVendors)
+		// protegidas (somente JWT)
+		v1.Group(func(pr chi.Router) {
+			pr.Use(mw.AuthJWT(keys, authH.ValidateSession))
+			pr.Use(mw.AuditImpersonation(imp.RecordRequest))
+			pr.Use(rateLimit)
+			pr.Use(mw.ImpersonationReadOnly)
 
-				r.Post("/payables", fin.CreatePayable)
-				r.Get("/payables", fin.ListPayables)
-				r.Patch("/payables/{id}", fin.UpdatePayable)
+			// conta do usuario: fora do alcance de quem esta impersonando
+			own := pr.With(mw.DenyImpersonation)
 
-				r.Post("/payables/{id}/submit", fin.SubmitPayable)
-				r.Post("/payables/{id}/approve", fin.ApprovePayable)
-				r.Post("/payables/{id}/reject", fin.RejectPayable)
-				r.Post("/payables/{id}/mark-paid", fin.MarkPaid)
-				r.Get("/payables/{id}/events", fin.ListPayableEvents)
+			// liberadas mesmo com troca de senha ou cadastro de MFA pendente
+			pr.Get("/me", authH.Me)
+			own.Post("/auth/password/change", authH.ChangePassword)
+			pr.Get("/auth/mfa", authH.GetMFAStatus)
+			own.Post("/auth/mfa/setup", authH.SetupMFA)
+			own.Post("/auth/mfa/activate", authH.ActivateMFA)
+
+			pr.Group(func(pr chi.Router) {
+				pr.Use(mw.RequirePasswordChanged)
+				pr.Use(mw.RequireMFAEnrolled)
+				pr.Use(idempotent)
+				can := func(perm string) chi.Router { return pr.With(mw.RequirePermission(perm)) }
+
+				own := pr.With(mw.DenyImpersonation)
+
+				own.Post("/auth/mfa/disable", authH.DisableMFA)
+				own.Post("/auth/mfa/recovery-codes", authH.RegenerateRecoveryCodes)
+
+				// qualquer usuario autenticado
+				pr.Get("/auth/memberships", authH.ListMemberships)
+				own.Post("/auth/switch-tenant", authH.SwitchTenant)
+				// vincula a identidade do SSO do tenant atual a conta logada
+				own.Post("/auth/sso/link", sso.LinkSSO)
+				own.Put("/me/locale", authH.UpdateLocale)
+				pr.Get("/time-entries/me", hr.GetMyTimeEntries)
+				pr.Post("/time-entries/clock-in", hr.ClockIn)
+				pr.Post("/time-entries/clock-out", hr.ClockOut)
+
+				// -------------------
+				// RH
+				// -------------------
+				can(mw.PermHRStructureWrite).Post("/departments", hr.CreateDepartment)
+				can(mw.PermHRStructureRead).Get("/departments", hr.ListDepartments)
+
+				can(mw.PermHRStructureWrite).Post("/positions", hr.CreatePosition)
+				can(mw.PermHRStructureRead).Get("/positions", hr.ListPositions)
+
+				can(mw.PermEmployeesWrite).Post("/employees", hr.CreateEmployee)
+				can(mw.PermEmployeesRead).Get("/employees", hr.ListEmployees)
+				can(mw.PermEmployeesRead).Get("/employees/{id}", hr.GetEmployee)
+				can(mw.PermEmployeesWrite).Patch("/employees/{id}", hr.UpdateEmployee)
+				can(mw.PermEmployeesWrite).Patch("/employees/{id}/status", hr.UpdateEmployeeStatus)
+				can(mw.PermEmployeesSalary).Post("/employees/{id}/compensations", hr.CreateCompensation)
+				can(mw.PermEmployeesSalary).Get("/employees/{id}/compensations", hr.ListCompensations)
+				can(mw.PermEmployeesWrite).Post("/employees/{id}/benefits", hr.AssignBenefitToEmployee)
+				can(mw.PermEmployeesRead).Get("/employees/{id}/benefits", hr.ListEmployeeBenefits)
+				can(mw.PermEmployeesWrite).Delete("/employees/{id}/benefits/{benefit_id}", hr.RemoveBenefitFromEmployee)
+				can(mw.PermEmployeesWrite).Post("/employees/{id}/documents", hr.CreateEmployeeDocument)
+				can(mw.PermEmployeesRead).Get("/employees/{id}/documents", hr.ListEmployeeDocuments)
+				// provisionar conta de colaborador vinculada ao cadastro de funcionario
+				can(mw.PermEmployeeAccounts).Post("/employees/{id}/account", hr.CreateEmployeeAccount)
+
+				can(mw.PermHRStructureWrite).Post("/locations", hr.CreateLocation)
+				can(mw.PermHRStructureRead).Get("/locations", hr.ListLocations)
+
+				can(mw.PermHRStructureWrite).Post("/teams", hr.CreateTeam)
+				can(mw.PermHRStructureRead).Get("/teams", hr.ListTeams)
+
+				can(mw.PermTimeOffWrite).Post("/time-off-types", hr.CreateTimeOffType)
+				can(mw.PermTimeOffRead).Get("/time-off-types", hr.ListTimeOffTypes)
+				can(mw.PermTimeOffWrite).Post("/time-off-requests", hr.CreateTimeOffRequest)
+				can(mw.PermTimeOffRead).Get("/time-off-requests", hr.ListTimeOffRequests)
+				can(mw.PermTimeOffApprove).Patch("/time-off-requests/{id}/approve", hr.ApproveTimeOff)
+				can(mw.PermTimeOffApprove).Patch("/time-off-requests/{id}/reject", hr.RejectTimeOff)
+				can(mw.PermTimeOffWrite).Patch("/time-off-requests/{id}/cancel", hr.CancelTimeOff)
+
+				can(mw.PermBenefitsWrite).Post("/benefits", hr.CreateBenefit)
+				can(mw.PermBenefitsRead).Get("/benefits", hr.ListBenefits)
+
+				// /integrations/clockify/status|sync e /time-entries: grupo de integracoes
+				can(mw.PermClockifyManage).Get("/integrations/clockify", hr.GetClockifyConfig)
+				can(mw.PermClockifyManage).Post("/integrations/clockify", hr.UpsertClockifyConfig)
+
+				can(mw.PermTimeBankRead).Get("/time-bank/settings", hr.GetTimeBankSettings)
+				can(mw.PermTimeBankWrite).Put("/time-bank/settings", hr.UpsertTimeBankSettings)
+				can(mw.PermTimeBankRead).Get("/time-bank/summary", hr.GetTimeBankSummary)
+				can(mw.PermTimeBankRead).Get("/time-bank/adjustments", hr.ListTimeBankAdjustments)
+				can(mw.PermTimeBankWrite).Post("/time-bank/adjustments", hr.CreateTimeBankAdjustment)
+				can(mw.PermTimeBankApprove).Post("/time-bank/adjustments/{id}/approve", hr.ApproveTimeBankAdjustment)
+				can(mw.PermTimeBankApprove).Post("/time-bank/adjustments/{id}/reject", hr.RejectTimeBankAdjustment)
+				can(mw.PermTimeBankRead).Get("/time-bank/closures", hr.ListTimeBankClosures)
+				can(mw.PermTimeBankRead).Get("/time-bank/closures/{id}/export.csv", hr.ExportTimeBankClosureCSV)
+				can(mw.PermTimeBankRead).Get("/time-bank/closures/{id}/cards.pdf", hr.ExportTimeBankClosureCardsPDF)
+				can(mw.PermTimeBankRead).Get("/time-bank/closures/{id}/employees", hr.ListTimeBankClosureEmployees)
+				can(mw.PermTimeBankRead).Get("/time-bank/closures/{id}/employees/{employee_id}/card.pdf", hr.ExportTimeBankEmployeeCardPDF)
+				can(mw.PermTimeBankRead).Get("/time-bank/closures/{id}/employees/{employee_id}/card.csv", hr.ExportTimeBankEmployeeCardCSV)
+				can(mw.PermTimeBankClose).Post("/time-bank/closures/close", hr.CloseTimeBankPeriod)
+				can(mw.PermTimeBankClose).Post("/time-bank/closures/{id}/reopen", hr.ReopenTimeBankClosure)
+
+				// convites: com members:manage ve todos; sem, so os de colaborador
+				can(mw.PermInvitationsManage).Get("/invitations", invH.ListInvitations)
+				can(mw.PermInvitationsManage).Post("/invitations/{id}/resend", invH.ResendInvitation)
+				can(mw.PermInvitationsManage).Post("/invitations/{id}/revoke", invH.RevokeInvitation)
+
+				// -------------------
+				// FINANCEIRO
+				// -------------------
+				// AP (GET/POST /payables, GET/PATCH /payables/{id}: grupo de integracoes)
+				can(mw.PermVendorsWrite).Post("/vendors", fin.CreateVendor)
+				can(mw.PermVendorsRead).Get("/vendors", fin.ListVendors)
+
+				can(mw.PermPayablesWrite).Post("/payables/{id}/submit", fin.SubmitPayable)
+				can(mw.PermPayablesApprove).Post("/payables/{id}/approve", fin.ApprovePayable)
+				can(mw.PermPayablesApprove).Post("/payables/{id}/reject", fin.RejectPayable)
+				can(mw.PermPayablesPay).Post("/payables/{id}/mark-paid", fin.MarkPaid)
+				can(mw.PermPayablesRead).Get("/payables/{id}/events", fin.ListPayableEvents)
 
 				// AR
-				ar := &handlers.FinanceARHandler{DB: db}
-				r.Post("/customers", ar.CreateCustomer)
-				r.Get("/customers", ar.ListCustomers)
-
-				r.Post("/receivables", ar.CreateReceivable)
-				r.Get("/receivables", ar.ListReceivables)
-				r.Patch("/receivables/{id}", ar.UpdateReceivable)
-
-				r.Post("/receivables/{id}/issue", ar.IssueReceivable)
-				r.Post("/receivables/{id}/cancel", ar.CancelReceivable)
-				r.Post("/receivables/{id}/mark-received", ar.MarkReceived)
-				r.Get("/receivables/{id}/events", ar.ListReceivableEvents)
-
-				// Cost centers + dashboard (finance)
-				cc := &handlers.CostCenterHandler{DB: db}
-				r.Post("/cost-centers", cc.Create)
-				r.Get("/cost-centers", cc.List)
-
-				dash := &handlers.DashboardHandler{DB: db}
-				r.Get("/dashboard/finance/summary", dash.FinanceSummary)
+				can(mw.PermCustomersWrite).Post("/customers", ar.CreateCustomer)
+				can(mw.PermCustomersRead).Get("/customers", ar.ListCustomers)
+
+				can(mw.PermReceivablesWrite).Post("/receivables", ar.CreateReceivable)
+				can(mw.PermReceivablesRead).Get("/receivables", ar.ListReceivables)
+				can(mw.PermReceivablesRead).Get("/receivables/{id}", ar.GetReceivable)
+				can(mw.PermReceivablesWrite).Patch("/receivables/{id}", ar.UpdateReceivable)
+
+				can(mw.PermReceivablesWrite).Post("/receivables/{id}/issue", ar.IssueReceivable)
+				can(mw.PermReceivablesWrite).Post("/receivables/{id}/cancel", ar.CancelReceivable)
+				can(mw.PermReceivablesReceive).Post("/receivables/{id}/mark-received", ar.MarkReceived)
+				can(mw.PermReceivablesRead).Get("/receivables/{id}/events", ar.ListReceivableEvents)
+
+				// Cost centers + dashboard
+				can(mw.PermCostCentersWrite).Post("/cost-centers", cc.Create)
+				can(mw.PermCostCentersRead).Get("/cost-centers", cc.List)
+
+				can(mw.PermFinanceDashboardRead).Get("/dashboard/finance/summary", dash.FinanceSummary)
+
+				// -------------------
+				// ADMINISTRACAO (permissoes exclusivas do owner)
+				// -------------------
+				can(mw.PermMembersManage).Get("/members", mem.ListMembers)
+				can(mw.PermMembersManage).Post("/members", mem.CreateMember)
+				can(mw.PermMembersManage).Patch("/members/{user_id}", mem.UpdateMemberRole)
+				can(mw.PermMembersManage).Delete("/members/{user_id}", mem.RemoveMember)
+				can(mw.PermMembersManage).Post("/members/{user_id}/unlock", mem.UnlockMember)
+				can(mw.PermMembersManage).Post("/invitations", invH.CreateInvitation)
+
+				can(mw.PermRolesManage).Get("/permissions", roles.ListPermissions)
+				can(mw.PermRolesManage).Get("/roles", roles.ListRoles)
+				can(mw.PermRolesManage).Post("/roles", roles.CreateRole)
+				can(mw.PermRolesManage).Put("/roles/{key}", roles.UpdateRole)
+				can(mw.PermRolesManage).Delete("/roles/{key}", roles.DeleteRole)
+
+				can(mw.PermAuditLogsRead).Get("/audit-logs", audit.ListAuditLogs)
+				can(mw.PermAuditLogsRead).Get("/audit-logs/export", audit.ExportAuditLogs)
+				can(mw.PermAuditLogsRead).Get("/audit-logs/verify", audit.VerifyAuditChain)
+
+				can(mw.PermAPIKeysManage).Get("/api-keys", apiKeys.ListAPIKeys)
+				can(mw.PermAPIKeysManage).Post("/api-keys", apiKeys.CreateAPIKey)
+				can(mw.PermAPIKeysManage).Delete("/api-keys/{id}", apiKeys.RevokeAPIKey)
+
+				can(mw.PermTenantSecurityManage).Get("/tenant/security", sec.GetSecuritySettings)
+				can(mw.PermTenantSecurityManage).Put("/tenant/security", sec.UpdateSecuritySettings)
+				can(mw.PermTenantSecurityManage).Get("/tenant/sso", sso.GetSSOConfig)
+				can(mw.PermTenantSecurityManage).Put("/tenant/sso", sso.UpdateSSOConfig)
+				can(mw.PermTenantSecurityManage).Delete("/tenant/sso", sso.DeleteSSOConfig)
+
+				// suporte da plataforma (fora das memberships)
+				pr.Route("/admin", func(adm chi.Router) {
+					adm.Use(mw.RequirePlatformAdmin(imp.IsPlatformAdmin))
+					adm.Post("/impersonations", imp.StartImpersonation)
+					adm.Get("/impersonations", imp.ListImpersonations)
+					adm.Delete("/impersonations/{id}", imp.EndImpersonation)
+				})
 			})
-
-			// owner-only: gerenciar membros do tenant
-			pr.Group(func(r chi.Router) {
-				r.Use(mw.RequireRoles("owner"))
-
-				mem := &handlers.MembersHandler{DB: db}
-				r.Get("/members", mem.ListMembers)
-				r.Post("/members", mem.CreateMember)
-				r.Patch("/members/{user_id}", mem.UpdateMemberRole)
-				r.Delete("/members/{user_id}", mem.RemoveMember)
-			})
-
 		})
 	})
 
@@ -0,0 +1,114 @@
+// Package i18n guarda o catalogo de mensagens da API (pt-BR, en, es) indexado
+// por chaves estaveis e escolhe o idioma a partir do Accept-Language. Erros
+// usam error.<code> e field.<regra>, com os codes e regras do pacote problem.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Lang string
+
+const (
+	PtBR Lang = "pt-BR"
+	En   Lang = "en"
+	Es   Lang = "es"
+
+	// Default e usado quando nem o usuario nem o Accept-Language escolhem um
+	// idioma suportado.
+	Default = PtBR
+)
+
+var Supported = []Lang{PtBR, En, Es}
+
+var catalogs = map[Lang]map[string]string{
+	PtBR: ptBR,
+	En:   en,
+	Es:   es,
+}
+
+// Normalize aceita tags como "pt", "pt-BR", "pt_br", "en-US" ou "es-419" e
+// devolve o idioma suportado correspondente (pelo idioma primario).
+func Normalize(tag string) (Lang, bool) {
+	tag = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, "_", "-")))
+	primary, _, _ := strings.Cut(tag, "-")
+	switch primary {
+	case "pt":
+		return PtBR, true
+	case "en":
+		return En, true
+	case "es":
+		return Es, true
+	}
+	return "", false
+}
+
+// FromAcceptLanguage escolhe o idioma suportado de maior peso (q) no header.
+// Empate fica com o que aparece primeiro.
+func FromAcceptLanguage(header string) (Lang, bool) {
+	type option struct {
+		lang Lang
+		q    float64
+		pos  int
+	}
+	var opts []option
+	for i, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, ok := Normalize(tag)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		opts = append(opts, option{lang, q, i})
+	}
+	if len(opts) == 0 {
+		return "", false
+	}
+	sort.SliceStable(opts, func(i, j int) bool { return opts[i].q > opts[j].q })
+	return opts[0].lang, true
+}
+
+// Lookup devolve a mensagem da chave no idioma, formatada com args
+// (fmt.Sprintf). ok=false quando o idioma nao tem a chave.
+func Lookup(lang Lang, key string, args ...any) (string, bool) {
+	msg, ok := catalogs[lang][key]
+	if !ok {
+		return "", false
+	}
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	return msg, true
+}
+
+// T e o Lookup com fallback para ingles e, por fim, a propria chave.
+func T(lang Lang, key string, args ...any) string {
+	if msg, ok := Lookup(lang, key, args...); ok {
+		return msg
+	}
+	if msg, ok := Lookup(En, key, args...); ok {
+		return msg
+	}
+	return key
+}
+
+// DateLayout e o formato de data curta usado em relatorios (PDF/CSV).
+func DateLayout(lang Lang) string {
+	if lang == En {
+		return "2006-01-02"
+	}
+	return "02/01/2006"
+}
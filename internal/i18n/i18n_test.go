@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalogParity(t *testing.T) {
+	for _, lang := range Supported {
+		cat := catalogs[lang]
+		if len(cat) != len(en) {
+			t.Errorf("%s has %d keys, en has %d", lang, len(cat), len(en))
+		}
+		for key, msg := range en {
+			got, ok := cat[key]
+			if !ok {
+				t.Errorf("%s: missing key %q", lang, key)
+				continue
+			}
+			if strings.Count(got, "%") != strings.Count(msg, "%") {
+				t.Errorf("%s: %q has different format verbs: %q vs %q", lang, key, got, msg)
+			}
+		}
+	}
+}
+
+func TestFromAcceptLanguage(t *testing.T) {
+	cases := []struct {
+		header string
+		want   Lang
+		ok     bool
+	}{
+		{"", "", false},
+		{"fr-FR, de", "", false},
+		{"en-US,en;q=0.9", En, true},
+		{"pt-BR,pt;q=0.9,en;q=0.8", PtBR, true},
+		{"fr;q=1, es;q=0.5, en;q=0.7", En, true},
+		{"es-419, en", Es, true},
+		{"en;q=0, es;q=0.1", Es, true},
+		{"pt_br", PtBR, true},
+	}
+	for _, tc := range cases {
+		got, ok := FromAcceptLanguage(tc.header)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("FromAcceptLanguage(%q) = %q, %v; want %q, %v", tc.header, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(PtBR, "field.required", "name"); got != "name e obrigatorio" {
+		t.Fatalf("T = %q", got)
+	}
+	if got := T(Lang("fr"), "field.required", "name"); got != "name is required" {
+		t.Fatalf("fallback = %q", got)
+	}
+	if got := T(En, "no.such.key"); got != "no.such.key" {
+		t.Fatalf("missing key = %q", got)
+	}
+}
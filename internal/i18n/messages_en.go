@@ -0,0 +1,264 @@
+package i18n
+
+// Mensagens em ingles.
+var en = map[string]string{
+	"field.required":       "%s is required",
+	"field.unknown_field":  "unknown field: %s",
+	"field.invalid_type":   "invalid type for field: %s",
+	"field.invalid_date":   "%s must be YYYY-MM-DD",
+	"field.invalid_number": "%s must be numeric",
+	"field.min_length":     "%s must be at least %v chars",
+	"field.min":            "%s must be >= %v",
+	"field.greater":        "%s must be > %v",
+	"field.between":        "%s must be between %v and %v",
+	"field.invalid_choice": "%s must be one of: %s",
+	"field.must_differ":    "%s must differ from %s",
+	"field.https_url":      "%s must be an https url",
+	"field.role_key":       "%s must be 2-40 chars: a-z, 0-9, '-' or '_'",
+	"field.after":          "%s must be after %s",
+	"field.non_zero":       "%s must be non-zero",
+
+	"error.validation_failed":                                "validation failed",
+	"error.rate_limited":                                     "too many requests, try again later",
+	"error.internal_error":                                   "unexpected internal error",
+	"error.route_not_found":                                  "route not found",
+	"error.method_not_allowed":                               "method not allowed",
+	"error.invalid_json":                                     "invalid json",
+	"error.invalid_field_type":                               "invalid field type",
+	"error.unknown_field":                                    "unknown field",
+	"error.invalid_input":                                    "invalid input",
+	"error.database_error":                                   "database error",
+	"error.audit_write_failed":                               "audit write failed",
+	"error.key_error":                                        "signing key error",
+	"error.token_error":                                      "token error",
+	"error.password_error":                                   "password error",
+	"error.failed_to_hash_password":                          "failed to hash password",
+	"error.missing_bearer_token":                             "missing bearer token",
+	"error.invalid_token":                                    "invalid token",
+	"error.invalid_token_claims":                             "invalid token claims",
+	"error.session_revoked":                                  "session revoked",
+	"error.invalid_refresh_token":                            "invalid refresh token",
+	"error.invalid_credentials":                              "invalid credentials",
+	"error.too_many_login_attempts":                          "too many login attempts, try again later",
+	"error.no_tenant_membership":                             "no tenant membership",
+	"error.password_change_required":                         "password change required",
+	"error.invalid_current_password":                         "invalid current password",
+	"error.invalid_or_expired_token":                         "invalid or expired token",
+	"error.invalid_or_expired_code":                          "invalid or expired code",
+	"error.valid_email_is_required":                          "valid email is required",
+	"error.tenant_required":                                  "tenant_id or tenant_slug is required",
+	"error.invalid_tenant_id":                                "invalid tenant_id",
+	"error.could_not_create_tenant":                          "could not create tenant",
+	"error.could_not_create_user":                            "could not create user",
+	"error.failed_to_create_user":                            "failed to create user",
+	"error.failed_to_revoke_sessions":                        "failed to revoke sessions",
+	"error.invalid_mfa_code":                                 "invalid mfa code",
+	"error.invalid_or_expired_mfa_token":                     "invalid or expired mfa token",
+	"error.mfa_already_enabled":                              "mfa already enabled",
+	"error.mfa_not_enabled":                                  "mfa not enabled",
+	"error.mfa_setup_required":                               "mfa setup required",
+	"error.mfa_enrollment_required":                          "mfa enrollment required",
+	"error.mfa_token_and_code_or_recovery_code_are_required": "mfa_token and code or recovery_code are required",
+	"error.enable_mfa_on_your_account_before_impersonating":  "enable mfa on your account before impersonating",
+	"error.enable_mfa_on_your_account_before_requiring_it":   "enable mfa on your account before requiring it",
+	"error.sso_not_configured":                               "sso not configured",
+	"error.sso_not_available":                                "sso not available",
+	"error.identity_provider_unavailable":                    "identity provider unavailable",
+	"error.issuer_discovery_failed":                          "issuer discovery failed",
+	"error.invalid_domain":                                   "invalid domain: %s",
+	"error.invalid_default_role":                             "invalid default_role",
+	"error.default_role_cannot_be_owner":                     "default_role cannot be owner",
+	"error.missing_permission":                               "missing permission %s",
+	"error.platform_admin_required":                          "platform admin required",
+	"error.invalid_permission":                               "invalid permission: %s",
+	"error.permission_reserved_to_owner":                     "permission reserved to owner: %s",
+	"error.salary_permission_required":                       "salary_cents requires employees:salary permission",
+	"error.invalid_role":                                     "invalid role",
+	"error.role_not_found":                                   "role not found",
+	"error.role_already_exists":                              "role already exists",
+	"error.role_is_assigned_to_members_pending_invitations_or_sso_default_role": "role is assigned to members, pending invitations or sso default role",
+	"error.builtin_roles_cannot_be_changed":                                     "builtin roles cannot be changed",
+	"error.builtin_roles_cannot_be_deleted":                                     "builtin roles cannot be deleted",
+	"error.key_is_reserved":                                                     "key is reserved",
+	"error.name_too_long":                                                       "name too long",
+	"error.description_too_long":                                                "description too long",
+	"error.failed_to_create_role":                                               "failed to create role",
+	"error.failed_to_update_role":                                               "failed to update role",
+	"error.failed_to_delete_role":                                               "failed to delete role",
+	"error.failed_to_list_roles":                                                "failed to list roles",
+	"error.failed_to_load_role":                                                 "failed to load role",
+	"error.colaborador_role_must_be_provisioned_by_hr":                          "colaborador role must be provisioned by hr",
+	"error.member_not_found":                                                    "member not found",
+	"error.membership_not_found":                                                "membership not found",
+	"error.user_not_found":                                                      "user not found",
+	"error.invalid_user_id":                                                     "invalid user_id",
+	"error.invalid_id":                                                          "invalid id",
+	"error.user_is_already_a_member":                                            "user is already a member",
+	"error.name_is_required_for_new_user":                                       "name is required for new user",
+	"error.cannot_change_your_own_role":                                         "cannot change your own role",
+	"error.cannot_change_your_own_role_here":                                    "cannot change your own role here",
+	"error.cannot_demote_the_last_owner":                                        "cannot demote the last owner",
+	"error.cannot_remove_the_last_owner":                                        "cannot remove the last owner",
+	"error.cannot_remove_yourself":                                              "cannot remove yourself",
+	"error.failed_to_list_members":                                              "failed to list members",
+	"error.failed_to_lookup_member":                                             "failed to lookup member",
+	"error.failed_to_lookup_user":                                               "failed to lookup user",
+	"error.failed_to_remove_member":                                             "failed to remove member",
+	"error.failed_to_unlock_member":                                             "failed to unlock member",
+	"error.failed_to_upsert_membership":                                         "failed to upsert membership",
+	"error.failed_to_load_membership":                                           "failed to load membership",
+	"error.could_not_create_membership":                                         "could not create membership",
+	"error.invitation_not_found":                                                "invitation not found",
+	"error.invitation_is_not_pending":                                           "invitation is not pending",
+	"error.invalid_or_expired_invitation":                                       "invalid or expired invitation",
+	"error.could_not_create_invitation":                                         "could not create invitation",
+	"error.failed_to_create_invitation":                                         "failed to create invitation",
+	"error.failed_to_list_invitations":                                          "failed to list invitations",
+	"error.failed_to_load_invitation":                                           "failed to load invitation",
+	"error.failed_to_update_invitation":                                         "failed to update invitation",
+	"error.failed_to_revoke_invitation":                                         "failed to revoke invitation",
+	"error.invalid_api_key":                                                     "invalid api key",
+	"error.api_key_not_found":                                                   "api key not found",
+	"error.invalid_scope":                                                       "invalid scope: %s",
+	"error.failed_to_create_api_key":                                            "failed to create api key",
+	"error.failed_to_list_api_keys":                                             "failed to list api keys",
+	"error.failed_to_load_api_key":                                              "failed to load api key",
+	"error.failed_to_revoke_api_key":                                            "failed to revoke api key",
+	"error.impersonation_not_found":                                             "impersonation not found",
+	"error.impersonation_is_read_only":                                          "impersonation is read-only",
+	"error.not_allowed_while_impersonating":                                     "not allowed while impersonating",
+	"error.cannot_impersonate_yourself":                                         "cannot impersonate yourself",
+	"error.tenant_id_and_user_id_or_email_are_required":                         "tenant_id and user_id or email are required",
+	"error.invalid_cursor":                                                      "invalid cursor",
+	"error.cursor_does_not_match_sort":                                          "cursor does not match sort",
+	"error.invalid_metrics_token":                                               "invalid metrics token",
+	"error.precondition_required":                                               "If-Match header is required",
+	"error.precondition_failed":                                                 "resource was modified by another request",
+	"error.invalid_idempotency_key":                                             "invalid idempotency key",
+	"error.could_not_read_request_body":                                         "could not read request body",
+	"error.idempotency_key_reused":                                              "idempotency key reused with different request",
+	"error.idempotency_in_progress":                                             "request with this idempotency key is in progress",
+	"error.vendor_not_found":                                                    "vendor not found",
+	"error.customer_not_found":                                                  "customer not found",
+	"error.payable_not_found":                                                   "payable not found",
+	"error.receivable_not_found":                                                "receivable not found",
+	"error.cost_center_not_found":                                               "cost center not found",
+	"error.invalid_cost_center_id":                                              "invalid cost_center_id",
+	"error.could_not_create_vendor":                                             "could not create vendor (name may exist)",
+	"error.could_not_create_customer":                                           "could not create customer (name/document may exist)",
+	"error.could_not_create_payable":                                            "could not create payable",
+	"error.could_not_create_receivable":                                         "could not create receivable",
+	"error.could_not_create_cost_center":                                        "could not create cost center (name/code may exist)",
+	"error.only_draft_payables_can_be_edited":                                   "only draft payables can be edited",
+	"error.only_approved_payables_can_be_marked_paid":                           "only approved payables can be marked paid",
+	"error.only_draft_receivables_can_be_edited":                                "only draft receivables can be edited",
+	"error.only_issued_receivables_can_be_marked_received":                      "only issued receivables can be marked received",
+	"error.only_draft_or_issued_can_be_canceled":                                "only draft or issued can be canceled",
+	"error.invalid_status":                                                      "invalid status",
+	"error.invalid_status_transition":                                           "invalid status transition",
+	"error.could_not_create_department":                                         "could not create department (name/code may exist)",
+	"error.could_not_create_position":                                           "could not create position (title may exist, or invalid department_id)",
+	"error.could_not_create_location":                                           "could not create location (name/code may exist)",
+	"error.could_not_create_team":                                               "could not create team (name may exist)",
+	"error.could_not_create_employee":                                           "could not create employee (invalid dept/position?)",
+	"error.could_not_create_compensation":                                       "could not create compensation",
+	"error.invalid_employee_id":                                                 "invalid employee id",
+	"error.employee_not_found":                                                  "employee not found",
+	"error.employee_is_not_active":                                              "employee is not active",
+	"error.employee_is_terminated":                                              "employee is terminated",
+	"error.employee_email_is_required":                                          "employee email is required",
+	"error.employee_profile_not_linked_to_user":                                 "employee profile not linked to user",
+	"error.user_already_has_elevated_role":                                      "user already has elevated role",
+	"error.user_already_linked_to_another_employee":                             "user already linked to another employee",
+	"error.could_not_link_employee_account":                                     "could not link employee account",
+	"error.could_not_create_benefit":                                            "could not create benefit (name may exist)",
+	"error.benefit_not_found":                                                   "benefit not found",
+	"error.invalid_benefit_id":                                                  "invalid benefit id",
+	"error.could_not_assign_benefit":                                            "could not assign benefit (maybe already assigned)",
+	"error.relation_not_found":                                                  "relation not found",
+	"error.could_not_create_document":                                           "could not create document",
+	"error.could_not_create_time_off_type":                                      "could not create time off type (name may exist)",
+	"error.time_off_type_not_found":                                             "time_off_type not found",
+	"error.could_not_create_time_off_request":                                   "could not create time off request",
+	"error.invalid_request_id":                                                  "invalid request id",
+	"error.time_off_request_not_found":                                          "time off request not found",
+	"error.you_already_have_an_open_time_entry":                                 "you already have an open time entry",
+	"error.no_open_time_entry_found":                                            "no open time entry found",
+	"error.could_not_create_time_entry":                                         "could not create time entry",
+	"error.could_not_close_time_entry":                                          "could not close time entry",
+	"error.clockify_api_key_is_required":                                        "clockify api_key is required",
+	"error.clockify_workspace_id_is_required":                                   "clockify workspace_id is required",
+	"error.clockify_is_not_configured":                                          "clockify is not configured",
+	"error.clockify_api_key_is_invalid":                                         "clockify api key is invalid",
+	"error.clockify_workspace_not_found":                                        "clockify workspace not found",
+	"error.clockify_rate_limit_exceeded":                                        "clockify rate limit exceeded",
+	"error.clockify_request_failed":                                             "clockify request failed",
+	"error.clockify_connection_failed":                                          "clockify connection failed",
+	"error.seconds_delta_or_minutes_delta_is_required":                          "seconds_delta or minutes_delta is required",
+	"error.seconds_delta_and_minutes_delta_cannot_be_used_together":             "seconds_delta and minutes_delta cannot be used together",
+	"error.could_not_create_time_bank_adjustment":                               "could not create time bank adjustment",
+	"error.period_is_closed_for_this_date":                                      "period is closed for this date",
+	"error.another_closed_period_overlaps_selected_range":                       "another closed period overlaps selected range",
+	"error.could_not_close_time_bank_period":                                    "could not close time bank period",
+	"error.time_bank_closure_not_found":                                         "time bank closure not found",
+	"error.could_not_reopen_time_bank_period":                                   "could not reopen time bank period",
+	"error.adjustment_status_must_be_pending_approved_rejected":                 "adjustment status must be pending|approved|rejected",
+	"error.time_bank_adjustment_not_found":                                      "time bank adjustment not found",
+	"error.employee_not_found_in_closure":                                       "employee not found in closure",
+	"error.allow_closed_period_not_allowed":                                     "allow_closed_period not allowed",
+	"error.there_are_pending_time_bank_adjustments_in_selected_period":          "there are pending time bank adjustments in selected period",
+	"error.could_not_generate_time_card_pdf":                                    "could not generate time card pdf",
+
+	"weekday.sun": "sun",
+	"weekday.mon": "mon",
+	"weekday.tue": "tue",
+	"weekday.wed": "wed",
+	"weekday.thu": "thu",
+	"weekday.fri": "fri",
+	"weekday.sat": "sat",
+
+	"employee_status.active":     "Active",
+	"employee_status.inactive":   "Inactive",
+	"employee_status.terminated": "Terminated",
+
+	"timecard.document_title": "Time cards",
+	"timecard.title":          "TIME CARD",
+	"timecard.period":         "Period: %s to %s",
+	"timecard.company":        "Company: %s",
+	"timecard.employee":       "Employee: %s",
+	"timecard.details":        "Code: %s   Department: %s   Position: %s",
+	"timecard.hire":           "Hired: %s   Status: %s",
+	"timecard.issued":         "Issued %s UTC   Employee %d/%d   Page %d/%d",
+	"timecard.empty":          "No entries in this period.",
+	"timecard.totals":         "TOTALS",
+	"timecard.declaration":    "I declare that the entries above match the hours worked.",
+	"timecard.signature":      "Employee signature",
+	"timecard.date":           "Date",
+	"timecard.name":           "Name: %s",
+	"timecard.col.date":       "DATE",
+	"timecard.col.weekday":    "DAY",
+	"timecard.col.in_1":       "IN 1",
+	"timecard.col.out_1":      "OUT 1",
+	"timecard.col.in_2":       "IN 2",
+	"timecard.col.out_2":      "OUT 2",
+	"timecard.col.worked":     "WORKED",
+	"timecard.col.expected":   "EXPECTED",
+	"timecard.col.adjustment": "ADJUST.",
+	"timecard.col.balance":    "BALANCE",
+
+	"csv.employee_id":      "employee_id",
+	"csv.employee":         "employee",
+	"csv.worked_hours":     "worked_hours",
+	"csv.expected_hours":   "expected_hours",
+	"csv.adjustment_hours": "adjustment_hours",
+	"csv.balance_hours":    "balance_hours",
+	"csv.time_card":        "time_card",
+	"csv.period":           "period",
+	"csv.date":             "date",
+	"csv.weekday":          "weekday",
+	"csv.in_1":             "in_1",
+	"csv.out_1":            "out_1",
+	"csv.in_2":             "in_2",
+	"csv.out_2":            "out_2",
+	"csv.totals":           "totals",
+}
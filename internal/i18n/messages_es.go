@@ -0,0 +1,264 @@
+package i18n
+
+// Mensagens em espanhol.
+var es = map[string]string{
+	"field.required":       "%s es obligatorio",
+	"field.unknown_field":  "campo no permitido: %s",
+	"field.invalid_type":   "tipo de dato invalido en el campo: %s",
+	"field.invalid_date":   "%s debe tener el formato YYYY-MM-DD",
+	"field.invalid_number": "%s debe ser numerico",
+	"field.min_length":     "%s debe tener al menos %v caracteres",
+	"field.min":            "%s debe ser mayor o igual a %v",
+	"field.greater":        "%s debe ser mayor que %v",
+	"field.between":        "%s debe estar entre %v y %v",
+	"field.invalid_choice": "%s debe ser uno de estos valores: %s",
+	"field.must_differ":    "%s debe ser diferente de %s",
+	"field.https_url":      "%s debe ser una url https",
+	"field.role_key":       "%s debe tener de 2 a 40 caracteres: a-z, 0-9, '-' o '_'",
+	"field.after":          "%s debe ser posterior a %s",
+	"field.non_zero":       "%s no puede ser cero",
+
+	"error.validation_failed":                                "datos invalidos",
+	"error.rate_limited":                                     "demasiadas solicitudes; intentalo de nuevo en unos instantes",
+	"error.internal_error":                                   "error interno inesperado",
+	"error.route_not_found":                                  "ruta no encontrada",
+	"error.method_not_allowed":                               "metodo no permitido",
+	"error.invalid_json":                                     "json invalido en el cuerpo de la solicitud",
+	"error.invalid_field_type":                               "tipo de dato invalido en uno o mas campos",
+	"error.unknown_field":                                    "campo no permitido en el cuerpo de la solicitud",
+	"error.invalid_input":                                    "datos invalidos",
+	"error.database_error":                                   "error interno en la base de datos",
+	"error.audit_write_failed":                               "error al registrar la auditoria; no se modifico nada",
+	"error.key_error":                                        "error en la clave de firma",
+	"error.token_error":                                      "error al generar el token",
+	"error.password_error":                                   "error al procesar la contrasena",
+	"error.failed_to_hash_password":                          "error al procesar la contrasena",
+	"error.missing_bearer_token":                             "falta el token de acceso",
+	"error.invalid_token":                                    "token invalido",
+	"error.invalid_token_claims":                             "token invalido",
+	"error.session_revoked":                                  "sesion finalizada; inicia sesion de nuevo",
+	"error.invalid_refresh_token":                            "refresh token invalido",
+	"error.invalid_credentials":                              "email o contrasena invalidos",
+	"error.too_many_login_attempts":                          "demasiados intentos de inicio de sesion; intentalo mas tarde",
+	"error.no_tenant_membership":                             "el usuario no tiene acceso a esta empresa",
+	"error.password_change_required":                         "cambio de contrasena obligatorio",
+	"error.invalid_current_password":                         "contrasena actual invalida",
+	"error.invalid_or_expired_token":                         "token invalido o caducado",
+	"error.invalid_or_expired_code":                          "codigo invalido o caducado",
+	"error.valid_email_is_required":                          "se requiere un email valido",
+	"error.tenant_required":                                  "se requiere tenant_id o tenant_slug",
+	"error.invalid_tenant_id":                                "tenant_id invalido",
+	"error.could_not_create_tenant":                          "no fue posible crear la empresa",
+	"error.could_not_create_user":                            "no fue posible crear el usuario",
+	"error.failed_to_create_user":                            "no fue posible crear el usuario",
+	"error.failed_to_revoke_sessions":                        "no fue posible cerrar las sesiones",
+	"error.invalid_mfa_code":                                 "codigo MFA invalido",
+	"error.invalid_or_expired_mfa_token":                     "token de MFA invalido o caducado",
+	"error.mfa_already_enabled":                              "MFA ya esta activo",
+	"error.mfa_not_enabled":                                  "MFA no esta activo",
+	"error.mfa_setup_required":                               "configura el MFA antes de activarlo",
+	"error.mfa_enrollment_required":                          "activa el MFA para continuar",
+	"error.mfa_token_and_code_or_recovery_code_are_required": "se requieren mfa_token y code o recovery_code",
+	"error.enable_mfa_on_your_account_before_impersonating":  "activa el MFA en tu cuenta antes de acceder como otro usuario",
+	"error.enable_mfa_on_your_account_before_requiring_it":   "activa el MFA en tu cuenta antes de exigirlo",
+	"error.sso_not_configured":                               "SSO no configurado",
+	"error.sso_not_available":                                "SSO no disponible para esta empresa",
+	"error.identity_provider_unavailable":                    "proveedor de identidad no disponible",
+	"error.issuer_discovery_failed":                          "fallo al consultar el emisor",
+	"error.invalid_domain":                                   "dominio invalido: %s",
+	"error.invalid_default_role":                             "default_role invalido",
+	"error.default_role_cannot_be_owner":                     "default_role no puede ser owner",
+	"error.missing_permission":                               "falta el permiso %s",
+	"error.platform_admin_required":                          "acceso restringido a administradores de la plataforma",
+	"error.invalid_permission":                               "permiso invalido: %s",
+	"error.permission_reserved_to_owner":                     "permiso reservado al owner: %s",
+	"error.salary_permission_required":                       "tu perfil no puede modificar salary_cents",
+	"error.invalid_role":                                     "rol invalido",
+	"error.role_not_found":                                   "rol no encontrado",
+	"error.role_already_exists":                              "ya existe un rol con esta clave",
+	"error.role_is_assigned_to_members_pending_invitations_or_sso_default_role": "rol en uso por miembros, invitaciones pendientes o como rol por defecto del SSO",
+	"error.builtin_roles_cannot_be_changed":                                     "los roles predeterminados no se pueden modificar",
+	"error.builtin_roles_cannot_be_deleted":                                     "los roles predeterminados no se pueden eliminar",
+	"error.key_is_reserved":                                                     "clave reservada",
+	"error.name_too_long":                                                       "nombre demasiado largo",
+	"error.description_too_long":                                                "descripcion demasiado larga",
+	"error.failed_to_create_role":                                               "no fue posible crear el rol",
+	"error.failed_to_update_role":                                               "no fue posible actualizar el rol",
+	"error.failed_to_delete_role":                                               "no fue posible eliminar el rol",
+	"error.failed_to_list_roles":                                                "no fue posible listar los roles",
+	"error.failed_to_load_role":                                                 "no fue posible cargar el rol",
+	"error.colaborador_role_must_be_provisioned_by_hr":                          "el rol colaborador solo puede ser asignado por RR. HH.",
+	"error.member_not_found":                                                    "miembro no encontrado",
+	"error.membership_not_found":                                                "acceso del usuario no encontrado",
+	"error.user_not_found":                                                      "usuario no encontrado",
+	"error.invalid_user_id":                                                     "user_id invalido",
+	"error.invalid_id":                                                          "id invalido",
+	"error.user_is_already_a_member":                                            "el usuario ya es miembro de esta empresa",
+	"error.name_is_required_for_new_user":                                       "el nombre es obligatorio para un usuario nuevo",
+	"error.cannot_change_your_own_role":                                         "no puedes cambiar tu propio rol",
+	"error.cannot_change_your_own_role_here":                                    "no puedes cambiar tu propio rol aqui",
+	"error.cannot_demote_the_last_owner":                                        "no es posible degradar al ultimo owner",
+	"error.cannot_remove_the_last_owner":                                        "no es posible eliminar al ultimo owner",
+	"error.cannot_remove_yourself":                                              "no puedes eliminarte a ti mismo",
+	"error.failed_to_list_members":                                              "no fue posible listar los miembros",
+	"error.failed_to_lookup_member":                                             "no fue posible consultar el miembro",
+	"error.failed_to_lookup_user":                                               "no fue posible consultar el usuario",
+	"error.failed_to_remove_member":                                             "no fue posible eliminar el miembro",
+	"error.failed_to_unlock_member":                                             "no fue posible desbloquear el miembro",
+	"error.failed_to_upsert_membership":                                         "no fue posible guardar el acceso del usuario",
+	"error.failed_to_load_membership":                                           "no fue posible cargar el acceso del usuario",
+	"error.could_not_create_membership":                                         "no fue posible crear el acceso para el empleado",
+	"error.invitation_not_found":                                                "invitacion no encontrada",
+	"error.invitation_is_not_pending":                                           "la invitacion no esta pendiente",
+	"error.invalid_or_expired_invitation":                                       "invitacion invalida o caducada",
+	"error.could_not_create_invitation":                                         "no fue posible crear la invitacion",
+	"error.failed_to_create_invitation":                                         "no fue posible crear la invitacion",
+	"error.failed_to_list_invitations":                                          "no fue posible listar las invitaciones",
+	"error.failed_to_load_invitation":                                           "no fue posible cargar la invitacion",
+	"error.failed_to_update_invitation":                                         "no fue posible actualizar la invitacion",
+	"error.failed_to_revoke_invitation":                                         "no fue posible revocar la invitacion",
+	"error.invalid_api_key":                                                     "api key invalida",
+	"error.api_key_not_found":                                                   "api key no encontrada",
+	"error.invalid_scope":                                                       "alcance invalido: %s",
+	"error.failed_to_create_api_key":                                            "no fue posible crear la api key",
+	"error.failed_to_list_api_keys":                                             "no fue posible listar las api keys",
+	"error.failed_to_load_api_key":                                              "no fue posible cargar la api key",
+	"error.failed_to_revoke_api_key":                                            "no fue posible revocar la api key",
+	"error.impersonation_not_found":                                             "acceso de soporte no encontrado",
+	"error.impersonation_is_read_only":                                          "el acceso de soporte es de solo lectura",
+	"error.not_allowed_while_impersonating":                                     "accion no permitida durante el acceso de soporte",
+	"error.cannot_impersonate_yourself":                                         "no puedes acceder como tu mismo",
+	"error.tenant_id_and_user_id_or_email_are_required":                         "se requieren tenant_id y user_id o email",
+	"error.invalid_cursor":                                                      "cursor invalido",
+	"error.cursor_does_not_match_sort":                                          "el cursor no corresponde al orden",
+	"error.invalid_metrics_token":                                               "token de metricas invalido",
+	"error.precondition_required":                                               "el header If-Match es obligatorio",
+	"error.precondition_failed":                                                 "el registro fue modificado por otra solicitud",
+	"error.invalid_idempotency_key":                                             "Idempotency-Key invalida",
+	"error.could_not_read_request_body":                                         "no se pudo leer el cuerpo de la solicitud",
+	"error.idempotency_key_reused":                                              "Idempotency-Key reutilizada con otra solicitud",
+	"error.idempotency_in_progress":                                             "solicitud con esta Idempotency-Key aun en curso",
+	"error.vendor_not_found":                                                    "proveedor no encontrado",
+	"error.customer_not_found":                                                  "cliente no encontrado",
+	"error.payable_not_found":                                                   "cuenta por pagar no encontrada",
+	"error.receivable_not_found":                                                "cuenta por cobrar no encontrada",
+	"error.cost_center_not_found":                                               "centro de costo no encontrado",
+	"error.invalid_cost_center_id":                                              "cost_center_id invalido",
+	"error.could_not_create_vendor":                                             "no fue posible crear el proveedor: el nombre ya existe",
+	"error.could_not_create_customer":                                           "no fue posible crear el cliente: el nombre o documento ya existe",
+	"error.could_not_create_payable":                                            "no fue posible crear la cuenta por pagar",
+	"error.could_not_create_receivable":                                         "no fue posible crear la cuenta por cobrar",
+	"error.could_not_create_cost_center":                                        "no fue posible crear el centro de costo: el nombre o codigo ya existe",
+	"error.only_draft_payables_can_be_edited":                                   "solo las cuentas por pagar en borrador se pueden editar",
+	"error.only_approved_payables_can_be_marked_paid":                           "solo las cuentas por pagar aprobadas se pueden marcar como pagadas",
+	"error.only_draft_receivables_can_be_edited":                                "solo las cuentas por cobrar en borrador se pueden editar",
+	"error.only_issued_receivables_can_be_marked_received":                      "solo las cuentas por cobrar emitidas se pueden marcar como cobradas",
+	"error.only_draft_or_issued_can_be_canceled":                                "solo los registros en borrador o emitidos se pueden cancelar",
+	"error.invalid_status":                                                      "estado invalido",
+	"error.invalid_status_transition":                                           "transicion de estado invalida",
+	"error.could_not_create_department":                                         "no fue posible crear el departamento: el nombre o codigo ya existe",
+	"error.could_not_create_position":                                           "no fue posible crear el cargo: titulo duplicado o departamento invalido",
+	"error.could_not_create_location":                                           "no fue posible crear la ubicacion: el nombre o codigo ya existe",
+	"error.could_not_create_team":                                               "no fue posible crear el equipo: el nombre ya existe",
+	"error.could_not_create_employee":                                           "no fue posible crear el empleado: departamento, cargo o responsable invalido",
+	"error.could_not_create_compensation":                                       "no fue posible crear el historial de remuneracion",
+	"error.invalid_employee_id":                                                 "id de empleado invalido",
+	"error.employee_not_found":                                                  "empleado no encontrado",
+	"error.employee_is_not_active":                                              "el empleado no esta activo para fichar",
+	"error.employee_is_terminated":                                              "un empleado dado de baja no puede recibir una cuenta de acceso",
+	"error.employee_email_is_required":                                          "el empleado necesita un email para crear la cuenta de acceso",
+	"error.employee_profile_not_linked_to_user":                                 "el usuario autenticado no esta vinculado a un empleado",
+	"error.user_already_has_elevated_role":                                      "este usuario ya tiene un perfil administrativo en esta empresa",
+	"error.user_already_linked_to_another_employee":                             "este usuario ya esta vinculado a otro empleado",
+	"error.could_not_link_employee_account":                                     "no fue posible vincular el usuario al empleado",
+	"error.could_not_create_benefit":                                            "no fue posible crear el beneficio: el nombre ya existe",
+	"error.benefit_not_found":                                                   "beneficio no encontrado",
+	"error.invalid_benefit_id":                                                  "id de beneficio invalido",
+	"error.could_not_assign_benefit":                                            "no fue posible asignar el beneficio: ya esta asignado o los datos son invalidos",
+	"error.relation_not_found":                                                  "vinculo no encontrado",
+	"error.could_not_create_document":                                           "no fue posible crear el documento",
+	"error.could_not_create_time_off_type":                                      "no fue posible crear el tipo de ausencia: el nombre ya existe",
+	"error.time_off_type_not_found":                                             "tipo de ausencia no encontrado",
+	"error.could_not_create_time_off_request":                                   "no fue posible crear la solicitud de ausencia",
+	"error.invalid_request_id":                                                  "id de solicitud invalido",
+	"error.time_off_request_not_found":                                          "solicitud de ausencia no encontrada",
+	"error.you_already_have_an_open_time_entry":                                 "ya existe un fichaje abierto para este empleado",
+	"error.no_open_time_entry_found":                                            "no se encontro ningun fichaje abierto",
+	"error.could_not_create_time_entry":                                         "no fue posible registrar el fichaje de entrada",
+	"error.could_not_close_time_entry":                                          "no fue posible registrar el fichaje de salida",
+	"error.clockify_api_key_is_required":                                        "la api_key de Clockify es obligatoria",
+	"error.clockify_workspace_id_is_required":                                   "el workspace_id de Clockify es obligatorio",
+	"error.clockify_is_not_configured":                                          "integracion con Clockify no configurada",
+	"error.clockify_api_key_is_invalid":                                         "api key de Clockify invalida",
+	"error.clockify_workspace_not_found":                                        "workspace de Clockify no encontrado",
+	"error.clockify_rate_limit_exceeded":                                        "limite de solicitudes de Clockify superado; intentalo de nuevo en unos instantes",
+	"error.clockify_request_failed":                                             "Clockify devolvio un error al procesar la solicitud",
+	"error.clockify_connection_failed":                                          "no fue posible conectar con Clockify",
+	"error.seconds_delta_or_minutes_delta_is_required":                          "se requiere seconds_delta o minutes_delta",
+	"error.seconds_delta_and_minutes_delta_cannot_be_used_together":             "usa solo seconds_delta o minutes_delta, no ambos",
+	"error.could_not_create_time_bank_adjustment":                               "no fue posible crear el ajuste de la bolsa de horas",
+	"error.period_is_closed_for_this_date":                                      "el periodo de esta fecha esta cerrado",
+	"error.another_closed_period_overlaps_selected_range":                       "ya existe un periodo cerrado que se superpone al rango seleccionado",
+	"error.could_not_close_time_bank_period":                                    "no fue posible cerrar el periodo de la bolsa de horas",
+	"error.time_bank_closure_not_found":                                         "cierre de la bolsa de horas no encontrado",
+	"error.could_not_reopen_time_bank_period":                                   "no fue posible reabrir el cierre de la bolsa de horas",
+	"error.adjustment_status_must_be_pending_approved_rejected":                 "el estado del ajuste debe ser pending, approved o rejected",
+	"error.time_bank_adjustment_not_found":                                      "ajuste de la bolsa de horas no encontrado",
+	"error.employee_not_found_in_closure":                                       "empleado no encontrado en este cierre",
+	"error.allow_closed_period_not_allowed":                                     "tu perfil no puede sincronizar ignorando el periodo cerrado",
+	"error.there_are_pending_time_bank_adjustments_in_selected_period":          "hay ajustes pendientes de aprobacion en el periodo seleccionado",
+	"error.could_not_generate_time_card_pdf":                                    "no fue posible generar el PDF del registro de jornada",
+
+	"weekday.sun": "dom",
+	"weekday.mon": "lun",
+	"weekday.tue": "mar",
+	"weekday.wed": "mie",
+	"weekday.thu": "jue",
+	"weekday.fri": "vie",
+	"weekday.sat": "sab",
+
+	"employee_status.active":     "Activo",
+	"employee_status.inactive":   "Inactivo",
+	"employee_status.terminated": "Dado de baja",
+
+	"timecard.document_title": "Registros de jornada",
+	"timecard.title":          "REGISTRO DE JORNADA",
+	"timecard.period":         "Periodo: %s a %s",
+	"timecard.company":        "Empresa: %s",
+	"timecard.employee":       "Empleado: %s",
+	"timecard.details":        "Codigo: %s   Departamento: %s   Cargo: %s",
+	"timecard.hire":           "Ingreso: %s   Estado: %s",
+	"timecard.issued":         "Emitido el %s UTC   Empleado %d/%d   Pagina %d/%d",
+	"timecard.empty":          "Sin registros en el periodo.",
+	"timecard.totals":         "TOTALES",
+	"timecard.declaration":    "Declaro que los registros anteriores corresponden a la jornada realizada.",
+	"timecard.signature":      "Firma del empleado",
+	"timecard.date":           "Fecha",
+	"timecard.name":           "Nombre: %s",
+	"timecard.col.date":       "FECHA",
+	"timecard.col.weekday":    "DIA",
+	"timecard.col.in_1":       "ENT 1",
+	"timecard.col.out_1":      "SAL 1",
+	"timecard.col.in_2":       "ENT 2",
+	"timecard.col.out_2":      "SAL 2",
+	"timecard.col.worked":     "TRAB.",
+	"timecard.col.expected":   "PREV.",
+	"timecard.col.adjustment": "AJUSTE",
+	"timecard.col.balance":    "SALDO",
+
+	"csv.employee_id":      "employee_id",
+	"csv.employee":         "empleado",
+	"csv.worked_hours":     "horas_trabajadas",
+	"csv.expected_hours":   "horas_previstas",
+	"csv.adjustment_hours": "horas_ajustes",
+	"csv.balance_hours":    "saldo_horas",
+	"csv.time_card":        "registro_jornada",
+	"csv.period":           "periodo",
+	"csv.date":             "fecha",
+	"csv.weekday":          "dia_semana",
+	"csv.in_1":             "ent_1",
+	"csv.out_1":            "sal_1",
+	"csv.in_2":             "ent_2",
+	"csv.out_2":            "sal_2",
+	"csv.totals":           "totales",
+}
@@ -0,0 +1,264 @@
+package i18n
+
+// Mensagens em portugues (Brasil), idioma padrao.
+var ptBR = map[string]string{
+	"field.required":       "%s e obrigatorio",
+	"field.unknown_field":  "campo nao permitido: %s",
+	"field.invalid_type":   "tipo de dado invalido no campo: %s",
+	"field.invalid_date":   "%s deve estar no formato YYYY-MM-DD",
+	"field.invalid_number": "%s deve ser numerico",
+	"field.min_length":     "%s deve ter no minimo %v caracteres",
+	"field.min":            "%s deve ser maior ou igual a %v",
+	"field.greater":        "%s deve ser maior que %v",
+	"field.between":        "%s deve ficar entre %v e %v",
+	"field.invalid_choice": "%s deve ser um destes valores: %s",
+	"field.must_differ":    "%s deve ser diferente de %s",
+	"field.https_url":      "%s deve ser uma url https",
+	"field.role_key":       "%s deve ter de 2 a 40 caracteres: a-z, 0-9, '-' ou '_'",
+	"field.after":          "%s deve ser posterior a %s",
+	"field.non_zero":       "%s nao pode ser zero",
+
+	"error.validation_failed":                                "dados invalidos",
+	"error.rate_limited":                                     "muitas requisicoes; tente novamente em instantes",
+	"error.internal_error":                                   "erro interno inesperado",
+	"error.route_not_found":                                  "rota nao encontrada",
+	"error.method_not_allowed":                               "metodo nao permitido",
+	"error.invalid_json":                                     "json invalido no corpo da requisicao",
+	"error.invalid_field_type":                               "tipo de dado invalido em um ou mais campos",
+	"error.unknown_field":                                    "campo nao permitido no corpo da requisicao",
+	"error.invalid_input":                                    "dados invalidos",
+	"error.database_error":                                   "erro interno no banco de dados",
+	"error.audit_write_failed":                               "erro ao gravar auditoria; nada foi alterado",
+	"error.key_error":                                        "erro na chave de assinatura",
+	"error.token_error":                                      "erro ao gerar token",
+	"error.password_error":                                   "erro ao processar senha",
+	"error.failed_to_hash_password":                          "erro ao processar senha",
+	"error.missing_bearer_token":                             "token de acesso ausente",
+	"error.invalid_token":                                    "token invalido",
+	"error.invalid_token_claims":                             "token invalido",
+	"error.session_revoked":                                  "sessao encerrada; faca login novamente",
+	"error.invalid_refresh_token":                            "refresh token invalido",
+	"error.invalid_credentials":                              "email ou senha invalidos",
+	"error.too_many_login_attempts":                          "muitas tentativas de login; tente novamente mais tarde",
+	"error.no_tenant_membership":                             "usuario sem acesso a esta empresa",
+	"error.password_change_required":                         "troca de senha obrigatoria",
+	"error.invalid_current_password":                         "senha atual invalida",
+	"error.invalid_or_expired_token":                         "token invalido ou expirado",
+	"error.invalid_or_expired_code":                          "codigo invalido ou expirado",
+	"error.valid_email_is_required":                          "informe um email valido",
+	"error.tenant_required":                                  "informe tenant_id ou tenant_slug",
+	"error.invalid_tenant_id":                                "tenant_id invalido",
+	"error.could_not_create_tenant":                          "nao foi possivel criar a empresa",
+	"error.could_not_create_user":                            "nao foi possivel criar usuario",
+	"error.failed_to_create_user":                            "nao foi possivel criar usuario",
+	"error.failed_to_revoke_sessions":                        "nao foi possivel encerrar as sessoes",
+	"error.invalid_mfa_code":                                 "codigo MFA invalido",
+	"error.invalid_or_expired_mfa_token":                     "token de MFA invalido ou expirado",
+	"error.mfa_already_enabled":                              "MFA ja esta ativo",
+	"error.mfa_not_enabled":                                  "MFA nao esta ativo",
+	"error.mfa_setup_required":                               "configure o MFA antes de ativar",
+	"error.mfa_enrollment_required":                          "ative o MFA para continuar",
+	"error.mfa_token_and_code_or_recovery_code_are_required": "informe mfa_token e code ou recovery_code",
+	"error.enable_mfa_on_your_account_before_impersonating":  "ative o MFA na sua conta antes de acessar como outro usuario",
+	"error.enable_mfa_on_your_account_before_requiring_it":   "ative o MFA na sua conta antes de exigi-lo",
+	"error.sso_not_configured":                               "SSO nao configurado",
+	"error.sso_not_available":                                "SSO indisponivel para esta empresa",
+	"error.identity_provider_unavailable":                    "provedor de identidade indisponivel",
+	"error.issuer_discovery_failed":                          "falha ao consultar o emissor",
+	"error.invalid_domain":                                   "dominio invalido: %s",
+	"error.invalid_default_role":                             "default_role invalido",
+	"error.default_role_cannot_be_owner":                     "default_role nao pode ser owner",
+	"error.missing_permission":                               "permissao ausente: %s",
+	"error.platform_admin_required":                          "acesso restrito a administradores da plataforma",
+	"error.invalid_permission":                               "permissao invalida: %s",
+	"error.permission_reserved_to_owner":                     "permissao exclusiva do owner: %s",
+	"error.salary_permission_required":                       "seu perfil nao pode alterar salary_cents",
+	"error.invalid_role":                                     "role invalido",
+	"error.role_not_found":                                   "role nao encontrado",
+	"error.role_already_exists":                              "ja existe um role com esta chave",
+	"error.role_is_assigned_to_members_pending_invitations_or_sso_default_role": "role em uso por membros, convites pendentes ou como padrao do SSO",
+	"error.builtin_roles_cannot_be_changed":                                     "roles padrao nao podem ser alterados",
+	"error.builtin_roles_cannot_be_deleted":                                     "roles padrao nao podem ser removidos",
+	"error.key_is_reserved":                                                     "chave reservada",
+	"error.name_too_long":                                                       "nome muito longo",
+	"error.description_too_long":                                                "descricao muito longa",
+	"error.failed_to_create_role":                                               "nao foi possivel criar o role",
+	"error.failed_to_update_role":                                               "nao foi possivel atualizar o role",
+	"error.failed_to_delete_role":                                               "nao foi possivel remover o role",
+	"error.failed_to_list_roles":                                                "nao foi possivel listar os roles",
+	"error.failed_to_load_role":                                                 "nao foi possivel carregar o role",
+	"error.colaborador_role_must_be_provisioned_by_hr":                          "o role colaborador so pode ser concedido pelo RH",
+	"error.member_not_found":                                                    "membro nao encontrado",
+	"error.membership_not_found":                                                "acesso do usuario nao encontrado",
+	"error.user_not_found":                                                      "usuario nao encontrado",
+	"error.invalid_user_id":                                                     "user_id invalido",
+	"error.invalid_id":                                                          "id invalido",
+	"error.user_is_already_a_member":                                            "usuario ja e membro desta empresa",
+	"error.name_is_required_for_new_user":                                       "nome e obrigatorio para novo usuario",
+	"error.cannot_change_your_own_role":                                         "voce nao pode alterar o proprio role",
+	"error.cannot_change_your_own_role_here":                                    "voce nao pode alterar o proprio role aqui",
+	"error.cannot_demote_the_last_owner":                                        "nao e possivel rebaixar o ultimo owner",
+	"error.cannot_remove_the_last_owner":                                        "nao e possivel remover o ultimo owner",
+	"error.cannot_remove_yourself":                                              "voce nao pode remover a si mesmo",
+	"error.failed_to_list_members":                                              "nao foi possivel listar os membros",
+	"error.failed_to_lookup_member":                                             "nao foi possivel consultar o membro",
+	"error.failed_to_lookup_user":                                               "nao foi possivel consultar o usuario",
+	"error.failed_to_remove_member":                                             "nao foi possivel remover o membro",
+	"error.failed_to_unlock_member":                                             "nao foi possivel desbloquear o membro",
+	"error.failed_to_upsert_membership":                                         "nao foi possivel salvar o acesso do usuario",
+	"error.failed_to_load_membership":                                           "nao foi possivel carregar o acesso do usuario",
+	"error.could_not_create_membership":                                         "nao foi possivel criar permissao de acesso para o colaborador",
+	"error.invitation_not_found":                                                "convite nao encontrado",
+	"error.invitation_is_not_pending":                                           "convite nao esta pendente",
+	"error.invalid_or_expired_invitation":                                       "convite invalido ou expirado",
+	"error.could_not_create_invitation":                                         "nao foi possivel criar o convite",
+	"error.failed_to_create_invitation":                                         "nao foi possivel criar o convite",
+	"error.failed_to_list_invitations":                                          "nao foi possivel listar os convites",
+	"error.failed_to_load_invitation":                                           "nao foi possivel carregar o convite",
+	"error.failed_to_update_invitation":                                         "nao foi possivel atualizar o convite",
+	"error.failed_to_revoke_invitation":                                         "nao foi possivel revogar o convite",
+	"error.invalid_api_key":                                                     "api key invalida",
+	"error.api_key_not_found":                                                   "api key nao encontrada",
+	"error.invalid_scope":                                                       "escopo invalido: %s",
+	"error.failed_to_create_api_key":                                            "nao foi possivel criar a api key",
+	"error.failed_to_list_api_keys":                                             "nao foi possivel listar as api keys",
+	"error.failed_to_load_api_key":                                              "nao foi possivel carregar a api key",
+	"error.failed_to_revoke_api_key":                                            "nao foi possivel revogar a api key",
+	"error.impersonation_not_found":                                             "acesso de suporte nao encontrado",
+	"error.impersonation_is_read_only":                                          "acesso de suporte somente leitura",
+	"error.not_allowed_while_impersonating":                                     "acao nao permitida durante acesso de suporte",
+	"error.cannot_impersonate_yourself":                                         "voce nao pode acessar como voce mesmo",
+	"error.tenant_id_and_user_id_or_email_are_required":                         "informe tenant_id e user_id ou email",
+	"error.invalid_cursor":                                                      "cursor invalido",
+	"error.cursor_does_not_match_sort":                                          "o cursor nao corresponde a ordenacao",
+	"error.invalid_metrics_token":                                               "token de metricas invalido",
+	"error.precondition_required":                                               "o header If-Match e obrigatorio",
+	"error.precondition_failed":                                                 "o registro foi alterado por outra requisicao",
+	"error.invalid_idempotency_key":                                             "Idempotency-Key invalida",
+	"error.could_not_read_request_body":                                         "nao foi possivel ler o corpo da requisicao",
+	"error.idempotency_key_reused":                                              "Idempotency-Key reutilizada com outra requisicao",
+	"error.idempotency_in_progress":                                             "requisicao com esta Idempotency-Key ainda em andamento",
+	"error.vendor_not_found":                                                    "fornecedor nao encontrado",
+	"error.customer_not_found":                                                  "cliente nao encontrado",
+	"error.payable_not_found":                                                   "conta a pagar nao encontrada",
+	"error.receivable_not_found":                                                "conta a receber nao encontrada",
+	"error.cost_center_not_found":                                               "centro de custo nao encontrado",
+	"error.invalid_cost_center_id":                                              "cost_center_id invalido",
+	"error.could_not_create_vendor":                                             "nao foi possivel criar fornecedor: nome ja existe",
+	"error.could_not_create_customer":                                           "nao foi possivel criar cliente: nome ou documento ja existe",
+	"error.could_not_create_payable":                                            "nao foi possivel criar conta a pagar",
+	"error.could_not_create_receivable":                                         "nao foi possivel criar conta a receber",
+	"error.could_not_create_cost_center":                                        "nao foi possivel criar centro de custo: nome ou codigo ja existe",
+	"error.only_draft_payables_can_be_edited":                                   "somente contas a pagar em rascunho podem ser editadas",
+	"error.only_approved_payables_can_be_marked_paid":                           "somente contas a pagar aprovadas podem ser marcadas como pagas",
+	"error.only_draft_receivables_can_be_edited":                                "somente contas a receber em rascunho podem ser editadas",
+	"error.only_issued_receivables_can_be_marked_received":                      "somente contas a receber emitidas podem ser marcadas como recebidas",
+	"error.only_draft_or_issued_can_be_canceled":                                "somente registros em rascunho ou emitidos podem ser cancelados",
+	"error.invalid_status":                                                      "status invalido",
+	"error.invalid_status_transition":                                           "transicao de status invalida",
+	"error.could_not_create_department":                                         "nao foi possivel criar departamento: nome ou codigo ja existe",
+	"error.could_not_create_position":                                           "nao foi possivel criar cargo: titulo duplicado ou departamento invalido",
+	"error.could_not_create_location":                                           "nao foi possivel criar local: nome ou codigo ja existe",
+	"error.could_not_create_team":                                               "nao foi possivel criar time: nome ja existe",
+	"error.could_not_create_employee":                                           "nao foi possivel criar colaborador: departamento, cargo ou gestor invalido",
+	"error.could_not_create_compensation":                                       "nao foi possivel criar historico de remuneracao",
+	"error.invalid_employee_id":                                                 "id do colaborador invalido",
+	"error.employee_not_found":                                                  "colaborador nao encontrado",
+	"error.employee_is_not_active":                                              "colaborador nao esta ativo para bater ponto",
+	"error.employee_is_terminated":                                              "colaborador desligado nao pode receber conta de acesso",
+	"error.employee_email_is_required":                                          "colaborador precisa ter email para criar conta de acesso",
+	"error.employee_profile_not_linked_to_user":                                 "usuario autenticado nao esta vinculado a um colaborador",
+	"error.user_already_has_elevated_role":                                      "este usuario ja possui perfil administrativo neste tenant",
+	"error.user_already_linked_to_another_employee":                             "este usuario ja esta vinculado a outro colaborador",
+	"error.could_not_link_employee_account":                                     "nao foi possivel vincular usuario ao colaborador",
+	"error.could_not_create_benefit":                                            "nao foi possivel criar beneficio: nome ja existe",
+	"error.benefit_not_found":                                                   "beneficio nao encontrado",
+	"error.invalid_benefit_id":                                                  "id do beneficio invalido",
+	"error.could_not_assign_benefit":                                            "nao foi possivel vincular beneficio: vinculo ja existe ou dados invalidos",
+	"error.relation_not_found":                                                  "vinculo nao encontrado",
+	"error.could_not_create_document":                                           "nao foi possivel criar documento",
+	"error.could_not_create_time_off_type":                                      "nao foi possivel criar tipo de folga: nome ja existe",
+	"error.time_off_type_not_found":                                             "tipo de folga nao encontrado",
+	"error.could_not_create_time_off_request":                                   "nao foi possivel criar solicitacao de folga",
+	"error.invalid_request_id":                                                  "id da solicitacao invalido",
+	"error.time_off_request_not_found":                                          "solicitacao de folga nao encontrada",
+	"error.you_already_have_an_open_time_entry":                                 "ja existe uma batida em aberto para este colaborador",
+	"error.no_open_time_entry_found":                                            "nenhuma batida em aberto foi encontrada",
+	"error.could_not_create_time_entry":                                         "nao foi possivel registrar batida de entrada",
+	"error.could_not_close_time_entry":                                          "nao foi possivel registrar batida de saida",
+	"error.clockify_api_key_is_required":                                        "api_key do Clockify e obrigatorio",
+	"error.clockify_workspace_id_is_required":                                   "workspace_id do Clockify e obrigatorio",
+	"error.clockify_is_not_configured":                                          "integracao Clockify nao configurada",
+	"error.clockify_api_key_is_invalid":                                         "api key do Clockify invalida",
+	"error.clockify_workspace_not_found":                                        "workspace do Clockify nao encontrado",
+	"error.clockify_rate_limit_exceeded":                                        "limite de requisicoes do Clockify excedido; tente novamente em instantes",
+	"error.clockify_request_failed":                                             "Clockify retornou erro ao processar a requisicao",
+	"error.clockify_connection_failed":                                          "nao foi possivel conectar no Clockify",
+	"error.seconds_delta_or_minutes_delta_is_required":                          "informe seconds_delta ou minutes_delta",
+	"error.seconds_delta_and_minutes_delta_cannot_be_used_together":             "use apenas seconds_delta ou minutes_delta, nao os dois",
+	"error.could_not_create_time_bank_adjustment":                               "nao foi possivel criar ajuste de banco de horas",
+	"error.period_is_closed_for_this_date":                                      "o periodo desta data esta fechado",
+	"error.another_closed_period_overlaps_selected_range":                       "ja existe periodo fechado que se sobrepoe ao intervalo selecionado",
+	"error.could_not_close_time_bank_period":                                    "nao foi possivel fechar o periodo do banco de horas",
+	"error.time_bank_closure_not_found":                                         "fechamento de banco de horas nao encontrado",
+	"error.could_not_reopen_time_bank_period":                                   "nao foi possivel reabrir o fechamento do banco de horas",
+	"error.adjustment_status_must_be_pending_approved_rejected":                 "status do ajuste deve ser pending, approved ou rejected",
+	"error.time_bank_adjustment_not_found":                                      "ajuste de banco de horas nao encontrado",
+	"error.employee_not_found_in_closure":                                       "colaborador nao encontrado neste fechamento",
+	"error.allow_closed_period_not_allowed":                                     "seu perfil nao pode sincronizar ignorando periodo fechado",
+	"error.there_are_pending_time_bank_adjustments_in_selected_period":          "existem ajustes pendentes de aprovacao no periodo selecionado",
+	"error.could_not_generate_time_card_pdf":                                    "nao foi possivel gerar o PDF do cartao ponto",
+
+	"weekday.sun": "dom",
+	"weekday.mon": "seg",
+	"weekday.tue": "ter",
+	"weekday.wed": "qua",
+	"weekday.thu": "qui",
+	"weekday.fri": "sex",
+	"weekday.sat": "sab",
+
+	"employee_status.active":     "Ativo",
+	"employee_status.inactive":   "Inativo",
+	"employee_status.terminated": "Desligado",
+
+	"timecard.document_title": "Cartoes de ponto",
+	"timecard.title":          "CARTAO PONTO",
+	"timecard.period":         "Periodo: %s ate %s",
+	"timecard.company":        "Empresa: %s",
+	"timecard.employee":       "Colaborador: %s",
+	"timecard.details":        "Codigo: %s   Departamento: %s   Funcao: %s",
+	"timecard.hire":           "Admissao: %s   Status: %s",
+	"timecard.issued":         "Emitido em %s UTC   Colaborador %d/%d   Pagina %d/%d",
+	"timecard.empty":          "Sem registros no periodo.",
+	"timecard.totals":         "TOTAIS",
+	"timecard.declaration":    "Declaro que as marcacoes acima conferem com a jornada realizada.",
+	"timecard.signature":      "Assinatura do colaborador",
+	"timecard.date":           "Data",
+	"timecard.name":           "Nome: %s",
+	"timecard.col.date":       "DATA",
+	"timecard.col.weekday":    "DIA",
+	"timecard.col.in_1":       "ENT 1",
+	"timecard.col.out_1":      "SAI 1",
+	"timecard.col.in_2":       "ENT 2",
+	"timecard.col.out_2":      "SAI 2",
+	"timecard.col.worked":     "TRAB.",
+	"timecard.col.expected":   "PREV.",
+	"timecard.col.adjustment": "AJUSTE",
+	"timecard.col.balance":    "SALDO",
+
+	"csv.employee_id":      "employee_id",
+	"csv.employee":         "colaborador",
+	"csv.worked_hours":     "trabalhadas_horas",
+	"csv.expected_hours":   "previstas_horas",
+	"csv.adjustment_hours": "ajustes_horas",
+	"csv.balance_hours":    "saldo_horas",
+	"csv.time_card":        "cartao_ponto",
+	"csv.period":           "periodo",
+	"csv.date":             "data",
+	"csv.weekday":          "dia_semana",
+	"csv.in_1":             "ent_1",
+	"csv.out_1":            "sai_1",
+	"csv.in_2":             "ent_2",
+	"csv.out_2":            "sai_2",
+	"csv.totals":           "totais",
+}
@@ -0,0 +1,247 @@
+// Package jwtkeys guarda as chaves de assinatura dos access tokens: uma chave
+// ativa assina (RS256 ou EdDSA, com kid no header) e todas as chaves carregadas
+// verificam, o que permite rotacionar sem derrubar sessoes. As chaves publicas
+// sao publicadas em JWKS para outros servicos validarem os tokens.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+	AlgHS256 = "HS256"
+
+	minRSABits = 2048
+)
+
+// Options define de onde vem as chaves.
+type Options struct {
+	// Dir: arquivos .pem; o nome do arquivo (sem extensao) e o kid. Chaves
+	// privadas assinam e verificam; chaves publicas so verificam (chaves antigas
+	// mantidas ate os tokens emitidos com elas expirarem).
+	Dir string
+	// SigningKeyPEM: chave privada inline (ex.: variavel de ambiente), com kid SigningKeyID.
+	SigningKeyPEM string
+	// SigningKeyID escolhe a chave ativa. Vazio: usa a unica chave privada carregada.
+	SigningKeyID string
+	// HMACSecret: HS256 legado. Assina so quando nao ha chave assimetrica; com
+	// chaves assimetricas continua valido apenas para verificar tokens antigos
+	// (sem kid) durante a migracao.
+	HMACSecret string
+}
+
+type key struct {
+	id      string
+	alg     string
+	private crypto.Signer // nil para chaves so de verificacao
+	public  crypto.PublicKey
+}
+
+type KeySet struct {
+	signing *key
+	byID    map[string]*key
+	hmac    []byte
+}
+
+func Load(opts Options) (*KeySet, error) {
+	ks := &KeySet{byID: map[string]*key{}}
+
+	if opts.Dir != "" {
+		files, err := filepath.Glob(filepath.Join(opts.Dir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			kid := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+			if err := ks.add(kid, b); err != nil {
+				return nil, fmt.Errorf("jwt key %s: %w", f, err)
+			}
+		}
+	}
+
+	if pemData := strings.TrimSpace(opts.SigningKeyPEM); pemData != "" {
+		if opts.SigningKeyID == "" {
+			return nil, errors.New("JWT_SIGNING_KEY_ID is required with JWT_SIGNING_KEY")
+		}
+		// variaveis de ambiente costumam chegar com \n literal
+		pemData = strings.ReplaceAll(pemData, `\n`, "\n")
+		if err := ks.add(opts.SigningKeyID, []byte(pemData)); err != nil {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY: %w", err)
+		}
+	}
+
+	if opts.HMACSecret != "" {
+		ks.hmac = []byte(opts.HMACSecret)
+	}
+
+	if len(ks.byID) == 0 {
+		if ks.hmac == nil {
+			return nil, errors.New("no jwt keys: set JWT_KEYS_DIR, JWT_SIGNING_KEY or JWT_SECRET")
+		}
+		return ks, nil
+	}
+
+	if opts.SigningKeyID != "" {
+		k, ok := ks.byID[opts.SigningKeyID]
+		if !ok {
+			return nil, fmt.Errorf("signing key %q not found", opts.SigningKeyID)
+		}
+		if k.private == nil {
+			return nil, fmt.Errorf("signing key %q has no private key", opts.SigningKeyID)
+		}
+		ks.signing = k
+		return ks, nil
+	}
+
+	for _, k := range ks.byID {
+		if k.private == nil {
+			continue
+		}
+		if ks.signing != nil {
+			return nil, errors.New("several private jwt keys loaded: set JWT_SIGNING_KEY_ID")
+		}
+		ks.signing = k
+	}
+	if ks.signing == nil {
+		return nil, errors.New("no private jwt key to sign with")
+	}
+	return ks, nil
+}
+
+func (ks *KeySet) add(kid string, pemData []byte) error {
+	if kid == "" {
+		return errors.New("empty kid")
+	}
+	if _, dup := ks.byID[kid]; dup {
+		return fmt.Errorf("duplicated kid %q", kid)
+	}
+	k, err := parsePEM(pemData)
+	if err != nil {
+		return err
+	}
+	k.id = kid
+	ks.byID[kid] = k
+	return nil
+}
+
+func parsePEM(data []byte) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid pem")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported pem block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := parsed.(type) {
+	case *rsa.PrivateKey:
+		if v.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("rsa key must have at least %d bits", minRSABits)
+		}
+		return &key{alg: AlgRS256, private: v, public: &v.PublicKey}, nil
+	case *rsa.PublicKey:
+		if v.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("rsa key must have at least %d bits", minRSABits)
+		}
+		return &key{alg: AlgRS256, public: v}, nil
+	case ed25519.PrivateKey:
+		return &key{alg: AlgEdDSA, private: v, public: v.Public()}, nil
+	case ed25519.PublicKey:
+		return &key{alg: AlgEdDSA, public: v}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", parsed)
+	}
+}
+
+// SigningKeyID devolve o kid da chave ativa ("" no modo HS256 legado).
+func (ks *KeySet) SigningKeyID() string {
+	if ks.signing == nil {
+		return ""
+	}
+	return ks.signing.id
+}
+
+// Sign assina as claims com a chave ativa, colocando o kid no header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.hmac)
+	}
+
+	var method jwt.SigningMethod = jwt.SigningMethodRS256
+	if ks.signing.alg == AlgEdDSA {
+		method = jwt.SigningMethodEdDSA
+	}
+	t := jwt.NewWithClaims(method, claims)
+	t.Header["kid"] = ks.signing.id
+	return t.SignedString(ks.signing.private)
+}
+
+// Keyfunc escolhe a chave de verificacao pelo kid e exige que o alg do token
+// seja o da chave (evita usar uma chave publica como segredo HMAC).
+func (ks *KeySet) Keyfunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		if ks.hmac != nil && t.Method.Alg() == AlgHS256 {
+			return ks.hmac, nil
+		}
+		return nil, errors.New("missing kid")
+	}
+
+	k, ok := ks.byID[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if t.Method.Alg() != k.alg {
+		return nil, fmt.Errorf("unexpected alg %q for kid %q", t.Method.Alg(), kid)
+	}
+	return k.public, nil
+}
+
+// ValidMethods lista os algoritmos aceitos na verificacao.
+func (ks *KeySet) ValidMethods() []string {
+	methods := []string{}
+	if ks.hmac != nil {
+		methods = append(methods, AlgHS256)
+	}
+	seen := map[string]bool{}
+	for _, k := range ks.byID {
+		if !seen[k.alg] {
+			seen[k.alg] = true
+			methods = append(methods, k.alg)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
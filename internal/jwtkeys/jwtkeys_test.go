@@ -0,0 +1,111 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeKey(t *testing.T, dir, kid string, k any, public bool) {
+	t.Helper()
+	var block *pem.Block
+	if public {
+		b, err := x509.MarshalPKIXPublicKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: b}
+	} else {
+		b, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: b}
+	}
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func parse(ks *KeySet, token string) error {
+	_, err := jwt.Parse(token, ks.Keyfunc, jwt.WithValidMethods(ks.ValidMethods()))
+	return err
+}
+
+func TestRotationKeepsOldTokensValid(t *testing.T) {
+	dir := t.TempDir()
+	oldPub, oldPriv, _ := ed25519.GenerateKey(rand.Reader)
+	newRSA, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "2026-01", oldPriv, false)
+
+	oldSet, err := Load(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := oldSet.Sign(jwt.MapClaims{"uid": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rotacao: chave antiga vira so publica, a nova assina
+	writeKey(t, dir, "2026-01", oldPub, true)
+	writeKey(t, dir, "2026-02", newRSA, false)
+	newSet, err := Load(Options{Dir: dir, SigningKeyID: "2026-02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := newSet.Sign(jwt.MapClaims{"uid": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := parse(newSet, oldToken); err != nil {
+		t.Fatalf("old token rejected after rotation: %v", err)
+	}
+	if err := parse(newSet, newToken); err != nil {
+		t.Fatalf("new token rejected: %v", err)
+	}
+	if err := parse(oldSet, newToken); err == nil {
+		t.Fatal("token with unknown kid accepted")
+	}
+
+	jwks := newSet.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kty != "OKP" || jwks.Keys[1].Kty != "RSA" {
+		t.Fatalf("unexpected jwks: %+v", jwks)
+	}
+}
+
+func TestRejectsAlgConfusion(t *testing.T) {
+	dir := t.TempDir()
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "k1", priv, false)
+
+	ks, err := Load(Options{Dir: dir, HMACSecret: "legacy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// HS256 assinado com a chave publica e kid da chave Ed25519
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"uid": 1})
+	forged.Header["kid"] = "k1"
+	s, _ := forged.SignedString([]byte(pub))
+	if err := parse(ks, s); err == nil {
+		t.Fatal("forged HS256 token accepted")
+	}
+
+	// token legado HS256 (sem kid) continua valido durante a migracao
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"uid": 1}).SignedString([]byte("legacy"))
+	if err := parse(ks, legacy); err != nil {
+		t.Fatalf("legacy token rejected: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package lifecycle sobe o servidor HTTP e os jobs em segundo plano e, no
+// SIGTERM/SIGINT, encerra tudo na ordem: cancela os jobs, para de aceitar
+// conexoes e espera as requisicoes em andamento e os jobs voltarem, e por fim
+// fecha os recursos (banco, exportador de traces).
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type job struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+type closer struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// Manager guarda os jobs e os closers registrados antes de Run.
+type Manager struct {
+	log     zerolog.Logger
+	timeout time.Duration
+	jobs    []job
+	closers []closer
+}
+
+// New cria o manager. timeout limita o encerramento inteiro (drenagem das
+// requisicoes + jobs + closers).
+func New(log zerolog.Logger, timeout time.Duration) *Manager {
+	return &Manager{log: log, timeout: timeout}
+}
+
+// Go registra um job que roda enquanto o servidor estiver de pe. O ctx e
+// cancelado no encerramento e o job deve retornar logo depois.
+func (m *Manager) Go(name string, run func(ctx context.Context)) {
+	m.jobs = append(m.jobs, job{name: name, run: run})
+}
+
+// OnStop registra um recurso para fechar depois do servidor e dos jobs. Os
+// closers rodam na ordem inversa do registro (o ultimo aberto fecha primeiro).
+func (m *Manager) OnStop(name string, close func(ctx context.Context) error) {
+	m.closers = append(m.closers, closer{name: name, close: close})
+}
+
+// Run escuta em srv.Addr e serve ate ctx ser cancelado (sinal) ou o servidor
+// falhar.
+func (m *Manager) Run(ctx context.Context, srv *http.Server) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		m.stop(nil, nil, nil)
+		return err
+	}
+	return m.Serve(ctx, srv, ln)
+}
+
+// Serve e o Run com um listener ja aberto.
+func (m *Manager) Serve(ctx context.Context, srv *http.Server, ln net.Listener) error {
+	jobsCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	var jobs sync.WaitGroup
+	for _, j := range m.jobs {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			j.run(jobsCtx)
+			m.log.Info().Str("job", j.name).Msg("background job stopped")
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		m.log.Info().Str("addr", ln.Addr().String()).Msg("api listening")
+		serveErr <- srv.Serve(ln)
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		m.log.Info().Msg("shutdown signal received")
+	case err = <-serveErr:
+		m.log.Error().Err(err).Msg("server failed")
+	}
+
+	m.stop(srv, cancelJobs, &jobs)
+	return err
+}
+
+func (m *Manager) stop(srv *http.Server, cancelJobs context.CancelFunc, jobs *sync.WaitGroup) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	// os jobs param em paralelo com a drenagem; o banco so fecha no final
+	if cancelJobs != nil {
+		cancelJobs()
+	}
+	if srv != nil {
+		// Shutdown fecha o listener e espera as requisicoes em andamento
+		if err := srv.Shutdown(ctx); err != nil {
+			m.log.Warn().Err(err).Msg("http drain timed out, closing connections")
+			_ = srv.Close()
+		} else {
+			m.log.Info().Msg("http server drained")
+		}
+	}
+
+	if jobs != nil {
+		done := make(chan struct{})
+		go func() {
+			jobs.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			m.log.Warn().Msg("background jobs did not stop in time")
+		}
+	}
+
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		c := m.closers[i]
+		if err := c.close(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			m.log.Error().Err(err).Str("resource", c.name).Msg("close failed")
+		}
+	}
+	m.log.Info().Msg("shutdown complete")
+}
@@ -0,0 +1,105 @@
+package lifecycle
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestServeDrainsAndStops(t *testing.T) {
+	started := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	})}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(zerolog.Nop(), 5*time.Second)
+	jobStopped := make(chan struct{})
+	m.Go("job", func(ctx context.Context) {
+		<-ctx.Done()
+		close(jobStopped)
+	})
+	var closed []string
+	m.OnStop("tracing", func(context.Context) error { closed = append(closed, "tracing"); return nil })
+	m.OnStop("database", func(context.Context) error { closed = append(closed, "database"); return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- m.Serve(ctx, srv, ln) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	got := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			got <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		got <- result{string(b), err}
+	}()
+
+	<-started
+	cancel() // SIGTERM com a requisicao em andamento
+
+	if r := <-got; r.err != nil || r.body != "done" {
+		t.Fatalf("in-flight request = %q, %v", r.body, r.err)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("Serve = %v", err)
+	}
+	select {
+	case <-jobStopped:
+	default:
+		t.Fatal("job context was not canceled")
+	}
+	if want := []string{"database", "tracing"}; !reflect.DeepEqual(closed, want) {
+		t.Fatalf("closers = %v, want %v", closed, want)
+	}
+	if _, err := net.DialTimeout("tcp", ln.Addr().String(), time.Second); err == nil {
+		t.Fatal("listener still accepting after shutdown")
+	}
+}
+
+func TestWorkerDrainsOnStop(t *testing.T) {
+	w := NewWorker(10, 2)
+	w.Start()
+
+	var mu sync.Mutex
+	ran := 0
+	for range 5 {
+		if !w.Submit(func(context.Context) {
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			ran++
+			mu.Unlock()
+		}) {
+			t.Fatal("submit refused")
+		}
+	}
+	if err := w.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran != 5 {
+		t.Fatalf("ran = %d, want 5", ran)
+	}
+	if w.Submit(func(context.Context) {}) {
+		t.Fatal("submit after stop must be refused")
+	}
+}
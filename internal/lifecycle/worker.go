@@ -0,0 +1,76 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+)
+
+// Worker roda tarefas curtas disparadas por requisicoes e que nao podem
+// atrasar a resposta (ex.: email de redefinicao de senha). Registre Stop com
+// Manager.OnStop depois do banco: os closers rodam apos a drenagem das
+// requisicoes (ninguem mais enfileira) e na ordem inversa, entao a fila
+// esvazia antes de o banco fechar.
+type Worker struct {
+	mu      sync.Mutex
+	closed  bool
+	jobs    chan func(ctx context.Context)
+	workers int
+	wg      sync.WaitGroup
+}
+
+// NewWorker cria o worker com uma fila de queue tarefas consumida por workers
+// goroutines.
+func NewWorker(queue, workers int) *Worker {
+	return &Worker{jobs: make(chan func(ctx context.Context), max(queue, 1)), workers: max(workers, 1)}
+}
+
+// Start sobe as goroutines que consomem a fila.
+func (w *Worker) Start() {
+	for range w.workers {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			for job := range w.jobs {
+				job(context.Background())
+			}
+		}()
+	}
+}
+
+// Submit enfileira a tarefa sem bloquear. Fila cheia ou worker parado devolve
+// false e a tarefa e descartada.
+func (w *Worker) Submit(job func(ctx context.Context)) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return false
+	}
+	select {
+	case w.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop fecha a fila e espera as tarefas enfileiradas terminarem (ou ctx).
+func (w *Worker) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.jobs)
+	}
+	w.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Message e um email simples em texto puro.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Mailer envia emails transacionais (reset de senha, convites etc).
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+	DriverSMTP = "smtp"
+)
+
+// Options reune a configuracao de todos os drivers; apenas os campos do
+// driver escolhido sao usados.
+type Options struct {
+	Driver string
+	From   string
+
+	SMTPHost string
+	SMTPPort int
+	SMTPUser string
+	SMTPPass string
+
+	FileDir string
+}
+
+// New devolve o Mailer do driver configurado. "log" e o padrao para dev.
+func New(opts Options, log zerolog.Logger) (Mailer, error) {
+	switch strings.ToLower(strings.TrimSpace(opts.Driver)) {
+	case "", DriverLog:
+		return &LogMailer{From: opts.From, Log: log}, nil
+	case DriverFile:
+		if strings.TrimSpace(opts.FileDir) == "" {
+			return nil, fmt.Errorf("mail: file driver requires a directory")
+		}
+		return &FileMailer{From: opts.From, Dir: opts.FileDir}, nil
+	case DriverSMTP:
+		if strings.TrimSpace(opts.SMTPHost) == "" {
+			return nil, fmt.Errorf("mail: smtp driver requires a host")
+		}
+		return &SMTPMailer{
+			Host:     opts.SMTPHost,
+			Port:     opts.SMTPPort,
+			Username: opts.SMTPUser,
+			Password: opts.SMTPPass,
+			From:     opts.From,
+		}, nil
+	default:
+		return nil, fmt.Errorf("mail: unknown driver %q", opts.Driver)
+	}
+}
+
+// buildMessage monta o email no formato RFC 5322 usado pelos drivers SMTP e file.
+func buildMessage(from string, msg Message, now time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+func validate(msg Message) error {
+	if strings.TrimSpace(msg.To) == "" {
+		return fmt.Errorf("mail: recipient is required")
+	}
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return fmt.Errorf("mail: invalid header value")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailerWritesMessage(t *testing.T) {
+	dir := t.TempDir()
+	m := &FileMailer{From: "no-reply@example.com", Dir: dir}
+
+	err := m.Send(context.Background(), Message{
+		To:      "ana@example.com",
+		Subject: "Redefinicao de senha",
+		Text:    "linha 1\nlinha 2",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(files))
+	}
+	raw, _ := os.ReadFile(files[0])
+	body := string(raw)
+	for _, want := range []string{"From: no-reply@example.com\r\n", "To: ana@example.com\r\n", "Subject: Redefinicao de senha\r\n", "linha 1\r\nlinha 2"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected message to contain %q, got %q", want, body)
+		}
+	}
+}
+
+func TestSendRejectsHeaderInjection(t *testing.T) {
+	m := &FileMailer{From: "no-reply@example.com", Dir: t.TempDir()}
+	err := m.Send(context.Background(), Message{To: "ana@example.com\r\nBcc: x@example.com", Subject: "oi"})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,120 @@
+// Package metrics concentra as metricas Prometheus da API (HTTP, pool do
+// MySQL e sync do Clockify), expostas em /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry proprio (em vez do global) para expor so o que a API registra.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duracao das requisicoes HTTP por rota (padrao do chi) e status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	clockifyRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "clockify_api_requests_total",
+		Help: "Chamadas a API do Clockify por status (error = falha de rede).",
+	}, []string{"status"})
+
+	clockifyRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "clockify_api_retries_total",
+		Help: "Chamadas ao Clockify que serao repetidas (429, 5xx ou falha de rede).",
+	}, []string{"status"})
+
+	clockifySyncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "clockify_sync_duration_seconds",
+		Help:    "Duracao do sync do Clockify por tenant.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200},
+	}, []string{"tenant_id", "trigger", "result"})
+
+	clockifyEntriesUpserted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "clockify_sync_entries_upserted_total",
+		Help: "Lancamentos gravados pelo sync do Clockify por tenant.",
+	}, []string{"tenant_id", "trigger"})
+
+	clockifyLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "clockify_sync_last_success_timestamp_seconds",
+		Help: "Unix time do ultimo sync do Clockify concluido por tenant.",
+	}, []string{"tenant_id", "trigger"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_rate_limited_total",
+		Help: "Requisicoes recusadas com 429 pelo rate limit, por classe de rota.",
+	}, []string{"class"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpDuration,
+		clockifyRequests,
+		clockifyRetries,
+		clockifySyncDuration,
+		clockifyEntriesUpserted,
+		clockifyLastSuccess,
+		rateLimited,
+	)
+}
+
+// Handler responde o scrape no formato de texto do Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB expoe o sql.DBStats do pool (go_sql_* com db_name).
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveHTTP registra uma requisicao. route deve ser o padrao da rota, nunca
+// o path (cardinalidade).
+func ObserveHTTP(method, route string, status int, d time.Duration) {
+	httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(d.Seconds())
+}
+
+// ClockifyRequest conta uma tentativa de chamada ao Clockify. status 0 e
+// falha de rede.
+func ClockifyRequest(status int, retry bool) {
+	label := "error"
+	if status > 0 {
+		label = strconv.Itoa(status)
+	}
+	clockifyRequests.WithLabelValues(label).Inc()
+	if retry {
+		clockifyRetries.WithLabelValues(label).Inc()
+	}
+}
+
+// ObserveClockifySync registra um sync de tenant. trigger e "manual" ou
+// "auto".
+func ObserveClockifySync(tenantID uint64, trigger string, d time.Duration, upserted int, err error) {
+	tenant := strconv.FormatUint(tenantID, 10)
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	clockifySyncDuration.WithLabelValues(tenant, trigger, result).Observe(d.Seconds())
+	if err != nil {
+		return
+	}
+	clockifyEntriesUpserted.WithLabelValues(tenant, trigger).Add(float64(upserted))
+	clockifyLastSuccess.WithLabelValues(tenant, trigger).SetToCurrentTime()
+}
+
+// RateLimited conta um 429 do rate limit.
+func RateLimited(class string) {
+	rateLimited.WithLabelValues(class).Inc()
+}
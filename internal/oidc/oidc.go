@@ -0,0 +1,305 @@
+// Package oidc implementa o lado cliente (relying party) do OpenID Connect
+// usado no SSO por tenant: discovery, URL de autorizacao com PKCE, troca do
+// code por tokens e validacao do id_token pelo JWKS do provedor.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	providerTTL = time.Hour
+	// tolerancia de relogio na validacao do id_token
+	clockSkew = time.Minute
+)
+
+var ErrInvalidIDToken = errors.New("invalid id_token")
+
+// Provider e o documento de discovery (/.well-known/openid-configuration).
+type Provider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	keys      map[string]any
+	fetchedAt time.Time
+}
+
+// Config identifica o app cadastrado no provedor.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string // default: openid email profile
+}
+
+// Claims sao os campos do id_token usados no login.
+type Claims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+	Name          string `json:"name"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// Client guarda discovery e JWKS por issuer (cache de uma hora; o JWKS e
+// recarregado quando aparece um kid desconhecido).
+type Client struct {
+	HTTP *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*Provider
+}
+
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{HTTP: httpClient, providers: map[string]*Provider{}}
+}
+
+// NewPKCE devolve o code_verifier (guardado no servidor) e o code_challenge S256.
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (c *Client) Discover(ctx context.Context, issuer string) (*Provider, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	c.mu.Lock()
+	p, ok := c.providers[issuer]
+	c.mu.Unlock()
+	if ok && time.Since(p.fetchedAt) < providerTTL {
+		return p, nil
+	}
+
+	var doc Provider
+	if err := c.getJSON(ctx, issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer mismatch %q", doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: incomplete provider metadata")
+	}
+	if err := c.loadKeys(ctx, &doc); err != nil {
+		return nil, err
+	}
+	doc.fetchedAt = time.Now()
+
+	c.mu.Lock()
+	c.providers[issuer] = &doc
+	c.mu.Unlock()
+	return &doc, nil
+}
+
+// AuthCodeURL monta a URL de login no provedor (authorization code + PKCE S256).
+func (c *Client) AuthCodeURL(ctx context.Context, cfg Config, state, nonce, challenge, loginHint string) (string, error) {
+	p, err := c.Discover(ctx, cfg.Issuer)
+	if err != nil {
+		return "", err
+	}
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", cfg.ClientID)
+	q.Set("redirect_uri", cfg.RedirectURI)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+	if loginHint != "" {
+		q.Set("login_hint", loginHint)
+	}
+
+	sep := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange troca o code pelo id_token e devolve as claims ja validadas
+// (assinatura, iss, aud, exp e nonce).
+func (c *Client) Exchange(ctx context.Context, cfg Config, code, verifier, nonce string) (*Claims, error) {
+	p, err := c.Discover(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectURI)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", cfg.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc token: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tok struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil || tok.IDToken == "" {
+		return nil, errors.New("oidc token: missing id_token")
+	}
+	return c.VerifyIDToken(ctx, cfg, tok.IDToken, nonce)
+}
+
+func (c *Client) VerifyIDToken(ctx context.Context, cfg Config, raw, nonce string) (*Claims, error) {
+	p, err := c.Discover(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		return c.keyFor(ctx, p, t)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(p.Issuer),
+		jwt.WithAudience(cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidIDToken)
+	}
+	return claims, nil
+}
+
+func (c *Client) keyFor(ctx context.Context, p *Provider, t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	c.mu.Lock()
+	key, ok := lookupKey(p.keys, kid)
+	c.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	// kid novo: o provedor pode ter rotacionado as chaves
+	if err := c.loadKeys(ctx, p); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	key, ok = lookupKey(p.keys, kid)
+	c.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	return key, nil
+}
+
+// lookupKey aceita token sem kid quando o provedor publica uma unica chave.
+func lookupKey(keys map[string]any, kid string) (any, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k, true
+		}
+	}
+	k, ok := keys[kid]
+	return k, ok
+}
+
+func (c *Client) loadKeys(ctx context.Context, p *Provider) error {
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := c.getJSON(ctx, p.JWKSURI, &set); err != nil {
+		return fmt.Errorf("oidc jwks: %w", err)
+	}
+
+	keys := map[string]any{}
+	for _, raw := range set.Keys {
+		kid, key, err := parseJWK(raw)
+		if err != nil {
+			continue // ignora chaves de tipos nao suportados
+		}
+		keys[kid] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("oidc jwks: no usable keys")
+	}
+
+	c.mu.Lock()
+	p.keys = keys
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Client) getJSON(ctx context.Context, u string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(dst)
+}
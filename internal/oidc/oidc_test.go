@@ -0,0 +1,116 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"saas-api/internal/oidc/oidctest"
+)
+
+func newMockIdP(t *testing.T) (*oidctest.Server, Config) {
+	t.Helper()
+	idp, err := oidctest.New("app", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(idp)
+	t.Cleanup(srv.Close)
+	idp.Issuer = srv.URL
+	idp.DefaultUser = oidctest.User{Subject: "u-1", Email: "ana@acme.com", EmailVerified: true, Name: "Ana"}
+
+	return idp, Config{
+		Issuer:       srv.URL,
+		ClientID:     "app",
+		ClientSecret: "secret",
+		RedirectURI:  "http://api.local/v1/auth/sso/callback",
+	}
+}
+
+// authorize segue a URL de login ate o redirect de volta e devolve o code.
+func authorize(t *testing.T, authURL string) string {
+	t.Helper()
+	noFollow := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noFollow.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status %d", resp.StatusCode)
+	}
+	loc, _ := url.Parse(resp.Header.Get("Location"))
+	return loc.Query().Get("code")
+}
+
+func TestAuthorizationCodeFlowWithPKCE(t *testing.T) {
+	_, cfg := newMockIdP(t)
+	ctx := context.Background()
+	c := NewClient(nil)
+
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := c.AuthCodeURL(ctx, cfg, "st", "n-1", challenge, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := c.Exchange(ctx, cfg, authorize(t, authURL), verifier, "n-1")
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if claims.Subject != "u-1" || claims.Email != "ana@acme.com" || claims.EmailVerified == nil || !*claims.EmailVerified {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	// code de uso unico e verifier errado
+	authURL, _ = c.AuthCodeURL(ctx, cfg, "st", "n-2", challenge, "")
+	code := authorize(t, authURL)
+	if _, err := c.Exchange(ctx, cfg, code, "wrong-verifier", "n-2"); err == nil {
+		t.Fatal("exchange accepted wrong code_verifier")
+	}
+	if _, err := c.Exchange(ctx, cfg, code, verifier, "n-2"); err == nil {
+		t.Fatal("code accepted twice")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp, cfg := newMockIdP(t)
+	ctx := context.Background()
+	c := NewClient(nil)
+	user := idp.DefaultUser
+
+	cases := []struct {
+		name    string
+		cfg     Config
+		nonce   string
+		expires time.Time
+		ok      bool
+	}{
+		{"valid", cfg, "n", time.Now().Add(time.Minute), true},
+		{"nonce mismatch", cfg, "other", time.Now().Add(time.Minute), false},
+		{"expired", cfg, "n", time.Now().Add(-time.Hour), false},
+		{"wrong audience", Config{Issuer: cfg.Issuer, ClientID: "other-app"}, "n", time.Now().Add(time.Minute), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := idp.SignIDToken(user, "n", tc.expires)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = c.VerifyIDToken(ctx, tc.cfg, raw, tc.nonce)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tc.ok && !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("expected ErrInvalidIDToken, got %v", err)
+			}
+		})
+	}
+}
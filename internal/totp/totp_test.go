@@ -0,0 +1,62 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Vetores do apendice B da RFC 6238 (SHA1, 8 digitos).
+func TestHOTPMatchesRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tc := range tests {
+		got := hotp(key, uint64(tc.unix/Period), 8)
+		if got != tc.want {
+			t.Fatalf("unix=%d: expected %s, got %s", tc.unix, tc.want, got)
+		}
+	}
+}
+
+func TestValidateAcceptsSkewAndRejectsOthers(t *testing.T) {
+	secret := b32.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1234567890, 0)
+
+	prev, err := Code(secret, now.Add(-Period*time.Second))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	step, ok := Validate(secret, prev, now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Fatalf("expected previous window to validate, got step=%d ok=%v", step, ok)
+	}
+
+	old, _ := Code(secret, now.Add(-3*Period*time.Second))
+	if _, ok := Validate(secret, old, now, 1); ok {
+		t.Fatal("expected code outside skew to be rejected")
+	}
+	if _, ok := Validate(secret, "12345", now, 1); ok {
+		t.Fatal("expected short code to be rejected")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("saas-api", "ana@example.com", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/saas-api:ana@example.com?") {
+		t.Fatalf("unexpected uri %q", uri)
+	}
+	if !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") || !strings.Contains(uri, "issuer=saas-api") {
+		t.Fatalf("expected secret and issuer in %q", uri)
+	}
+}
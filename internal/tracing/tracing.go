@@ -0,0 +1,159 @@
+// Package tracing configura o OpenTelemetry da API: exportador OTLP/HTTP ou
+// stdout, propagacao W3C (traceparent/baggage) e os spans das queries SQL.
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const TracerName = "saas-api"
+
+type Options struct {
+	// Exporter: none (padrao), stdout ou otlp. O otlp le o destino das
+	// variaveis OTEL_EXPORTER_OTLP_* (ex.: OTEL_EXPORTER_OTLP_ENDPOINT).
+	Exporter    string
+	ServiceName string
+	SampleRatio float64
+	// Writer do exportador stdout (os.Stdout quando nil).
+	Writer io.Writer
+}
+
+// Setup instala o propagador e o TracerProvider global. O shutdown devolvido
+// envia os spans pendentes e deve rodar antes do processo sair.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	var exportOpt sdktrace.TracerProviderOption
+	switch opts.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		w := opts.Writer
+		if w == nil {
+			w = os.Stdout
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, err
+		}
+		// sincrono: o span aparece assim que termina
+		exportOpt = sdktrace.WithSyncer(exp)
+	case "otlp":
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		exportOpt = sdktrace.WithBatcher(exp)
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", opts.Exporter)
+	}
+
+	name := opts.ServiceName
+	if name == "" {
+		name = TracerName
+	}
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", name)))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		exportOpt,
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Tracer e o tracer da API (usa o provider global instalado por Setup).
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// SQLOptions configura o driver instrumentado: um span por statement, filho
+// do span da requisicao e nomeado pelo comando e tabela (ex.: "SELECT
+// employees"). Queries sem contexto com span (jobs sem trace, chamadas sem
+// ctx) nao geram span.
+func SQLOptions() []otelsql.Option {
+	return []otelsql.Option{
+		otelsql.WithAttributes(attribute.String("db.system", "mysql")),
+		otelsql.WithSpanNameFormatter(func(_ context.Context, method otelsql.Method, query string) string {
+			if query == "" {
+				return string(method)
+			}
+			return StatementName(query)
+		}),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+			OmitConnectorConnect: true,
+			SpanFilter: func(ctx context.Context, method otelsql.Method, _ string, args []driver.NamedValue) bool {
+				if !trace.SpanContextFromContext(ctx).IsValid() {
+					return false
+				}
+				// com argumentos o driver do MySQL responde ErrSkip e o
+				// database/sql refaz como prepare + stmt (que gera o span)
+				if (method == otelsql.MethodConnQuery || method == otelsql.MethodConnExec) && len(args) > 0 {
+					return false
+				}
+				return true
+			},
+		}),
+	}
+}
+
+// StatementName resume a query em "COMANDO tabela" para o nome do span.
+func StatementName(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "SQL"
+	}
+	op := strings.ToUpper(fields[0])
+	var after string
+	switch op {
+	case "SELECT", "DELETE":
+		after = "FROM"
+	case "INSERT", "REPLACE":
+		after = "INTO"
+	case "UPDATE":
+		if len(fields) > 1 {
+			return op + " " + tableName(fields[1])
+		}
+		return op
+	default:
+		return op
+	}
+	for i := 1; i < len(fields)-1; i++ {
+		if strings.EqualFold(fields[i], after) {
+			return op + " " + tableName(fields[i+1])
+		}
+	}
+	return op
+}
+
+func tableName(s string) string {
+	if i := strings.IndexAny(s, "(,;"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.Trim(s, "`")
+}
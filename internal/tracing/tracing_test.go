@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStatementName(t *testing.T) {
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT id, name FROM employees WHERE tenant_id=?", "SELECT employees"},
+		{"\n\t\tselect e.id\n\t\tfrom `hr_time_entries` e JOIN employees x ON x.id=e.employee_id", "SELECT hr_time_entries"},
+		{"INSERT INTO audit_logs (tenant_id) VALUES (?)", "INSERT audit_logs"},
+		{"INSERT IGNORE INTO audit_chain_heads(chain_id) VALUES (?)", "INSERT audit_chain_heads"},
+		{"UPDATE payables SET status=? WHERE id=?", "UPDATE payables"},
+		{"DELETE FROM idempotency_keys WHERE expires_at<?", "DELETE idempotency_keys"},
+		{"SELECT 1", "SELECT"},
+		{"BEGIN", "BEGIN"},
+		{"  ", "SQL"},
+	}
+	for _, tc := range cases {
+		if got := StatementName(tc.query); got != tc.want {
+			t.Errorf("StatementName(%q) = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestSetupStdout(t *testing.T) {
+	var buf bytes.Buffer
+	shutdown, err := Setup(context.Background(), Options{Exporter: "stdout", SampleRatio: 1, Writer: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := Tracer().Start(context.Background(), "timebank.summary")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"Name":"timebank.summary"`) {
+		t.Fatalf("span not exported: %s", buf.String())
+	}
+
+	if _, err := Setup(context.Background(), Options{Exporter: "zipkin"}); err == nil {
+		t.Fatal("unknown exporter must fail")
+	}
+}
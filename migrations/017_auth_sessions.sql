@@ -0,0 +1,59 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS auth_sessions (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  tenant_id BIGINT UNSIGNED NOT NULL,
+  user_id BIGINT UNSIGNED NOT NULL,
+  refresh_token_hash CHAR(64) NOT NULL,
+  previous_token_hash CHAR(64) NULL,
+  expires_at DATETIME NOT NULL,
+  last_used_at DATETIME NULL,
+  revoked_at DATETIME NULL,
+  revoked_reason VARCHAR(50) NULL,
+  ip VARCHAR(64) NULL,
+  user_agent VARCHAR(255) NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_auth_sessions_refresh_hash (refresh_token_hash),
+  KEY idx_auth_sessions_previous_hash (previous_token_hash),
+  KEY idx_auth_sessions_tenant_user (tenant_id, user_id),
+  KEY idx_auth_sessions_user (user_id),
+
+  CONSTRAINT fk_auth_sessions_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  CONSTRAINT fk_auth_sessions_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+SET @has_token_version_col := (
+  SELECT COUNT(*)
+  FROM information_schema.COLUMNS
+  WHERE TABLE_SCHEMA = DATABASE()
+    AND TABLE_NAME = 'memberships'
+    AND COLUMN_NAME = 'token_version'
+);
+SET @sql := IF(
+  @has_token_version_col = 0,
+  'ALTER TABLE memberships ADD COLUMN token_version INT UNSIGNED NOT NULL DEFAULT 1 AFTER role',
+  'SELECT 1'
+);
+PREPARE stmt FROM @sql;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- +goose Down
+SET @has_token_version_col := (
+  SELECT COUNT(*)
+  FROM information_schema.COLUMNS
+  WHERE TABLE_SCHEMA = DATABASE()
+    AND TABLE_NAME = 'memberships'
+    AND COLUMN_NAME = 'token_version'
+);
+SET @sql := IF(
+  @has_token_version_col = 1,
+  'ALTER TABLE memberships DROP COLUMN token_version',
+  'SELECT 1'
+);
+PREPARE stmt FROM @sql;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+DROP TABLE IF EXISTS auth_sessions;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS invitations (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  tenant_id BIGINT UNSIGNED NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  name VARCHAR(255) NULL,
+  role VARCHAR(20) NOT NULL,
+  employee_id BIGINT UNSIGNED NULL,
+  token_hash CHAR(64) NOT NULL,
+  status ENUM('pending','accepted','revoked') NOT NULL DEFAULT 'pending',
+  invited_by BIGINT UNSIGNED NOT NULL,
+  expires_at DATETIME NOT NULL,
+  last_sent_at DATETIME NOT NULL,
+  send_count INT UNSIGNED NOT NULL DEFAULT 1,
+  accepted_at DATETIME NULL,
+  accepted_user_id BIGINT UNSIGNED NULL,
+  revoked_at DATETIME NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_invitations_token_hash (token_hash),
+  KEY idx_invitations_tenant_status (tenant_id, status),
+  KEY idx_invitations_tenant_email (tenant_id, email),
+
+  CONSTRAINT fk_invitations_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  CONSTRAINT fk_invitations_employee FOREIGN KEY (employee_id) REFERENCES employees(id),
+  CONSTRAINT fk_invitations_invited_by FOREIGN KEY (invited_by) REFERENCES users(id),
+  CONSTRAINT fk_invitations_accepted_user FOREIGN KEY (accepted_user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS invitations;
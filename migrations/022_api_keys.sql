@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS api_keys (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  tenant_id BIGINT UNSIGNED NOT NULL,
+  name VARCHAR(120) NOT NULL,
+  prefix VARCHAR(32) NOT NULL,
+  key_hash CHAR(64) NOT NULL,
+  scopes_json JSON NOT NULL,
+  created_by BIGINT UNSIGNED NOT NULL,
+  expires_at DATETIME NULL,
+  last_used_at DATETIME NULL,
+  last_used_ip VARCHAR(64) NULL,
+  revoked_at DATETIME NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_api_keys_key_hash (key_hash),
+  KEY idx_api_keys_tenant (tenant_id),
+
+  CONSTRAINT fk_api_keys_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  CONSTRAINT fk_api_keys_created_by FOREIGN KEY (created_by) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
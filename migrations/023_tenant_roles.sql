@@ -0,0 +1,28 @@
+-- +goose Up
+-- Roles customizados do tenant. Os roles embutidos (owner, hr, finance,
+-- colaborador) tem conjuntos de permissoes fixos no codigo.
+CREATE TABLE IF NOT EXISTS tenant_roles (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  tenant_id BIGINT UNSIGNED NOT NULL,
+  role_key VARCHAR(40) NOT NULL,
+  name VARCHAR(120) NOT NULL,
+  description VARCHAR(500) NULL,
+  permissions_json JSON NOT NULL,
+  created_by BIGINT UNSIGNED NOT NULL,
+  updated_by BIGINT UNSIGNED NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_tenant_roles_key (tenant_id, role_key),
+
+  CONSTRAINT fk_tenant_roles_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  CONSTRAINT fk_tenant_roles_created_by FOREIGN KEY (created_by) REFERENCES users(id),
+  CONSTRAINT fk_tenant_roles_updated_by FOREIGN KEY (updated_by) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- convites podem usar roles customizados
+ALTER TABLE invitations MODIFY role VARCHAR(50) NOT NULL;
+
+-- +goose Down
+ALTER TABLE invitations MODIFY role VARCHAR(20) NOT NULL;
+DROP TABLE IF EXISTS tenant_roles;
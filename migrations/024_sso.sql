@@ -0,0 +1,60 @@
+-- +goose Up
+-- Configuracao OIDC por tenant (um provedor por tenant).
+CREATE TABLE IF NOT EXISTS tenant_sso_configs (
+  tenant_id BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+  issuer VARCHAR(255) NOT NULL,
+  client_id VARCHAR(255) NOT NULL,
+  client_secret VARCHAR(500) NULL,
+  allowed_domains_json JSON NOT NULL,
+  default_role VARCHAR(50) NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  updated_by BIGINT UNSIGNED NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  CONSTRAINT fk_tenant_sso_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  CONSTRAINT fk_tenant_sso_updated_by FOREIGN KEY (updated_by) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Identidade externa (issuer + sub) ligada a um usuario local.
+CREATE TABLE IF NOT EXISTS user_identities (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  user_id BIGINT UNSIGNED NOT NULL,
+  issuer VARCHAR(255) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  last_login_at DATETIME NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_user_identities_issuer_subject (issuer, subject),
+  KEY idx_user_identities_user (user_id),
+  CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Estado do login em andamento: state/nonce/PKCE ate o callback e depois o
+-- codigo de uso unico que o frontend troca pela sessao.
+CREATE TABLE IF NOT EXISTS sso_login_states (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  tenant_id BIGINT UNSIGNED NOT NULL,
+  state_hash CHAR(64) NOT NULL,
+  nonce VARCHAR(64) NOT NULL,
+  code_verifier VARCHAR(128) NOT NULL,
+  expires_at DATETIME NOT NULL,
+  callback_at DATETIME NULL,
+  user_id BIGINT UNSIGNED NULL,
+  login_code_hash CHAR(64) NULL,
+  login_code_expires_at DATETIME NULL,
+  used_at DATETIME NULL,
+  ip VARCHAR(64) NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_sso_login_states_state (state_hash),
+  UNIQUE KEY uq_sso_login_states_code (login_code_hash),
+  CONSTRAINT fk_sso_login_states_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  CONSTRAINT fk_sso_login_states_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS sso_login_states;
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS tenant_sso_configs;
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Administradores da plataforma (suporte), fora das memberships dos tenants.
+-- Cadastro manual: INSERT INTO platform_admins (user_id) VALUES (?).
+CREATE TABLE IF NOT EXISTS platform_admins (
+  user_id BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT fk_platform_admins_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS impersonation_sessions (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  admin_user_id BIGINT UNSIGNED NOT NULL,
+  tenant_id BIGINT UNSIGNED NOT NULL,
+  user_id BIGINT UNSIGNED NOT NULL,
+  reason VARCHAR(500) NOT NULL,
+  read_only BOOLEAN NOT NULL DEFAULT TRUE,
+  expires_at DATETIME NOT NULL,
+  ended_at DATETIME NULL,
+  ip VARCHAR(64) NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  KEY idx_impersonation_admin (admin_user_id, created_at),
+  KEY idx_impersonation_tenant (tenant_id, created_at),
+  CONSTRAINT fk_impersonation_admin FOREIGN KEY (admin_user_id) REFERENCES users(id),
+  CONSTRAINT fk_impersonation_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  CONSTRAINT fk_impersonation_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- acoes feitas sob impersonation: user_id e o usuario impersonado, actor_user_id o admin
+ALTER TABLE audit_logs
+  ADD COLUMN actor_user_id BIGINT UNSIGNED NULL AFTER user_id,
+  ADD COLUMN impersonation_id BIGINT UNSIGNED NULL AFTER actor_user_id,
+  ADD KEY idx_audit_impersonation (impersonation_id);
+
+-- +goose Down
+ALTER TABLE audit_logs
+  DROP KEY idx_audit_impersonation,
+  DROP COLUMN impersonation_id,
+  DROP COLUMN actor_user_id;
+DROP TABLE IF EXISTS impersonation_sessions;
+DROP TABLE IF EXISTS platform_admins;
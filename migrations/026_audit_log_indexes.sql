@@ -0,0 +1,6 @@
+-- +goose Up
+-- consultas de /v1/audit-logs por entidade (historico de um registro)
+ALTER TABLE audit_logs ADD KEY idx_audit_tenant_entity (tenant_id, entity, entity_id);
+
+-- +goose Down
+ALTER TABLE audit_logs DROP KEY idx_audit_tenant_entity;
@@ -0,0 +1,48 @@
+-- +goose Up
+-- Cadeia de hashes por tenant em audit_logs (chain_id = tenant_id, 0 = plataforma).
+-- Linhas antigas ficam com chain_seq NULL e aparecem como "unsealed" na verificacao.
+ALTER TABLE audit_logs
+  ADD COLUMN chain_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  ADD COLUMN chain_seq BIGINT UNSIGNED NULL,
+  ADD COLUMN prev_hash CHAR(64) NULL,
+  ADD COLUMN row_hash CHAR(64) NULL,
+  ADD UNIQUE KEY uq_audit_chain_seq (chain_id, chain_seq),
+  ADD KEY idx_audit_chain_id (chain_id, id);
+
+UPDATE audit_logs SET chain_id = COALESCE(tenant_id, 0);
+
+CREATE TABLE IF NOT EXISTS audit_chain_heads (
+  chain_id BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+  last_seq BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  last_hash CHAR(64) NOT NULL DEFAULT '',
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- append-only: o usuario da aplicacao precisa do privilegio TRIGGER para criar
+-- +goose StatementBegin
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs
+FOR EACH ROW
+BEGIN
+  SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER audit_logs_no_delete BEFORE DELETE ON audit_logs
+FOR EACH ROW
+BEGIN
+  SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS audit_logs_no_delete;
+DROP TRIGGER IF EXISTS audit_logs_no_update;
+DROP TABLE IF EXISTS audit_chain_heads;
+ALTER TABLE audit_logs
+  DROP KEY idx_audit_chain_id,
+  DROP KEY uq_audit_chain_seq,
+  DROP COLUMN row_hash,
+  DROP COLUMN prev_hash,
+  DROP COLUMN chain_seq,
+  DROP COLUMN chain_id;
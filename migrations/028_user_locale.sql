@@ -0,0 +1,6 @@
+-- +goose Up
+-- idioma preferido do usuario (pt-BR, en, es); NULL segue o Accept-Language
+ALTER TABLE users ADD COLUMN locale VARCHAR(10) NULL AFTER name;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN locale;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- ordenacoes por keyset das listagens (?sort=); o id entra no fim do indice (InnoDB)
+ALTER TABLE payables
+  ADD KEY idx_payable_tenant_amount (tenant_id, amount_cents),
+  ADD KEY idx_payable_tenant_created (tenant_id, created_at);
+
+ALTER TABLE receivables
+  ADD KEY idx_rec_tenant_amount (tenant_id, amount_cents),
+  ADD KEY idx_rec_tenant_created (tenant_id, created_at);
+
+ALTER TABLE employees
+  ADD KEY idx_emp_tenant_name (tenant_id, name),
+  ADD KEY idx_emp_tenant_created (tenant_id, created_at);
+
+-- +goose Down
+ALTER TABLE employees
+  DROP KEY idx_emp_tenant_created,
+  DROP KEY idx_emp_tenant_name;
+
+ALTER TABLE receivables
+  DROP KEY idx_rec_tenant_created,
+  DROP KEY idx_rec_tenant_amount;
+
+ALTER TABLE payables
+  DROP KEY idx_payable_tenant_created,
+  DROP KEY idx_payable_tenant_amount;
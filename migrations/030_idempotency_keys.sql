@@ -0,0 +1,25 @@
+-- +goose Up
+-- respostas guardadas por Idempotency-Key (tenant + usuario + metodo + rota + key)
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  scope_hash CHAR(64) NOT NULL,
+  tenant_id BIGINT UNSIGNED NOT NULL,
+  user_id BIGINT UNSIGNED NOT NULL,
+  method VARCHAR(10) NOT NULL,
+  path VARCHAR(255) NOT NULL,
+  idem_key VARCHAR(255) NOT NULL,
+  request_hash CHAR(64) NOT NULL,
+  status_code SMALLINT UNSIGNED NULL, -- NULL enquanto a primeira requisicao roda
+  headers_json JSON NULL,
+  response_body MEDIUMBLOB NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at DATETIME NOT NULL,
+
+  UNIQUE KEY uq_idem_scope (scope_hash),
+  KEY idx_idem_expires (expires_at),
+
+  CONSTRAINT fk_idem_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
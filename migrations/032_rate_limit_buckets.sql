@@ -0,0 +1,12 @@
+-- +goose Up
+-- token buckets do rate limit compartilhados entre instancias (RATE_LIMIT_STORE=mysql)
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+  bucket_key VARCHAR(128) NOT NULL PRIMARY KEY, -- classe:tenant[:usuario|api key]
+  tokens DOUBLE NOT NULL,
+  updated_at DATETIME(6) NOT NULL,
+
+  KEY idx_rate_limit_updated (updated_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS rate_limit_buckets;
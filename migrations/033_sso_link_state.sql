@@ -0,0 +1,11 @@
+-- +goose Up
+-- login SSO iniciado por um usuario autenticado para vincular a identidade do
+-- provedor a conta dele (conta existente fora do tenant nao e vinculada pelo email)
+ALTER TABLE sso_login_states
+  ADD COLUMN link_user_id BIGINT UNSIGNED NULL AFTER code_verifier,
+  ADD CONSTRAINT fk_sso_login_states_link_user FOREIGN KEY (link_user_id) REFERENCES users(id);
+
+-- +goose Down
+ALTER TABLE sso_login_states
+  DROP FOREIGN KEY fk_sso_login_states_link_user,
+  DROP COLUMN link_user_id;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- pedidos de redefinicao de senha contam por email e por IP na mesma tabela
+ALTER TABLE login_throttles
+  MODIFY scope ENUM('email','ip','reset_email','reset_ip') NOT NULL;
+
+-- +goose Down
+DELETE FROM login_throttles WHERE scope IN ('reset_email','reset_ip');
+ALTER TABLE login_throttles
+  MODIFY scope ENUM('email','ip') NOT NULL;
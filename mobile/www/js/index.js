@@ -11,7 +11,9 @@
   var state = {
     apiUrl: "",
     token: "",
-    role: ""
+    role: "",
+    // Idempotency-Key da batida que falhou por rede; o proximo toque reenvia a mesma
+    pendingKeys: {}
   };
 
   var ui = {
@@ -102,6 +104,9 @@
     if (auth && state.token) {
       headers.Authorization = "Bearer " + state.token;
     }
+    if (options.idempotencyKey) {
+      headers["Idempotency-Key"] = options.idempotencyKey;
+    }
 
     var response = await fetch(state.apiUrl + path, {
       method: method,
@@ -118,7 +123,7 @@
     }
 
     if (!response.ok) {
-      var message = (data && (data.error || data.message)) || text || "Erro na API";
+      var message = (data && (data.detail || data.error || data.message)) || text || "Erro na API";
       throw new Error(message);
     }
 
@@ -234,11 +239,34 @@
     }
   }
 
+  function newIdempotencyKey() {
+    if (window.crypto && window.crypto.randomUUID) {
+      return window.crypto.randomUUID();
+    }
+    return Date.now().toString(36) + "-" + Math.random().toString(36).slice(2);
+  }
+
+  // Envia a batida com Idempotency-Key. Se a resposta nao chegar (falha de
+  // rede), a key e mantida e o proximo toque repete a mesma requisicao.
+  async function punch(path) {
+    var key = state.pendingKeys[path] || newIdempotencyKey();
+    state.pendingKeys[path] = key;
+    try {
+      await api(path, { method: "POST", idempotencyKey: key });
+      delete state.pendingKeys[path];
+    } catch (error) {
+      if (!(error instanceof TypeError)) {
+        delete state.pendingKeys[path];
+      }
+      throw error;
+    }
+  }
+
   async function handleClockIn() {
     setError(ui.clockError, "");
     ui.clockInButton.disabled = true;
     try {
-      await api("/time-entries/clock-in", { method: "POST" });
+      await punch("/time-entries/clock-in");
       await refreshClock();
     } catch (error) {
       setError(ui.clockError, translateError(error));
@@ -250,7 +278,7 @@
     setError(ui.clockError, "");
     ui.clockOutButton.disabled = true;
     try {
-      await api("/time-entries/clock-out", { method: "POST" });
+      await punch("/time-entries/clock-out");
       await refreshClock();
     } catch (error) {
       setError(ui.clockError, translateError(error));
@@ -1,5 +1,5 @@
 ﻿import React, { createContext, useCallback, useContext, useEffect, useMemo, useState } from "react";
-import { apiFetch, type ApiOptions, type AuthResponse, type UserRole } from "./api";
+import { apiFetch, apiFetchAll, type ApiOptions, type AuthResponse, type UserRole } from "./api";
 import { useLocalStorage } from "../hooks/useLocalStorage";
 
 // fallback para produção se a env não vier setada no deploy
@@ -20,6 +20,7 @@ interface ApiContextValue {
   setToken: (v: string) => void;
   me: MeInfo | null;
   request: <T>(path: string, options?: ApiOptions) => Promise<T>;
+  requestAll: <T>(path: string) => Promise<T[]>;
   login: (email: string, password: string) => Promise<AuthResponse>;
   register: (payload: { company_name: string; name: string; email: string; password: string; }) => Promise<AuthResponse>;
   logout: () => void;
@@ -36,6 +37,7 @@ export function ApiProvider({ children }: { children: React.ReactNode }) {
   const config = useMemo(() => ({ baseUrl, token, onUnauthorized: () => setToken("") }), [baseUrl, token, setToken]);
 
   const request = useCallback(<T,>(path: string, options: ApiOptions = {}) => apiFetch<T>(config, path, options), [config]);
+  const requestAll = useCallback(<T,>(path: string) => apiFetchAll<T>(config, path), [config]);
 
   const refreshMe = useCallback(async () => {
     if (!token) { setMe(null); return; }
@@ -83,6 +85,7 @@ export function ApiProvider({ children }: { children: React.ReactNode }) {
     setToken,
     me,
     request,
+    requestAll,
     login,
     register,
     logout,
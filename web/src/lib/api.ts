@@ -355,18 +355,33 @@ export interface FinanceSummary {
   };
 }
 
+export type ApiFieldError = {
+  field: string;
+  code: string;
+  message: string;
+};
+
+// Erros da API seguem a RFC 7807 (application/problem+json): use `code` para
+// decidir o comportamento e `detail` so para exibir.
 export class ApiError extends Error {
   status: number;
   data: any;
+  code: string;
+  fieldErrors: ApiFieldError[];
+  requestId?: string;
   constructor(status: number, data: any) {
     super(translateApiMessage(status, data));
     this.status = status;
     this.data = data;
+    this.code = typeof data?.code === "string" ? data.code : "";
+    this.fieldErrors = Array.isArray(data?.errors) ? data.errors : [];
+    this.requestId = typeof data?.request_id === "string" ? data.request_id : undefined;
   }
 }
 
 function readRawMessage(data: any) {
   if (typeof data === "string") return data;
+  if (typeof data?.detail === "string") return data.detail;
   if (typeof data?.error === "string") return data.error;
   if (typeof data?.message === "string") return data.message;
   return "";
@@ -432,6 +447,7 @@ function translateApiMessage(status: number, data: any) {
     [/user already linked to another employee/i, "Este usuario ja esta vinculado a outro colaborador."],
     [/could not link employee account/i, "Nao foi possivel vincular o colaborador ao usuario."],
     [/unknown field/i, "Campo nao permitido no corpo da requisicao."],
+    [/resource was modified by another request/i, "O registro foi alterado por outra pessoa. Recarregue e tente novamente."],
     [/invalid character|cannot unmarshal/i, "JSON invalido no corpo da requisicao."],
     [/could not create/i, "Nao foi possivel concluir a criacao. Verifique os dados enviados."],
   ];
@@ -445,6 +461,14 @@ function translateApiMessage(status: number, data: any) {
   return "Nao foi possivel concluir a requisicao.";
 }
 
+// Le a mensagem de erro de respostas fora do apiFetch (ex.: downloads de PDF).
+export async function readProblemMessage(res: Response, fallback: string) {
+  const text = await res.text();
+  let data: any = text;
+  try { data = text ? JSON.parse(text) : null; } catch { /* texto puro */ }
+  return (readRawMessage(data) || fallback).trim();
+}
+
 export type ApiConfig = {
   baseUrl: string;
   token?: string;
@@ -455,13 +479,20 @@ export type ApiOptions = {
   method?: string;
   body?: any;
   auth?: boolean;
+  headers?: Record<string, string>;
 };
 
-export async function apiFetch<T>(config: ApiConfig, path: string, options: ApiOptions = {}): Promise<T> {
+// ETag de employees/payables/receivables: o updated_at entre aspas. Vai no
+// If-Match dos PATCH; se outra pessoa alterou antes, a API responde 412.
+export function etagOf(item: { updated_at?: string }) {
+  return item.updated_at ? `"${item.updated_at}"` : "*";
+}
+
+async function apiSend(config: ApiConfig, path: string, options: ApiOptions = {}) {
   const { method = "GET", body, auth = true } = options;
   const urlBase = config.baseUrl.replace(/\/$/, "");
   const url = path.startsWith("http") ? path : `${urlBase}/${path.replace(/^\//, "")}`;
-  const headers: Record<string, string> = { "Content-Type": "application/json" };
+  const headers: Record<string, string> = { "Content-Type": "application/json", ...options.headers };
   if (auth && config.token) headers["Authorization"] = `Bearer ${config.token}`;
 
   const res = await fetch(url, { method, headers, body: body ? JSON.stringify(body) : undefined });
@@ -473,9 +504,33 @@ export async function apiFetch<T>(config: ApiConfig, path: string, options: ApiO
     if (res.status === 401 && config.onUnauthorized) config.onUnauthorized();
     throw new ApiError(res.status, data);
   }
+  return { url, res, data };
+}
+
+export async function apiFetch<T>(config: ApiConfig, path: string, options: ApiOptions = {}): Promise<T> {
+  const { data } = await apiSend(config, path, options);
   return data as T;
 }
 
+// Le o rel="next" do header Link das listagens paginadas.
+function nextLink(res: Response, url: string) {
+  const link = res.headers.get("Link") || "";
+  const match = link.match(/<([^>]+)>\s*;\s*rel="?next"?/);
+  return match ? new URL(match[1], url).toString() : "";
+}
+
+// Busca todas as paginas de uma listagem seguindo o Link rel="next".
+export async function apiFetchAll<T>(config: ApiConfig, path: string): Promise<T[]> {
+  const items: T[] = [];
+  let next = path;
+  while (next) {
+    const { url, res, data } = await apiSend(config, next);
+    if (Array.isArray(data)) items.push(...data);
+    next = nextLink(res, url);
+  }
+  return items;
+}
+
 
 
 
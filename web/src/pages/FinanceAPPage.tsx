@@ -1,6 +1,6 @@
 import { FormEvent, useEffect, useMemo, useState } from "react";
 import { useApi } from "../lib/api-provider";
-import { Payable, Vendor, CostCenter } from "../lib/api";
+import { Payable, Vendor, CostCenter, etagOf } from "../lib/api";
 import { useToast } from "../components/toast";
 import { Card, CardDescription, CardHeader, CardTitle } from "../components/ui/card";
 import { Input } from "../components/ui/input";
@@ -24,7 +24,7 @@ const statusColors: Record<string, "default" | "success" | "warning" | "outline"
 };
 
 export function FinanceAPPage() {
-  const { request } = useApi();
+  const { request, requestAll } = useApi();
   const { toast } = useToast();
   const [vendors, setVendors] = useState<Vendor[]>([]);
   const [payables, setPayables] = useState<Payable[]>([]);
@@ -50,8 +50,8 @@ export function FinanceAPPage() {
   const loadAll = async () => {
     try {
       const [v, p, cc] = await Promise.all([
-        request<Vendor[]>("/vendors"),
-        request<Payable[]>(`/payables${statusFilter ? `?status=${statusFilter}` : ""}`),
+        requestAll<Vendor>("/vendors"),
+        requestAll<Payable>(`/payables${statusFilter ? `?status=${statusFilter}` : ""}`),
         request<CostCenter[]>("/cost-centers"),
       ]);
       setVendors(v); setPayables(p); setCostCenters(cc);
@@ -115,7 +115,11 @@ export function FinanceAPPage() {
 
   const updateCostCenter = async (payable: Payable, ccId: string) => {
     try {
-      await request(`/payables/${payable.id}`, { method: "PATCH", body: { cost_center_id: ccId ? Number(ccId) : null } });
+      await request(`/payables/${payable.id}`, {
+        method: "PATCH",
+        headers: { "If-Match": etagOf(payable) },
+        body: { cost_center_id: ccId ? Number(ccId) : null },
+      });
       toast({ title: "Centro de custo salvo", variant: "success" });
       loadAll();
     } catch (err: any) { toast({ title: "Erro", description: err.message, variant: "error" }); }
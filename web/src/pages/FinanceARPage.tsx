@@ -1,6 +1,6 @@
 import { FormEvent, useEffect, useMemo, useState } from "react";
 import { useApi } from "../lib/api-provider";
-import { Customer, Receivable, CostCenter } from "../lib/api";
+import { Customer, Receivable, CostCenter, etagOf } from "../lib/api";
 import { useToast } from "../components/toast";
 import { Card, CardDescription, CardHeader, CardTitle } from "../components/ui/card";
 import { Input } from "../components/ui/input";
@@ -22,7 +22,7 @@ const statusColor: Record<string, "default" | "success" | "warning" | "outline">
 };
 
 export function FinanceARPage() {
-  const { request } = useApi();
+  const { request, requestAll } = useApi();
   const { toast } = useToast();
   const [customers, setCustomers] = useState<Customer[]>([]);
   const [receivables, setReceivables] = useState<Receivable[]>([]);
@@ -48,8 +48,8 @@ export function FinanceARPage() {
   const loadAll = async () => {
     try {
       const [c, r, cc] = await Promise.all([
-        request<Customer[]>("/customers"),
-        request<Receivable[]>(`/receivables${statusFilter ? `?status=${statusFilter}` : ""}`),
+        requestAll<Customer>("/customers"),
+        requestAll<Receivable>(`/receivables${statusFilter ? `?status=${statusFilter}` : ""}`),
         request<CostCenter[]>("/cost-centers"),
       ]);
       setCustomers(c); setReceivables(r); setCostCenters(cc);
@@ -113,7 +113,11 @@ export function FinanceARPage() {
 
   const updateCostCenter = async (rec: Receivable, ccId: string) => {
     try {
-      await request(`/receivables/${rec.id}`, { method: "PATCH", body: { cost_center_id: ccId ? Number(ccId) : null } });
+      await request(`/receivables/${rec.id}`, {
+        method: "PATCH",
+        headers: { "If-Match": etagOf(rec) },
+        body: { cost_center_id: ccId ? Number(ccId) : null },
+      });
       toast({ title: "Centro de custo salvo", variant: "success" });
       loadAll();
     } catch (err: any) { toast({ title: "Erro", description: err.message, variant: "error" }); }
@@ -12,7 +12,9 @@ import {
   EmployeeCompensation,
   EmployeeDocument,
   HRTimeEntry,
+  etagOf,
   Location,
+  readProblemMessage,
   Position,
   Team,
   TimeBankAdjustment,
@@ -73,7 +75,7 @@ const defaultTimeBankSummary: TimeBankSummary = {
 };
 
 export function HRPage() {
-  const { request, me, baseUrl, token } = useApi();
+  const { request, requestAll, me, baseUrl, token } = useApi();
   const { toast } = useToast();
   const [searchParams, setSearchParams] = useSearchParams();
 
@@ -171,7 +173,7 @@ export function HRPage() {
 
   const loadEmployees = async () => {
     try {
-      const e = await request<Employee[]>("/employees");
+      const e = await requestAll<Employee>("/employees");
       setEmployees(toArray(e));
     } catch (err: any) {
       toast({ title: "Erro ao carregar colaboradores", description: err.message, variant: "error" });
@@ -435,8 +437,7 @@ export function HRPage() {
         headers: { Authorization: `Bearer ${token}` },
       });
       if (!res.ok) {
-        const message = await res.text();
-        throw new Error((message || "Falha ao exportar PDF").trim());
+        throw new Error(await readProblemMessage(res, "Falha ao exportar PDF"));
       }
 
       const blob = await res.blob();
@@ -486,8 +487,7 @@ export function HRPage() {
         headers: { Authorization: `Bearer ${token}` },
       });
       if (!res.ok) {
-        const message = await res.text();
-        throw new Error((message || "Falha ao exportar cartao").trim());
+        throw new Error(await readProblemMessage(res, "Falha ao exportar cartao"));
       }
       const blob = await res.blob();
       const url = URL.createObjectURL(blob);
@@ -666,6 +666,7 @@ export function HRPage() {
     try {
       await request(`/employees/${selectedEmployee.id}`, {
         method: "PATCH",
+        headers: { "If-Match": etagOf(selectedEmployee) },
         body: {
           name: fd.get("name") || undefined,
           email: fd.get("email") || null,
@@ -15,13 +15,13 @@ import { PageHeader } from "../components/page-header";
 const roleOptions: UserRole[] = ["owner", "hr", "finance"];
 
 export function MembersPage() {
-  const { request } = useApi();
+  const { request, requestAll } = useApi();
   const { toast } = useToast();
   const [members, setMembers] = useState<Member[]>([]);
 
   const load = async () => {
     try {
-      const res = await request<Member[]>("/members");
+      const res = await requestAll<Member>("/members");
       setMembers(res);
     } catch (err: any) {
       toast({ title: "Erro ao carregar membros", description: err.message, variant: "error" });